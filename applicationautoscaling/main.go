@@ -0,0 +1,19 @@
+package applicationautoscaling
+
+import (
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/applicationautoscaling"
+	"github.com/aws/aws-sdk-go/service/applicationautoscaling/applicationautoscalingiface"
+)
+
+// SDKClient implements access to Application Auto Scaling via the AWS SDK.
+type SDKClient struct {
+	client applicationautoscalingiface.ApplicationAutoScalingAPI
+}
+
+// New returns an SDKClient configured with the given session.
+func New(sess *session.Session) SDKClient {
+	return SDKClient{
+		client: applicationautoscaling.New(sess),
+	}
+}
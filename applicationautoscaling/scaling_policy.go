@@ -0,0 +1,78 @@
+package applicationautoscaling
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsaas "github.com/aws/aws-sdk-go/service/applicationautoscaling"
+)
+
+// Predefined metrics a service's task count can track.
+const (
+	MetricCPU    = awsaas.MetricTypeEcsserviceAverageCpuutilization
+	MetricMemory = awsaas.MetricTypeEcsserviceAverageMemoryUtilization
+)
+
+// TargetTrackingPolicy scales a service's task count to keep a metric near a target value.
+type TargetTrackingPolicy struct {
+	Name        string
+	Metric      string
+	TargetValue float64
+}
+
+// PutServiceTargetTrackingPolicy creates or replaces a target tracking scaling policy on a service.
+func (aas SDKClient) PutServiceTargetTrackingPolicy(resourceID string, policy TargetTrackingPolicy) error {
+	_, err := aas.client.PutScalingPolicy(
+		&awsaas.PutScalingPolicyInput{
+			PolicyName:        aws.String(policy.Name),
+			PolicyType:        aws.String(awsaas.PolicyTypeTargetTrackingScaling),
+			ResourceId:        aws.String(resourceID),
+			ScalableDimension: aws.String(awsaas.ScalableDimensionEcsServiceDesiredCount),
+			ServiceNamespace:  aws.String(awsaas.ServiceNamespaceEcs),
+			TargetTrackingScalingPolicyConfiguration: &awsaas.TargetTrackingScalingPolicyConfiguration{
+				PredefinedMetricSpecification: &awsaas.PredefinedMetricSpecification{
+					PredefinedMetricType: aws.String(policy.Metric),
+				},
+				TargetValue: aws.Float64(policy.TargetValue),
+			},
+		},
+	)
+
+	return err
+}
+
+// DeleteServiceScalingPolicy deletes a scaling policy from a service. Deleting a policy that
+// doesn't exist is not an error.
+func (aas SDKClient) DeleteServiceScalingPolicy(resourceID, policyName string) error {
+	_, err := aas.client.DeleteScalingPolicy(
+		&awsaas.DeleteScalingPolicyInput{
+			PolicyName:        aws.String(policyName),
+			ResourceId:        aws.String(resourceID),
+			ScalableDimension: aws.String(awsaas.ScalableDimensionEcsServiceDesiredCount),
+			ServiceNamespace:  aws.String(awsaas.ServiceNamespaceEcs),
+		},
+	)
+
+	return ignoreNotFound(err)
+}
+
+// DeregisterServiceScalableTarget stops scaling a service, which also deletes its scaling
+// policies and scheduled actions. A service that isn't registered is not an error.
+func (aas SDKClient) DeregisterServiceScalableTarget(resourceID string) error {
+	_, err := aas.client.DeregisterScalableTarget(
+		&awsaas.DeregisterScalableTargetInput{
+			ResourceId:        aws.String(resourceID),
+			ScalableDimension: aws.String(awsaas.ScalableDimensionEcsServiceDesiredCount),
+			ServiceNamespace:  aws.String(awsaas.ServiceNamespaceEcs),
+		},
+	)
+
+	return ignoreNotFound(err)
+}
+
+func ignoreNotFound(err error) error {
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == awsaas.ErrCodeObjectNotFoundException {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,96 @@
+package applicationautoscaling
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsaas "github.com/aws/aws-sdk-go/service/applicationautoscaling"
+	"github.com/aws/aws-sdk-go/service/applicationautoscaling/applicationautoscalingiface"
+)
+
+type mockScalingPolicyAPI struct {
+	applicationautoscalingiface.ApplicationAutoScalingAPI
+	err             error
+	policyInput     *awsaas.PutScalingPolicyInput
+	deleteInput     *awsaas.DeleteScalingPolicyInput
+	deregisterInput *awsaas.DeregisterScalableTargetInput
+}
+
+func (m *mockScalingPolicyAPI) PutScalingPolicy(i *awsaas.PutScalingPolicyInput) (*awsaas.PutScalingPolicyOutput, error) {
+	m.policyInput = i
+
+	return &awsaas.PutScalingPolicyOutput{}, m.err
+}
+
+func (m *mockScalingPolicyAPI) DeleteScalingPolicy(i *awsaas.DeleteScalingPolicyInput) (*awsaas.DeleteScalingPolicyOutput, error) {
+	m.deleteInput = i
+
+	return &awsaas.DeleteScalingPolicyOutput{}, m.err
+}
+
+func (m *mockScalingPolicyAPI) DeregisterScalableTarget(i *awsaas.DeregisterScalableTargetInput) (*awsaas.DeregisterScalableTargetOutput, error) {
+	m.deregisterInput = i
+
+	return &awsaas.DeregisterScalableTargetOutput{}, m.err
+}
+
+func TestPutServiceTargetTrackingPolicy(t *testing.T) {
+	mockAAS := &mockScalingPolicyAPI{}
+	aas := SDKClient{client: mockAAS}
+	policy := TargetTrackingPolicy{
+		Name:        "fargate-web-cpu",
+		Metric:      MetricCPU,
+		TargetValue: 60,
+	}
+
+	if err := aas.PutServiceTargetTrackingPolicy("service/my-cluster/web", policy); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	input := mockAAS.policyInput
+
+	if aws.StringValue(input.PolicyType) != "TargetTrackingScaling" {
+		t.Errorf("expected TargetTrackingScaling, got %s", aws.StringValue(input.PolicyType))
+	}
+
+	config := input.TargetTrackingScalingPolicyConfiguration
+
+	if metric := aws.StringValue(config.PredefinedMetricSpecification.PredefinedMetricType); metric != "ECSServiceAverageCPUUtilization" {
+		t.Errorf("expected ECSServiceAverageCPUUtilization, got %s", metric)
+	}
+
+	if aws.Float64Value(config.TargetValue) != 60 {
+		t.Errorf("expected target 60, got %f", aws.Float64Value(config.TargetValue))
+	}
+}
+
+func TestDeleteServiceScalingPolicyNotFound(t *testing.T) {
+	aas := SDKClient{client: &mockScalingPolicyAPI{err: awserr.New(awsaas.ErrCodeObjectNotFoundException, "not found", nil)}}
+
+	if err := aas.DeleteServiceScalingPolicy("service/my-cluster/web", "fargate-web-memory"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestDeregisterServiceScalableTarget(t *testing.T) {
+	mockAAS := &mockScalingPolicyAPI{}
+	aas := SDKClient{client: mockAAS}
+
+	if err := aas.DeregisterServiceScalableTarget("service/my-cluster/web"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if id := aws.StringValue(mockAAS.deregisterInput.ResourceId); id != "service/my-cluster/web" {
+		t.Errorf("expected service/my-cluster/web, got %s", id)
+	}
+}
+
+func TestDeregisterServiceScalableTargetError(t *testing.T) {
+	aas := SDKClient{client: &mockScalingPolicyAPI{err: errors.New("boom")}}
+
+	if err := aas.DeregisterServiceScalableTarget("service/my-cluster/web"); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
@@ -0,0 +1,57 @@
+package applicationautoscaling
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsaas "github.com/aws/aws-sdk-go/service/applicationautoscaling"
+)
+
+// ScheduledAction sets a service's task count on a schedule.
+type ScheduledAction struct {
+	Name     string
+	Schedule string
+	Timezone string
+	Count    int64
+}
+
+// ServiceResourceID returns the Application Auto Scaling resource ID of an ECS service.
+func ServiceResourceID(clusterName, serviceName string) string {
+	return fmt.Sprintf("service/%s/%s", clusterName, serviceName)
+}
+
+// RegisterServiceScalableTarget allows a service's desired count to be scaled between min and max.
+func (aas SDKClient) RegisterServiceScalableTarget(resourceID string, min, max int64) error {
+	_, err := aas.client.RegisterScalableTarget(
+		&awsaas.RegisterScalableTargetInput{
+			MaxCapacity:       aws.Int64(max),
+			MinCapacity:       aws.Int64(min),
+			ResourceId:        aws.String(resourceID),
+			ScalableDimension: aws.String(awsaas.ScalableDimensionEcsServiceDesiredCount),
+			ServiceNamespace:  aws.String(awsaas.ServiceNamespaceEcs),
+		},
+	)
+
+	return err
+}
+
+// PutServiceScheduledAction creates or replaces a scheduled action that sets a service's task
+// count. Both the minimum and maximum capacity are set so scaling policies can't move it.
+func (aas SDKClient) PutServiceScheduledAction(resourceID string, action ScheduledAction) error {
+	_, err := aas.client.PutScheduledAction(
+		&awsaas.PutScheduledActionInput{
+			ResourceId:        aws.String(resourceID),
+			ScalableDimension: aws.String(awsaas.ScalableDimensionEcsServiceDesiredCount),
+			ScalableTargetAction: &awsaas.ScalableTargetAction{
+				MaxCapacity: aws.Int64(action.Count),
+				MinCapacity: aws.Int64(action.Count),
+			},
+			Schedule:            aws.String(action.Schedule),
+			ScheduledActionName: aws.String(action.Name),
+			ServiceNamespace:    aws.String(awsaas.ServiceNamespaceEcs),
+			Timezone:            aws.String(action.Timezone),
+		},
+	)
+
+	return err
+}
@@ -0,0 +1,88 @@
+package applicationautoscaling
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsaas "github.com/aws/aws-sdk-go/service/applicationautoscaling"
+	"github.com/aws/aws-sdk-go/service/applicationautoscaling/applicationautoscalingiface"
+)
+
+type mockApplicationAutoScalingAPI struct {
+	applicationautoscalingiface.ApplicationAutoScalingAPI
+	err            error
+	registerInput  *awsaas.RegisterScalableTargetInput
+	scheduledInput *awsaas.PutScheduledActionInput
+}
+
+func (m *mockApplicationAutoScalingAPI) RegisterScalableTarget(i *awsaas.RegisterScalableTargetInput) (*awsaas.RegisterScalableTargetOutput, error) {
+	m.registerInput = i
+
+	return &awsaas.RegisterScalableTargetOutput{}, m.err
+}
+
+func (m *mockApplicationAutoScalingAPI) PutScheduledAction(i *awsaas.PutScheduledActionInput) (*awsaas.PutScheduledActionOutput, error) {
+	m.scheduledInput = i
+
+	return &awsaas.PutScheduledActionOutput{}, m.err
+}
+
+func TestServiceResourceID(t *testing.T) {
+	if id := ServiceResourceID("my-cluster", "web"); id != "service/my-cluster/web" {
+		t.Errorf("expected service/my-cluster/web, got %s", id)
+	}
+}
+
+func TestRegisterServiceScalableTarget(t *testing.T) {
+	mockAAS := &mockApplicationAutoScalingAPI{}
+	aas := SDKClient{client: mockAAS}
+
+	if err := aas.RegisterServiceScalableTarget("service/my-cluster/web", 0, 4); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	input := mockAAS.registerInput
+
+	if aws.Int64Value(input.MinCapacity) != 0 || aws.Int64Value(input.MaxCapacity) != 4 {
+		t.Errorf("expected capacity 0-4, got %d-%d", aws.Int64Value(input.MinCapacity), aws.Int64Value(input.MaxCapacity))
+	}
+
+	if aws.StringValue(input.ScalableDimension) != "ecs:service:DesiredCount" {
+		t.Errorf("expected ecs:service:DesiredCount, got %s", aws.StringValue(input.ScalableDimension))
+	}
+}
+
+func TestPutServiceScheduledAction(t *testing.T) {
+	mockAAS := &mockApplicationAutoScalingAPI{}
+	aas := SDKClient{client: mockAAS}
+
+	action := ScheduledAction{
+		Name:     "fargate-web-peak",
+		Schedule: "cron(0 8 ? * * *)",
+		Timezone: "America/New_York",
+		Count:    4,
+	}
+
+	if err := aas.PutServiceScheduledAction("service/my-cluster/web", action); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	input := mockAAS.scheduledInput
+
+	if aws.StringValue(input.Schedule) != action.Schedule || aws.StringValue(input.Timezone) != action.Timezone {
+		t.Errorf("expected %s in %s, got %s in %s", action.Schedule, action.Timezone, aws.StringValue(input.Schedule), aws.StringValue(input.Timezone))
+	}
+
+	if aws.Int64Value(input.ScalableTargetAction.MinCapacity) != 4 || aws.Int64Value(input.ScalableTargetAction.MaxCapacity) != 4 {
+		t.Errorf("expected min and max capacity of 4, got %v", input.ScalableTargetAction)
+	}
+}
+
+func TestPutServiceScheduledActionError(t *testing.T) {
+	aas := SDKClient{client: &mockApplicationAutoScalingAPI{err: errors.New("boom")}}
+
+	if err := aas.PutServiceScheduledAction("service/my-cluster/web", ScheduledAction{}); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
@@ -0,0 +1,102 @@
+// Package arn parses Amazon Resource Names, e.g.
+// arn:aws:ecs:us-east-1:123456789012:task/my-cluster/0123456789abcdef, into
+// their parts, so resource names and IDs don't have to be split out of ARNs
+// by hand.
+package arn
+
+import (
+	"strings"
+
+	awsarn "github.com/aws/aws-sdk-go/aws/arn"
+)
+
+// ARN is a parsed Amazon Resource Name.
+type ARN struct {
+	Partition string
+	Service   string
+	Region    string
+	AccountID string
+
+	// Resource is everything after the account, e.g. task/my-cluster/0123456789abcdef.
+	Resource string
+
+	// ResourceType is the part of the resource before the first / or :, e.g.
+	// task, or "" if the resource has no type, as with S3 buckets.
+	ResourceType string
+
+	// ResourceID is the part of the resource after its type, e.g.
+	// my-cluster/0123456789abcdef.
+	ResourceID string
+}
+
+// Parse parses an ARN, returning an error if it isn't one.
+func Parse(s string) (ARN, error) {
+	parsed, err := awsarn.Parse(s)
+
+	if err != nil {
+		return ARN{}, err
+	}
+
+	a := ARN{
+		Partition:  parsed.Partition,
+		Service:    parsed.Service,
+		Region:     parsed.Region,
+		AccountID:  parsed.AccountID,
+		Resource:   parsed.Resource,
+		ResourceID: parsed.Resource,
+	}
+
+	if i := strings.IndexAny(parsed.Resource, "/:"); i >= 0 {
+		a.ResourceType = parsed.Resource[:i]
+		a.ResourceID = parsed.Resource[i+1:]
+	}
+
+	return a, nil
+}
+
+// IsARN returns whether s is an ARN.
+func IsARN(s string) bool {
+	return awsarn.IsARN(s)
+}
+
+// Name returns the last /-separated part of the resource ID, which is the
+// task ID of a task, the name of a service or role (without its path), or
+// the ID of a target group.
+func (a ARN) Name() string {
+	return a.ResourceID[strings.LastIndex(a.ResourceID, "/")+1:]
+}
+
+// String returns the ARN.
+func (a ARN) String() string {
+	return awsarn.ARN{
+		Partition: a.Partition,
+		Service:   a.Service,
+		Region:    a.Region,
+		AccountID: a.AccountID,
+		Resource:  a.Resource,
+	}.String()
+}
+
+// ResourceName returns the name of the resource s refers to, as ARN.Name
+// does, or s itself if it isn't an ARN, e.g. a task ID rather than a task ARN.
+func ResourceName(s string) string {
+	a, err := Parse(s)
+
+	if err != nil {
+		return s
+	}
+
+	return a.Name()
+}
+
+// Partition returns the partition a region is in, e.g. aws-cn for cn-north-1.
+func Partition(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return "aws-cn"
+	case strings.HasPrefix(region, "us-gov-"):
+		return "aws-us-gov"
+	default:
+		return "aws"
+	}
+}
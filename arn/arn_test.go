@@ -0,0 +1,121 @@
+package arn
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	var tests = []struct {
+		arn  string
+		want ARN
+		name string
+	}{
+		{
+			"arn:aws:ecs:us-east-1:123456789012:task/my-cluster/0123456789abcdef0123456789abcdef",
+			ARN{Partition: "aws", Service: "ecs", Region: "us-east-1", AccountID: "123456789012", Resource: "task/my-cluster/0123456789abcdef0123456789abcdef", ResourceType: "task", ResourceID: "my-cluster/0123456789abcdef0123456789abcdef"},
+			"0123456789abcdef0123456789abcdef",
+		},
+		{
+			"arn:aws:ecs:us-east-1:123456789012:task/0123456789abcdef0123456789abcdef",
+			ARN{Partition: "aws", Service: "ecs", Region: "us-east-1", AccountID: "123456789012", Resource: "task/0123456789abcdef0123456789abcdef", ResourceType: "task", ResourceID: "0123456789abcdef0123456789abcdef"},
+			"0123456789abcdef0123456789abcdef",
+		},
+		{
+			"arn:aws:ecs:us-east-1:123456789012:service/my-cluster/web",
+			ARN{Partition: "aws", Service: "ecs", Region: "us-east-1", AccountID: "123456789012", Resource: "service/my-cluster/web", ResourceType: "service", ResourceID: "my-cluster/web"},
+			"web",
+		},
+		{
+			"arn:aws:ecs:us-east-1:123456789012:task-definition/web:12",
+			ARN{Partition: "aws", Service: "ecs", Region: "us-east-1", AccountID: "123456789012", Resource: "task-definition/web:12", ResourceType: "task-definition", ResourceID: "web:12"},
+			"web:12",
+		},
+		{
+			"arn:aws:elasticloadbalancing:us-west-2:123456789012:targetgroup/my-targets/73e2d6bc24d8a067",
+			ARN{Partition: "aws", Service: "elasticloadbalancing", Region: "us-west-2", AccountID: "123456789012", Resource: "targetgroup/my-targets/73e2d6bc24d8a067", ResourceType: "targetgroup", ResourceID: "my-targets/73e2d6bc24d8a067"},
+			"73e2d6bc24d8a067",
+		},
+		{
+			"arn:aws:iam::123456789012:role/service/ecsTaskExecutionRole",
+			ARN{Partition: "aws", Service: "iam", AccountID: "123456789012", Resource: "role/service/ecsTaskExecutionRole", ResourceType: "role", ResourceID: "service/ecsTaskExecutionRole"},
+			"ecsTaskExecutionRole",
+		},
+		{
+			"arn:aws-cn:secretsmanager:cn-north-1:123456789012:secret:db-AbCdEf",
+			ARN{Partition: "aws-cn", Service: "secretsmanager", Region: "cn-north-1", AccountID: "123456789012", Resource: "secret:db-AbCdEf", ResourceType: "secret", ResourceID: "db-AbCdEf"},
+			"db-AbCdEf",
+		},
+		{
+			"arn:aws:s3:::my-bucket",
+			ARN{Partition: "aws", Service: "s3", Resource: "my-bucket", ResourceID: "my-bucket"},
+			"my-bucket",
+		},
+	}
+
+	for _, test := range tests {
+		got, err := Parse(test.arn)
+
+		if err != nil {
+			t.Errorf("Parse(%s) => unexpected error %v", test.arn, err)
+			continue
+		}
+
+		if got != test.want {
+			t.Errorf("Parse(%s) => %+v, want %+v", test.arn, got, test.want)
+		}
+
+		if got.Name() != test.name {
+			t.Errorf("Parse(%s).Name() => %s, want %s", test.arn, got.Name(), test.name)
+		}
+
+		if got.String() != test.arn {
+			t.Errorf("Parse(%s).String() => %s", test.arn, got.String())
+		}
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, s := range []string{"", "my-service", "0123456789abcdef", "arn:aws:ecs"} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("Parse(%q) => expected error", s)
+		}
+
+		if IsARN(s) {
+			t.Errorf("IsARN(%q) => true", s)
+		}
+	}
+}
+
+func TestResourceName(t *testing.T) {
+	var tests = []struct {
+		s    string
+		want string
+	}{
+		{"arn:aws:ecs:us-east-1:123456789012:task/my-cluster/0123456789abcdef", "0123456789abcdef"},
+		{"0123456789abcdef", "0123456789abcdef"},
+		{"arn:aws:servicediscovery:us-east-1:123456789012:service/srv-abcdefghijklmnop", "srv-abcdefghijklmnop"},
+	}
+
+	for _, test := range tests {
+		if got := ResourceName(test.s); got != test.want {
+			t.Errorf("ResourceName(%s) => %s, want %s", test.s, got, test.want)
+		}
+	}
+}
+
+func TestPartition(t *testing.T) {
+	var tests = []struct {
+		region    string
+		partition string
+	}{
+		{"us-east-1", "aws"},
+		{"cn-north-1", "aws-cn"},
+		{"us-gov-west-1", "aws-us-gov"},
+	}
+
+	for _, test := range tests {
+		if got := Partition(test.region); got != test.partition {
+			t.Errorf("Partition(%s) => %s, want %s", test.region, got, test.partition)
+		}
+	}
+}
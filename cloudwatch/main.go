@@ -0,0 +1,19 @@
+package cloudwatch
+
+import (
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+)
+
+// SDKClient implements access to Amazon CloudWatch metrics via the AWS SDK.
+type SDKClient struct {
+	client cloudwatchiface.CloudWatchAPI
+}
+
+// New returns an SDKClient configured with the given session.
+func New(sess *session.Session) SDKClient {
+	return SDKClient{
+		client: cloudwatch.New(sess),
+	}
+}
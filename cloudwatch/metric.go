@@ -0,0 +1,110 @@
+package cloudwatch
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+)
+
+// maxDatapoints is the most datapoints GetMetricStatistics returns at once.
+const maxDatapoints = 1440
+
+// periods are the metric periods, in seconds, tried from finest to coarsest.
+// CloudWatch keeps 1 minute data for 15 days, 5 minute data for 63 days, and
+// hourly data for 455 days.
+var periods = []int64{60, 300, 3600, 21600, 86400}
+
+// MetricStatistics summarizes a metric over a window of time.
+type MetricStatistics struct {
+	Average    float64
+	Maximum    float64
+	Datapoints int
+}
+
+// ServiceUtilization is how much of its tasks' CPU and memory an ECS service
+// used, as percentages.
+type ServiceUtilization struct {
+	CPU    MetricStatistics
+	Memory MetricStatistics
+}
+
+// GetMetricStatistics returns the average and maximum of a metric between
+// start and end, with the finest period that fits in a single request.
+func (cw SDKClient) GetMetricStatistics(namespace, metricName string, dimensions map[string]string, start, end time.Time) (MetricStatistics, error) {
+	var statistics MetricStatistics
+	var sum float64
+
+	input := &cloudwatch.GetMetricStatisticsInput{
+		Namespace:  aws.String(namespace),
+		MetricName: aws.String(metricName),
+		StartTime:  aws.Time(start),
+		EndTime:    aws.Time(end),
+		Period:     aws.Int64(period(end.Sub(start))),
+		Statistics: aws.StringSlice([]string{cloudwatch.StatisticAverage, cloudwatch.StatisticMaximum}),
+	}
+
+	for name, value := range dimensions {
+		input.Dimensions = append(input.Dimensions,
+			&cloudwatch.Dimension{
+				Name:  aws.String(name),
+				Value: aws.String(value),
+			},
+		)
+	}
+
+	resp, err := cw.client.GetMetricStatistics(input)
+
+	if err != nil {
+		return statistics, err
+	}
+
+	for _, datapoint := range resp.Datapoints {
+		sum += aws.Float64Value(datapoint.Average)
+
+		if maximum := aws.Float64Value(datapoint.Maximum); maximum > statistics.Maximum {
+			statistics.Maximum = maximum
+		}
+	}
+
+	statistics.Datapoints = len(resp.Datapoints)
+
+	if statistics.Datapoints > 0 {
+		statistics.Average = sum / float64(statistics.Datapoints)
+	}
+
+	return statistics, nil
+}
+
+// GetServiceUtilization returns the CPU and memory utilization of an ECS
+// service's tasks between start and end.
+func (cw SDKClient) GetServiceUtilization(clusterName, serviceName string, start, end time.Time) (ServiceUtilization, error) {
+	var utilization ServiceUtilization
+	var err error
+
+	dimensions := map[string]string{
+		"ClusterName": clusterName,
+		"ServiceName": serviceName,
+	}
+
+	if utilization.CPU, err = cw.GetMetricStatistics("AWS/ECS", "CPUUtilization", dimensions, start, end); err != nil {
+		return utilization, err
+	}
+
+	if utilization.Memory, err = cw.GetMetricStatistics("AWS/ECS", "MemoryUtilization", dimensions, start, end); err != nil {
+		return utilization, err
+	}
+
+	return utilization, nil
+}
+
+// period returns the finest period that covers a window in one request.
+func period(window time.Duration) int64 {
+	for _, p := range periods {
+		if int64(window/time.Second)/p <= maxDatapoints {
+			return p
+		}
+	}
+
+	return periods[len(periods)-1]
+}
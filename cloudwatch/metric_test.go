@@ -0,0 +1,121 @@
+package cloudwatch
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awscloudwatch "github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+)
+
+type mockCloudWatchAPI struct {
+	cloudwatchiface.CloudWatchAPI
+	datapoints []*awscloudwatch.Datapoint
+	err        error
+	inputs     []*awscloudwatch.GetMetricStatisticsInput
+}
+
+func (m *mockCloudWatchAPI) GetMetricStatistics(i *awscloudwatch.GetMetricStatisticsInput) (*awscloudwatch.GetMetricStatisticsOutput, error) {
+	m.inputs = append(m.inputs, i)
+
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &awscloudwatch.GetMetricStatisticsOutput{Datapoints: m.datapoints}, nil
+}
+
+func TestGetMetricStatistics(t *testing.T) {
+	mockClient := &mockCloudWatchAPI{
+		datapoints: []*awscloudwatch.Datapoint{
+			&awscloudwatch.Datapoint{Average: aws.Float64(10), Maximum: aws.Float64(40)},
+			&awscloudwatch.Datapoint{Average: aws.Float64(20), Maximum: aws.Float64(55)},
+		},
+	}
+	cw := SDKClient{client: mockClient}
+	end := time.Date(2021, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	statistics, err := cw.GetMetricStatistics("AWS/ECS", "CPUUtilization", map[string]string{"ServiceName": "web"}, end.Add(-14*24*time.Hour), end)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if statistics.Average != 15 || statistics.Maximum != 55 || statistics.Datapoints != 2 {
+		t.Errorf("expected average 15, maximum 55, and 2 datapoints, got %+v", statistics)
+	}
+
+	if got := aws.Int64Value(mockClient.inputs[0].Period); got != 3600 {
+		t.Errorf("expected a 3600 second period for 14 days, got %d", got)
+	}
+
+	if got := aws.StringValue(mockClient.inputs[0].Dimensions[0].Value); got != "web" {
+		t.Errorf("expected dimension value web, got %s", got)
+	}
+}
+
+func TestGetMetricStatistics_NoData(t *testing.T) {
+	cw := SDKClient{client: &mockCloudWatchAPI{}}
+	end := time.Now()
+
+	statistics, err := cw.GetMetricStatistics("AWS/ECS", "CPUUtilization", nil, end.Add(-time.Hour), end)
+
+	if err != nil || statistics != (MetricStatistics{}) {
+		t.Errorf("expected empty statistics, got (%+v, %v)", statistics, err)
+	}
+}
+
+func TestGetServiceUtilization(t *testing.T) {
+	mockClient := &mockCloudWatchAPI{
+		datapoints: []*awscloudwatch.Datapoint{
+			&awscloudwatch.Datapoint{Average: aws.Float64(10), Maximum: aws.Float64(40)},
+		},
+	}
+	cw := SDKClient{client: mockClient}
+	end := time.Now()
+
+	utilization, err := cw.GetServiceUtilization("default", "web", end.Add(-time.Hour), end)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if utilization.CPU.Maximum != 40 || utilization.Memory.Maximum != 40 {
+		t.Errorf("expected CPU and memory maximums of 40, got %+v", utilization)
+	}
+
+	if len(mockClient.inputs) != 2 || aws.StringValue(mockClient.inputs[1].MetricName) != "MemoryUtilization" {
+		t.Errorf("expected CPU and memory utilization to be requested")
+	}
+}
+
+func TestGetServiceUtilization_Error(t *testing.T) {
+	cw := SDKClient{client: &mockCloudWatchAPI{err: errors.New("boom")}}
+	end := time.Now()
+
+	if _, err := cw.GetServiceUtilization("default", "web", end.Add(-time.Hour), end); err == nil {
+		t.Errorf("expected error, got none")
+	}
+}
+
+func TestPeriod(t *testing.T) {
+	var tests = []struct {
+		window time.Duration
+		period int64
+	}{
+		{time.Hour, 60},
+		{24 * time.Hour, 60},
+		{3 * 24 * time.Hour, 300},
+		{14 * 24 * time.Hour, 3600},
+		{100 * 24 * time.Hour, 21600},
+		{400 * 24 * time.Hour, 86400},
+	}
+
+	for _, test := range tests {
+		if got := period(test.window); got != test.period {
+			t.Errorf("period(%s) => %d, want %d", test.window, got, test.period)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package cloudwatchlogs
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -25,50 +27,196 @@ type LogLine struct {
 	Timestamp     time.Time
 }
 
-func (cwl *CloudWatchLogs) CreateLogGroup(logGroupName string, a ...interface{}) string {
+const (
+	maxTags           = 50
+	maxTagKeyLength   = 128
+	maxTagValueLength = 256
+)
+
+var validTagPattern = regexp.MustCompile(`^[\p{L}\p{Z}\p{N}_.:/=+\-@]*$`)
+
+//RetentionInDaysValues are the log retention periods CloudWatch Logs accepts
+var RetentionInDaysValues = []int64{1, 3, 5, 7, 14, 30, 60, 90, 120, 150, 180, 365, 400, 545, 731, 1096, 1827, 2192, 2557, 2922, 3288, 3653}
+
+//CreateLogGroup creates a log group that retains logs for the given number of
+//days, or forever if retentionInDays is 0
+func (cwl *CloudWatchLogs) CreateLogGroup(retentionInDays int64, logGroupName string, a ...interface{}) string {
+	return cwl.CreateLogGroupWithTags(nil, retentionInDays, logGroupName, a...)
+}
+
+//CreateLogGroupWithTags creates a log group with the given tags and retention.
+//If the log group already exists the tags are added to it and the retention
+//is set, so the retention is the same however many times this is called.
+func (cwl *CloudWatchLogs) CreateLogGroupWithTags(tags map[string]string, retentionInDays int64, logGroupName string, a ...interface{}) string {
 	formattedLogGroupName := fmt.Sprintf(logGroupName, a...)
-	_, err := cwl.svc.CreateLogGroup(
-		&awscwl.CreateLogGroupInput{
-			LogGroupName: aws.String(formattedLogGroupName),
-		},
-	)
+
+	if err := ValidateTags(tags); err != nil {
+		console.ErrorExit(err, "Invalid Cloudwatch Logs log group tags")
+	}
+
+	if err := ValidateRetentionInDays(retentionInDays); err != nil {
+		console.ErrorExit(err, "Invalid Cloudwatch Logs log group retention")
+	}
+
+	input := &awscwl.CreateLogGroupInput{
+		LogGroupName: aws.String(formattedLogGroupName),
+	}
+
+	if len(tags) > 0 {
+		input.SetTags(aws.StringMap(tags))
+	}
+
+	_, err := cwl.svc.CreateLogGroup(input)
 
 	if err != nil {
 		if awsErr, ok := err.(awserr.Error); ok {
 			switch awsErr.Code() {
 			case awscwl.ErrCodeResourceAlreadyExistsException:
-				return formattedLogGroupName
+				cwl.tagLogGroup(formattedLogGroupName, tags)
 			default:
 				console.ErrorExit(awsErr, "Could not create Cloudwatch Logs log group")
 			}
 		}
 	}
 
+	cwl.putRetentionPolicy(formattedLogGroupName, retentionInDays)
+
 	return formattedLogGroupName
 }
 
+//DeleteLogGroup deletes a log group and all of its logs, returning false if
+//the log group doesn't exist
+func (cwl *CloudWatchLogs) DeleteLogGroup(logGroupName string) bool {
+	_, err := cwl.svc.DeleteLogGroup(
+		&awscwl.DeleteLogGroupInput{
+			LogGroupName: aws.String(logGroupName),
+		},
+	)
+
+	if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == awscwl.ErrCodeResourceNotFoundException {
+		return false
+	}
+
+	if err != nil {
+		console.ErrorExit(err, "Could not delete Cloudwatch Logs log group")
+	}
+
+	return true
+}
+
+func (cwl *CloudWatchLogs) putRetentionPolicy(logGroupName string, retentionInDays int64) {
+	if retentionInDays == 0 {
+		return
+	}
+
+	_, err := cwl.svc.PutRetentionPolicy(
+		&awscwl.PutRetentionPolicyInput{
+			LogGroupName:    aws.String(logGroupName),
+			RetentionInDays: aws.Int64(retentionInDays),
+		},
+	)
+
+	if err != nil {
+		console.ErrorExit(err, "Could not set Cloudwatch Logs log group retention")
+	}
+}
+
+//ValidateRetentionInDays checks a log retention period is one CloudWatch Logs
+//accepts. 0 means logs are kept forever.
+func ValidateRetentionInDays(retentionInDays int64) error {
+	if retentionInDays == 0 {
+		return nil
+	}
+
+	var values []string
+
+	for _, value := range RetentionInDaysValues {
+		if value == retentionInDays {
+			return nil
+		}
+
+		values = append(values, fmt.Sprint(value))
+	}
+
+	return fmt.Errorf("%d is not a valid log retention, days must be one of: %s", retentionInDays, strings.Join(values, ", "))
+}
+
+func (cwl *CloudWatchLogs) tagLogGroup(logGroupName string, tags map[string]string) {
+	if len(tags) == 0 {
+		return
+	}
+
+	_, err := cwl.svc.TagLogGroup(
+		&awscwl.TagLogGroupInput{
+			LogGroupName: aws.String(logGroupName),
+			Tags:         aws.StringMap(tags),
+		},
+	)
+
+	if err != nil {
+		console.ErrorExit(err, "Could not tag Cloudwatch Logs log group")
+	}
+}
+
+//ValidateTags checks tags against CloudWatch Logs' tagging constraints
+func ValidateTags(tags map[string]string) error {
+	if len(tags) > maxTags {
+		return fmt.Errorf("%d tags given, log groups support at most %d", len(tags), maxTags)
+	}
+
+	for key, value := range tags {
+		switch {
+		case len(key) == 0 || len(key) > maxTagKeyLength:
+			return fmt.Errorf("tag key %q must be between 1 and %d characters", key, maxTagKeyLength)
+		case len(value) > maxTagValueLength:
+			return fmt.Errorf("tag value for %s must be at most %d characters", key, maxTagValueLength)
+		case strings.HasPrefix(strings.ToLower(key), "aws:"):
+			return fmt.Errorf("tag key %s must not start with aws:", key)
+		case !validTagPattern.MatchString(key) || !validTagPattern.MatchString(value):
+			return fmt.Errorf("tag %s=%s may only contain letters, numbers, spaces, and _ . : / = + - @", key, value)
+		}
+	}
+
+	return nil
+}
+
 func (cwl *CloudWatchLogs) GetLogs(i *GetLogsInput) []LogLine {
+	logLines, err := cwl.GetLogEvents(i.LogGroupName, i.LogStreamNames, i.StartTime, i.EndTime, i.Filter)
+
+	if err != nil {
+		console.ErrorExit(err, "Could not get logs for: " + i.LogGroupName)
+	}
+
+	return logLines
+}
+
+//GetLogEvents returns the events in a log group's streams (or every stream if
+//none are given) between start and end that match a filter pattern. A zero
+//start or end leaves that side of the window open, and an empty pattern
+//matches every event. Filtering happens in CloudWatch Logs, so only matching
+//events are transferred.
+func (cwl *CloudWatchLogs) GetLogEvents(logGroupName string, logStreamNames []string, start, end time.Time, pattern string) ([]LogLine, error) {
 	var logLines []LogLine
 
 	input := &awscwl.FilterLogEventsInput{
-		LogGroupName: aws.String(i.LogGroupName),
+		LogGroupName: aws.String(logGroupName),
 		Interleaved:  aws.Bool(true),
 	}
 
-	if !i.StartTime.IsZero() {
-		input.SetStartTime(i.StartTime.UTC().UnixNano() / int64(time.Millisecond))
+	if !start.IsZero() {
+		input.SetStartTime(start.UTC().UnixNano() / int64(time.Millisecond))
 	}
 
-	if !i.EndTime.IsZero() {
-		input.SetEndTime(i.EndTime.UTC().UnixNano() / int64(time.Millisecond))
+	if !end.IsZero() {
+		input.SetEndTime(end.UTC().UnixNano() / int64(time.Millisecond))
 	}
 
-	if i.Filter != "" {
-		input.SetFilterPattern(i.Filter)
+	if pattern != "" {
+		input.SetFilterPattern(pattern)
 	}
 
-	if len(i.LogStreamNames) > 0 {
-		input.SetLogStreamNames(aws.StringSlice(i.LogStreamNames))
+	if len(logStreamNames) > 0 {
+		input.SetLogStreamNames(aws.StringSlice(logStreamNames))
 	}
 
 	err := cwl.svc.FilterLogEventsPages(
@@ -90,9 +238,5 @@ func (cwl *CloudWatchLogs) GetLogs(i *GetLogsInput) []LogLine {
 		},
 	)
 
-	if err != nil {
-		console.ErrorExit(err, "Could not get logs for: " + i.LogGroupName)
-	}
-
-	return logLines
+	return logLines, err
 }
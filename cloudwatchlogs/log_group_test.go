@@ -0,0 +1,70 @@
+package cloudwatchlogs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateTags(t *testing.T) {
+	tooMany := make(map[string]string)
+
+	for i := 0; i <= maxTags; i++ {
+		tooMany[strings.Repeat("k", i+1)] = "v"
+	}
+
+	var tests = []struct {
+		name  string
+		tags  map[string]string
+		valid bool
+	}{
+		{"nil", nil, true},
+		{"simple", map[string]string{"team": "platform", "cost-center": "1234"}, true},
+		{"empty value", map[string]string{"team": ""}, true},
+		{"punctuation", map[string]string{"app/env": "a.b:c=d+e@f_g h"}, true},
+		{"empty key", map[string]string{"": "value"}, false},
+		{"long key", map[string]string{strings.Repeat("k", 129): "value"}, false},
+		{"long value", map[string]string{"team": strings.Repeat("v", 257)}, false},
+		{"aws prefix", map[string]string{"aws:team": "value"}, false},
+		{"invalid character", map[string]string{"team": "a*b"}, false},
+		{"too many", tooMany, false},
+	}
+
+	for _, test := range tests {
+		err := ValidateTags(test.tags)
+
+		if test.valid && err != nil {
+			t.Errorf("%s: expected valid, got %v", test.name, err)
+		}
+
+		if !test.valid && err == nil {
+			t.Errorf("%s: expected error, got none", test.name)
+		}
+	}
+}
+
+func TestValidateRetentionInDays(t *testing.T) {
+	var tests = []struct {
+		days  int64
+		valid bool
+	}{
+		{0, true},
+		{1, true},
+		{14, true},
+		{3653, true},
+		{2, false},
+		{-1, false},
+		{3654, false},
+	}
+
+	for _, test := range tests {
+		err := ValidateRetentionInDays(test.days)
+
+		if test.valid && err != nil {
+			t.Errorf("%d: expected valid, got %v", test.days, err)
+		}
+
+		if !test.valid && err == nil {
+			t.Errorf("%d: expected error, got none", test.days)
+		}
+	}
+}
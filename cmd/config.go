@@ -3,19 +3,31 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 const (
-	keyCluster = "cluster"
-	keyService = "service"
-	keyRegion  = "region"
-	keyVerbose = "verbose"
-	keyNoColor = "nocolor"
-	keyTask    = "task"
-	keyRule    = "rule"
+	keyCluster    = "cluster"
+	keyService    = "service"
+	keyRegion     = "region"
+	keyProfile    = "profile"
+	keyVerbose    = "verbose"
+	keyNoColor    = "nocolor"
+	keyTask       = "task"
+	keyRule       = "rule"
+	keyOutput     = "output"
+	keyTimeout    = "timeout"
+	keyFIPS       = "fips"
+	keyMaxRetries = "max-retries"
+
+	keyAssumeRoleArn = "assume-role-arn"
+	keyExternalID    = "external-id"
+	keyEndpointURL   = "endpoint-url"
 )
 
 //configure viper to manage parameter input
@@ -33,19 +45,35 @@ func initConfig(cmd *cobra.Command) {
 	viper.BindEnv(keyNoColor, "FARGATE_NOCOLOR")
 	viper.BindEnv(keyTask, "FARGATE_TASK")
 	viper.BindEnv(keyRule, "FARGATE_RULE")
+	viper.BindEnv(keyOutput, "FARGATE_OUTPUT")
+	viper.BindEnv(keyTimeout, "FARGATE_TIMEOUT")
+	viper.BindEnv(keyFIPS, "FARGATE_FIPS")
+	viper.BindEnv(keyMaxRetries, "FARGATE_MAX_RETRIES")
+	viper.BindEnv(keyAssumeRoleArn, "FARGATE_ASSUME_ROLE_ARN")
+	viper.BindEnv(keyExternalID, "FARGATE_EXTERNAL_ID")
+	viper.BindEnv(keyEndpointURL, "AWS_ENDPOINT_URL")
 
 	//cli arg
 	initPFlag(keyCluster, cmd)
 	initPFlag(keyVerbose, cmd)
 	initPFlag(keyRegion, cmd)
+	initPFlag(keyProfile, cmd)
 	initPFlag(keyNoColor, cmd)
+	initPFlag(keyOutput, cmd)
+	initPFlag(keyTimeout, cmd)
+	initPFlag(keyFIPS, cmd)
+	initPFlag(keyMaxRetries, cmd)
+	initPFlag(keyAssumeRoleArn, cmd)
+	initPFlag(keyExternalID, cmd)
+	initPFlag(keyEndpointURL, cmd)
 }
 
 func initPFlag(key string, cmd *cobra.Command) {
 	viper.BindPFlag(key, cmd.PersistentFlags().Lookup(key))
 }
 
-//region can come from fargate.yml, AWS_REGION, AWS_DEFAULT_REGION or --region
+//region can come from fargate.yml, AWS_REGION, AWS_DEFAULT_REGION, --region,
+//or the region of the AWS profile in use
 func getRegion() string {
 	result := viper.GetString(keyRegion)
 	if result == "" {
@@ -56,6 +84,8 @@ func getRegion() string {
 			result = envAwsDefaultRegion
 		} else if envAwsRegion != "" {
 			result = envAwsRegion
+		} else if profileRegion := getProfileRegion(getProfile()); profileRegion != "" {
+			result = profileRegion
 		} else {
 			result = defaultRegion
 		}
@@ -64,6 +94,33 @@ func getRegion() string {
 	return result
 }
 
+//profile can come from fargate.yml, AWS_PROFILE, or --profile
+func getProfile() string {
+	result := viper.GetString(keyProfile)
+	if result == "" {
+		result = os.Getenv("AWS_PROFILE")
+	}
+
+	return result
+}
+
+//getProfileRegion returns the region configured for an AWS profile in
+//~/.aws/config, if any
+func getProfileRegion(profile string) string {
+	s, err := session.NewSessionWithOptions(
+		session.Options{
+			Profile:           profile,
+			SharedConfigState: session.SharedConfigEnable,
+		},
+	)
+
+	if err != nil {
+		return ""
+	}
+
+	return aws.StringValue(s.Config.Region)
+}
+
 //cluster can come from fargate.yml, FARGATE_CLUSTER envar, or --cluster cli arg
 func getClusterName() string {
 	result := viper.GetString(keyCluster)
@@ -111,3 +168,48 @@ func getVerbose() bool {
 func getNoColor() bool {
 	return viper.GetBool(keyNoColor)
 }
+
+//max retries can come from fargate.yml, FARGATE_MAX_RETRIES, or --max-retries
+//cli arg
+func getMaxRetries() int {
+	return viper.GetInt(keyMaxRetries)
+}
+
+//fips can come from fargate.yml, FARGATE_FIPS, or --fips cli arg; the AWS SDK
+//also honors AWS_USE_FIPS_ENDPOINT
+func getFIPS() bool {
+	return viper.GetBool(keyFIPS)
+}
+
+//assume role arn can come from fargate.yml, FARGATE_ASSUME_ROLE_ARN, or
+//--assume-role-arn cli arg
+func getAssumeRoleArn() string {
+	return viper.GetString(keyAssumeRoleArn)
+}
+
+//external id can come from fargate.yml, FARGATE_EXTERNAL_ID, or --external-id
+//cli arg
+func getExternalID() string {
+	return viper.GetString(keyExternalID)
+}
+
+//endpoint url can come from fargate.yml, AWS_ENDPOINT_URL, or --endpoint-url
+//cli arg
+func getEndpointURL() string {
+	return viper.GetString(keyEndpointURL)
+}
+
+//output format can come from fargate.yml, FARGATE_OUTPUT, or --output cli arg
+func getOutput() string {
+	result := viper.GetString(keyOutput)
+	if result == "" {
+		result = outputText
+	}
+	return result
+}
+
+//timeout can come from fargate.yml, FARGATE_TIMEOUT, or --timeout cli arg
+//(zero means no timeout)
+func getTimeout() time.Duration {
+	return viper.GetDuration(keyTimeout)
+}
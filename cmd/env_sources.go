@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"sort"
+
+	"github.com/turnerlabs/fargate/console"
+	ECS "github.com/turnerlabs/fargate/ecs"
+)
+
+//Environment variable sources. When the same key is set by more than one
+//source, the value from the source listed first wins. Variables loaded from
+//S3 environment files (--env-s3) rank below all of these; ECS applies that
+//when the task starts, so those overrides can't be reported.
+const (
+	envSourceCommandLine = "command line"
+	envSourceFile        = "env file"
+	envSourceSSM         = "ssm"
+)
+
+var envSourcePrecedence = []string{envSourceCommandLine, envSourceFile, envSourceSSM}
+
+//envVarSource is a set of environment variables and secrets from one source
+type envVarSource struct {
+	Name       string
+	EnvVars    []ECS.EnvVar
+	SecretVars []ECS.Secret
+}
+
+//envVarOverride records a key set by more than one source
+type envVarOverride struct {
+	Key        string
+	Source     string
+	Overridden string
+}
+
+//envVarSources collects variables by source so they can be merged by precedence
+type envVarSources []envVarSource
+
+//Add appends variables to the named source
+func (s *envVarSources) Add(name string, envVars []ECS.EnvVar, secretVars []ECS.Secret) {
+	if len(envVars) == 0 && len(secretVars) == 0 {
+		return
+	}
+
+	for i := range *s {
+		if (*s)[i].Name == name {
+			(*s)[i].EnvVars = append((*s)[i].EnvVars, envVars...)
+			(*s)[i].SecretVars = append((*s)[i].SecretVars, secretVars...)
+			return
+		}
+	}
+
+	*s = append(*s, envVarSource{Name: name, EnvVars: envVars, SecretVars: secretVars})
+}
+
+//Merge returns each key's variable or secret from the highest precedence
+//source that sets it, along with the values that were dropped. Within a
+//source, the last value for a key wins.
+//
+//Keys keep the order they were first set in, starting from the lowest
+//precedence source, so a file's order is preserved and a command line value
+//replaces the file's value in place.
+func (s envVarSources) Merge() ([]ECS.EnvVar, []ECS.Secret, []envVarOverride) {
+	var (
+		envVars    []ECS.EnvVar
+		secretVars []ECS.Secret
+		overrides  []envVarOverride
+		order      []string
+	)
+
+	sources := make(envVarSources, len(s))
+	copy(sources, s)
+
+	sort.SliceStable(sources, func(i, j int) bool {
+		return envSourceRank(sources[i].Name) < envSourceRank(sources[j].Name)
+	})
+
+	setBy := make(map[string]string)
+	winners := make(map[string]ECS.EnvVar)
+	winnerIsSecret := make(map[string]bool)
+
+	for _, source := range sources {
+		values, secrets, keys := source.values()
+
+		for _, key := range keys {
+			if winner, ok := setBy[key]; ok {
+				overrides = append(overrides, envVarOverride{Key: key, Source: winner, Overridden: source.Name})
+				continue
+			}
+
+			setBy[key] = source.Name
+			winners[key] = values[key]
+			winnerIsSecret[key] = secrets[key]
+		}
+	}
+
+	for i := len(sources) - 1; i >= 0; i-- {
+		_, _, keys := sources[i].values()
+
+		for _, key := range keys {
+			if !containsString(order, key) {
+				order = append(order, key)
+			}
+		}
+	}
+
+	for _, key := range order {
+		if winnerIsSecret[key] {
+			secretVars = append(secretVars, ECS.Secret{Key: key, ValueFrom: winners[key].Value})
+		} else {
+			envVars = append(envVars, winners[key])
+		}
+	}
+
+	return envVars, secretVars, overrides
+}
+
+//values returns the source's value for each key, whether it is a secret, and
+//the keys in the order they were first set. A later value for a key,
+//variable or secret, replaces an earlier one.
+func (source envVarSource) values() (map[string]ECS.EnvVar, map[string]bool, []string) {
+	values := make(map[string]ECS.EnvVar)
+	secrets := make(map[string]bool)
+	var keys []string
+
+	for _, envVar := range source.EnvVars {
+		if _, ok := values[envVar.Key]; !ok {
+			keys = append(keys, envVar.Key)
+		}
+
+		values[envVar.Key] = envVar
+		secrets[envVar.Key] = false
+	}
+
+	for _, secretVar := range source.SecretVars {
+		if _, ok := values[secretVar.Key]; !ok {
+			keys = append(keys, secretVar.Key)
+		}
+
+		values[secretVar.Key] = ECS.EnvVar{Key: secretVar.Key, Value: secretVar.ValueFrom}
+		secrets[secretVar.Key] = true
+	}
+
+	return values, secrets, keys
+}
+
+func envSourceRank(name string) int {
+	for i, source := range envSourcePrecedence {
+		if source == name {
+			return i
+		}
+	}
+
+	return len(envSourcePrecedence)
+}
+
+//printEnvVarOverrides reports each key whose value from one source was
+//dropped in favor of a higher precedence source
+func printEnvVarOverrides(overrides []envVarOverride) {
+	for _, override := range overrides {
+		console.Issue("%s from %s is overridden by %s", override.Key, override.Overridden, override.Source)
+	}
+}
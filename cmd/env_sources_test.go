@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	ECS "github.com/turnerlabs/fargate/ecs"
+)
+
+func TestEnvVarSourcesMerge(t *testing.T) {
+	commandLine := ECS.EnvVar{Key: "FOO", Value: "command-line"}
+	file := ECS.EnvVar{Key: "FOO", Value: "file"}
+	ssm := ECS.EnvVar{Key: "FOO", Value: "ssm"}
+
+	var tests = []struct {
+		name      string
+		sources   map[string]ECS.EnvVar
+		value     string
+		overrides []envVarOverride
+	}{
+		{
+			"command line over env file",
+			map[string]ECS.EnvVar{envSourceCommandLine: commandLine, envSourceFile: file},
+			"command-line",
+			[]envVarOverride{{"FOO", envSourceCommandLine, envSourceFile}},
+		},
+		{
+			"command line over ssm",
+			map[string]ECS.EnvVar{envSourceCommandLine: commandLine, envSourceSSM: ssm},
+			"command-line",
+			[]envVarOverride{{"FOO", envSourceCommandLine, envSourceSSM}},
+		},
+		{
+			"env file over ssm",
+			map[string]ECS.EnvVar{envSourceFile: file, envSourceSSM: ssm},
+			"file",
+			[]envVarOverride{{"FOO", envSourceFile, envSourceSSM}},
+		},
+		{
+			"command line over env file and ssm",
+			map[string]ECS.EnvVar{envSourceCommandLine: commandLine, envSourceFile: file, envSourceSSM: ssm},
+			"command-line",
+			[]envVarOverride{{"FOO", envSourceCommandLine, envSourceFile}, {"FOO", envSourceCommandLine, envSourceSSM}},
+		},
+		{
+			"single source",
+			map[string]ECS.EnvVar{envSourceSSM: ssm},
+			"ssm",
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		var sources envVarSources
+
+		//add lowest precedence first to show the order sources are added doesn't matter
+		for _, name := range []string{envSourceSSM, envSourceFile, envSourceCommandLine} {
+			if envVar, ok := test.sources[name]; ok {
+				sources.Add(name, []ECS.EnvVar{envVar}, nil)
+			}
+		}
+
+		envVars, secretVars, overrides := sources.Merge()
+
+		if len(envVars) != 1 || envVars[0].Value != test.value {
+			t.Errorf("%s: expected FOO=%s, got %v", test.name, test.value, envVars)
+		}
+
+		if len(secretVars) != 0 {
+			t.Errorf("%s: expected no secrets, got %v", test.name, secretVars)
+		}
+
+		if !reflect.DeepEqual(overrides, test.overrides) {
+			t.Errorf("%s: expected overrides %v, got %v", test.name, test.overrides, overrides)
+		}
+	}
+}
+
+func TestEnvVarSourcesMergeSecrets(t *testing.T) {
+	var sources envVarSources
+
+	sources.Add(envSourceSSM, nil, []ECS.Secret{{Key: "DB_PASSWORD", ValueFrom: "arn:aws:ssm:us-east-1:123456789012:parameter/app/DB_PASSWORD"}})
+	sources.Add(envSourceCommandLine, []ECS.EnvVar{{Key: "DB_PASSWORD", Value: "local"}}, nil)
+
+	envVars, secretVars, overrides := sources.Merge()
+
+	if !reflect.DeepEqual(envVars, []ECS.EnvVar{{Key: "DB_PASSWORD", Value: "local"}}) {
+		t.Errorf("expected DB_PASSWORD=local, got %v", envVars)
+	}
+
+	if len(secretVars) != 0 {
+		t.Errorf("expected the ssm secret to be overridden, got %v", secretVars)
+	}
+
+	if !reflect.DeepEqual(overrides, []envVarOverride{{"DB_PASSWORD", envSourceCommandLine, envSourceSSM}}) {
+		t.Errorf("expected DB_PASSWORD override, got %v", overrides)
+	}
+}
+
+func TestEnvVarSourcesMergeWithinSource(t *testing.T) {
+	var sources envVarSources
+
+	sources.Add(envSourceCommandLine, []ECS.EnvVar{{Key: "FOO", Value: "first"}, {Key: "BAR", Value: "bar"}}, nil)
+	sources.Add(envSourceCommandLine, []ECS.EnvVar{{Key: "FOO", Value: "second"}}, nil)
+
+	envVars, _, overrides := sources.Merge()
+	expected := []ECS.EnvVar{{Key: "FOO", Value: "second"}, {Key: "BAR", Value: "bar"}}
+
+	if !reflect.DeepEqual(envVars, expected) {
+		t.Errorf("expected %v, got %v", expected, envVars)
+	}
+
+	if len(overrides) != 0 {
+		t.Errorf("expected no overrides within a source, got %v", overrides)
+	}
+}
+
+func TestEnvVarSourcesMergeKeepsFileOrder(t *testing.T) {
+	var sources envVarSources
+
+	sources.Add(envSourceCommandLine, []ECS.EnvVar{{Key: "LOG_LEVEL", Value: "debug"}, {Key: "EXTRA", Value: "1"}}, nil)
+	sources.Add(envSourceFile, []ECS.EnvVar{
+		{Key: "ZEBRA", Value: "z"},
+		{Key: "LOG_LEVEL", Value: "info"},
+		{Key: "APPLE", Value: "a"},
+	}, nil)
+
+	envVars, _, _ := sources.Merge()
+	expected := []ECS.EnvVar{
+		{Key: "ZEBRA", Value: "z"},
+		{Key: "LOG_LEVEL", Value: "debug"},
+		{Key: "APPLE", Value: "a"},
+		{Key: "EXTRA", Value: "1"},
+	}
+
+	if !reflect.DeepEqual(envVars, expected) {
+		t.Errorf("expected %v, got %v", expected, envVars)
+	}
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/turnerlabs/fargate/console"
+	ECS "github.com/turnerlabs/fargate/ecs"
+	ELBV2 "github.com/turnerlabs/fargate/elbv2"
+)
+
+//warnHealthCheckConflicts warns when a service's container health check and
+//its load balancer's target group health check are likely to disagree
+func warnHealthCheckConflicts(service ECS.Service) {
+	if service.HealthCheck == nil || service.TargetGroupArn == "" {
+		return
+	}
+
+	targetGroups := ELBV2.New(sess).DescribeTargetGroups([]string{service.TargetGroupArn})
+
+	if len(targetGroups) == 0 {
+		return
+	}
+
+	for _, warning := range healthCheckConflicts(service, targetGroups[0]) {
+		console.Issue(warning)
+	}
+}
+
+//healthCheckConflicts compares how long the container and load balancer
+//health checks take to decide a task is healthy or unhealthy. ECS registers
+//tasks with the target group by the load balancer's health check, but
+//replaces tasks that fail either check, so checks that disagree can cause
+//tasks to be stopped and started over and over.
+func healthCheckConflicts(service ECS.Service, targetGroup ELBV2.TargetGroup) []string {
+	var warnings []string
+
+	container := service.HealthCheck
+	targetGroupHealthy := targetGroup.HealthCheckIntervalSeconds * targetGroup.HealthyThresholdCount
+	targetGroupUnhealthy := targetGroup.HealthCheckIntervalSeconds * targetGroup.UnhealthyThresholdCount
+	containerUnhealthy := container.IntervalSeconds * container.Retries
+
+	if service.HealthCheckGracePeriodSeconds < targetGroupHealthy {
+		warnings = append(warnings, fmt.Sprintf(
+			"The load balancer takes %ds to mark a new task healthy, but the service's health check grace period is %ds; tasks may be replaced before they pass",
+			targetGroupHealthy, service.HealthCheckGracePeriodSeconds))
+	}
+
+	if container.StartPeriodSeconds > service.HealthCheckGracePeriodSeconds && container.StartPeriodSeconds > targetGroupHealthy {
+		warnings = append(warnings, fmt.Sprintf(
+			"The container health check start period (%ds) is longer than the load balancer takes to mark a task healthy (%ds); the load balancer may send traffic to tasks that aren't ready",
+			container.StartPeriodSeconds, targetGroupHealthy))
+	}
+
+	if containerUnhealthy > 2*targetGroupUnhealthy || targetGroupUnhealthy > 2*containerUnhealthy {
+		warnings = append(warnings, fmt.Sprintf(
+			"The container health check marks a task unhealthy after %ds but the load balancer after %ds; align their intervals and thresholds so they agree",
+			containerUnhealthy, targetGroupUnhealthy))
+	}
+
+	return warnings
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	ECS "github.com/turnerlabs/fargate/ecs"
+	ELBV2 "github.com/turnerlabs/fargate/elbv2"
+)
+
+func TestHealthCheckConflicts(t *testing.T) {
+	targetGroup := ELBV2.TargetGroup{HealthCheckIntervalSeconds: 30, HealthyThresholdCount: 5, UnhealthyThresholdCount: 2}
+
+	var tests = []struct {
+		name     string
+		service  ECS.Service
+		warnings []string
+	}{
+		{
+			"compatible",
+			ECS.Service{
+				HealthCheck:                   &ECS.ContainerHealthCheck{IntervalSeconds: 30, Retries: 3},
+				HealthCheckGracePeriodSeconds: 180,
+			},
+			nil,
+		},
+		{
+			"short grace period",
+			ECS.Service{
+				HealthCheck:                   &ECS.ContainerHealthCheck{IntervalSeconds: 30, Retries: 3},
+				HealthCheckGracePeriodSeconds: 60,
+			},
+			[]string{"grace period is 60s"},
+		},
+		{
+			"long start period",
+			ECS.Service{
+				HealthCheck:                   &ECS.ContainerHealthCheck{IntervalSeconds: 30, Retries: 3, StartPeriodSeconds: 300},
+				HealthCheckGracePeriodSeconds: 180,
+			},
+			[]string{"start period (300s)"},
+		},
+		{
+			"container fails much faster",
+			ECS.Service{
+				HealthCheck:                   &ECS.ContainerHealthCheck{IntervalSeconds: 5, Retries: 1},
+				HealthCheckGracePeriodSeconds: 180,
+			},
+			[]string{"unhealthy after 5s but the load balancer after 60s"},
+		},
+	}
+
+	for _, test := range tests {
+		warnings := healthCheckConflicts(test.service, targetGroup)
+
+		if len(warnings) != len(test.warnings) {
+			t.Errorf("%s: expected %d warnings, got %v", test.name, len(test.warnings), warnings)
+			continue
+		}
+
+		for i, warning := range warnings {
+			if !strings.Contains(warning, test.warnings[i]) {
+				t.Errorf("%s: expected %q in %q", test.name, test.warnings[i], warning)
+			}
+		}
+	}
+}
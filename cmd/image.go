@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var imageCmd = &cobra.Command{
+	Use:   "image",
+	Short: "Manage images",
+	Long: `Manage images
+
+Images are the docker images your services run, stored in Amazon Elastic
+Container Registry (ECR).`,
+}
+
+func init() {
+	rootCmd.AddCommand(imageCmd)
+}
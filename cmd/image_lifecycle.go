@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/spf13/cobra"
+	"github.com/turnerlabs/fargate/console"
+	ECR "github.com/turnerlabs/fargate/ecr"
+	ECS "github.com/turnerlabs/fargate/ecs"
+)
+
+//defaultLifecycleKeep is how many images the default lifecycle policy keeps
+const defaultLifecycleKeep = 30
+
+type ImageLifecycleOperation struct {
+	ServiceName string
+	Keep        int
+	PolicyFile  string
+}
+
+func (o *ImageLifecycleOperation) Validate() error {
+	if o.Keep != 0 && o.PolicyFile != "" {
+		return fmt.Errorf("--keep and --policy-file can't be used together")
+	}
+
+	if o.Keep < 0 {
+		return fmt.Errorf("--keep must be at least 1")
+	}
+
+	return nil
+}
+
+//Policy returns the lifecycle policy document to apply
+func (o *ImageLifecycleOperation) Policy() (string, error) {
+	if o.PolicyFile != "" {
+		bits, err := ioutil.ReadFile(o.PolicyFile)
+
+		if err != nil {
+			return "", err
+		}
+
+		return string(bits), nil
+	}
+
+	if o.Keep == 0 {
+		return ECR.KeepMostRecentPolicy(defaultLifecycleKeep)
+	}
+
+	return ECR.KeepMostRecentPolicy(o.Keep)
+}
+
+var (
+	flagImageLifecycleKeep       int
+	flagImageLifecyclePolicyFile string
+)
+
+var imageLifecycleCmd = &cobra.Command{
+	Use:   "lifecycle [service-name]",
+	Short: "Prune old images from a service's ECR repository",
+	Long: `Prune old images from a service's ECR repository
+
+Sets a lifecycle policy on the ECR repository of the image the service is
+running that keeps the 30 most recent images (or --keep) and expires the rest,
+so the repository doesn't grow with every deploy. Any lifecycle policy the
+repository already has is replaced.
+
+To apply your own policy instead, pass --policy-file with a lifecycle policy
+JSON document. The service name defaults to --service.`,
+	Example: `
+fargate image lifecycle web --keep 10
+fargate image lifecycle web --policy-file lifecycle-policy.json
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		operation := &ImageLifecycleOperation{
+			Keep:       flagImageLifecycleKeep,
+			PolicyFile: flagImageLifecyclePolicyFile,
+		}
+
+		if len(args) == 1 {
+			operation.ServiceName = args[0]
+		} else {
+			operation.ServiceName = getServiceName()
+		}
+
+		if err := operation.Validate(); err != nil {
+			console.ErrorExit(err, "Invalid command line flags")
+		}
+
+		setImageLifecyclePolicy(operation)
+	},
+}
+
+func init() {
+	imageLifecycleCmd.Flags().IntVar(&flagImageLifecycleKeep, "keep", 0, fmt.Sprintf("Number of most recent images to keep (default %d)", defaultLifecycleKeep))
+	imageLifecycleCmd.Flags().StringVar(&flagImageLifecyclePolicyFile, "policy-file", "", "File containing a lifecycle policy JSON document to apply")
+
+	imageCmd.AddCommand(imageLifecycleCmd)
+}
+
+func setImageLifecyclePolicy(operation *ImageLifecycleOperation) {
+	policy, err := operation.Policy()
+
+	if err != nil {
+		console.ErrorExit(err, "Could not read lifecycle policy")
+	}
+
+	ecs := ECS.New(sess, getClusterName())
+	service := ecs.DescribeService(operation.ServiceName)
+	uri, ok := ECR.ParseImageURI(service.Image)
+
+	if !ok {
+		console.IssueExit("Image %s of service %s is not in ECR", service.Image, operation.ServiceName)
+	}
+
+	//the image may be in another region's registry
+	ecr := ECR.New(sess.Copy(&aws.Config{Region: aws.String(uri.Region)}))
+
+	if err := ecr.PutLifecyclePolicy(uri.RegistryID, uri.Repository, policy); err != nil {
+		console.ErrorExit(err, "Could not set lifecycle policy of ECR repository %s", uri.Repository)
+	}
+
+	console.Info("Set lifecycle policy of ECR repository %s", uri.Repository)
+}
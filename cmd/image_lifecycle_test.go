@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestImageLifecycleOperationValidate(t *testing.T) {
+	var tests = []struct {
+		keep       int
+		policyFile string
+		valid      bool
+	}{
+		{0, "", true},
+		{10, "", true},
+		{0, "policy.json", true},
+		{10, "policy.json", false},
+		{-1, "", false},
+	}
+
+	for _, test := range tests {
+		operation := &ImageLifecycleOperation{Keep: test.keep, PolicyFile: test.policyFile}
+
+		if err := operation.Validate(); (err == nil) != test.valid {
+			t.Errorf("Validate() with keep %d and policy file %q => %v, want valid %t", test.keep, test.policyFile, err, test.valid)
+		}
+	}
+}
+
+func TestImageLifecycleOperationPolicy(t *testing.T) {
+	policy, err := (&ImageLifecycleOperation{}).Policy()
+
+	if err != nil || !strings.Contains(policy, `"countNumber":30`) {
+		t.Errorf("expected the default policy to keep 30 images, got %s (%v)", policy, err)
+	}
+
+	policy, err = (&ImageLifecycleOperation{Keep: 5}).Policy()
+
+	if err != nil || !strings.Contains(policy, `"countNumber":5`) {
+		t.Errorf("expected the policy to keep 5 images, got %s (%v)", policy, err)
+	}
+
+	policy, err = (&ImageLifecycleOperation{PolicyFile: "./testdata/lifecycle-policy.json"}).Policy()
+
+	if err != nil || policy != "{\"rules\":[]}\n" {
+		t.Errorf("expected the policy file's contents, got %s (%v)", policy, err)
+	}
+}
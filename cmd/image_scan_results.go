@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/spf13/cobra"
+	"github.com/turnerlabs/fargate/console"
+	ECR "github.com/turnerlabs/fargate/ecr"
+	ECS "github.com/turnerlabs/fargate/ecs"
+)
+
+type ImageScanResultsOperation struct {
+	ServiceName string
+	ScanOnPush  bool
+}
+
+var flagImageScanResultsScanOnPush bool
+
+var imageScanResultsCmd = &cobra.Command{
+	Use:   "scan-results [service-name]",
+	Short: "Show vulnerability scan results for a service's image",
+	Long: `Show vulnerability scan results for a service's image
+
+Shows the status of the latest ECR vulnerability scan of the image the service
+is running and the number of findings of each severity. The service's image
+must be in ECR.
+
+Pass --scan-on-push to also turn on scan on push for the image's repository,
+so images pushed to it later are scanned automatically. Repositories that
+already scan on push are left as is. The service name defaults to --service.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		operation := &ImageScanResultsOperation{
+			ScanOnPush: flagImageScanResultsScanOnPush,
+		}
+
+		if len(args) == 1 {
+			operation.ServiceName = args[0]
+		} else {
+			operation.ServiceName = getServiceName()
+		}
+
+		getImageScanResults(operation)
+	},
+}
+
+func init() {
+	imageScanResultsCmd.Flags().BoolVar(&flagImageScanResultsScanOnPush, "scan-on-push", false, "Turn on scan on push for the image's repository")
+
+	imageCmd.AddCommand(imageScanResultsCmd)
+}
+
+func getImageScanResults(operation *ImageScanResultsOperation) {
+	ecs := ECS.New(sess, getClusterName())
+	service := ecs.DescribeService(operation.ServiceName)
+	uri, ok := ECR.ParseImageURI(service.Image)
+
+	if !ok {
+		console.IssueExit("Image %s of service %s is not in ECR", service.Image, operation.ServiceName)
+	}
+
+	//the image may be in another region's registry
+	ecr := ECR.New(sess.Copy(&aws.Config{Region: aws.String(uri.Region)}))
+
+	if operation.ScanOnPush {
+		if err := ecr.EnableScanOnPush(uri.RegistryID, uri.Repository); err != nil {
+			console.ErrorExit(err, "Could not turn on scan on push for ECR repository %s", uri.Repository)
+		}
+
+		console.Info("Turned on scan on push for ECR repository %s", uri.Repository)
+	}
+
+	findings, err := ecr.ImageScanFindings(uri)
+
+	if err == ECR.ErrScanNotFound {
+		console.IssueExit("Image %s has not been scanned", service.Image)
+	}
+
+	if err != nil {
+		console.ErrorExit(err, "Could not get scan results for image %s", service.Image)
+	}
+
+	if getOutput() == outputJSON {
+		printJSON(findings)
+		return
+	}
+
+	console.KeyValue("Image", "%s\n", service.Image)
+	console.KeyValue("Scan Status", "%s\n", findings.Status)
+
+	if findings.Description != "" {
+		console.KeyValue("Description", "%s\n", findings.Description)
+	}
+
+	console.Header("Findings")
+
+	for _, severity := range ECR.Severities {
+		console.KeyValue("  "+severity, "%d\n", findings.SeverityCounts[severity])
+	}
+}
@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var lbCmd = &cobra.Command{
+	Use:   "lb",
+	Short: "Manage load balancers",
+	Long: `Manage load balancers
+
+Load balancers distribute incoming traffic between the tasks within a service
+for HTTP/HTTPS and TCP applications.`,
+}
+
+func init() {
+	rootCmd.AddCommand(lbCmd)
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/turnerlabs/fargate/console"
+	ELBV2 "github.com/turnerlabs/fargate/elbv2"
+	"github.com/spf13/cobra"
+)
+
+type LbRulesOperation struct {
+	LoadBalancerName string
+}
+
+//lbRule is a listener rule as shown to users.
+type lbRule struct {
+	Listener    string   `json:"listener"`
+	Priority    string   `json:"priority"`
+	Conditions  []string `json:"conditions"`
+	TargetGroup string   `json:"targetGroup"`
+	ARN         string   `json:"arn"`
+}
+
+var lbRulesCmd = &cobra.Command{
+	Use:   "rules <load-balancer-name>",
+	Short: "List load balancer rules",
+	Long: `List load balancer rules
+
+Lists every rule on every listener of a load balancer with its priority,
+conditions, and the target group it forwards to. Use this to find stale rules
+left behind by deleted services, then remove them with lb rules delete.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		operation := &LbRulesOperation{
+			LoadBalancerName: args[0],
+		}
+
+		listLoadBalancerRules(operation)
+	},
+}
+
+func init() {
+	lbCmd.AddCommand(lbRulesCmd)
+}
+
+func listLoadBalancerRules(operation *LbRulesOperation) {
+	rules := describeLoadBalancerRules(operation.LoadBalancerName)
+
+	if getOutput() == outputJSON {
+		printJSON(rules)
+		return
+	}
+
+	if len(rules) == 0 {
+		console.Info("No rules found")
+		return
+	}
+
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 0, 8, 1, '\t', 0)
+	fmt.Fprintln(w, "LISTENER\tPRIORITY\tCONDITIONS\tTARGET GROUP\t")
+
+	for _, rule := range rules {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t\n",
+			rule.Listener,
+			rule.Priority,
+			strings.Join(rule.Conditions, ", "),
+			rule.TargetGroup,
+		)
+	}
+
+	w.Flush()
+}
+
+func describeLoadBalancerRules(loadBalancerName string) []lbRule {
+	var (
+		rules           []lbRule
+		targetGroupARNs []string
+	)
+
+	elbv2 := ELBV2.New(sess)
+	loadBalancer := elbv2.DescribeLoadBalancer(loadBalancerName)
+	targetGroupNames := make(map[string]string)
+
+	for _, listener := range elbv2.GetListeners(loadBalancer.ARN) {
+		listenerRules, err := elbv2.ListRules(listener.ARN)
+
+		if err != nil {
+			console.ErrorExit(err, "Could not list ELB rules")
+		}
+
+		for _, listenerRule := range listenerRules {
+			priority := fmt.Sprintf("%d", listenerRule.Priority)
+
+			if listenerRule.IsDefault {
+				priority = "default"
+			}
+
+			if listenerRule.TargetGroupARN != "" && !containsString(targetGroupARNs, listenerRule.TargetGroupARN) {
+				targetGroupARNs = append(targetGroupARNs, listenerRule.TargetGroupARN)
+			}
+
+			rules = append(rules, lbRule{
+				Listener:    listener.String(),
+				Priority:    priority,
+				Conditions:  listenerRule.Conditions,
+				TargetGroup: listenerRule.TargetGroupARN,
+				ARN:         listenerRule.ARN,
+			})
+		}
+	}
+
+	if len(targetGroupARNs) > 0 {
+		for _, targetGroup := range elbv2.DescribeTargetGroups(targetGroupARNs) {
+			targetGroupNames[targetGroup.Arn] = targetGroup.Name
+		}
+	}
+
+	for i, rule := range rules {
+		if name, ok := targetGroupNames[rule.TargetGroup]; ok {
+			rules[i].TargetGroup = name
+		}
+	}
+
+	return rules
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/turnerlabs/fargate/console"
+	ELBV2 "github.com/turnerlabs/fargate/elbv2"
+	"github.com/spf13/cobra"
+)
+
+type LbRulesDeleteOperation struct {
+	LoadBalancerName string
+	Priority         int
+	Port             int64
+}
+
+func (o *LbRulesDeleteOperation) SetPriority(inputPriority string) {
+	priority, err := strconv.Atoi(inputPriority)
+
+	if err != nil || priority < 1 {
+		console.IssueExit("Invalid priority %s, must be a positive number (default rules cannot be deleted)", inputPriority)
+	}
+
+	o.Priority = priority
+}
+
+var flagLbRulesDeletePort int64
+
+var lbRulesDeleteCmd = &cobra.Command{
+	Use:   "delete <load-balancer-name> <priority>",
+	Short: "Delete a load balancer rule",
+	Long: `Delete a load balancer rule
+
+Deletes the rule with the given priority from a load balancer. Priorities are
+unique per listener, so if more than one listener has a rule with the same
+priority, use --port to choose the listener. Default rules cannot be deleted.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		operation := &LbRulesDeleteOperation{
+			LoadBalancerName: args[0],
+			Port:             flagLbRulesDeletePort,
+		}
+
+		operation.SetPriority(args[1])
+
+		deleteLoadBalancerRule(operation)
+	},
+}
+
+func init() {
+	lbRulesDeleteCmd.Flags().Int64Var(&flagLbRulesDeletePort, "port", 0, "Port of the listener to delete the rule from")
+
+	lbRulesCmd.AddCommand(lbRulesDeleteCmd)
+}
+
+func deleteLoadBalancerRule(operation *LbRulesDeleteOperation) {
+	var matches []ELBV2.ListenerRule
+
+	elbv2 := ELBV2.New(sess)
+	loadBalancer := elbv2.DescribeLoadBalancer(operation.LoadBalancerName)
+
+	for _, listener := range elbv2.GetListeners(loadBalancer.ARN) {
+		if operation.Port != 0 && listener.Port != operation.Port {
+			continue
+		}
+
+		rules, err := elbv2.ListRules(listener.ARN)
+
+		if err != nil {
+			console.ErrorExit(err, "Could not list ELB rules")
+		}
+
+		matches = append(matches, findRulesByPriority(rules, operation.Priority)...)
+	}
+
+	switch len(matches) {
+	case 0:
+		console.IssueExit("No rule with priority %d found on load balancer %s", operation.Priority, operation.LoadBalancerName)
+	case 1:
+		elbv2.DeleteRule(matches[0].ARN)
+		console.Info("Deleted rule with priority %d from load balancer %s", operation.Priority, operation.LoadBalancerName)
+	default:
+		console.ErrorExit(
+			fmt.Errorf("%d listeners have a rule with priority %d", len(matches), operation.Priority),
+			"Specify the listener with --port",
+		)
+	}
+}
+
+func findRulesByPriority(rules ELBV2.ListenerRules, priority int) []ELBV2.ListenerRule {
+	var matches []ELBV2.ListenerRule
+
+	for _, rule := range rules {
+		if !rule.IsDefault && rule.Priority == priority {
+			matches = append(matches, rule)
+		}
+	}
+
+	return matches
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"testing"
+
+	ELBV2 "github.com/turnerlabs/fargate/elbv2"
+)
+
+func TestFindRulesByPriority(t *testing.T) {
+	rules := ELBV2.ListenerRules{
+		ELBV2.ListenerRule{ARN: "arn:rule/1", Priority: 1},
+		ELBV2.ListenerRule{ARN: "arn:rule/5", Priority: 5},
+		ELBV2.ListenerRule{ARN: "arn:rule/default", IsDefault: true},
+	}
+
+	if matches := findRulesByPriority(rules, 5); len(matches) != 1 || matches[0].ARN != "arn:rule/5" {
+		t.Errorf("expected arn:rule/5, got %v", matches)
+	}
+
+	if matches := findRulesByPriority(rules, 0); len(matches) != 0 {
+		t.Errorf("expected default rule to be excluded, got %v", matches)
+	}
+
+	if matches := findRulesByPriority(rules, 7); len(matches) != 0 {
+		t.Errorf("expected no matches, got %v", matches)
+	}
+}
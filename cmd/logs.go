@@ -3,7 +3,11 @@ package cmd
 import (
 	"fmt"
 	"math/rand"
+	"os"
+	"os/signal"
+	"sort"
 	"strings"
+	"syscall"
 	"time"
 
 	lru "github.com/hashicorp/golang-lru"
@@ -26,12 +30,15 @@ type GetLogsOperation struct {
 	EndTime           time.Time
 	Filter            string
 	Follow            bool
+	Grep              string
+	Invert            bool
 	LogStreamColors   map[string]int
 	LogStreamNames    []string
 	StartTime         time.Time
 	EventCache        *lru.Cache
 	IncludeTime       bool
 	NoLogStreamPrefix bool
+	TaskIDPrefix      bool
 }
 
 func (o *GetLogsOperation) AddStartTime(rawStartTime string) {
@@ -46,6 +53,17 @@ func (o *GetLogsOperation) AddEndTime(rawEndTime string) {
 	}
 }
 
+//AddSince starts logs the given duration ago, unless a start time was given
+func (o *GetLogsOperation) AddSince(since time.Duration) {
+	if since <= 0 {
+		console.ErrorExit(fmt.Errorf("--since must be a positive duration (e.g. 10m)"), "Invalid command line flags")
+	}
+
+	if o.StartTime.IsZero() {
+		o.StartTime = time.Now().Add(-since)
+	}
+}
+
 func (o *GetLogsOperation) AddTasks(tasks []string) {
 	for _, task := range tasks {
 		logStreamName := fmt.Sprintf(logStreamNameFormat, o.Namespace, task)
@@ -57,6 +75,55 @@ func (o *GetLogsOperation) Validate() {
 	if o.Follow && !o.EndTime.IsZero() {
 		console.ErrorExit(fmt.Errorf("--end-time cannot be specified if following"), "Invalid command line flags")
 	}
+
+	if !o.StartTime.IsZero() && !o.EndTime.IsZero() && !o.StartTime.Before(o.EndTime) {
+		console.ErrorExit(fmt.Errorf("--start must be before --end"), "Invalid command line flags")
+	}
+
+	if o.Grep != "" && o.Filter != "" {
+		console.ErrorExit(fmt.Errorf("--grep and --filter cannot be used together"), "Invalid command line flags")
+	}
+
+	if o.Invert && o.Grep == "" {
+		console.ErrorExit(fmt.Errorf("--invert requires --grep"), "Invalid command line flags")
+	}
+
+	if strings.Contains(o.Grep, `"`) {
+		console.ErrorExit(fmt.Errorf("--grep pattern cannot contain double quotes, use --filter instead"), "Invalid command line flags")
+	}
+
+	if err := validateFilterPattern(o.Filter); err != nil {
+		console.ErrorExit(err, "Invalid command line flags")
+	}
+}
+
+//FilterPattern returns the CloudWatch Logs filter pattern to search with.
+//Inverted greps can't be expressed as a filter pattern, so they're applied
+//as logs are printed instead.
+func (o *GetLogsOperation) FilterPattern() string {
+	if o.Grep != "" && !o.Invert {
+		return `"` + o.Grep + `"`
+	}
+
+	return o.Filter
+}
+
+//Matches returns whether a log message should be printed, given --grep and --invert
+func (o *GetLogsOperation) Matches(message string) bool {
+	if o.Grep == "" {
+		return true
+	}
+
+	return strings.Contains(message, o.Grep) != o.Invert
+}
+
+//Highlight emphasizes --grep matches in a log message
+func (o *GetLogsOperation) Highlight(message string) string {
+	if o.Grep == "" || o.Invert {
+		return message
+	}
+
+	return strings.Replace(message, o.Grep, console.Highlight(o.Grep), -1)
 }
 
 func (o *GetLogsOperation) GetStreamColor(logStreamName string) int {
@@ -85,23 +152,30 @@ func (o *GetLogsOperation) SeenEvent(eventId string) bool {
 }
 
 func (o *GetLogsOperation) parseTime(rawTime string) time.Time {
-	var t time.Time
+	t, err := parseTimeExpression(rawTime, time.Now())
 
-	if duration, err := time.ParseDuration(strings.ToLower(rawTime)); err == nil {
-		return time.Now().Add(duration)
+	if err != nil {
+		console.ErrorExit(err, "Invalid command line flags")
 	}
 
-	if t, err := time.Parse(timeFormat, rawTime); err == nil {
-		return t
-	}
+	return t
+}
 
-	if t, err := time.Parse(timeFormatWithZone, rawTime); err == nil {
-		return t
+//parseTimeExpression parses a duration relative to now (e.g. -1h), an RFC3339
+//timestamp (e.g. 2017-12-22T15:10:03-05:00), or a timestamp in the format
+//YYYY-MM-DD HH:MM:SS [TZ], which is UTC if no timezone is given
+func parseTimeExpression(rawTime string, now time.Time) (time.Time, error) {
+	if duration, err := time.ParseDuration(strings.ToLower(rawTime)); err == nil {
+		return now.Add(duration), nil
 	}
 
-	console.ErrorExit(fmt.Errorf("Could not parse %s", rawTime), "Invalid command line flags")
+	for _, layout := range []string{time.RFC3339, timeFormat, timeFormatWithZone} {
+		if t, err := time.Parse(layout, rawTime); err == nil {
+			return t, nil
+		}
+	}
 
-	return t
+	return time.Time{}, fmt.Errorf("Could not parse %s [expected a duration such as -1h, an RFC3339 timestamp, or YYYY-MM-DD HH:MM:SS [TZ]]", rawTime)
 }
 
 func GetLogs(operation *GetLogsOperation) {
@@ -114,8 +188,16 @@ func GetLogs(operation *GetLogsOperation) {
 	}
 }
 
+//followLogs polls for new log events until interrupted. Each poll starts a
+//little before the last one, since events can arrive out of order; events
+//already printed are skipped.
 func followLogs(operation *GetLogsOperation) {
 	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	//stop following, rather than exiting outright, on Control-C
+	ctx, stop := signal.NotifyContext(commandContext, os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	if operation.StartTime.IsZero() {
 		operation.StartTime = time.Now()
@@ -128,7 +210,15 @@ func followLogs(operation *GetLogsOperation) {
 			operation.StartTime = newStartTime
 		}
 
-		<-ticker.C
+		select {
+		case <-ctx.Done():
+			if timeoutExpired() {
+				console.IssueExit("Timed out after %s", getTimeout())
+			}
+
+			return
+		case <-ticker.C:
+		}
 	}
 }
 
@@ -137,12 +227,19 @@ func getLogs(operation *GetLogsOperation) {
 	input := &CWL.GetLogsInput{
 		LogStreamNames: operation.LogStreamNames,
 		LogGroupName:   operation.LogGroupName,
-		Filter:         operation.Filter,
+		Filter:         operation.FilterPattern(),
 		StartTime:      operation.StartTime,
 		EndTime:        operation.EndTime,
 	}
 
-	for _, logLine := range cwl.GetLogs(input) {
+	logLines := cwl.GetLogs(input)
+
+	//interleave events from every log stream in the order they happened
+	sort.SliceStable(logLines, func(i, j int) bool {
+		return logLines[i].Timestamp.Before(logLines[j].Timestamp)
+	})
+
+	for _, logLine := range logLines {
 
 		//format time if needed
 		var logTime string
@@ -153,8 +250,61 @@ func getLogs(operation *GetLogsOperation) {
 		// logLine.Timestamp
 		streamColor := operation.GetStreamColor(logLine.LogStreamName)
 
-		if !operation.SeenEvent(logLine.EventId) {
-			console.LogLine(logLine.LogStreamName, logLine.Message, streamColor, logTime, operation.NoLogStreamPrefix)
+		prefix := logLine.LogStreamName
+		if operation.TaskIDPrefix {
+			prefix = logStreamTaskID(prefix)
+		}
+
+		if !operation.SeenEvent(logLine.EventId) && operation.Matches(logLine.Message) {
+			console.LogLine(prefix, operation.Highlight(logLine.Message), streamColor, logTime, operation.NoLogStreamPrefix)
 		}
 	}
 }
+
+//logStreamTaskID returns the task ID a log stream is named after, e.g. the
+//last segment of fargate/web/0123456789abcdef0123456789abcdef
+func logStreamTaskID(logStreamName string) string {
+	return logStreamName[strings.LastIndex(logStreamName, "/")+1:]
+}
+
+//validateFilterPattern catches malformed CloudWatch Logs filter patterns, such
+//as unbalanced quotes or brackets, before they're sent to AWS
+func validateFilterPattern(pattern string) error {
+	var (
+		closers []rune
+		quoted  bool
+		escaped bool
+	)
+
+	pairs := map[rune]rune{'{': '}', '[': ']', '(': ')'}
+
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case pairs[r] != 0:
+			closers = append(closers, pairs[r])
+		case r == '}' || r == ']' || r == ')':
+			if len(closers) == 0 || closers[len(closers)-1] != r {
+				return fmt.Errorf("invalid filter pattern %s: unexpected %c", pattern, r)
+			}
+
+			closers = closers[:len(closers)-1]
+		}
+	}
+
+	if quoted {
+		return fmt.Errorf("invalid filter pattern %s: unterminated quote", pattern)
+	}
+
+	if len(closers) > 0 {
+		return fmt.Errorf("invalid filter pattern %s: missing %c", pattern, closers[len(closers)-1])
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/turnerlabs/fargate/console"
+)
+
+func TestValidateFilterPattern(t *testing.T) {
+	var tests = []struct {
+		pattern string
+		valid   bool
+	}{
+		{"", true},
+		{"ERROR", true},
+		{"ERROR -timeout", true},
+		{`"connection refused"`, true},
+		{`{ $.level = "error" }`, true},
+		{`[ip, user, status = 5*, size]`, true},
+		{`"it's a \"quote\""`, true},
+		{`"connection refused`, false},
+		{`{ $.level = "error"`, false},
+		{`[ip, user`, false},
+		{`{ $.level = "error" ]`, false},
+		{`}`, false},
+	}
+
+	for _, test := range tests {
+		err := validateFilterPattern(test.pattern)
+
+		if test.valid && err != nil {
+			t.Errorf("expected %s to be valid, got %v", test.pattern, err)
+		}
+
+		if !test.valid && err == nil {
+			t.Errorf("expected %s to be invalid", test.pattern)
+		}
+	}
+}
+
+func TestGetLogsOperationFilterPattern(t *testing.T) {
+	var tests = []struct {
+		operation GetLogsOperation
+		pattern   string
+	}{
+		{GetLogsOperation{Filter: "ERROR -timeout"}, "ERROR -timeout"},
+		{GetLogsOperation{Grep: "connection refused"}, `"connection refused"`},
+		{GetLogsOperation{Grep: "healthcheck", Invert: true}, ""},
+	}
+
+	for _, test := range tests {
+		if pattern := test.operation.FilterPattern(); pattern != test.pattern {
+			t.Errorf("expected %q, got %q", test.pattern, pattern)
+		}
+	}
+}
+
+func TestGetLogsOperationMatches(t *testing.T) {
+	grep := GetLogsOperation{Grep: "healthcheck"}
+	invert := GetLogsOperation{Grep: "healthcheck", Invert: true}
+	none := GetLogsOperation{}
+
+	if !grep.Matches("GET /healthcheck 200") || grep.Matches("GET /users 200") {
+		t.Error("expected --grep to only match messages containing the phrase")
+	}
+
+	if invert.Matches("GET /healthcheck 200") || !invert.Matches("GET /users 200") {
+		t.Error("expected --invert to only match messages without the phrase")
+	}
+
+	if !none.Matches("GET /users 200") {
+		t.Error("expected every message to match without --grep")
+	}
+}
+
+func TestGetLogsOperationHighlight(t *testing.T) {
+	color := console.Color
+	defer func() { console.Color = color }()
+
+	operation := GetLogsOperation{Grep: "ERROR"}
+
+	console.Color = false
+
+	if message := operation.Highlight("ERROR: boom"); message != "ERROR: boom" {
+		t.Errorf("expected message unchanged without color, got %q", message)
+	}
+
+	console.Color = true
+
+	if message := operation.Highlight("ERROR: boom"); message == "ERROR: boom" {
+		t.Error("expected match to be highlighted")
+	}
+
+	operation.Invert = true
+
+	if message := operation.Highlight("ERROR: boom"); message != "ERROR: boom" {
+		t.Errorf("expected no highlighting with --invert, got %q", message)
+	}
+}
+
+func TestLogStreamTaskID(t *testing.T) {
+	if id := logStreamTaskID("fargate/web/0123456789abcdef0123456789abcdef"); id != "0123456789abcdef0123456789abcdef" {
+		t.Errorf("expected task ID, got %s", id)
+	}
+}
+
+func TestGetLogsOperationAddSince(t *testing.T) {
+	operation := &GetLogsOperation{}
+	operation.AddSince(10 * time.Minute)
+
+	if since := time.Since(operation.StartTime); since < 10*time.Minute || since > 11*time.Minute {
+		t.Errorf("expected logs from 10 minutes ago, got %s", operation.StartTime)
+	}
+
+	start := time.Date(2021, 1, 20, 12, 0, 0, 0, time.UTC)
+	operation = &GetLogsOperation{StartTime: start}
+	operation.AddSince(10 * time.Minute)
+
+	if !operation.StartTime.Equal(start) {
+		t.Errorf("expected --start to take precedence, got %s", operation.StartTime)
+	}
+}
+
+func TestParseTimeExpression(t *testing.T) {
+	now := time.Date(2021, 1, 20, 12, 0, 0, 0, time.UTC)
+	est := time.FixedZone("EST", -5*60*60)
+
+	var tests = []struct {
+		raw      string
+		expected time.Time
+	}{
+		{"-1h", now.Add(-time.Hour)},
+		{"-1H10M", now.Add(-70 * time.Minute)},
+		{"2021-01-20T09:30:00Z", time.Date(2021, 1, 20, 9, 30, 0, 0, time.UTC)},
+		{"2021-01-20T09:30:00-05:00", time.Date(2021, 1, 20, 9, 30, 0, 0, est)},
+		{"2021-01-20 09:30:00", time.Date(2021, 1, 20, 9, 30, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		parsed, err := parseTimeExpression(test.raw, now)
+
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", test.raw, err)
+		} else if !parsed.Equal(test.expected) {
+			t.Errorf("%s: expected %s, got %s", test.raw, test.expected, parsed)
+		}
+	}
+
+	for _, raw := range []string{"yesterday", "2021-01-20", "2021-01-20T09:30"} {
+		if _, err := parseTimeExpression(raw, now); err == nil {
+			t.Errorf("%s: expected error, got none", raw)
+		}
+	}
+}
@@ -24,7 +24,7 @@ func (p Port) String() string {
 	return fmt.Sprintf("%s:%d", p.Protocol, p.Number)
 }
 
-var validProtocol = regexp.MustCompile("(?i)\\ATCP|HTTP(S)?\\z")
+var validProtocol = regexp.MustCompile("(?i)\\A(TCP|UDP|TCP_UDP|HTTPS?)\\z")
 
 func inflatePort(portExpr string) (Port, error) {
 	switch {
@@ -73,7 +73,7 @@ func inflatePorts(portExprs []string) ([]Port, []error) {
 
 func validatePort(port Port) (errs []error) {
 	if !validProtocol.MatchString(port.Protocol) {
-		errs = append(errs, fmt.Errorf("invalid protocol %s (specify TCP, HTTP, or HTTPS)", port.Protocol))
+		errs = append(errs, fmt.Errorf("invalid protocol %s (specify TCP, UDP, TCP_UDP, HTTP, or HTTPS)", port.Protocol))
 	}
 
 	if port.Number < 1 || port.Number > 65535 {
@@ -40,3 +40,40 @@ func TestString(t *testing.T) {
 		}
 	}
 }
+
+func TestInflatePort(t *testing.T) {
+	var tests = []struct {
+		expr string
+		port Port
+	}{
+		{"80", Port{80, "HTTP"}},
+		{"443", Port{443, "HTTPS"}},
+		{"8080", Port{8080, "TCP"}},
+		{"udp:1935", Port{1935, "UDP"}},
+		{"tcp_udp:53", Port{53, "TCP_UDP"}},
+	}
+
+	for _, test := range tests {
+		port, err := inflatePort(test.expr)
+
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", test.expr, err)
+		}
+
+		if port != test.port {
+			t.Errorf("%s: expected %s, got %s", test.expr, test.port, port)
+		}
+
+		if errs := validatePort(port); len(errs) > 0 {
+			t.Errorf("%s: expected valid port, got %v", test.expr, errs)
+		}
+	}
+}
+
+func TestValidatePortProtocol(t *testing.T) {
+	for _, protocol := range []string{"SCTP", "XTCP", "HTTPX", "TLS"} {
+		if errs := validatePort(Port{1935, protocol}); len(errs) == 0 {
+			t.Errorf("expected protocol %s to be invalid", protocol)
+		}
+	}
+}
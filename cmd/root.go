@@ -2,17 +2,27 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"net/url"
 	"os"
 	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/credentials/ssocreds"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sso"
 	"github.com/spf13/cobra"
+	ARN "github.com/turnerlabs/fargate/arn"
 	"github.com/turnerlabs/fargate/console"
 	ECS "github.com/turnerlabs/fargate/ecs"
 	"golang.org/x/crypto/ssh/terminal"
@@ -23,6 +33,8 @@ const (
 	defaultRegion      = "us-east-1"
 
 	mebibytesInGibibyte   = 1024
+	outputJSON            = "json"
+	outputText            = "text"
 	protocolHttp          = "HTTP"
 	protocolHttps         = "HTTPS"
 	protocolTcp           = "TCP"
@@ -32,6 +44,19 @@ const (
 	validRuleTypesPattern = "(?i)^host|path$"
 
 	describeRequestLimitRate = 10
+
+	//AWS API calls that are throttled or fail transiently are retried up to
+	//defaultMaxRetries times, backing off exponentially up to maxRetryDelay
+	defaultMaxRetries = 10
+	maxRetryDelay     = 20 * time.Second
+
+	//roles assumed with --assume-role-arn show up in CloudTrail under this
+	//session name
+	assumeRoleSessionName = "fargate"
+
+	//commands annotated as offline don't call AWS, so no session is created
+	//for them and they run without credentials
+	annotationOffline = "offline"
 )
 
 var InvalidCpuAndMemoryCombination = fmt.Errorf(`Invalid CPU and Memory settings
@@ -43,8 +68,45 @@ CPU (CPU Units)    Memory (MiB)
 1024               2048 through 8192 in 1GiB increments
 2048               4096 through 16384 in 1GiB increments
 4096               8192 through 30720 in 1GiB increments
+8192               16384 through 61440 in 4GiB increments
+16384              32768 through 122880 in 8GiB increments
 `)
 
+//cpuMemoryCombination is the memory Fargate allows for a CPU size: 512 when
+//Small is set, and Min through Max in Increment steps
+type cpuMemoryCombination struct {
+	CpuUnits  int64
+	Small     bool
+	Min       int64
+	Max       int64
+	Increment int64
+}
+
+var cpuMemoryCombinations = []cpuMemoryCombination{
+	{CpuUnits: 256, Small: true, Min: 1024, Max: 2048, Increment: 1024},
+	{CpuUnits: 512, Min: 1024, Max: 4096, Increment: 1024},
+	{CpuUnits: 1024, Min: 2048, Max: 8192, Increment: 1024},
+	{CpuUnits: 2048, Min: 4096, Max: 16384, Increment: 1024},
+	{CpuUnits: 4096, Min: 8192, Max: 30720, Increment: 1024},
+	{CpuUnits: 8192, Min: 16384, Max: 61440, Increment: 4096},
+	{CpuUnits: 16384, Min: 32768, Max: 122880, Increment: 8192},
+}
+
+//Mebibytes lists every memory value allowed with the combination's CPU
+func (c cpuMemoryCombination) Mebibytes() []int64 {
+	var mebibytes []int64
+
+	if c.Small {
+		mebibytes = append(mebibytes, 512)
+	}
+
+	for m := c.Min; m <= c.Max; m += c.Increment {
+		mebibytes = append(mebibytes, m)
+	}
+
+	return mebibytes
+}
+
 var validRegions = []string{
 	"us-east-1",
 	"us-east-2",
@@ -57,19 +119,46 @@ var validRegions = []string{
 	"ap-southeast-1",
 	"ap-southeast-2",
 	"ap-northeast-1",
-	"ap-northheast-2",
+	"ap-northeast-2",
+	"ap-northeast-3",
 	"ap-south-1",
+	"ap-east-1",
+	"eu-west-3",
+	"eu-north-1",
+	"eu-south-1",
+	"sa-east-1",
+	"me-south-1",
+	"af-south-1",
 }
 
+//fipsServices are the endpoint IDs of ECS, ECR, CloudWatch Logs, and STS,
+//which nearly every command calls
+var fipsServices = []string{"ecs", "api.ecr", "logs", "sts"}
+
+//commandContext is cancelled once the command has run longer than --timeout,
+//aborting the AWS requests, retries, and waits made with it
+var (
+	commandContext = context.Background()
+	cancelCommand  = func() {}
+)
+
 var (
-	clusterName string
-	noColor     bool
-	noEmoji     bool
-	output      ConsoleOutput
-	region      string
-	sess        *session.Session
-	verbose     bool
-	identifier  *regexp.Regexp
+	assumeRoleArn string
+	clusterName   string
+	endpointURL   string
+	externalID    string
+	fips          bool
+	maxRetries    int
+	noColor       bool
+	noEmoji       bool
+	output        ConsoleOutput
+	outputFormat  string
+	profile       string
+	region        string
+	sess          *session.Session
+	timeout       time.Duration
+	verbose       bool
+	identifier    *regexp.Regexp
 )
 
 var rootCmd = &cobra.Command{
@@ -102,24 +191,82 @@ CloudWatch Logs, and Amazon Route 53 into an easy-to-use CLI.`,
 			}
 		}
 
+		if err := validateOutput(getOutput()); err != nil {
+			console.IssueExit(err.Error())
+		}
+
+		startTimeout(getTimeout())
+
 		region = getRegion()
 
 		if err := validateRegion(region); err != nil {
 			console.IssueExit(err.Error())
 		}
 
+		if cmd.Annotations[annotationOffline] == "true" {
+			return
+		}
+
+		if getMaxRetries() < 0 {
+			console.IssueExit("--max-retries must be 0 or more")
+		}
+
+		if endpointURL := getEndpointURL(); endpointURL != "" {
+			if err := validateEndpointURL(endpointURL); err != nil {
+				console.IssueExit(err.Error())
+			}
+		}
+
+		if getAssumeRoleArn() != "" {
+			if err := validateAssumeRoleArn(getAssumeRoleArn()); err != nil {
+				console.IssueExit(err.Error())
+			}
+		} else if getExternalID() != "" {
+			console.IssueExit("--external-id requires --assume-role-arn")
+		}
+
 		config := &aws.Config{
 			Region: aws.String(region),
 		}
 
+		request.WithRetryer(config, retryer(getMaxRetries()))
+
 		if getVerbose() {
 			config.LogLevel = aws.LogLevel(aws.LogDebugWithHTTPBody)
 		}
 
+		if getFIPS() {
+			config.UseFIPSEndpoint = endpoints.FIPSEndpointStateEnabled
+		}
+
+		//send every AWS call to a single endpoint, such as LocalStack, which
+		//serves S3 buckets by path rather than by host name
+		if endpointURL := getEndpointURL(); endpointURL != "" {
+			config.Endpoint = aws.String(endpointURL)
+			config.S3ForcePathStyle = aws.Bool(true)
+		}
+
+		//load ~/.aws/config too, so SSO and credential_process profiles are
+		//part of the credential chain
 		sess = session.Must(
-			session.NewSession(config),
+			session.NewSessionWithOptions(
+				session.Options{
+					Config:            *config,
+					Profile:           getProfile(),
+					SharedConfigState: session.SharedConfigEnable,
+				},
+			),
 		)
 
+		sess.Handlers.Build.PushBack(withCommandContext)
+
+		//AWS_USE_FIPS_ENDPOINT is read by the session, so check its config
+		if sess.Config.UseFIPSEndpoint == endpoints.FIPSEndpointStateEnabled {
+			if err := validateFIPSEndpoints(region); err != nil {
+				console.IssueExit(err.Error())
+			}
+		}
+
 		_, err := sess.Config.Credentials.Get()
 
 		if aerr, ok := err.(awserr.Error); ok {
@@ -131,10 +278,19 @@ CloudWatch Logs, and Amazon Route 53 into an easy-to-use CLI.`,
 				console.Info("   See http://docs.aws.amazon.com/sdk-for-go/v1/developer-guide/configuring-sdk.html#specifying-credentials")
 				console.Info("   for more details.")
 				console.Exit(1)
+			case ssocreds.ErrCodeSSOProviderInvalidToken, sso.ErrCodeUnauthorizedException:
+				console.Issue("Your AWS SSO session has expired or you haven't logged in")
+				console.Info("Log in again with:")
+				console.Info("   %s", ssoLoginCommand(getProfile()))
+				console.Exit(1)
 			default:
 				console.ErrorExit(err, "Could not create create AWS session")
 			}
 		}
+
+		if getAssumeRoleArn() != "" {
+			sess = assumeRole(sess, getAssumeRoleArn(), getExternalID())
+		}
 	},
 }
 
@@ -142,14 +298,24 @@ CloudWatch Logs, and Amazon Route 53 into an easy-to-use CLI.`,
 func Execute(version string) {
 	rootCmd.Version = version
 	rootCmd.Execute()
+	cancelCommand()
 }
 
 func init() {
 
 	rootCmd.PersistentFlags().StringVar(&region, "region", "", `AWS region (default "us-east-1")`)
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", `AWS profile from your shared config and credentials files`)
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Verbose output")
 	rootCmd.PersistentFlags().BoolVar(&noColor, "nocolor", false, "Disable color output")
 	rootCmd.PersistentFlags().StringVarP(&clusterName, "cluster", "c", "", `ECS cluster name`)
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", outputText, `Output format for listings (text or json)`)
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, `Abort the command if it runs longer than this (e.g. 30s, 15m)`)
+	rootCmd.PersistentFlags().BoolVar(&fips, "fips", false, `Use FIPS endpoints for AWS services`)
+	rootCmd.PersistentFlags().StringVar(&assumeRoleArn, "assume-role-arn", "", `IAM role to assume with STS, e.g. to deploy into another account`)
+	rootCmd.PersistentFlags().StringVar(&externalID, "external-id", "", `External ID required by the role's trust policy (requires --assume-role-arn)`)
+	rootCmd.PersistentFlags().IntVar(&maxRetries, "max-retries", defaultMaxRetries, `Retry throttled AWS API calls up to this many times (0 disables retries)`)
+	rootCmd.PersistentFlags().StringVar(&endpointURL, "endpoint-url", "", `Send AWS API calls to this URL instead, e.g. LocalStack at http://localhost:4566`)
+	rootCmd.PersistentFlags().MarkHidden("endpoint-url")
 
 	if runtime.GOOS == runtimeMacOS {
 		rootCmd.PersistentFlags().BoolVar(&noEmoji, "no-emoji", false, "Disable emoji output")
@@ -158,6 +324,56 @@ func init() {
 	initConfig(rootCmd)
 }
 
+//validateEndpointURL checks that an --endpoint-url is an absolute http or https
+//URL
+func validateEndpointURL(endpointURL string) error {
+	u, err := url.Parse(endpointURL)
+
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("Invalid --endpoint-url %s, expected a URL such as http://localhost:4566", endpointURL)
+	}
+
+	return nil
+}
+
+//validateAssumeRoleArn checks that an --assume-role-arn is an IAM role ARN
+func validateAssumeRoleArn(roleArn string) error {
+	parsed, err := ARN.Parse(roleArn)
+
+	if err != nil || parsed.Service != "iam" || parsed.ResourceType != "role" {
+		return fmt.Errorf("Invalid --assume-role-arn %s, expected an IAM role ARN such as arn:aws:iam::123456789012:role/deploy", roleArn)
+	}
+
+	return nil
+}
+
+//assumeRole returns a copy of a session whose credentials are those of a role
+//assumed with the session's own credentials, so every client created from it
+//calls AWS as the role. The role is assumed right away, so a denied
+//AssumeRole call exits before any command runs.
+func assumeRole(base *session.Session, roleArn, externalID string) *session.Session {
+	credentials := stscreds.NewCredentials(base, roleArn, func(p *stscreds.AssumeRoleProvider) {
+		p.RoleSessionName = assumeRoleSessionName
+
+		if externalID != "" {
+			p.ExternalID = aws.String(externalID)
+		}
+	})
+
+	if _, err := credentials.Get(); err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "AccessDenied" {
+			console.Issue("Not authorized to assume role %s", roleArn)
+			console.Info("Check that the role's trust policy allows your identity to assume it and, if it")
+			console.Info("   requires one, that --external-id matches.")
+			console.Exit(1)
+		}
+
+		console.ErrorExit(err, "Could not assume role %s", roleArn)
+	}
+
+	return base.Copy(&aws.Config{Credentials: credentials})
+}
+
 //converts array of KEY=VALUE to array of EnvVar types
 func extractEnvVars(inputEnvVars []string) []ECS.EnvVar {
 	var envVars []ECS.EnvVar
@@ -205,53 +421,160 @@ func readVarFile(filename string) []string {
 	defer file.Close()
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
-		result = append(result, scanner.Text())
+		line := scanner.Text()
+
+		//skip blank lines and comments
+		if trimmed := strings.TrimSpace(line); trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		result = append(result, unquoteVarLine(line))
 	}
 
 	return result
 }
 
-func validateCpuAndMemory(inputCpuUnits, inputMebibytes string) error {
-	cpuUnits, err := strconv.ParseInt(inputCpuUnits, 10, 16)
+//unquotes a double quoted value in a KEY="value" line, as written by env export
+func unquoteVarLine(line string) string {
+	parts := strings.SplitN(line, "=", 2)
+
+	if len(parts) != 2 || len(parts[1]) < 2 || !strings.HasPrefix(parts[1], `"`) || !strings.HasSuffix(parts[1], `"`) {
+		return line
+	}
+
+	value, err := strconv.Unquote(parts[1])
+
+	if err != nil {
+		return line
+	}
+
+	return parts[0] + "=" + value
+}
+
+func validateCpuAndMemory(inputCpu, inputMemory string) error {
+	inputCpuUnits, err := normalizeCpu(inputCpu)
 
 	if err != nil {
 		return err
 	}
 
-	mebibytes, err := strconv.ParseInt(inputMebibytes, 10, 16)
+	inputMebibytes, err := normalizeMemory(inputMemory)
 
 	if err != nil {
 		return err
 	}
 
-	switch cpuUnits {
-	case 256:
-		if mebibytes == 512 || validateMebibytes(mebibytes, 1024, 2048) {
-			return nil
-		}
-	case 512:
-		if validateMebibytes(mebibytes, 1024, 4096) {
-			return nil
-		}
-	case 1024:
-		if validateMebibytes(mebibytes, 2048, 8192) {
-			return nil
-		}
-	case 2048:
-		if validateMebibytes(mebibytes, 4096, 16384) {
-			return nil
+	cpuUnits, err := strconv.ParseInt(inputCpuUnits, 10, 64)
+
+	if err != nil {
+		return err
+	}
+
+	mebibytes, err := strconv.ParseInt(inputMebibytes, 10, 64)
+
+	if err != nil {
+		return err
+	}
+
+	for _, combination := range cpuMemoryCombinations {
+		if combination.CpuUnits != cpuUnits {
+			continue
 		}
-	case 4096:
-		if validateMebibytes(mebibytes, 8192, 30720) {
-			return nil
+
+		var valid []string
+
+		for _, m := range combination.Mebibytes() {
+			if m == mebibytes {
+				return nil
+			}
+
+			valid = append(valid, strconv.FormatInt(m, 10))
 		}
+
+		return fmt.Errorf("%d MiB is not valid with %d CPU units, memory must be one of: %s MiB",
+			mebibytes, cpuUnits, strings.Join(valid, ", "))
 	}
 
 	return InvalidCpuAndMemoryCombination
 }
 
-func validateMebibytes(mebibytes, min, max int64) bool {
-	return mebibytes >= min && mebibytes <= max && mebibytes%mebibytesInGibibyte == 0
+//startTimeout bounds commandContext by the given duration, if there is one
+func startTimeout(d time.Duration) {
+	if d > 0 {
+		commandContext, cancelCommand = context.WithTimeout(context.Background(), d)
+	}
+}
+
+//withCommandContext makes AWS requests that weren't given a context of their
+//own use commandContext, so --timeout cancels them
+func withCommandContext(r *request.Request) {
+	if r.Context() == aws.BackgroundContext() {
+		r.SetContext(commandContext)
+	}
+}
+
+//timeoutExpired returns whether --timeout has expired. If it has, AWS
+//requests are no longer bound by it, so the command can still look up and
+//report what it was waiting on before exiting.
+func timeoutExpired() bool {
+	if commandContext.Err() == nil {
+		return false
+	}
+
+	commandContext, cancelCommand = context.Background(), func() {}
+
+	return true
+}
+
+func validateOutput(format string) error {
+	if format != outputText && format != outputJSON {
+		return fmt.Errorf("Invalid output format %s [specify %s or %s]", format, outputText, outputJSON)
+	}
+
+	return nil
+}
+
+//ssoLoginCommand returns the command to refresh cached SSO credentials for a
+//profile. Profiles that share an [sso-session] are logged in together, so
+//naming the profile is enough either way.
+func ssoLoginCommand(profile string) string {
+	if profile == "" {
+		return "aws sso login"
+	}
+
+	return fmt.Sprintf("aws sso login --profile %s", profile)
+}
+
+//retryer retries throttled and other transient AWS API errors up to
+//maxRetries times with exponential backoff and jitter
+func retryer(maxRetries int) client.DefaultRetryer {
+	return client.DefaultRetryer{
+		NumMaxRetries:    maxRetries,
+		MinRetryDelay:    client.DefaultRetryerMinRetryDelay,
+		MinThrottleDelay: client.DefaultRetryerMinThrottleDelay,
+		MaxRetryDelay:    maxRetryDelay,
+		MaxThrottleDelay: maxRetryDelay,
+	}
+}
+
+//validateFIPSEndpoints returns an error naming the services fargate relies on
+//that have no FIPS endpoint in a region
+func validateFIPSEndpoints(region string) error {
+	var missing []string
+
+	for _, service := range fipsServices {
+		_, err := endpoints.DefaultResolver().EndpointFor(service, region, endpoints.UseFIPSEndpointOption, endpoints.StrictMatchingOption)
+
+		if err != nil {
+			missing = append(missing, service)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("FIPS endpoints are not available in %s for: %s", region, strings.Join(missing, ", "))
+	}
+
+	return nil
 }
 
 func validateRegion(region string) error {
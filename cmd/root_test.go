@@ -1,48 +1,83 @@
 package cmd
 
-import "testing"
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client/metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/spf13/viper"
+)
 
 var validateCpuAndMemoryTests = []struct {
 	CpuUnits  string
 	Mebibytes string
-	Out       error
+	Valid     bool
 }{
 	// 0.25 vCpu
-	{"256", "512", nil},
-	{"256", "1024", nil},
-	{"256", "2048", nil},
-	{"256", "0", InvalidCpuAndMemoryCombination},
-	{"256", "768", InvalidCpuAndMemoryCombination},
-	{"256", "2151", InvalidCpuAndMemoryCombination},
-	{"256", "3072", InvalidCpuAndMemoryCombination},
+	{"256", "512", true},
+	{"256", "1024", true},
+	{"256", "2048", true},
+	{"256", "0", false},
+	{"256", "768", false},
+	{"256", "2151", false},
+	{"256", "3072", false},
 
 	// 0.5 vCpu
-	{"512", "1024", nil},
-	{"512", "2048", nil},
-	{"512", "3072", nil},
-	{"512", "4096", nil},
-	{"512", "512", InvalidCpuAndMemoryCombination},
+	{"512", "1024", true},
+	{"512", "2048", true},
+	{"512", "3072", true},
+	{"512", "4096", true},
+	{"512", "512", false},
 
 	// 1 vCpu
-	{"1024", "2048", nil},
-	{"1024", "5120", nil},
-	{"1024", "8192", nil},
-	{"1024", "1024", InvalidCpuAndMemoryCombination},
-	{"1024", "9216", InvalidCpuAndMemoryCombination},
+	{"1024", "2048", true},
+	{"1024", "5120", true},
+	{"1024", "8192", true},
+	{"1024", "1024", false},
+	{"1024", "9216", false},
 
 	// 2 vCpu
-	{"2048", "4096", nil},
-	{"2048", "10240", nil},
-	{"2048", "16384", nil},
-	{"2048", "3072", InvalidCpuAndMemoryCombination},
-	{"2048", "17408", InvalidCpuAndMemoryCombination},
+	{"2048", "4096", true},
+	{"2048", "10240", true},
+	{"2048", "16384", true},
+	{"2048", "3072", false},
+	{"2048", "17408", false},
 
 	// 4 vCpu
-	{"4096", "8192", nil},
-	{"4096", "15360", nil},
-	{"4096", "30720", nil},
-	{"4096", "1024", InvalidCpuAndMemoryCombination},
-	{"4096", "31744", InvalidCpuAndMemoryCombination},
+	{"4096", "8192", true},
+	{"4096", "15360", true},
+	{"4096", "30720", true},
+	{"4096", "1024", false},
+	{"4096", "31744", false},
+
+	// 8 vCpu
+	{"8192", "16384", true},
+	{"8192", "32768", true},
+	{"8192", "61440", true},
+	{"8192", "17408", false},
+	{"8192", "65536", false},
+
+	// 16 vCpu
+	{"16384", "32768", true},
+	{"16384", "65536", true},
+	{"16384", "122880", true},
+	{"16384", "36864", false},
+	{"16384", "131072", false},
+
+	// Unsupported CPU
+	{"3072", "8192", false},
+
+	// Human friendly units
+	{"0.25vcpu", "0.5GB", true},
+	{"0.5vcpu", "4GB", true},
+	{"1vcpu", "2048MiB", true},
+	{"4vcpu", "30GB", true},
+	{"0.25vcpu", "4GB", false},
+	{"16vcpu", "120GB", true},
 }
 
 func TestValidateCpuAndMemoryWithValidParameters(t *testing.T) {
@@ -63,14 +98,37 @@ func TestValidateCpuAndMemoryWithInvalidParameters(t *testing.T) {
 
 func TestValidateCpuAndMemory(t *testing.T) {
 	for _, test := range validateCpuAndMemoryTests {
-		s := validateCpuAndMemory(test.CpuUnits, test.Mebibytes)
+		err := validateCpuAndMemory(test.CpuUnits, test.Mebibytes)
 
-		if s != test.Out {
-			t.Errorf("validateCpuAndMemory(%s, %s) => %#v, want %s", test.CpuUnits, test.Mebibytes, s, test.Out)
+		if (err == nil) != test.Valid {
+			t.Errorf("validateCpuAndMemory(%s, %s) => %v, want valid %t", test.CpuUnits, test.Mebibytes, err, test.Valid)
 		}
 	}
 }
 
+func TestValidateCpuAndMemoryEveryCombination(t *testing.T) {
+	for _, combination := range cpuMemoryCombinations {
+		for _, mebibytes := range combination.Mebibytes() {
+			if err := validateCpuAndMemory(strconv.FormatInt(combination.CpuUnits, 10), strconv.FormatInt(mebibytes, 10)); err != nil {
+				t.Errorf("validateCpuAndMemory(%d, %d) => %v, want nil", combination.CpuUnits, mebibytes, err)
+			}
+		}
+	}
+}
+
+func TestValidateCpuAndMemoryListsValidMemory(t *testing.T) {
+	err := validateCpuAndMemory("256", "4096")
+	expected := "4096 MiB is not valid with 256 CPU units, memory must be one of: 512, 1024, 2048 MiB"
+
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected %q, got %v", expected, err)
+	}
+
+	if err := validateCpuAndMemory("3072", "8192"); err != InvalidCpuAndMemoryCombination {
+		t.Errorf("expected the combination table for an unsupported CPU, got %v", err)
+	}
+}
+
 func TestReadEnvFile(t *testing.T) {
 	expected := []string{"FOO=bar", "BAR=baz"}
 	results := readVarFile("./testdata/test.env")
@@ -105,6 +163,11 @@ func TestRegion(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
+	region = "ap-northeast-2"
+	err = validateRegion(region)
+	if err != nil {
+		t.Error(err)
+	}
 
 }
 
@@ -114,4 +177,179 @@ func TestRegion_Invalid(t *testing.T) {
 	if err == nil {
 		t.Error("expecting invalid region")
 	}
-}
\ No newline at end of file
+}
+func TestWithCommandContext(t *testing.T) {
+	defer func() { commandContext, cancelCommand = context.Background(), func() {} }()
+
+	newRequest := func() *request.Request {
+		return request.New(aws.Config{}, metadata.ClientInfo{}, request.Handlers{}, nil, &request.Operation{Name: "Test"}, nil, nil)
+	}
+
+	startTimeout(time.Millisecond)
+
+	r := newRequest()
+	withCommandContext(r)
+
+	if r.Context() != commandContext {
+		t.Error("expected a request without a context to use the command context")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r = newRequest()
+	r.SetContext(ctx)
+	withCommandContext(r)
+
+	if r.Context() != ctx {
+		t.Error("expected a request's own context to be kept")
+	}
+
+	<-commandContext.Done()
+
+	if !timeoutExpired() {
+		t.Error("expected the timeout to have expired")
+	}
+
+	if commandContext.Err() != nil {
+		t.Error("expected requests to no longer be bound by the expired timeout")
+	}
+}
+
+func TestRetryer(t *testing.T) {
+	if got := retryer(5).MaxRetries(); got != 5 {
+		t.Errorf("expected 5 retries, got %d", got)
+	}
+
+	if got := retryer(0).MaxRetries(); got != 0 {
+		t.Errorf("expected retries to be disabled, got %d", got)
+	}
+
+	if got := retryer(defaultMaxRetries).MaxThrottleDelay; got != maxRetryDelay {
+		t.Errorf("expected throttled retries to back off up to %s, got %s", maxRetryDelay, got)
+	}
+}
+
+func TestValidateEndpointURL(t *testing.T) {
+	var tests = []struct {
+		endpointURL string
+		valid       bool
+	}{
+		{"http://localhost:4566", true},
+		{"https://localstack.internal", true},
+		{"localhost:4566", false},
+		{"ftp://localhost:4566", false},
+		{"http://", false},
+	}
+
+	for _, test := range tests {
+		if err := validateEndpointURL(test.endpointURL); (err == nil) != test.valid {
+			t.Errorf("validateEndpointURL(%q) => %v, want valid %t", test.endpointURL, err, test.valid)
+		}
+	}
+}
+
+func TestValidateAssumeRoleArn(t *testing.T) {
+	var tests = []struct {
+		roleArn string
+		valid   bool
+	}{
+		{"arn:aws:iam::123456789012:role/deploy", true},
+		{"arn:aws-us-gov:iam::123456789012:role/ci/deploy", true},
+		{"arn:aws:iam::123456789012:user/deploy", false},
+		{"arn:aws:ecs:us-east-1:123456789012:role/deploy", false},
+		{"deploy", false},
+	}
+
+	for _, test := range tests {
+		if err := validateAssumeRoleArn(test.roleArn); (err == nil) != test.valid {
+			t.Errorf("validateAssumeRoleArn(%q) => %v, want valid %t", test.roleArn, err, test.valid)
+		}
+	}
+}
+
+func TestValidateFIPSEndpoints(t *testing.T) {
+	var tests = []struct {
+		region string
+		valid  bool
+	}{
+		{"us-east-1", true},
+		{"us-west-2", true},
+		{"eu-west-1", false},
+	}
+
+	for _, test := range tests {
+		if err := validateFIPSEndpoints(test.region); (err == nil) != test.valid {
+			t.Errorf("validateFIPSEndpoints(%q) => %v, want valid %t", test.region, err, test.valid)
+		}
+	}
+}
+
+func TestUnquoteVarLine(t *testing.T) {
+	var tests = []struct {
+		in  string
+		out string
+	}{
+		{"FOO=bar", "FOO=bar"},
+		{`FOO="bar baz"`, "FOO=bar baz"},
+		{`FOO="a \"b\""`, `FOO=a "b"`},
+		{`FOO="`, `FOO="`},
+		{`FOO="unterminated`, `FOO="unterminated`},
+		{"FOO", "FOO"},
+	}
+
+	for _, test := range tests {
+		if out := unquoteVarLine(test.in); out != test.out {
+			t.Errorf("unquoteVarLine(%s) => %s, want %s", test.in, out, test.out)
+		}
+	}
+}
+
+func TestValidateOutput(t *testing.T) {
+	var tests = []struct {
+		format string
+		valid  bool
+	}{
+		{"text", true},
+		{"json", true},
+		{"yaml", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		err := validateOutput(test.format)
+
+		if test.valid && err != nil {
+			t.Errorf("validateOutput(%q) returned %v, want nil", test.format, err)
+		}
+
+		if !test.valid && err == nil {
+			t.Errorf("validateOutput(%q) returned nil, want error", test.format)
+		}
+	}
+}
+
+func TestSSOLoginCommand(t *testing.T) {
+	if cmd := ssoLoginCommand(""); cmd != "aws sso login" {
+		t.Errorf("expected aws sso login, got %s", cmd)
+	}
+
+	if cmd := ssoLoginCommand("prod"); cmd != "aws sso login --profile prod" {
+		t.Errorf("expected aws sso login --profile prod, got %s", cmd)
+	}
+}
+
+func TestGetProfile(t *testing.T) {
+	t.Setenv("AWS_PROFILE", "from-env")
+
+	if profile := getProfile(); profile != "from-env" {
+		t.Errorf("expected from-env, got %s", profile)
+	}
+
+	viper.Set(keyProfile, "from-flag")
+	defer viper.Set(keyProfile, "")
+
+	if profile := getProfile(); profile != "from-flag" {
+		t.Errorf("expected from-flag, got %s", profile)
+	}
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	ARN "github.com/turnerlabs/fargate/arn"
+	"github.com/turnerlabs/fargate/console"
+	ECS "github.com/turnerlabs/fargate/ecs"
+	IAM "github.com/turnerlabs/fargate/iam"
+	"github.com/turnerlabs/fargate/sts"
+)
+
+//resolveSecrets expands secrets given as SSM parameter names (e.g. db-password
+//or /my-app/db-password) to parameter ARNs in the current region and account,
+//so the execution role can be granted access to exactly those parameters
+func resolveSecrets(secrets []ECS.Secret) []ECS.Secret {
+	for _, secret := range secrets {
+		if !strings.HasPrefix(secret.ValueFrom, "arn:") {
+			sts := sts.New(sess)
+			return resolveSecretArns(secrets, region, sts.GetCallerIdentity().Account)
+		}
+	}
+
+	return secrets
+}
+
+func resolveSecretArns(secrets []ECS.Secret, region, account string) []ECS.Secret {
+	var result []ECS.Secret
+
+	for _, secret := range secrets {
+		if !strings.HasPrefix(secret.ValueFrom, "arn:") {
+			secret.ValueFrom = fmt.Sprintf("arn:%s:ssm:%s:%s:parameter/%s",
+				ARN.Partition(region), region, account, strings.TrimPrefix(secret.ValueFrom, "/"))
+		}
+
+		result = append(result, secret)
+	}
+
+	return result
+}
+
+//grantSecretsRead allows a task definition's execution role, which fetches
+//secrets when tasks start, to read the given secrets
+func grantSecretsRead(ecs ECS.ECS, taskDefinition string, secrets []ECS.Secret) {
+	if len(secrets) == 0 {
+		return
+	}
+
+	dtd := ecs.DescribeTaskDefinition(taskDefinition)
+	executionRoleArn := aws.StringValue(dtd.TaskDefinition.ExecutionRoleArn)
+
+	if executionRoleArn == "" {
+		console.IssueExit("Task definition %s has no execution role, which is required to use secrets", taskDefinition)
+	}
+
+	var valueFroms []string
+
+	for _, secret := range secrets {
+		valueFroms = append(valueFroms, secret.ValueFrom)
+	}
+
+	if err := IAM.New(sess).GrantSecretsRead(executionRoleArn, valueFroms); err != nil {
+		console.ErrorExit(err, "Could not grant execution role %s access to secrets", IAM.RoleName(executionRoleArn))
+	}
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	ECS "github.com/turnerlabs/fargate/ecs"
+)
+
+func TestResolveSecretArns(t *testing.T) {
+	secrets := []ECS.Secret{
+		{Key: "DB_PASSWORD", ValueFrom: "/my-app/db-password"},
+		{Key: "API_KEY", ValueFrom: "api-key"},
+		{Key: "TOKEN", ValueFrom: "arn:aws:secretsmanager:us-east-1:123456789012:secret:token-AbCdEf"},
+	}
+
+	expected := []ECS.Secret{
+		{Key: "DB_PASSWORD", ValueFrom: "arn:aws:ssm:us-west-2:123456789012:parameter/my-app/db-password"},
+		{Key: "API_KEY", ValueFrom: "arn:aws:ssm:us-west-2:123456789012:parameter/api-key"},
+		{Key: "TOKEN", ValueFrom: "arn:aws:secretsmanager:us-east-1:123456789012:secret:token-AbCdEf"},
+	}
+
+	if got := resolveSecretArns(secrets, "us-west-2", "123456789012"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var securityGroupCmd = &cobra.Command{
+	Use:   "security-group",
+	Short: "Manage security groups",
+	Long: `Manage security groups
+
+Security groups control the traffic allowed to and from the tasks in a service
+or the load balancer in front of it.`,
+}
+
+func init() {
+	rootCmd.AddCommand(securityGroupCmd)
+}
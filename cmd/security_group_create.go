@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/turnerlabs/fargate/console"
+	EC2 "github.com/turnerlabs/fargate/ec2"
+	"github.com/spf13/cobra"
+)
+
+type SecurityGroupCreateOperation struct {
+	Description  string
+	IngressRules []EC2.IngressRule
+	Name         string
+	VPCID        string
+}
+
+func (o *SecurityGroupCreateOperation) Validate() error {
+	if o.VPCID == "" {
+		return errors.New("--vpc-id is required")
+	}
+
+	return nil
+}
+
+//SetIngressRules parses --ingress protocol:port:cidr flags
+func (o *SecurityGroupCreateOperation) SetIngressRules(inputRules []string) error {
+	for _, inputRule := range inputRules {
+		rule, err := EC2.ParseIngressRule(inputRule)
+
+		if err != nil {
+			return err
+		}
+
+		o.IngressRules = append(o.IngressRules, rule)
+	}
+
+	return nil
+}
+
+var (
+	flagSecurityGroupCreateDescription string
+	flagSecurityGroupCreateIngress     []string
+	flagSecurityGroupCreateVPCID       string
+)
+
+var securityGroupCreateCmd = &cobra.Command{
+	Use:   "create <security-group-name> --vpc-id <vpc-id> [--ingress <protocol:port:cidr>]",
+	Short: "Create a security group",
+	Long: `Create a security group
+
+Creates a security group in the given VPC and prints its ID. Allow inbound
+traffic with one or many --ingress flags of the form protocol:port:cidr, where
+the protocol is tcp or udp, the port is a port number or range (e.g.
+8000-8080), and the cidr is an IPv4 or IPv6 CIDR block. All outbound traffic
+is allowed.`,
+	Example: `
+fargate security-group create web --vpc-id vpc-1234567 --ingress tcp:443:0.0.0.0/0 --ingress tcp:80:0.0.0.0/0
+fargate security-group create workers --vpc-id vpc-1234567 --ingress tcp:8000-8080:10.0.0.0/16
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		operation := &SecurityGroupCreateOperation{
+			Description: flagSecurityGroupCreateDescription,
+			Name:        args[0],
+			VPCID:       flagSecurityGroupCreateVPCID,
+		}
+
+		if err := operation.SetIngressRules(flagSecurityGroupCreateIngress); err != nil {
+			console.ErrorExit(err, "Invalid command line flags")
+		}
+
+		if err := operation.Validate(); err != nil {
+			console.ErrorExit(err, "Invalid command line flags")
+		}
+
+		if operation.Description == "" {
+			operation.Description = operation.Name
+		}
+
+		createSecurityGroup(operation)
+	},
+}
+
+func init() {
+	securityGroupCreateCmd.Flags().StringVar(&flagSecurityGroupCreateDescription, "description", "", "Description of the security group (defaults to its name)")
+	securityGroupCreateCmd.Flags().StringArrayVar(&flagSecurityGroupCreateIngress, "ingress", []string{}, "Inbound traffic to allow [e.g. --ingress tcp:443:0.0.0.0/0]")
+	securityGroupCreateCmd.Flags().StringVar(&flagSecurityGroupCreateVPCID, "vpc-id", "", "ID of the VPC to create the security group in")
+
+	securityGroupCmd.AddCommand(securityGroupCreateCmd)
+}
+
+func createSecurityGroup(operation *SecurityGroupCreateOperation) {
+	ec2 := EC2.New(sess)
+
+	groupID, err := ec2.CreateSecurityGroup(operation.Name, operation.Description, operation.VPCID)
+
+	if err != nil {
+		console.ErrorExit(err, "Could not create security group")
+	}
+
+	console.Info("Created security group %s (%s)", operation.Name, groupID)
+
+	if err := ec2.AuthorizeSecurityGroupIngressRules(groupID, operation.IngressRules); err != nil {
+		console.ErrorExit(err, "Could not add ingress rules to security group %s", groupID)
+	}
+
+	for _, rule := range operation.IngressRules {
+		console.Info("Allowed ingress %s", rule)
+	}
+
+	console.KeyValue("Security Group ID", "%s\n", groupID)
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestSecurityGroupCreateOperationSetIngressRules(t *testing.T) {
+	operation := &SecurityGroupCreateOperation{Name: "web", VPCID: "vpc-1234567"}
+
+	if err := operation.SetIngressRules([]string{"tcp:443:0.0.0.0/0", "tcp:80:0.0.0.0/0"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(operation.IngressRules) != 2 || operation.IngressRules[0].FromPort != 443 || operation.IngressRules[1].FromPort != 80 {
+		t.Errorf("expected rules for ports 443 and 80, got %v", operation.IngressRules)
+	}
+
+	if err := operation.SetIngressRules([]string{"tcp:https:0.0.0.0/0"}); err == nil {
+		t.Error("expected an invalid port to be an error")
+	}
+}
+
+func TestSecurityGroupCreateOperationValidate(t *testing.T) {
+	if err := (&SecurityGroupCreateOperation{Name: "web", VPCID: "vpc-1234567"}).Validate(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := (&SecurityGroupCreateOperation{Name: "web"}).Validate(); err == nil {
+		t.Error("expected a missing VPC ID to be an error")
+	}
+}
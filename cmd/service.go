@@ -4,7 +4,12 @@ import (
 	"github.com/spf13/cobra"
 )
 
-const serviceLogGroupFormat = "/fargate/service/%s"
+const (
+	serviceLogGroupFormat = "/fargate/service/%s"
+
+	//typeService prefixes the task definition family of a service's tasks
+	typeService = "service"
+)
 
 var serviceCmd = &cobra.Command{
 	Use:   "service",
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	AAS "github.com/turnerlabs/fargate/applicationautoscaling"
+	"github.com/turnerlabs/fargate/console"
+	"github.com/spf13/cobra"
+)
+
+type ServiceAutoscaleOperation struct {
+	ServiceName  string
+	Min          int64
+	Max          int64
+	CPUTarget    float64
+	MemoryTarget float64
+	Remove       bool
+}
+
+func (o *ServiceAutoscaleOperation) Validate() error {
+	if o.Remove {
+		if o.Min != 0 || o.Max != 0 || o.CPUTarget != 0 || o.MemoryTarget != 0 {
+			return errors.New("--remove can't be used with --min, --max, --cpu-target, or --memory-target")
+		}
+
+		return nil
+	}
+
+	if o.Min < 0 {
+		return errors.New("--min must be 0 or greater")
+	}
+
+	if o.Max < 1 || o.Max < o.Min {
+		return errors.New("--max must be at least 1 and no less than --min")
+	}
+
+	if o.CPUTarget == 0 && o.MemoryTarget == 0 {
+		return errors.New("--cpu-target or --memory-target is required")
+	}
+
+	for _, target := range []float64{o.CPUTarget, o.MemoryTarget} {
+		if target < 0 || target > 100 {
+			return fmt.Errorf("invalid target %g, must be a utilization percentage (1-100)", target)
+		}
+	}
+
+	return nil
+}
+
+var (
+	flagServiceAutoscaleMin          int64
+	flagServiceAutoscaleMax          int64
+	flagServiceAutoscaleCPUTarget    float64
+	flagServiceAutoscaleMemoryTarget float64
+	flagServiceAutoscaleRemove       bool
+)
+
+var serviceAutoscaleCmd = &cobra.Command{
+	Use:   "autoscale --min <count> --max <count> [--cpu-target <percent>] [--memory-target <percent>]",
+	Short: "Scale a service's tasks with CPU or memory utilization",
+	Long: `Scale a service's tasks with CPU or memory utilization
+
+Keeps the service's average CPU and/or memory utilization near a target
+percentage by running between --min and --max tasks. When both targets are
+given, the service scales out if either is exceeded and only scales in when
+both allow it.
+
+The targets are created as Application Auto Scaling target tracking policies
+on the service, which replace any from a previous run of this command. Pass
+--remove to stop autoscaling the service; this also removes any schedule
+created with service schedule. The service keeps its current number of tasks.`,
+	Example: `
+fargate service autoscale --min 2 --max 10 --cpu-target 60
+fargate service autoscale --min 1 --max 4 --cpu-target 70 --memory-target 80
+fargate service autoscale --remove
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		operation := &ServiceAutoscaleOperation{
+			ServiceName:  getServiceName(),
+			Min:          flagServiceAutoscaleMin,
+			Max:          flagServiceAutoscaleMax,
+			CPUTarget:    flagServiceAutoscaleCPUTarget,
+			MemoryTarget: flagServiceAutoscaleMemoryTarget,
+			Remove:       flagServiceAutoscaleRemove,
+		}
+
+		if err := operation.Validate(); err != nil {
+			console.ErrorExit(err, "Invalid command line flags")
+		}
+
+		if operation.Remove {
+			removeServiceAutoscaling(operation)
+			return
+		}
+
+		autoscaleService(operation)
+	},
+}
+
+func init() {
+	serviceAutoscaleCmd.Flags().Int64Var(&flagServiceAutoscaleMin, "min", 0, "Minimum number of tasks")
+	serviceAutoscaleCmd.Flags().Int64Var(&flagServiceAutoscaleMax, "max", 0, "Maximum number of tasks")
+	serviceAutoscaleCmd.Flags().Float64Var(&flagServiceAutoscaleCPUTarget, "cpu-target", 0, "Average CPU utilization percentage to maintain [e.g. 60]")
+	serviceAutoscaleCmd.Flags().Float64Var(&flagServiceAutoscaleMemoryTarget, "memory-target", 0, "Average memory utilization percentage to maintain [e.g. 80]")
+	serviceAutoscaleCmd.Flags().BoolVar(&flagServiceAutoscaleRemove, "remove", false, "Stop autoscaling the service")
+
+	serviceCmd.AddCommand(serviceAutoscaleCmd)
+}
+
+func autoscaleService(operation *ServiceAutoscaleOperation) {
+	aas := AAS.New(sess)
+	resourceID := AAS.ServiceResourceID(getClusterName(), operation.ServiceName)
+
+	if err := aas.RegisterServiceScalableTarget(resourceID, operation.Min, operation.Max); err != nil {
+		console.ErrorExit(err, "Could not register service %s for scaling", operation.ServiceName)
+	}
+
+	for _, policy := range autoscalePolicies(operation) {
+		if policy.TargetValue == 0 {
+			if err := aas.DeleteServiceScalingPolicy(resourceID, policy.Name); err != nil {
+				console.ErrorExit(err, "Could not delete scaling policy %s", policy.Name)
+			}
+
+			continue
+		}
+
+		if err := aas.PutServiceTargetTrackingPolicy(resourceID, policy); err != nil {
+			console.ErrorExit(err, "Could not create scaling policy %s", policy.Name)
+		}
+
+		console.Info("Scaling service %s to keep %s at %g%%", operation.ServiceName, policy.Metric, policy.TargetValue)
+	}
+
+	console.Info("Service %s will run between %d and %d tasks", operation.ServiceName, operation.Min, operation.Max)
+}
+
+func removeServiceAutoscaling(operation *ServiceAutoscaleOperation) {
+	aas := AAS.New(sess)
+	resourceID := AAS.ServiceResourceID(getClusterName(), operation.ServiceName)
+
+	if err := aas.DeregisterServiceScalableTarget(resourceID); err != nil {
+		console.ErrorExit(err, "Could not stop autoscaling service %s", operation.ServiceName)
+	}
+
+	console.Info("Stopped autoscaling service %s", operation.ServiceName)
+}
+
+//autoscalePolicies returns the CPU and memory policies for a service. A policy whose target
+//wasn't given has a target value of 0 and is deleted rather than created.
+func autoscalePolicies(operation *ServiceAutoscaleOperation) []AAS.TargetTrackingPolicy {
+	return []AAS.TargetTrackingPolicy{
+		AAS.TargetTrackingPolicy{
+			Name:        fmt.Sprintf("fargate-%s-cpu", operation.ServiceName),
+			Metric:      AAS.MetricCPU,
+			TargetValue: operation.CPUTarget,
+		},
+		AAS.TargetTrackingPolicy{
+			Name:        fmt.Sprintf("fargate-%s-memory", operation.ServiceName),
+			Metric:      AAS.MetricMemory,
+			TargetValue: operation.MemoryTarget,
+		},
+	}
+}
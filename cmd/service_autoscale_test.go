@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestServiceAutoscaleOperationValidate(t *testing.T) {
+	var tests = []struct {
+		name      string
+		operation ServiceAutoscaleOperation
+		valid     bool
+	}{
+		{"cpu", ServiceAutoscaleOperation{Min: 2, Max: 10, CPUTarget: 60}, true},
+		{"cpu and memory", ServiceAutoscaleOperation{Min: 0, Max: 4, CPUTarget: 70, MemoryTarget: 80}, true},
+		{"remove", ServiceAutoscaleOperation{Remove: true}, true},
+		{"remove with flags", ServiceAutoscaleOperation{Remove: true, Max: 4}, false},
+		{"no target", ServiceAutoscaleOperation{Min: 1, Max: 4}, false},
+		{"max below min", ServiceAutoscaleOperation{Min: 4, Max: 2, CPUTarget: 60}, false},
+		{"no max", ServiceAutoscaleOperation{CPUTarget: 60}, false},
+		{"negative min", ServiceAutoscaleOperation{Min: -1, Max: 2, CPUTarget: 60}, false},
+		{"target over 100", ServiceAutoscaleOperation{Min: 1, Max: 2, MemoryTarget: 150}, false},
+	}
+
+	for _, test := range tests {
+		if err := test.operation.Validate(); (err == nil) != test.valid {
+			t.Errorf("%s: expected valid %t, got %v", test.name, test.valid, err)
+		}
+	}
+}
+
+func TestAutoscalePolicies(t *testing.T) {
+	policies := autoscalePolicies(&ServiceAutoscaleOperation{ServiceName: "web", CPUTarget: 60})
+
+	if len(policies) != 2 {
+		t.Fatalf("expected 2 policies, got %d", len(policies))
+	}
+
+	if policies[0].Name != "fargate-web-cpu" || policies[0].Metric != "ECSServiceAverageCPUUtilization" || policies[0].TargetValue != 60 {
+		t.Errorf("unexpected cpu policy: %+v", policies[0])
+	}
+
+	if policies[1].Name != "fargate-web-memory" || policies[1].TargetValue != 0 {
+		t.Errorf("expected memory policy to be removed, got %+v", policies[1])
+	}
+}
@@ -1,9 +1,15 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
 	"github.com/spf13/cobra"
 	"github.com/turnerlabs/fargate/console"
 	"github.com/turnerlabs/fargate/dockercompose"
+	ECR "github.com/turnerlabs/fargate/ecr"
 	ECS "github.com/turnerlabs/fargate/ecs"
 	"github.com/turnerlabs/fargate/sts"
 )
@@ -11,20 +17,34 @@ import (
 // ServiceDeployOperation represents a deploy operation
 type ServiceDeployOperation struct {
 	ServiceName    string
+	ServiceNames   []string
 	Image          string
 	ComposeFile    string
 	Region         string
 	Revision       string
 	WaitForService bool
+
+	NoReplicaRewrite  bool
+	RollbackOnTimeout bool
+	DeregisterOld     bool
+	KeepRevisions     int
 }
 
-const deployDockerComposeLabel = "aws.ecs.fargate.deploy"
+const (
+	deployDockerComposeLabel = "aws.ecs.fargate.deploy"
+	defaultKeepRevisions     = 5
+)
 
 var flagServiceDeployImage string
 var flagServiceDeployDockerComposeFile string
 var flagServiceDeployDockerComposeImageOnly bool
 var flagServiceDeployRevision string
 var flagServiceDeployWaitForService bool
+var flagServiceDeployServices []string
+var flagServiceDeployNoReplicaRewrite bool
+var flagServiceDeployRollbackOnTimeout bool
+var flagServiceDeployDeregisterOld bool
+var flagServiceDeployKeepRevisions int
 
 var serviceDeployCmd = &cobra.Command{
 	Use:   "deploy",
@@ -32,30 +52,76 @@ var serviceDeployCmd = &cobra.Command{
 	Long: `Deploy applications to services
 
 The Docker container image to use in the service can be specified
-via the --image flag.
+via the --image flag. A new task definition revision is registered with the
+image, keeping the rest of the current revision (CPU, memory, environment
+variables, and so on), and the old and new revision numbers are printed.
 
 The docker-compose.yml format is also supported using the --file flag.
 If -f is specified, the image and the environment variables in the
 docker-compose.yml file will be deployed.
 
+ECR images from another region are deployed from the service's region when
+ECR replication has copied them there, so tasks don't pull across regions.
+If the image hasn't been replicated, it's deployed as given with a warning.
+Use --no-replica-rewrite to always deploy the image as given.
+
 A task definition revision can be specified via the --revision flag.
 The revision number can either be absolute or a delta specified with a sign
 such as +5 or -2, where -2 is "2 configurations ago" from the current
 deployed revision.
+
+Services created with the CODE_DEPLOY or EXTERNAL deployment controller are
+rolled out by CodeDeploy or another tool. For those, deploy registers the new
+task definition revision and prints it without updating the service.
+
+Interrupting a --wait-for-service deploy (e.g. with Control-C) leaves the
+rollout running. The current deployment state and recent service events are
+printed and, unless the service's deployment circuit breaker is enabled, you
+are offered a roll back to the previous revision.
+
+If a --wait-for-service deploy doesn't reach a steady state, the service
+events reporting problems since the deployment started, such as tasks failing
+health checks or images that couldn't be pulled, are printed to explain why
+(see service events --failures-only).
+
+Pass --rollback-on-timeout with --wait-for-service to roll back to the
+previous revision automatically when the service doesn't reach a steady state,
+such as when its new tasks keep failing health checks. The roll back is also
+waited on, and the command exits non-zero either way so CI can detect the
+failed deploy.
+
+Pass --deregister-old with --wait-for-service to deregister superseded task
+definition revisions once the service reaches a steady state, keeping the
+newest --keep-revisions (default 5) active revisions of the family. Revisions
+still used by a deployment of any service in the region are never
+deregistered, so they remain available to roll back to.
+
+To deploy the same image, compose file, or revision to several services at
+once, list them with --services. Each service is deployed with the other flags
+given, up to 4 at a time, and the command fails if any of the deploys failed.
+Their output is interleaved, with each line prefixed by its service's name.
+On Ctrl-C the command waits for the deploys in progress to report where they
+got to and exit.
 `,
 	Example: `
 fargate service deploy -i 123456789.dkr.ecr.us-east-1.amazonaws.com/my-service:1.0
 fargate service deploy -f docker-compose.yml
 fargate service deploy -r 37
+fargate service deploy --services api,worker,scheduler -i 123456789.dkr.ecr.us-east-1.amazonaws.com/my-app:1.0
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		operation := &ServiceDeployOperation{
-			ServiceName:    getServiceName(),
+			ServiceNames:   flagServiceDeployServices,
 			Region:         region,
 			Image:          flagServiceDeployImage,
 			ComposeFile:    flagServiceDeployDockerComposeFile,
 			Revision:       flagServiceDeployRevision,
 			WaitForService: flagServiceDeployWaitForService,
+
+			NoReplicaRewrite:  flagServiceDeployNoReplicaRewrite,
+			RollbackOnTimeout: flagServiceDeployRollbackOnTimeout,
+			DeregisterOld:     flagServiceDeployDeregisterOld,
+			KeepRevisions:     flagServiceDeployKeepRevisions,
 		}
 
 		if !validateFlags(operation) {
@@ -63,6 +129,25 @@ fargate service deploy -r 37
 			return
 		}
 
+		if operation.RollbackOnTimeout && !operation.WaitForService {
+			console.IssueExit("--rollback-on-timeout requires --wait-for-service")
+		}
+
+		if operation.DeregisterOld && !operation.WaitForService {
+			console.IssueExit("--deregister-old requires --wait-for-service")
+		}
+
+		if operation.KeepRevisions < 1 {
+			console.IssueExit("--keep-revisions must be at least 1")
+		}
+
+		if len(operation.ServiceNames) > 0 {
+			deployServices(operation, cmd.Flags())
+			return
+		}
+
+		operation.ServiceName = getServiceName()
+
 		deployService(operation)
 	},
 }
@@ -78,6 +163,16 @@ func init() {
 
 	serviceDeployCmd.Flags().BoolVarP(&flagServiceDeployWaitForService, "wait-for-service", "w", false, "Wait for the service to reach a steady state after deploying the new task definition.")
 
+	serviceDeployCmd.Flags().BoolVar(&flagServiceDeployRollbackOnTimeout, "rollback-on-timeout", false, "Roll back to the previous revision if the service doesn't reach a steady state (requires --wait-for-service)")
+
+	serviceDeployCmd.Flags().BoolVar(&flagServiceDeployDeregisterOld, "deregister-old", false, "Deregister superseded task definition revisions once the service reaches a steady state (requires --wait-for-service)")
+
+	serviceDeployCmd.Flags().IntVar(&flagServiceDeployKeepRevisions, "keep-revisions", defaultKeepRevisions, "Number of the newest task definition revisions --deregister-old keeps")
+
+	serviceDeployCmd.Flags().BoolVar(&flagServiceDeployNoReplicaRewrite, "no-replica-rewrite", false, "Deploy ECR images as given, even when they are replicated to the service's region")
+
+	serviceDeployCmd.Flags().StringSliceVar(&flagServiceDeployServices, "services", []string{}, "Deploy to several services at once [e.g. --services api,worker]")
+
 	serviceCmd.AddCommand(serviceDeployCmd)
 }
 
@@ -92,11 +187,43 @@ func deployService(operation *ServiceDeployOperation) {
 		taskDefinitionArn = deployImage(operation)
 	}
 
+	//warn about container and load balancer health checks that disagree
+	ecs := ECS.New(sess, getClusterName())
+	warnHealthCheckConflicts(ecs.DescribeService(operation.ServiceName))
+
 	if operation.WaitForService {
-		ecs := ECS.New(sess, getClusterName())
+		if service := ecs.DescribeService(operation.ServiceName); !service.IsDeployedByECS() {
+			return
+		}
 
 		console.Info("Waiting for service %s to reach a steady state...", operation.ServiceName)
-		ecs.WaitUntilServiceStable(operation.ServiceName)
+
+		//stop waiting, rather than exiting outright, on Control-C
+		ctx, stop := signal.NotifyContext(commandContext, os.Interrupt, syscall.SIGTERM)
+		err := ecs.WaitUntilServiceStableWithContext(ctx, operation.ServiceName)
+		expired := timeoutExpired()
+		stop()
+
+		if ctx.Err() != nil && !expired {
+			deployInterrupted(&ecs, operation, taskDefinitionArn)
+		}
+
+		if err != nil {
+			if expired {
+				console.Issue("Timed out after %s waiting for service %s", getTimeout(), operation.ServiceName)
+			}
+
+			if operation.RollbackOnTimeout {
+				rollbackDeploy(&ecs, operation, taskDefinitionArn, err)
+			}
+
+			service := ecs.DescribeService(operation.ServiceName)
+
+			console.Issue("Service %s did not reach a steady state", operation.ServiceName)
+			console.Info(deploymentProgress(service))
+			printDeployFailures(service)
+			console.ErrorExit(err, "Could not wait for ECS service to reach a steady state")
+		}
 
 		//validate that the stable revision matches the deployed task
 		service := ecs.DescribeService(operation.ServiceName)
@@ -105,7 +232,146 @@ func deployService(operation *ServiceDeployOperation) {
 		} else {
 			console.Info("Service %s has reached a steady state.", operation.ServiceName)
 		}
+
+		if operation.DeregisterOld {
+			deregisterSupersededRevisions(&ecs, operation, taskDefinitionArn)
+		}
+	}
+}
+
+//deregisterSupersededRevisions deregisters a family's active revisions other
+//than the newest few and any still used by a deployment in the region
+func deregisterSupersededRevisions(ecs *ECS.ECS, operation *ServiceDeployOperation, taskDefinitionArn string) {
+	family := ecs.GetTaskFamily(taskDefinitionArn)
+	revisions, err := ecs.ListTaskDefinitionRevisions(family)
+
+	if err != nil {
+		console.ErrorExit(err, "Could not list task definition revisions")
+	}
+
+	//a revision is only deregistered once every service in the region is known
+	//not to use it
+	inUse := []string{taskDefinitionArn}
+	regionECS := ECS.New(sess, "")
+	clusterNames, err := regionECS.ListClusterNames()
+
+	if err != nil {
+		console.ErrorExit(err, "Could not list ECS clusters, no revisions were deregistered")
+	}
+
+	for _, cluster := range clusterNames {
+		clusterECS := ECS.New(sess, cluster)
+		taskDefinitionArns, err := clusterECS.ListServiceTaskDefinitions()
+
+		if err != nil {
+			console.ErrorExit(err, "Could not list the services in cluster %s, no revisions were deregistered", cluster)
+		}
+
+		inUse = append(inUse, taskDefinitionArns...)
+	}
+
+	superseded := ECS.SupersededRevisions(revisions, operation.KeepRevisions, inUse)
+
+	if len(superseded) == 0 {
+		console.Info("No superseded revisions of %s to deregister", family)
+		return
+	}
+
+	for _, revision := range superseded {
+		if err := ecs.DeregisterTaskDefinition(revision); err != nil {
+			console.ErrorExit(err, "Could not deregister task definition revision %s", ecs.GetRevisionNumber(revision))
+		}
+
+		console.Debug("Deregistered task definition %s", revision)
+	}
+
+	console.Info("Deregistered %d superseded revision(s) of %s, keeping the newest %d", len(superseded), family, operation.KeepRevisions)
+}
+
+//deployInterrupted reports the state of a rollout that was interrupted while
+//waiting for it and offers to roll back to the previous revision
+func deployInterrupted(ecs *ECS.ECS, operation *ServiceDeployOperation, taskDefinitionArn string) {
+	service := ecs.DescribeService(operation.ServiceName)
+
+	fmt.Println()
+	console.Issue("Interrupted while waiting for service %s; the deployment is still in progress", operation.ServiceName)
+	console.Info(deploymentProgress(service))
+	console.Header("Events")
+	printServiceEvents(service.Events)
+
+	if service.CircuitBreaker {
+		console.Info("The deployment circuit breaker is enabled, ECS will stop the deployment if its tasks fail to start")
+		console.Exit(1)
+	}
+
+	previous := previousDeploymentRevision(service, ecs.GetRevisionNumber(taskDefinitionArn))
+
+	if previous == "" {
+		console.Exit(1)
+	}
+
+	if !stdinIsTerminal() {
+		console.Info("To roll back, run: fargate service deploy --service %s --revision %s", operation.ServiceName, previous)
+		console.Exit(1)
+	}
+
+	fmt.Printf("Roll back service %s to revision %s? (yes/no)\n", operation.ServiceName, previous)
+
+	if askForConfirmation() {
+		deployRevision(
+			&ServiceDeployOperation{
+				ServiceName: operation.ServiceName,
+				Region:      operation.Region,
+				Revision:    previous,
+			},
+		)
+	}
+
+	console.Exit(1)
+}
+
+//rollbackDeploy rolls a service that didn't reach a steady state back to the
+//revision it was running before the deploy, waits for it to settle, and exits
+func rollbackDeploy(ecs *ECS.ECS, operation *ServiceDeployOperation, taskDefinitionArn string, err error) {
+	service := ecs.DescribeService(operation.ServiceName)
+	previous := previousDeploymentRevision(service, ecs.GetRevisionNumber(taskDefinitionArn))
+
+	console.Issue("Service %s did not reach a steady state: %s", operation.ServiceName, err)
+	console.Info(deploymentProgress(service))
+	printDeployFailures(service)
+
+	if previous == "" {
+		console.IssueExit("Could not find a previous revision of service %s to roll back to", operation.ServiceName)
+	}
+
+	console.Info("Rolling back service %s to revision %s...", operation.ServiceName, previous)
+
+	deployRevision(
+		&ServiceDeployOperation{
+			ServiceName: operation.ServiceName,
+			Region:      operation.Region,
+			Revision:    previous,
+		},
+	)
+
+	if err := ecs.WaitUntilServiceStableWithContext(commandContext, operation.ServiceName); err != nil {
+		console.ErrorExit(err, "Could not wait for ECS service to reach a steady state")
+	}
+
+	console.Issue("Rolled back service %s to revision %s", operation.ServiceName, previous)
+	console.Exit(1)
+}
+
+//previousDeploymentRevision returns the revision of the deployment being
+//replaced by a rollout to the given revision, if there is one
+func previousDeploymentRevision(service ECS.Service, revision string) string {
+	for _, deployment := range service.Deployments {
+		if deployment.Status == "ACTIVE" && deployment.Id != revision {
+			return deployment.Id
+		}
 	}
+
+	return ""
 }
 
 //deploy a docker-compose.yml file to fargate
@@ -116,6 +382,7 @@ func deployDockerComposeFile(operation *ServiceDeployOperation) string {
 	ecsService := ecs.DescribeService(operation.ServiceName)
 
 	dockerService := getDockerServiceFromComposeFile(operation.ComposeFile)
+	dockerService.Image = replicaImage(operation, dockerService.Image)
 
 	envvars := convertDockerComposeEnvVarsToECSEnvVars(dockerService)
 	secrets := convertDockerComposeSecretsToECSSecrets(dockerService)
@@ -123,17 +390,20 @@ func deployDockerComposeFile(operation *ServiceDeployOperation) string {
 	//if --image-only flag is set, update image only
 	if flagServiceDeployDockerComposeImageOnly {
 		//register a new task definition based on the image from the compose file
-		taskDefinitionArn = ecs.UpdateTaskDefinitionImage(ecsService.TaskDefinitionArn, dockerService.Image)
+		taskDefinitionArn = ecs.UpdateTaskDefinitionImage(ecsService.TaskDefinitionArn, ecsService.ContainerName, dockerService.Image)
 	} else {
 		//register a new task definition based on the image and environment variables from the compose file
-		taskDefinitionArn = ecs.UpdateTaskDefinitionImageAndEnvVars(ecsService.TaskDefinitionArn, dockerService.Image, envvars, true, secrets)
+		taskDefinitionArn = ecs.UpdateTaskDefinitionImageAndEnvVars(ecsService.TaskDefinitionArn, dockerService.Image, envvars, true, ECS.TaskDefinitionUpdate{ContainerName: ecsService.ContainerName, Secrets: secrets})
 	}
 
 	//update service with new task definition
-	ecs.UpdateServiceTaskDefinition(operation.ServiceName, taskDefinitionArn)
+	if !updateServiceTaskDefinition(&ecs, ecsService, taskDefinitionArn) {
+		return taskDefinitionArn
+	}
 
 	if flagServiceDeployDockerComposeImageOnly {
-		console.Info("Deployed %s to service %s", dockerService.Image, operation.ServiceName)
+		console.Info("Deployed %s to service %s, revision %s to %s", dockerService.Image, operation.ServiceName,
+			ecs.GetRevisionNumber(ecsService.TaskDefinitionArn), ecs.GetRevisionNumber(taskDefinitionArn))
 	} else {
 		console.Info("Deployed %s to service %s as revision %s", operation.ComposeFile, operation.ServiceName, ecs.GetRevisionNumber(taskDefinitionArn))
 	}
@@ -158,7 +428,9 @@ func deployRevision(operation *ServiceDeployOperation) string {
 
 	taskDefinitionArn := ecs.GetTaskDefinitionARN(operation.Region, account, taskFamily, revisionNumber)
 
-	ecs.UpdateServiceTaskDefinition(operation.ServiceName, taskDefinitionArn)
+	if !updateServiceTaskDefinition(&ecs, service, taskDefinitionArn) {
+		return taskDefinitionArn
+	}
 
 	console.Info("Deployed revision %s to service %s.", revisionNumber, operation.ServiceName)
 
@@ -168,15 +440,63 @@ func deployRevision(operation *ServiceDeployOperation) string {
 func deployImage(operation *ServiceDeployOperation) string {
 	ecs := ECS.New(sess, getClusterName())
 	service := ecs.DescribeService(operation.ServiceName)
-	taskDefinitionArn := ecs.UpdateTaskDefinitionImage(service.TaskDefinitionArn, operation.Image)
+	image := replicaImage(operation, operation.Image)
+	taskDefinitionArn := ecs.UpdateTaskDefinitionImage(service.TaskDefinitionArn, service.ContainerName, image)
 
-	ecs.UpdateServiceTaskDefinition(operation.ServiceName, taskDefinitionArn)
+	if !updateServiceTaskDefinition(&ecs, service, taskDefinitionArn) {
+		return taskDefinitionArn
+	}
 
-	console.Info("Deployed %s to service %s", operation.Image, operation.ServiceName)
+	console.Info("Deployed %s to service %s, revision %s to %s", image, operation.ServiceName,
+		ecs.GetRevisionNumber(service.TaskDefinitionArn), ecs.GetRevisionNumber(taskDefinitionArn))
 
 	return taskDefinitionArn
 }
 
+//replicaImage rewrites an ECR image from another region to the same image in
+//the service's region, when replication has copied it there, so tasks don't
+//pull across regions
+func replicaImage(operation *ServiceDeployOperation, image string) string {
+	uri, ok := ECR.ParseImageURI(image)
+
+	if operation.NoReplicaRewrite || !ok || uri.Region == operation.Region {
+		return image
+	}
+
+	replica := uri.InRegion(operation.Region)
+	exists, err := ECR.New(sess).ImageExists(replica)
+
+	if err != nil {
+		console.Issue("Could not look for a replica of %s in %s, deploying it from %s: %s", image, operation.Region, uri.Region, err)
+		return image
+	}
+
+	if !exists {
+		console.Issue("%s is not replicated to %s, deploying it from %s", image, operation.Region, uri.Region)
+		return image
+	}
+
+	console.Info("Using %s, the %s replica of %s", replica, operation.Region, image)
+
+	return replica.String()
+}
+
+//updateServiceTaskDefinition points the service at a new task definition
+//revision. Services using the CODE_DEPLOY or EXTERNAL deployment controllers
+//can't be updated this way, so the revision is only reported for the user to
+//roll out through their deployment controller.
+func updateServiceTaskDefinition(ecs *ECS.ECS, service ECS.Service, taskDefinitionArn string) bool {
+	if !service.IsDeployedByECS() {
+		console.Info("Service %s is deployed by %s; create a deployment there for revision %s", service.Name, service.DeploymentController, ecs.GetRevisionNumber(taskDefinitionArn))
+		console.Info("Task definition: %s", taskDefinitionArn)
+		return false
+	}
+
+	ecs.UpdateServiceTaskDefinition(service.Name, taskDefinitionArn)
+
+	return true
+}
+
 func getDockerServiceFromComposeFile(dockerComposeFile string) *dockercompose.Service {
 	//read the compose file configuration
 	composeFile, err := dockercompose.Read(dockerComposeFile)
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/spf13/pflag"
+	"github.com/turnerlabs/fargate/console"
+)
+
+//maxConcurrentDeploys bounds how many services are deployed at once
+const maxConcurrentDeploys = 4
+
+type serviceDeployResult struct {
+	ServiceName string
+	Err         error
+}
+
+//deployServices deploys the same image, compose file, or revision to several
+//services concurrently. Each service is deployed by running this binary's
+//single service deploy with the same flags so that one service failing
+//doesn't abort the others. Their output is printed line by line as it's
+//written, prefixed with the service name.
+func deployServices(operation *ServiceDeployOperation, flags *pflag.FlagSet) {
+	executable, err := os.Executable()
+	if err != nil {
+		console.ErrorExit(err, "Could not locate the fargate executable")
+	}
+
+	results := make([]serviceDeployResult, len(operation.ServiceNames))
+	slots := make(chan struct{}, maxConcurrentDeploys)
+	running := map[string]*exec.Cmd{}
+	done := make(chan struct{})
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	//Ctrl-C reaches the deploys through the terminal, so keep running until
+	//they have reported where they got to. A SIGTERM sent to this process
+	//alone is passed on to them.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				mu.Lock()
+				console.Issue("Interrupted, waiting for the deploys in progress to exit")
+
+				if sig == syscall.SIGTERM {
+					for _, deploy := range running {
+						deploy.Process.Signal(sig)
+					}
+				}
+
+				mu.Unlock()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	console.Info("Deploying to services %s...", strings.Join(operation.ServiceNames, ", "))
+
+	for i, serviceName := range operation.ServiceNames {
+		wg.Add(1)
+
+		go func(i int, serviceName string) {
+			defer wg.Done()
+
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			output := &prefixWriter{prefix: serviceName + " | ", mu: &mu, out: os.Stdout}
+			deploy := exec.Command(executable, serviceDeployArgs(flags, serviceName)...)
+			deploy.Stdout = output
+			deploy.Stderr = output
+
+			mu.Lock()
+			err := deploy.Start()
+
+			if err == nil {
+				running[serviceName] = deploy
+			}
+
+			mu.Unlock()
+
+			if err == nil {
+				err = deploy.Wait()
+			}
+
+			mu.Lock()
+			delete(running, serviceName)
+			mu.Unlock()
+
+			output.Flush()
+
+			results[i] = serviceDeployResult{
+				ServiceName: serviceName,
+				Err:         err,
+			}
+		}(i, serviceName)
+	}
+
+	wg.Wait()
+	close(done)
+
+	failed := failedServiceDeploys(results)
+
+	if len(failed) > 0 {
+		console.IssueExit("Deploy failed for %d of %d services: %s", len(failed), len(results), strings.Join(failed, ", "))
+	}
+
+	console.Info("Deployed to %d services", len(results))
+}
+
+//failedServiceDeploys returns the names of the services whose deploy failed
+func failedServiceDeploys(results []serviceDeployResult) []string {
+	var failed []string
+
+	for _, result := range results {
+		if result.Err != nil {
+			failed = append(failed, result.ServiceName)
+		}
+	}
+
+	return failed
+}
+
+//serviceDeployArgs builds the single service deploy command line for one of
+//a multi-service deploy's services, passing on every flag that was set
+//except --services and --service. --services is the only list flag deploy
+//takes, so every other flag's value can be passed on as is.
+func serviceDeployArgs(flags *pflag.FlagSet, serviceName string) []string {
+	args := []string{"service", "deploy", "--service", serviceName}
+
+	flags.Visit(
+		func(flag *pflag.Flag) {
+			if flag.Name == "services" || flag.Name == "service" {
+				return
+			}
+
+			args = append(args, fmt.Sprintf("--%s=%s", flag.Name, flag.Value.String()))
+		},
+	)
+
+	return args
+}
+
+//prefixWriter writes each complete line written to it to out with a prefix,
+//holding mu so lines from concurrent writers aren't interleaved
+type prefixWriter struct {
+	prefix string
+	mu     *sync.Mutex
+	out    io.Writer
+	buf    []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+
+		if i < 0 {
+			break
+		}
+
+		w.writeLine(w.buf[:i+1])
+		w.buf = w.buf[i+1:]
+	}
+
+	return len(p), nil
+}
+
+//Flush writes the last line if it didn't end in a newline
+func (w *prefixWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.writeLine(append(w.buf, '\n'))
+		w.buf = nil
+	}
+}
+
+func (w *prefixWriter) writeLine(line []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	fmt.Fprintf(w.out, "%s%s", w.prefix, line)
+}
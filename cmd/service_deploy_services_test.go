@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestServiceDeployArgs(t *testing.T) {
+	flags := pflag.NewFlagSet("deploy", pflag.ContinueOnError)
+	flags.StringP("cluster", "c", "", "")
+	flags.StringP("image", "i", "", "")
+	flags.StringP("service", "s", "", "")
+	flags.StringSlice("services", []string{}, "")
+	flags.BoolP("wait-for-service", "w", false, "")
+	flags.Bool("deregister-old", false, "")
+	flags.Int("keep-revisions", 5, "")
+	flags.String("profile", "", "")
+
+	err := flags.Parse([]string{
+		"--services", "api,worker",
+		"-s", "api",
+		"-c", "my-cluster",
+		"-i", "123456789.dkr.ecr.us-east-1.amazonaws.com/my-app:1.0",
+		"-w",
+		"--keep-revisions", "3",
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"service", "deploy",
+		"--service", "worker",
+		"--cluster=my-cluster",
+		"--image=123456789.dkr.ecr.us-east-1.amazonaws.com/my-app:1.0",
+		"--keep-revisions=3",
+		"--wait-for-service=true",
+	}
+
+	if args := serviceDeployArgs(flags, "worker"); !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected %v, got %v", expected, args)
+	}
+}
+
+func TestFailedServiceDeploys(t *testing.T) {
+	results := []serviceDeployResult{
+		{ServiceName: "api"},
+		{ServiceName: "worker", Err: errors.New("exit status 1")},
+		{ServiceName: "scheduler"},
+	}
+
+	if failed := failedServiceDeploys(results); !reflect.DeepEqual(failed, []string{"worker"}) {
+		t.Errorf("expected [worker], got %v", failed)
+	}
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+
+	w := &prefixWriter{prefix: "api | ", mu: &sync.Mutex{}, out: &out}
+
+	w.Write([]byte("[i] Deploying\n[i] Wait"))
+	w.Write([]byte("ing for service\n[!] Interrupted"))
+	w.Flush()
+
+	expected := "api | [i] Deploying\napi | [i] Waiting for service\napi | [!] Interrupted\n"
+
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/turnerlabs/fargate/console"
 	"github.com/turnerlabs/fargate/dockercompose"
+	ECS "github.com/turnerlabs/fargate/ecs"
 )
 
 func TestGetDockerServiceToDeploy_Happy(t *testing.T) {
@@ -141,3 +142,41 @@ services:
 		t.Errorf("expected: %s, got: %s", expected, got)
 	}
 }
+
+func TestPreviousDeploymentRevision(t *testing.T) {
+	service := ECS.Service{
+		Deployments: []ECS.Deployment{
+			{Id: "38", Status: "PRIMARY"},
+			{Id: "37", Status: "ACTIVE"},
+		},
+	}
+
+	if revision := previousDeploymentRevision(service, "38"); revision != "37" {
+		t.Errorf("expected 37, got %s", revision)
+	}
+
+	service.Deployments = service.Deployments[:1]
+
+	if revision := previousDeploymentRevision(service, "38"); revision != "" {
+		t.Errorf("expected no previous revision, got %s", revision)
+	}
+}
+
+func TestReplicaImage_NotRewritten(t *testing.T) {
+	var tests = []struct {
+		image            string
+		noReplicaRewrite bool
+	}{
+		{"nginx:latest", false},
+		{"123456789012.dkr.ecr.us-west-2.amazonaws.com/web:1.0", false},
+		{"123456789012.dkr.ecr.us-east-1.amazonaws.com/web:1.0", true},
+	}
+
+	for _, test := range tests {
+		operation := &ServiceDeployOperation{Region: "us-west-2", NoReplicaRewrite: test.noReplicaRewrite}
+
+		if got := replicaImage(operation, test.image); got != test.image {
+			t.Errorf("replicaImage(%q) => %s, want it unchanged", test.image, got)
+		}
+	}
+}
@@ -0,0 +1,344 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	CWL "github.com/turnerlabs/fargate/cloudwatchlogs"
+	"github.com/turnerlabs/fargate/console"
+	ECR "github.com/turnerlabs/fargate/ecr"
+	ECS "github.com/turnerlabs/fargate/ecs"
+	ELBV2 "github.com/turnerlabs/fargate/elbv2"
+	"github.com/spf13/cobra"
+)
+
+type ServiceDestroyOperation struct {
+	ServiceName             string
+	PropagateDeregistration bool
+	Purge                   bool
+	Yes                     bool
+}
+
+var (
+	flagServiceDestroyPropagateDeregistration bool
+	flagServiceDestroyPurge                   bool
+	flagServiceDestroyYes                     bool
+)
+
+var serviceDestroyCmd = &cobra.Command{
+	Use:   "destroy [service-name]",
+	Short: "Destroy a service and the resources created for it",
+	Long: `Destroy a service and the resources created for it
+
+Scales the service to 0, deletes it, and waits for its tasks to drain. If the
+service is behind a load balancer, the listener rules forwarding to its target
+group are then deleted, followed by the target group itself. A target group
+that is a listener's default action is left in place, as default rules cannot
+be deleted. Finally, every active revision of the service's task definition
+family is deregistered.
+
+Resources shared with other services are never deleted: a target group any
+other service in the region is registered with is kept along with its rules,
+as are rules that also forward to other target groups, and a task definition
+family any other service in the region uses is left registered.
+
+Pass --propagate-deregistration-on-delete to deregister every target from the
+target group as soon as the service is deleted, so the load balancer stops
+routing to its tasks right away rather than as ECS stops each one.
+
+Pass --purge to also delete the service's log group (/fargate/service/<name>)
+and the ECR repository of the service's image, including all of its images.
+The repository is kept if the image of any other service in the region is in
+it.
+
+The resources to be deleted are listed and you are asked to confirm first,
+unless --yes is passed. The service name defaults to --service.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		operation := &ServiceDestroyOperation{
+			PropagateDeregistration: flagServiceDestroyPropagateDeregistration,
+			Purge:                   flagServiceDestroyPurge,
+			Yes:                     flagServiceDestroyYes,
+		}
+
+		if len(args) == 1 {
+			operation.ServiceName = args[0]
+		} else {
+			operation.ServiceName = getServiceName()
+		}
+
+		destroyService(operation)
+	},
+}
+
+func init() {
+	serviceDestroyCmd.Flags().BoolVar(&flagServiceDestroyPropagateDeregistration, "propagate-deregistration-on-delete", false, "Deregister the service's targets from its target group as soon as it is deleted")
+	serviceDestroyCmd.Flags().BoolVar(&flagServiceDestroyPurge, "purge", false, "Also delete the service's log group and ECR repository")
+	serviceDestroyCmd.Flags().BoolVarP(&flagServiceDestroyYes, "yes", "y", false, "Destroy the service without asking for confirmation")
+
+	serviceCmd.AddCommand(serviceDestroyCmd)
+}
+
+func destroyService(operation *ServiceDestroyOperation) {
+	ecs := ECS.New(sess, getClusterName())
+	service := ecs.DescribeService(operation.ServiceName)
+
+	if service.Status == "INACTIVE" {
+		console.IssueExit("Service %s has already been destroyed", operation.ServiceName)
+	}
+
+	var sharedWith, repositorySharedWith []string
+
+	logGroupName := fmt.Sprintf(serviceLogGroupFormat, operation.ServiceName)
+	repository, inECR := ECR.ParseImageURI(service.Image)
+	family := ecs.GetTaskFamily(service.TaskDefinitionArn)
+	others := otherRegionServices(getClusterName(), operation.ServiceName)
+
+	if service.TargetGroupArn != "" {
+		sharedWith = servicesSharing(others, func(other ECS.Service) bool {
+			return other.TargetGroupArn == service.TargetGroupArn
+		})
+	}
+
+	familySharedWith := servicesSharing(others, func(other ECS.Service) bool {
+		for _, deployment := range other.Deployments {
+			if ecs.GetTaskFamily(deployment.TaskDefinitionArn) == family {
+				return true
+			}
+		}
+
+		return ecs.GetTaskFamily(other.TaskDefinitionArn) == family
+	})
+
+	if operation.Purge && inECR {
+		repositorySharedWith = servicesSharing(others, func(other ECS.Service) bool {
+			uri, ok := ECR.ParseImageURI(other.Image)
+			return ok && uri.SameRepository(repository)
+		})
+	}
+
+	console.Info("Destroying service %s will delete:", operation.ServiceName)
+	console.Info("  ECS service %s in cluster %s", operation.ServiceName, getClusterName())
+
+	if service.TargetGroupArn != "" && len(sharedWith) == 0 {
+		console.Info("  Target group %s and the listener rules forwarding to it", service.TargetGroupArn)
+	}
+
+	if len(familySharedWith) == 0 {
+		console.Info("  Task definition family %s (deregistered)", family)
+	}
+
+	if operation.Purge {
+		console.Info("  Log group %s", logGroupName)
+
+		if inECR && len(repositorySharedWith) == 0 {
+			console.Info("  ECR repository %s in %s and all of its images", repository.Repository, repository.Region)
+		}
+	}
+
+	if len(sharedWith) > 0 {
+		console.Issue("Target group %s is shared with %s, it and its rules will be kept", service.TargetGroupArn, strings.Join(sharedWith, ", "))
+	}
+
+	if len(familySharedWith) > 0 {
+		console.Issue("Task definition family %s is used by %s, it will be kept", family, strings.Join(familySharedWith, ", "))
+	}
+
+	if len(repositorySharedWith) > 0 {
+		console.Issue("ECR repository %s is used by %s, it will be kept", repository.Repository, strings.Join(repositorySharedWith, ", "))
+	}
+
+	if !operation.Yes {
+		if !stdinIsTerminal() {
+			console.IssueExit("Pass --yes to destroy service %s without confirmation", operation.ServiceName)
+		}
+
+		fmt.Printf("Destroy service %s? (yes/no)\n", operation.ServiceName)
+
+		if !askForConfirmation() {
+			console.Info("Service %s was not destroyed", operation.ServiceName)
+			return
+		}
+	}
+
+	if service.DesiredCount > 0 {
+		ecs.SetDesiredCount(operation.ServiceName, 0)
+		console.Info("Scaled service %s to 0", operation.ServiceName)
+	}
+
+	ecs.DestroyService(operation.ServiceName)
+	console.Info("Deleted service %s, waiting for its tasks to drain", operation.ServiceName)
+
+	if operation.PropagateDeregistration && service.TargetGroupArn != "" && len(sharedWith) == 0 {
+		deregisterAllTargets(service.TargetGroupArn)
+	}
+
+	ecs.WaitUntilServiceInactive(operation.ServiceName)
+
+	if service.TargetGroupArn != "" && len(sharedWith) == 0 {
+		deleteServiceTargetGroup(service.TargetGroupArn)
+	}
+
+	if len(familySharedWith) == 0 {
+		deregisterTaskFamily(&ecs, family)
+	}
+
+	if operation.Purge {
+		cwl := CWL.New(sess)
+
+		if cwl.DeleteLogGroup(logGroupName) {
+			console.Info("Deleted log group %s", logGroupName)
+		}
+
+		if inECR && len(repositorySharedWith) == 0 {
+			//the image may be in another region's registry
+			ecr := ECR.New(sess.Copy(&aws.Config{Region: aws.String(repository.Region)}))
+			deleted, err := ecr.DeleteRepository(repository.RegistryID, repository.Repository)
+
+			if err != nil {
+				console.ErrorExit(err, "Could not delete ECR repository")
+			}
+
+			if deleted {
+				console.Info("Deleted ECR repository %s", repository.Repository)
+			}
+		}
+	}
+
+	console.Info("Destroyed service %s", operation.ServiceName)
+}
+
+//otherRegionServices returns the services in every cluster in the region,
+//keyed by cluster/service, other than the given one
+func otherRegionServices(clusterName, serviceName string) map[string]ECS.Service {
+	services := make(map[string]ECS.Service)
+
+	ecs := ECS.New(sess, "")
+	clusterNames, err := ecs.ListClusterNames()
+
+	if err != nil {
+		console.ErrorExit(err, "Could not list ECS clusters")
+	}
+
+	for _, cluster := range clusterNames {
+		clusterECS := ECS.New(sess, cluster)
+
+		for _, service := range clusterECS.ListServices() {
+			if cluster == clusterName && service.Name == serviceName {
+				continue
+			}
+
+			services[cluster+"/"+service.Name] = service
+		}
+	}
+
+	return services
+}
+
+//servicesSharing returns the services, as cluster/service in order, that
+//share a resource according to shares
+func servicesSharing(services map[string]ECS.Service, shares func(ECS.Service) bool) []string {
+	var names []string
+
+	for name, service := range services {
+		if shares(service) {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+//deregisterTaskFamily deregisters every active revision of a task definition
+//family
+func deregisterTaskFamily(ecs *ECS.ECS, family string) {
+	revisions, err := ecs.ListTaskDefinitionRevisions(family)
+
+	if err != nil {
+		console.ErrorExit(err, "Could not list task definition revisions")
+	}
+
+	for _, revision := range revisions {
+		if err := ecs.DeregisterTaskDefinition(revision); err != nil {
+			console.ErrorExit(err, "Could not deregister task definition revision %s", ecs.GetRevisionNumber(revision))
+		}
+
+		console.Debug("Deregistered task definition %s", revision)
+	}
+
+	console.Info("Deregistered %d revision(s) of task definition family %s", len(revisions), family)
+}
+
+//deregisterAllTargets deregisters every target from a target group
+func deregisterAllTargets(targetGroupARN string) {
+	elbv2 := ELBV2.New(sess)
+	targets, err := elbv2.DescribeTargetHealth(targetGroupARN)
+
+	if err != nil {
+		console.ErrorExit(err, "Could not describe ELB target health")
+	}
+
+	if err := elbv2.DeregisterTargets(targetGroupARN, targets); err != nil {
+		console.ErrorExit(err, "Could not deregister ELB targets")
+	}
+
+	console.Info("Deregistered %d target(s) from target group %s", len(targets), targetGroupARN)
+}
+
+//deleteServiceTargetGroup deletes the listener rules forwarding only to a
+//target group and then the target group, unless a rule that can't be deleted
+//still forwards to it
+func deleteServiceTargetGroup(targetGroupARN string) {
+	var kept []ELBV2.ListenerRule
+
+	elbv2 := ELBV2.New(sess)
+
+	if loadBalancerARN := elbv2.GetTargetGroupLoadBalancerArn(targetGroupARN); loadBalancerARN != "" {
+		for _, listener := range elbv2.GetListeners(loadBalancerARN) {
+			rules, err := elbv2.ListRules(listener.ARN)
+
+			if err != nil {
+				console.ErrorExit(err, "Could not list ELB rules")
+			}
+
+			forwarding, keptRules := targetGroupRules(rules, targetGroupARN)
+			kept = append(kept, keptRules...)
+
+			for _, rule := range forwarding {
+				elbv2.DeleteRule(rule.ARN)
+				console.Info("Deleted rule with priority %d from listener %s", rule.Priority, listener)
+			}
+		}
+	}
+
+	if len(kept) > 0 {
+		console.Issue("Target group %s was not deleted, %d default or shared rule(s) still forward to it", targetGroupARN, len(kept))
+		return
+	}
+
+	elbv2.DeleteTargetGroupByArn(targetGroupARN)
+	console.Info("Deleted target group %s", targetGroupARN)
+}
+
+//targetGroupRules returns the rules that forward to a target group, split
+//into those that can be deleted and those that must be kept: default rules,
+//and rules that also forward to other target groups
+func targetGroupRules(rules ELBV2.ListenerRules, targetGroupARN string) (forwarding, kept []ELBV2.ListenerRule) {
+	for _, rule := range rules {
+		if !rule.ForwardsTo(targetGroupARN) {
+			continue
+		}
+
+		if rule.IsDefault || len(rule.TargetGroupARNs) > 1 {
+			kept = append(kept, rule)
+		} else {
+			forwarding = append(forwarding, rule)
+		}
+	}
+
+	return forwarding, kept
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	ECS "github.com/turnerlabs/fargate/ecs"
+	ELBV2 "github.com/turnerlabs/fargate/elbv2"
+)
+
+func TestTargetGroupRules(t *testing.T) {
+	rules := ELBV2.ListenerRules{
+		ELBV2.ListenerRule{ARN: "arn:rule/1", Priority: 1, TargetGroupARNs: []string{"arn:tg/web"}},
+		ELBV2.ListenerRule{ARN: "arn:rule/2", Priority: 2, TargetGroupARNs: []string{"arn:tg/api"}},
+		ELBV2.ListenerRule{ARN: "arn:rule/3", Priority: 3, TargetGroupARNs: []string{"arn:tg/web"}},
+		ELBV2.ListenerRule{ARN: "arn:rule/4", Priority: 4, TargetGroupARNs: []string{"arn:tg/api", "arn:tg/web"}},
+		ELBV2.ListenerRule{ARN: "arn:rule/default", IsDefault: true, TargetGroupARNs: []string{"arn:tg/web"}},
+	}
+
+	forwarding, kept := targetGroupRules(rules, "arn:tg/web")
+
+	if len(forwarding) != 2 || forwarding[0].ARN != "arn:rule/1" || forwarding[1].ARN != "arn:rule/3" {
+		t.Errorf("expected rules 1 and 3, got %v", forwarding)
+	}
+
+	if len(kept) != 2 || kept[0].ARN != "arn:rule/4" || kept[1].ARN != "arn:rule/default" {
+		t.Errorf("expected the shared and default rules to be kept, got %v", kept)
+	}
+
+	if forwarding, kept := targetGroupRules(rules, "arn:tg/api"); len(forwarding) != 1 || len(kept) != 1 {
+		t.Errorf("expected rule 2 to be deleted and rule 4 kept, got %v and %v", forwarding, kept)
+	}
+}
+
+func TestServicesSharing(t *testing.T) {
+	services := map[string]ECS.Service{
+		"prod/web-canary": ECS.Service{Name: "web-canary", TargetGroupArn: "arn:tg/web"},
+		"staging/web":     ECS.Service{Name: "web", TargetGroupArn: "arn:tg/web"},
+		"prod/api":        ECS.Service{Name: "api", TargetGroupArn: "arn:tg/api"},
+		"prod/worker":     ECS.Service{Name: "worker"},
+	}
+
+	usesTargetGroup := func(targetGroupARN string) func(ECS.Service) bool {
+		return func(service ECS.Service) bool {
+			return service.TargetGroupArn == targetGroupARN
+		}
+	}
+
+	if got, want := servicesSharing(services, usesTargetGroup("arn:tg/web")), []string{"prod/web-canary", "staging/web"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if got := servicesSharing(services, usesTargetGroup("arn:tg/other")); len(got) != 0 {
+		t.Errorf("expected no other services, got %v", got)
+	}
+}
@@ -1,6 +1,10 @@
 package cmd
 
 import (
+	"fmt"
+	"sort"
+
+	ECS "github.com/turnerlabs/fargate/ecs"
 	"github.com/spf13/cobra"
 )
 
@@ -12,3 +16,22 @@ var serviceEnvCmd = &cobra.Command{
 func init() {
 	serviceCmd.AddCommand(serviceEnvCmd)
 }
+
+//formatEnvVars renders environment variables as KEY=VALUE lines sorted by
+//key. The given slice is left in its original order.
+func formatEnvVars(envVars []ECS.EnvVar) []string {
+	sorted := make([]ECS.EnvVar, len(envVars))
+	copy(sorted, envVars)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Key < sorted[j].Key
+	})
+
+	lines := make([]string, len(sorted))
+
+	for i, envVar := range sorted {
+		lines[i] = fmt.Sprintf("%s=%s", envVar.Key, envVar.Value)
+	}
+
+	return lines
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	ECS "github.com/turnerlabs/fargate/ecs"
+	"github.com/spf13/cobra"
+)
+
+const secretPlaceholderFormat = "<from:%s>"
+
+var secretPlaceholder = regexp.MustCompile(`^<from:(.+)>$`)
+
+type ServiceEnvExportOperation struct {
+	ServiceName string
+}
+
+var serviceEnvExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export environment variables in dotenv format",
+	Long: `Export environment variables in dotenv format
+
+Writes the service's environment variables to standard output in dotenv
+format, one KEY=value per line, quoting values that need it. Secret values are
+never fetched; secrets are written as KEY=<from:valueFrom> placeholders.
+
+The output can be read back with env set --file, which sets the placeholders
+as secrets again:
+
+  fargate service env export > .env
+  fargate service env set --file .env`,
+	Run: func(cmd *cobra.Command, args []string) {
+		operation := &ServiceEnvExportOperation{
+			ServiceName: getServiceName(),
+		}
+
+		serviceEnvExport(operation)
+	},
+}
+
+func init() {
+	serviceEnvCmd.AddCommand(serviceEnvExportCmd)
+}
+
+func serviceEnvExport(operation *ServiceEnvExportOperation) {
+	ecs := ECS.New(sess, getClusterName())
+	service := ecs.DescribeService(operation.ServiceName)
+	envVars := ecs.GetEnvVarsFromTaskDefinition(service.TaskDefinitionArn, service.ContainerName)
+	secretVars := ecs.GetSecretVarsFromTaskDefinition(service.TaskDefinitionArn, service.ContainerName)
+
+	for _, line := range formatDotenv(envVars, secretVars) {
+		fmt.Println(line)
+	}
+}
+
+//formatDotenv renders environment variables and secret placeholders as dotenv lines sorted by key.
+func formatDotenv(envVars, secretVars []ECS.EnvVar) []string {
+	var vars []ECS.EnvVar
+
+	vars = append(vars, envVars...)
+
+	for _, secret := range secretVars {
+		vars = append(vars, ECS.EnvVar{Key: secret.Key, Value: fmt.Sprintf(secretPlaceholderFormat, secret.Value)})
+	}
+
+	sort.SliceStable(vars, func(i, j int) bool {
+		return vars[i].Key < vars[j].Key
+	})
+
+	lines := make([]string, len(vars))
+
+	for i, envVar := range vars {
+		lines[i] = envVar.Key + "=" + dotenvValue(envVar.Value)
+	}
+
+	return lines
+}
+
+//dotenvValue double quotes a value if it would otherwise be misread.
+func dotenvValue(value string) string {
+	if value != strings.TrimSpace(value) || strings.ContainsAny(value, " \t\r\n#\"'\\") {
+		return strconv.Quote(value)
+	}
+
+	return value
+}
+
+//splitSecretPlaceholders separates KEY=<from:valueFrom> placeholders written
+//by env export from plain environment variables.
+func splitSecretPlaceholders(envVars []ECS.EnvVar) ([]ECS.EnvVar, []ECS.Secret) {
+	var (
+		plain   []ECS.EnvVar
+		secrets []ECS.Secret
+	)
+
+	for _, envVar := range envVars {
+		if matches := secretPlaceholder.FindStringSubmatch(envVar.Value); matches != nil {
+			secrets = append(secrets, ECS.Secret{Key: envVar.Key, ValueFrom: matches[1]})
+		} else {
+			plain = append(plain, envVar)
+		}
+	}
+
+	return plain, secrets
+}
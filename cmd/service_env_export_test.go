@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	ECS "github.com/turnerlabs/fargate/ecs"
+)
+
+func TestFormatDotenv(t *testing.T) {
+	envVars := []ECS.EnvVar{
+		{Key: "PORT", Value: "8080"},
+		{Key: "GREETING", Value: "hello world"},
+		{Key: "EMPTY", Value: ""},
+	}
+	secretVars := []ECS.EnvVar{
+		{Key: "DB_PASSWORD", Value: "arn:aws:ssm:us-east-1:123456789012:parameter/db"},
+	}
+
+	want := []string{
+		"DB_PASSWORD=<from:arn:aws:ssm:us-east-1:123456789012:parameter/db>",
+		"EMPTY=",
+		`GREETING="hello world"`,
+		"PORT=8080",
+	}
+
+	if got := formatDotenv(envVars, secretVars); !reflect.DeepEqual(got, want) {
+		t.Errorf("formatDotenv => %v, want %v", got, want)
+	}
+}
+
+func TestDotenvValue(t *testing.T) {
+	var tests = []struct {
+		in  string
+		out string
+	}{
+		{"bar", "bar"},
+		{"a=b", "a=b"},
+		{"", ""},
+		{"two words", `"two words"`},
+		{" padded", `" padded"`},
+		{"has#hash", `"has#hash"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{"line\nbreak", `"line\nbreak"`},
+	}
+
+	for _, test := range tests {
+		if out := dotenvValue(test.in); out != test.out {
+			t.Errorf("dotenvValue(%q) => %s, want %s", test.in, out, test.out)
+		}
+	}
+}
+
+func TestDotenvRoundTrip(t *testing.T) {
+	envVars := []ECS.EnvVar{
+		{Key: "A", Value: "plain"},
+		{Key: "B", Value: `quoted "value" with spaces`},
+		{Key: "C", Value: "multi\nline"},
+	}
+	secretVars := []ECS.EnvVar{
+		{Key: "D", Value: "arn:aws:secretsmanager:us-east-1:123456789012:secret:d"},
+	}
+
+	var lines []string
+
+	for _, line := range formatDotenv(envVars, secretVars) {
+		lines = append(lines, unquoteVarLine(line))
+	}
+
+	gotEnvVars, gotSecrets := splitSecretPlaceholders(extractEnvVars(lines))
+	wantSecrets := []ECS.Secret{{Key: "D", ValueFrom: "arn:aws:secretsmanager:us-east-1:123456789012:secret:d"}}
+
+	if !reflect.DeepEqual(gotEnvVars, envVars) {
+		t.Errorf("env vars => %v, want %v", gotEnvVars, envVars)
+	}
+
+	if !reflect.DeepEqual(gotSecrets, wantSecrets) {
+		t.Errorf("secrets => %v, want %v", gotSecrets, wantSecrets)
+	}
+}
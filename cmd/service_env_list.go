@@ -29,11 +29,9 @@ func init() {
 func serviceEnvList(operation *ServiceEnvListOperation) {
 	ecs := ECS.New(sess, getClusterName())
 	service := ecs.DescribeService(operation.ServiceName)
-	envVars := ecs.GetEnvVarsFromTaskDefinition(service.TaskDefinitionArn)
+	envVars := ecs.GetEnvVarsFromTaskDefinition(service.TaskDefinitionArn, service.ContainerName)
 
-	ecs.SortEnvVars(envVars)
-
-	for _, envVar := range envVars {
-		fmt.Printf("%s=%s\n", envVar.Key, envVar.Value)
+	for _, line := range formatEnvVars(envVars) {
+		fmt.Println(line)
 	}
 }
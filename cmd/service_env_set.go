@@ -4,12 +4,16 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/turnerlabs/fargate/console"
 	ECS "github.com/turnerlabs/fargate/ecs"
+	SSM "github.com/turnerlabs/fargate/ssm"
 )
 
 type ServiceEnvSetOperation struct {
 	ServiceName string
 	EnvVars     []ECS.EnvVar
 	SecretVars  []ECS.Secret
+	Overrides   []envVarOverride
+
+	sources envVarSources
 }
 
 func (o *ServiceEnvSetOperation) Validate() {
@@ -19,11 +23,119 @@ func (o *ServiceEnvSetOperation) Validate() {
 }
 
 func (o *ServiceEnvSetOperation) SetEnvVars(inputEnvVars []string, envVarFile string) {
-	o.EnvVars = processEnvVarArgs(inputEnvVars, envVarFile)
+	//secret placeholders written by env export are set as secrets
+	envVars, secretVars := splitSecretPlaceholders(processEnvVarArgs(inputEnvVars, ""))
+	o.sources.Add(envSourceCommandLine, envVars, secretVars)
+
+	if envVarFile != "" {
+		envVars, secretVars := splitSecretPlaceholders(processEnvVarArgs(nil, envVarFile))
+		o.sources.Add(envSourceFile, envVars, secretVars)
+	}
+
+	o.merge()
 }
 
 func (o *ServiceEnvSetOperation) SetSecretVars(inputSecretVars []string, secretVarFile string) {
-	o.SecretVars = processSecretVarArgs(inputSecretVars, secretVarFile)
+	o.sources.Add(envSourceCommandLine, nil, processSecretVarArgs(inputSecretVars, ""))
+
+	if secretVarFile != "" {
+		o.sources.Add(envSourceFile, nil, processSecretVarArgs(nil, secretVarFile))
+	}
+
+	o.merge()
+}
+
+//SetSSMPath adds every parameter under an SSM path, keyed by its last path segment.
+//SecureString parameters are added as secrets referencing the parameter ARN.
+func (o *ServiceEnvSetOperation) SetSSMPath(path string) {
+	if path == "" {
+		return
+	}
+
+	ssm := SSM.New(sess)
+	parameters, err := ssm.GetParametersByPath(path)
+
+	if err != nil {
+		console.ErrorExit(err, "Could not read SSM parameters under %s", path)
+	}
+
+	envVars, secretVars := ssmParametersToVars(parameters)
+
+	o.sources.Add(envSourceSSM, envVars, secretVars)
+	o.merge()
+}
+
+//SetSSMParameters adds specific SSM parameters given as KEY=/path/to/param.
+//SecureString parameters, and String parameters when asSecrets is set, are
+//added as secrets referencing the parameter ARN; other String parameters are
+//read and added as plaintext environment variables.
+func (o *ServiceEnvSetOperation) SetSSMParameters(inputMappings []string, asSecrets bool) {
+	if len(inputMappings) == 0 {
+		return
+	}
+
+	mappings := extractEnvVars(inputMappings)
+
+	var names []string
+
+	for _, mapping := range mappings {
+		names = append(names, mapping.Value)
+	}
+
+	ssm := SSM.New(sess)
+	parameters, err := ssm.GetParameters(names)
+
+	if err != nil {
+		console.ErrorExit(err, "Could not read SSM parameters")
+	}
+
+	envVars, secretVars := ssmParameterMappingsToVars(mappings, parameters, asSecrets)
+
+	o.sources.Add(envSourceSSM, envVars, secretVars)
+	o.merge()
+}
+
+//merge resolves the variables set so far by source precedence
+func (o *ServiceEnvSetOperation) merge() {
+	o.EnvVars, o.SecretVars, o.Overrides = o.sources.Merge()
+}
+
+//ResolveSecrets expands secrets given as SSM parameter names to parameter ARNs
+func (o *ServiceEnvSetOperation) ResolveSecrets() {
+	o.SecretVars = resolveSecrets(o.SecretVars)
+}
+
+func ssmParametersToVars(parameters []SSM.Parameter) ([]ECS.EnvVar, []ECS.Secret) {
+	var inputEnvVars, inputSecretVars []string
+
+	for _, parameter := range parameters {
+		if parameter.Secure {
+			inputSecretVars = append(inputSecretVars, parameter.Key()+"="+parameter.ARN)
+		} else {
+			inputEnvVars = append(inputEnvVars, parameter.Key()+"="+parameter.Value)
+		}
+	}
+
+	return extractEnvVars(inputEnvVars), processSecretVarArgs(inputSecretVars, "")
+}
+
+//ssmParameterMappingsToVars pairs KEY=/path mappings with the parameters read
+//for them, in the same order
+func ssmParameterMappingsToVars(mappings []ECS.EnvVar, parameters []SSM.Parameter, asSecrets bool) ([]ECS.EnvVar, []ECS.Secret) {
+	var envVars []ECS.EnvVar
+	var secretVars []ECS.Secret
+
+	for i, mapping := range mappings {
+		parameter := parameters[i]
+
+		if parameter.Secure || asSecrets {
+			secretVars = append(secretVars, ECS.Secret{Key: mapping.Key, ValueFrom: parameter.ARN})
+		} else {
+			envVars = append(envVars, ECS.EnvVar{Key: mapping.Key, Value: parameter.Value})
+		}
+	}
+
+	return envVars, secretVars
 }
 
 func processEnvVarArgs(inputEnvVars []string, envVarFile string) []ECS.EnvVar {
@@ -54,6 +166,10 @@ var flagServiceEnvSetEnvVars []string
 var flagServiceEnvSetEnvFile string
 var flagServiceEnvSetSecretVars []string
 var flagServiceEnvSetSecretFile string
+var flagServiceEnvSetSSMPath string
+var flagServiceEnvSetSSMParameters []string
+var flagServiceEnvSetSSMAsSecrets bool
+var flagServiceEnvSetWarnOverrides bool
 
 var serviceEnvSetCmd = &cobra.Command{
 	Use:   "set --env <key=value> [--env <key=value>] [--file filename] [--secret <key=valueFrom>] [--secret-file filename]...",
@@ -61,7 +177,7 @@ var serviceEnvSetCmd = &cobra.Command{
 	Long: `Set environment variables
 
 At least one environment variable must be specified via either the --env, --secret,
---file, or --secret-file flags. You may specify any number of variables on the command line by
+--file, --secret-file, --ssm-path, or --ssm flags. You may specify any number of variables on the command line by
 repeating --env or --secret before each one, or else place multiple variables in a file, one
 per line, and specify the filename with --file or --secret-file.
 
@@ -69,7 +185,29 @@ Each --env and --secret parameter string or line in the file must be of the form
 "key=value", with no quotation marks and no whitespace around the "=" unless you want
 literal leading whitespace in the value.  Additionally, the "key" side must be
 a legal shell identifier, which means it must start with an ASCII letter A-Z or
-underscore and consist of only letters, digits, and underscores.`,
+underscore and consist of only letters, digits, and underscores.
+
+--ssm-path reads every parameter under an SSM Parameter Store path such as
+/myapp/prod/ and sets each as a variable named after the last segment of the
+parameter name. SecureString parameters are set as secrets referencing the
+parameter ARN rather than as plaintext environment variables.
+
+--ssm maps a single SSM parameter to a variable name, e.g.
+--ssm DB_HOST=/shared/prod/database/host. SecureString parameters are set as
+secrets referencing the parameter ARN, and String parameters are read and set
+as environment variables, or as secrets too with --ssm-as-secret. Each
+parameter must exist.
+
+Each --secret value is an SSM parameter name or ARN, or a Secrets Manager
+secret ARN. Parameter names are resolved to ARNs in the current region and
+account. The service's execution role, which fetches secrets when tasks start,
+is granted read access to each secret through its fargate-secrets inline
+policy.
+
+When the same variable is set more than once, --env and --secret take
+precedence over --file and --secret-file, which take precedence over
+--ssm-path and --ssm. Pass --warn-overrides to list each variable that was overridden
+and by which source.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		operation := &ServiceEnvSetOperation{
 			ServiceName: getServiceName(),
@@ -77,7 +215,15 @@ underscore and consist of only letters, digits, and underscores.`,
 
 		operation.SetEnvVars(flagServiceEnvSetEnvVars, flagServiceEnvSetEnvFile)
 		operation.SetSecretVars(flagServiceEnvSetSecretVars, flagServiceEnvSetSecretFile)
+		operation.SetSSMPath(flagServiceEnvSetSSMPath)
+		operation.SetSSMParameters(flagServiceEnvSetSSMParameters, flagServiceEnvSetSSMAsSecrets)
 		operation.Validate()
+		operation.ResolveSecrets()
+
+		if flagServiceEnvSetWarnOverrides {
+			printEnvVarOverrides(operation.Overrides)
+		}
+
 		serviceEnvSet(operation)
 	},
 }
@@ -87,6 +233,10 @@ func init() {
 	serviceEnvSetCmd.Flags().StringVarP(&flagServiceEnvSetEnvFile, "file", "f", "", "File containing list of environment variables to set, one per line, of the form KEY=value")
 	serviceEnvSetCmd.Flags().StringArrayVar(&flagServiceEnvSetSecretVars, "secret", []string{}, "Secret variables to set [e.g. KEY=valueFrom]")
 	serviceEnvSetCmd.Flags().StringVar(&flagServiceEnvSetSecretFile, "secret-file", "", "File containing list of secret variables to set, one per line, of the form KEY=valueFrom")
+	serviceEnvSetCmd.Flags().StringVar(&flagServiceEnvSetSSMPath, "ssm-path", "", "SSM Parameter Store path to read variables from [e.g. /myapp/prod/]")
+	serviceEnvSetCmd.Flags().StringArrayVar(&flagServiceEnvSetSSMParameters, "ssm", []string{}, "SSM parameter to set as a variable [e.g. KEY=/path/to/param]")
+	serviceEnvSetCmd.Flags().BoolVar(&flagServiceEnvSetSSMAsSecrets, "ssm-as-secret", false, "Set String parameters given with --ssm as secrets instead of plaintext values")
+	serviceEnvSetCmd.Flags().BoolVar(&flagServiceEnvSetWarnOverrides, "warn-overrides", false, "Warn about variables set by more than one source")
 
 	serviceEnvCmd.AddCommand(serviceEnvSetCmd)
 }
@@ -94,7 +244,10 @@ func init() {
 func serviceEnvSet(operation *ServiceEnvSetOperation) {
 	ecs := ECS.New(sess, getClusterName())
 	service := ecs.DescribeService(operation.ServiceName)
-	taskDefinitionArn := ecs.AddEnvVarsToTaskDefinition(service.TaskDefinitionArn, operation.EnvVars, operation.SecretVars)
+
+	grantSecretsRead(ecs, service.TaskDefinitionArn, operation.SecretVars)
+
+	taskDefinitionArn := ecs.AddEnvVarsToTaskDefinition(service.TaskDefinitionArn, service.ContainerName, operation.EnvVars, operation.SecretVars)
 
 	ecs.UpdateServiceTaskDefinition(operation.ServiceName, taskDefinitionArn)
 
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	ECS "github.com/turnerlabs/fargate/ecs"
+	SSM "github.com/turnerlabs/fargate/ssm"
+)
+
+func TestFormatEnvVars_SortedRegardlessOfInputOrder(t *testing.T) {
+	want := []string{"A=1", "B=2", "C=3"}
+
+	inputs := [][]ECS.EnvVar{
+		{{Key: "A", Value: "1"}, {Key: "B", Value: "2"}, {Key: "C", Value: "3"}},
+		{{Key: "C", Value: "3"}, {Key: "A", Value: "1"}, {Key: "B", Value: "2"}},
+		{{Key: "B", Value: "2"}, {Key: "C", Value: "3"}, {Key: "A", Value: "1"}},
+	}
+
+	for _, input := range inputs {
+		got := formatEnvVars(input)
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("formatEnvVars(%v) => %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestFormatEnvVars_PreservesInputOrder(t *testing.T) {
+	input := []ECS.EnvVar{{Key: "C", Value: "3"}, {Key: "A", Value: "1"}}
+
+	formatEnvVars(input)
+
+	if input[0].Key != "C" || input[1].Key != "A" {
+		t.Errorf("formatEnvVars reordered its input: %v", input)
+	}
+}
+
+func TestSSMParametersToVars(t *testing.T) {
+	parameters := []SSM.Parameter{
+		{Name: "/myapp/prod/DB_HOST", Value: "db.example.com"},
+		{Name: "/myapp/prod/DB_PASSWORD", ARN: "arn:aws:ssm:us-east-1:123456789012:parameter/myapp/prod/DB_PASSWORD", Secure: true, Value: "AQICAHh..."},
+	}
+
+	envVars, secretVars := ssmParametersToVars(parameters)
+
+	wantEnvVars := []ECS.EnvVar{{Key: "DB_HOST", Value: "db.example.com"}}
+	wantSecretVars := []ECS.Secret{{Key: "DB_PASSWORD", ValueFrom: "arn:aws:ssm:us-east-1:123456789012:parameter/myapp/prod/DB_PASSWORD"}}
+
+	if !reflect.DeepEqual(envVars, wantEnvVars) {
+		t.Errorf("env vars => %v, want %v", envVars, wantEnvVars)
+	}
+
+	if !reflect.DeepEqual(secretVars, wantSecretVars) {
+		t.Errorf("secret vars => %v, want %v", secretVars, wantSecretVars)
+	}
+}
+
+func TestSSMParameterMappingsToVars(t *testing.T) {
+	mappings := []ECS.EnvVar{
+		{Key: "DATABASE_HOST", Value: "/shared/prod/db/host"},
+		{Key: "DATABASE_PASSWORD", Value: "/shared/prod/db/password"},
+	}
+	parameters := []SSM.Parameter{
+		{Name: "/shared/prod/db/host", ARN: "arn:aws:ssm:us-east-1:123456789012:parameter/shared/prod/db/host", Value: "db.example.com"},
+		{Name: "/shared/prod/db/password", ARN: "arn:aws:ssm:us-east-1:123456789012:parameter/shared/prod/db/password", Secure: true, Value: "AQICAHh..."},
+	}
+
+	envVars, secretVars := ssmParameterMappingsToVars(mappings, parameters, false)
+
+	wantEnvVars := []ECS.EnvVar{{Key: "DATABASE_HOST", Value: "db.example.com"}}
+	wantSecretVars := []ECS.Secret{{Key: "DATABASE_PASSWORD", ValueFrom: "arn:aws:ssm:us-east-1:123456789012:parameter/shared/prod/db/password"}}
+
+	if !reflect.DeepEqual(envVars, wantEnvVars) {
+		t.Errorf("env vars => %v, want %v", envVars, wantEnvVars)
+	}
+
+	if !reflect.DeepEqual(secretVars, wantSecretVars) {
+		t.Errorf("secret vars => %v, want %v", secretVars, wantSecretVars)
+	}
+
+	envVars, secretVars = ssmParameterMappingsToVars(mappings, parameters, true)
+
+	if len(envVars) != 0 || len(secretVars) != 2 || secretVars[0].ValueFrom != parameters[0].ARN {
+		t.Errorf("expected every parameter as a secret, got %v and %v", envVars, secretVars)
+	}
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/turnerlabs/fargate/console"
+	ECS "github.com/turnerlabs/fargate/ecs"
+	"github.com/spf13/cobra"
+)
+
+type ServiceEventsOperation struct {
+	ServiceName  string
+	FailuresOnly bool
+}
+
+type serviceEventItem struct {
+	CreatedAt time.Time `json:"createdAt"`
+	Message   string    `json:"message"`
+}
+
+var flagServiceEventsFailuresOnly bool
+
+var serviceEventsCmd = &cobra.Command{
+	Use:   "events [--failures-only]",
+	Short: "Show service events",
+	Long: `Show service events
+
+Lists the service's recent events, newest first, such as tasks being started
+and stopped, targets being registered with the load balancer, and the service
+reaching a steady state.
+
+Pass --failures-only to show only the events that report a problem, such as
+tasks failing load balancer or container health checks, tasks that couldn't be
+placed or whose image couldn't be pulled, and deployments failing or being
+rolled back. Events are classified by their message. Pass --output json for
+machine readable output.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		operation := &ServiceEventsOperation{
+			ServiceName:  getServiceName(),
+			FailuresOnly: flagServiceEventsFailuresOnly,
+		}
+
+		showServiceEvents(operation)
+	},
+}
+
+func init() {
+	serviceEventsCmd.Flags().BoolVar(&flagServiceEventsFailuresOnly, "failures-only", false, "Only show events that report a problem")
+
+	serviceCmd.AddCommand(serviceEventsCmd)
+}
+
+func showServiceEvents(operation *ServiceEventsOperation) {
+	items := []serviceEventItem{}
+
+	ecs := ECS.New(sess, getClusterName())
+	events := ecs.DescribeService(operation.ServiceName).Events
+
+	if operation.FailuresOnly {
+		events = ECS.FailureEvents(events, time.Time{})
+	}
+
+	for _, event := range events {
+		items = append(items, serviceEventItem{CreatedAt: event.CreatedAt, Message: event.Message})
+	}
+
+	if getOutput() == outputJSON {
+		printJSON(items)
+		return
+	}
+
+	if len(items) == 0 {
+		if operation.FailuresOnly {
+			console.Info("No failure events found for service %s", operation.ServiceName)
+		} else {
+			console.Info("No events found for service %s", operation.ServiceName)
+		}
+
+		return
+	}
+
+	for _, item := range items {
+		fmt.Printf("[%s] %s\n", item.CreatedAt, item.Message)
+	}
+}
+
+//printDeployFailures prints the events reporting problems since the service's
+//latest deployment started, to explain why it didn't reach a steady state
+func printDeployFailures(service ECS.Service) {
+	var since time.Time
+
+	if deployment := service.PrimaryDeployment(); deployment != nil {
+		since = deployment.CreatedAt
+	}
+
+	failures := ECS.FailureEvents(service.Events, since)
+
+	if len(failures) == 0 {
+		console.Info("No failure events were reported for the deployment")
+		return
+	}
+
+	console.Header("Failures")
+	printServiceEvents(failures)
+}
@@ -33,7 +33,15 @@ active deployments, and environment variables.
 
 Deployments show active versions of your service that are running. Multiple
 deployments are shown if a service is transitioning due to a deployment or
-update to configuration such a CPU, memory, or environment variables.`,
+update to configuration such a CPU, memory, or environment variables.
+
+When the service's container has a health check and the service is behind a
+load balancer, a warning is shown if the two checks are likely to disagree.
+ECS registers tasks with the load balancer by its health check but replaces
+tasks that fail either one, so mismatched intervals and thresholds, or a
+health check grace period shorter than the load balancer takes to mark a task
+healthy, can cause tasks to be replaced over and over. service deploy shows
+the same warnings.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		operation := &ServiceInfoOperation{
 			ServiceName: getServiceName(),
@@ -113,25 +121,23 @@ func getServiceInfo(operation *ServiceInfoOperation) {
 				}
 			}
 		}
+
+		warnHealthCheckConflicts(service)
 	}
 
 	if len(service.EnvVars) > 0 {
 		console.KeyValue("Environment Variables", "\n")
 
-		ecs.SortEnvVars(service.EnvVars)
-
-		for _, envVar := range service.EnvVars {
-			fmt.Printf("   %s=%s\n", envVar.Key, envVar.Value)
+		for _, line := range formatEnvVars(service.EnvVars) {
+			fmt.Printf("   %s\n", line)
 		}
 	}
 
 	if len(service.SecretVars) > 0 {
 		console.KeyValue("Secrets", "\n")
 
-		ecs.SortEnvVars(service.SecretVars)
-
-		for _, secret := range service.SecretVars {
-			fmt.Printf("   %s=%s\n", secret.Key, secret.Value)
+		for _, line := range formatEnvVars(service.SecretVars) {
+			fmt.Printf("   %s\n", line)
 		}
 	}
 
@@ -188,15 +194,16 @@ func getServiceInfo(operation *ServiceInfoOperation) {
 		w := new(tabwriter.Writer)
 
 		w.Init(os.Stdout, 0, 8, 1, '\t', 0)
-		fmt.Fprintln(w, "ID\tIMAGE\tSTATUS\tRUNNING\tIP\tCPU\tMEMORY\tDEPLOYMENT\t")
+		fmt.Fprintln(w, "ID\tIMAGE\tSTATUS\tDESIRED\tRUNNING\tIP\tCPU\tMEMORY\tDEPLOYMENT\t")
 
 		for _, t := range tasks {
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
 				t.TaskId,
 				t.Image,
-				Humanize(t.LastStatus),
+				taskStatus(t),
+				Humanize(t.DesiredStatus),
 				t.RunningFor(),
-				enis[t.EniId].PublicIpAddress,
+				taskPublicIP(t, enis),
 				t.Cpu,
 				t.Memory,
 				t.DeploymentId,
@@ -230,13 +237,17 @@ func getServiceInfo(operation *ServiceInfoOperation) {
 
 	if len(service.Events) > 0 {
 		console.Header("Events")
+		printServiceEvents(service.Events)
+	}
+}
 
-		for i, event := range service.Events {
-			fmt.Printf("[%s] %s\n", event.CreatedAt, event.Message)
+//prints the most recent service events (all of them when verbose)
+func printServiceEvents(events []ECS.Event) {
+	for i, event := range events {
+		fmt.Printf("[%s] %s\n", event.CreatedAt, event.Message)
 
-			if i == 10 && !getVerbose() {
-				break
-			}
+		if i == 10 && !getVerbose() {
+			break
 		}
 	}
 }
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"sync"
 	"text/tabwriter"
 
 	"github.com/turnerlabs/fargate/console"
@@ -11,9 +12,32 @@ import (
 	"github.com/spf13/cobra"
 )
 
+//clusterAll lists services in every cluster rather than a single one
+const clusterAll = "all"
+
+//maxConcurrentClusterLists bounds how many clusters' services are described at once
+const maxConcurrentClusterLists = 4
+
+type serviceListItem struct {
+	Cluster      string `json:"cluster"`
+	Name         string `json:"name"`
+	Image        string `json:"image"`
+	Cpu          string `json:"cpu"`
+	Memory       string `json:"memory"`
+	LoadBalancer string `json:"loadBalancer"`
+	DesiredCount int64  `json:"desiredCount"`
+	RunningCount int64  `json:"runningCount"`
+	PendingCount int64  `json:"pendingCount"`
+}
+
 var serviceListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List services",
+	Long: `List services
+
+Lists the services in the cluster. Pass --cluster all to list the services in
+every cluster in the region, with a column for each service's cluster.
+Pass --output json for machine readable output.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		listServices()
 	},
@@ -24,13 +48,110 @@ func init() {
 }
 
 func listServices() {
+	var services []serviceListItem
+
+	clusterName := getClusterName()
+	allClusters := clusterName == clusterAll
+
+	if allClusters {
+		services = describeServicesInAllClusters()
+	} else {
+		services = describeServiceList(clusterName)
+	}
+
+	if getOutput() == outputJSON {
+		if services == nil {
+			services = []serviceListItem{}
+		}
+
+		printJSON(services)
+		return
+	}
+
+	if len(services) == 0 {
+		console.Info("No services found")
+		return
+	}
+
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 0, 8, 1, '\t', 0)
+
+	if allClusters {
+		fmt.Fprint(w, "CLUSTER\t")
+	}
+
+	fmt.Fprintln(w, "NAME\tIMAGE\tCPU\tMEMORY\tLOAD BALANCER\tDESIRED\tRUNNING\tPENDING\t")
+
+	for _, service := range services {
+		if allClusters {
+			fmt.Fprintf(w, "%s\t", service.Cluster)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%d\t%d\t\n",
+			service.Name,
+			service.Image,
+			service.Cpu,
+			service.Memory,
+			service.LoadBalancer,
+			service.DesiredCount,
+			service.RunningCount,
+			service.PendingCount,
+		)
+	}
+
+	w.Flush()
+}
+
+//describeServicesInAllClusters describes the services in every active
+//cluster concurrently, ordered by cluster name
+func describeServicesInAllClusters() []serviceListItem {
+	ecs := ECS.New(sess, "")
+	clusterNames, err := ecs.ListClusterNames()
+
+	if err != nil {
+		console.ErrorExit(err, "Could not list ECS clusters")
+	}
+
+	results := make([][]serviceListItem, len(clusterNames))
+	slots := make(chan struct{}, maxConcurrentClusterLists)
+
+	var wg sync.WaitGroup
+
+	for i, clusterName := range clusterNames {
+		wg.Add(1)
+
+		go func(i int, clusterName string) {
+			defer wg.Done()
+
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			results[i] = describeServiceList(clusterName)
+		}(i, clusterName)
+	}
+
+	wg.Wait()
+
+	var services []serviceListItem
+
+	for _, result := range results {
+		services = append(services, result...)
+	}
+
+	return services
+}
+
+//describeServiceList describes the services in a cluster along with the
+//load balancers they're behind
+func describeServiceList(clusterName string) []serviceListItem {
 	var targetGroupArns []string
 	var loadBalancerArns []string
+	var items []serviceListItem
 
 	targetGroups := make(map[string]ELBV2.TargetGroup)
 	loadBalancers := make(map[string]ELBV2.LoadBalancer)
 
-	ecs := ECS.New(sess, getClusterName())
+	ecs := ECS.New(sess, clusterName)
 	elbv2 := ELBV2.New(sess)
 	services := ecs.ListServices()
 
@@ -57,35 +178,30 @@ func listServices() {
 		}
 	}
 
-	if len(services) > 0 {
-		w := new(tabwriter.Writer)
-		w.Init(os.Stdout, 0, 8, 1, '\t', 0)
-		fmt.Fprintln(w, "NAME\tIMAGE\tCPU\tMEMORY\tLOAD BALANCER\tDESIRED\tRUNNING\tPENDING\t")
-
-		for _, service := range services {
-			var loadBalancer string
-
-			if service.TargetGroupArn != "" {
-				tg := targetGroups[service.TargetGroupArn]
-				lb := loadBalancers[tg.LoadBalancerARN]
+	for _, service := range services {
+		var loadBalancer string
 
-				loadBalancer = lb.Name
-			}
+		if service.TargetGroupArn != "" {
+			tg := targetGroups[service.TargetGroupArn]
+			lb := loadBalancers[tg.LoadBalancerARN]
 
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%d\t%d\t\n",
-				service.Name,
-				service.Image,
-				service.Cpu,
-				service.Memory,
-				loadBalancer,
-				service.DesiredCount,
-				service.RunningCount,
-				service.PendingCount,
-			)
+			loadBalancer = lb.Name
 		}
 
-		w.Flush()
-	} else {
-		console.Info("No services found")
+		items = append(items,
+			serviceListItem{
+				Cluster:      clusterName,
+				Name:         service.Name,
+				Image:        service.Image,
+				Cpu:          service.Cpu,
+				Memory:       service.Memory,
+				LoadBalancer: loadBalancer,
+				DesiredCount: service.DesiredCount,
+				RunningCount: service.RunningCount,
+				PendingCount: service.PendingCount,
+			},
+		)
 	}
+
+	return items
 }
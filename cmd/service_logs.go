@@ -2,14 +2,18 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
 var (
 	flagServiceLogsFilter            string
+	flagServiceLogsGrep              string
+	flagServiceLogsInvert            bool
 	flagServiceLogsEndTime           string
 	flagServiceLogsStartTime         string
+	flagServiceLogsSince             time.Duration
 	flagServiceLogsFollow            bool
 	flagServiceLogsTasks             []string
 	flagServiceLogsTime              bool
@@ -22,11 +26,14 @@ var serviceLogsCmd = &cobra.Command{
 	Long: `Show logs from tasks in a service
 
 Return either a specific segment of service logs or tail logs in real-time
-using the --follow option. Logs are prefixed by their log stream name which is
-in the format of "fargate/\<service-name>/\<task-id>."
+using the --follow option. Logs are prefixed by the ID of the task that wrote
+them, and logs from every task are interleaved in the order they were written.
 
-Follow will continue to run and return logs until interrupted by Control-C. If
---follow is passed --end cannot be specified.
+Without --follow or --start, logs from the last 10 minutes are returned; pass
+--since with a duration (e.g. --since 1h) to go further back.
+
+Follow will continue to run and return new logs until interrupted by
+Control-C. If --follow is passed --end cannot be specified.
 
 Logs can be returned for specific tasks within a service by passing a task ID
 via the --task flag. Pass --task with a task ID multiple times in order to
@@ -40,14 +47,19 @@ timestamp:
     thirty seconds ago], 2h [two hours from now])
   - Timestamp with optional timezone in the format of YYYY-MM-DD HH:MM:SS [TZ];
     timezone will default to UTC if omitted (e.g. 2017-12-22 15:10:03 EST)
+  - RFC3339 timestamp (e.g. 2017-12-22T15:10:03-05:00)
 
 You can filter logs for specific term by passing a filter expression via the
 --filter flag. Pass a single term to search for that term, pass multiple terms
 to search for log messages that include all terms.
 
+--grep searches for log messages containing an exact phrase and highlights it
+in the output. Add --invert to instead exclude messages containing the phrase.
+--grep cannot be combined with --filter.
+
 --time includes the log timestamp in the output
 
---no-prefix excludes the log stream prefix from the output
+--no-prefix excludes the task ID prefix from the output
 `,
 	PreRun: func(cmd *cobra.Command, args []string) {
 	},
@@ -55,16 +67,26 @@ to search for log messages that include all terms.
 		operation := &GetLogsOperation{
 			LogGroupName:      fmt.Sprintf(serviceLogGroupFormat, getServiceName()),
 			Filter:            flagServiceLogsFilter,
+			Grep:              flagServiceLogsGrep,
+			Invert:            flagServiceLogsInvert,
 			Follow:            flagServiceLogsFollow,
 			Namespace:         getServiceName(),
 			IncludeTime:       flagServiceLogsTime,
 			NoLogStreamPrefix: flagServiceLogsNoLogStreamPrefix,
+			TaskIDPrefix:      true,
 		}
 
 		operation.AddTasks(flagServiceLogsTasks)
 		operation.AddStartTime(flagServiceLogsStartTime)
 		operation.AddEndTime(flagServiceLogsEndTime)
 
+		//following starts from now unless asked to go back
+		if !flagServiceLogsFollow || cmd.Flags().Changed("since") {
+			operation.AddSince(flagServiceLogsSince)
+		}
+
+		operation.Validate()
+
 		GetLogs(operation)
 	},
 }
@@ -74,7 +96,10 @@ func init() {
 
 	serviceLogsCmd.Flags().BoolVarP(&flagServiceLogsFollow, "follow", "f", false, "Poll logs and continuously print new events")
 	serviceLogsCmd.Flags().StringVar(&flagServiceLogsFilter, "filter", "", "Filter pattern to apply")
+	serviceLogsCmd.Flags().StringVar(&flagServiceLogsGrep, "grep", "", "Only show log messages containing a phrase, highlighting it")
+	serviceLogsCmd.Flags().BoolVar(&flagServiceLogsInvert, "invert", false, "With --grep, exclude log messages containing the phrase")
 	serviceLogsCmd.Flags().StringVar(&flagServiceLogsStartTime, "start", "", "Earliest time to return logs (e.g. -1h, 2018-01-01 09:36:00 EST")
+	serviceLogsCmd.Flags().DurationVar(&flagServiceLogsSince, "since", 10*time.Minute, "Return logs from this long ago when --start isn't given (e.g. 30m, 2h)")
 	serviceLogsCmd.Flags().StringVar(&flagServiceLogsEndTime, "end", "", "Latest time to return logs (e.g. 3y, 2021-01-20 12:00:00 EST")
 	serviceLogsCmd.Flags().StringSliceVarP(&flagServiceLogsTasks, "task", "t", []string{}, "Show logs from specific task (can be specified multiple times)")
 	serviceLogsCmd.PersistentFlags().BoolVarP(&flagServiceLogsTime, "time", "T", false, "append time to logs")
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/turnerlabs/fargate/console"
+	ECS "github.com/turnerlabs/fargate/ecs"
+	ELBV2 "github.com/turnerlabs/fargate/elbv2"
+	"github.com/spf13/cobra"
+)
+
+type ServiceOpenOperation struct {
+	ServiceName string
+}
+
+var serviceOpenCmd = &cobra.Command{
+	Use:   "open",
+	Short: "Open service in a browser",
+	Long: `Open service in a browser
+
+Resolves the URL of a load balanced service and opens it in the default
+browser. HTTPS listeners are preferred over HTTP, and a host-based listener
+rule routing to the service is used as the hostname in place of the load
+balancer DNS name. If no browser can be opened, the URL is printed instead.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		operation := &ServiceOpenOperation{
+			ServiceName: getServiceName(),
+		}
+
+		openService(operation)
+	},
+}
+
+func init() {
+	serviceCmd.AddCommand(serviceOpenCmd)
+}
+
+func openService(operation *ServiceOpenOperation) {
+	ecs := ECS.New(sess, getClusterName())
+	elbv2 := ELBV2.New(sess)
+	service := ecs.DescribeService(operation.ServiceName)
+
+	if service.Status != statusActive {
+		console.InfoExit("Service not found")
+	}
+
+	if service.TargetGroupArn == "" {
+		console.IssueExit("Service %s is not behind a load balancer", operation.ServiceName)
+	}
+
+	loadBalancerArn := elbv2.GetTargetGroupLoadBalancerArn(service.TargetGroupArn)
+
+	if loadBalancerArn == "" {
+		console.IssueExit("Could not find a load balancer for service %s", operation.ServiceName)
+	}
+
+	loadBalancer := elbv2.DescribeLoadBalancerByARN(loadBalancerArn)
+	listener, ok := preferredListener(elbv2.GetListeners(loadBalancerArn))
+
+	if !ok {
+		console.IssueExit("Load balancer %s has no HTTP or HTTPS listeners", loadBalancer.Name)
+	}
+
+	host := loadBalancer.DNSName
+
+	for _, rule := range elbv2.DescribeRules(listener.ARN) {
+		if rule.TargetGroupARN == service.TargetGroupArn && rule.Type == "HOST" && !strings.ContainsAny(rule.Value, "*?") {
+			host = rule.Value
+			break
+		}
+	}
+
+	url := serviceURL(listener, host)
+
+	if err := openBrowser(url); err != nil {
+		console.Info("Could not open a browser, service is available at:")
+		fmt.Println(url)
+		return
+	}
+
+	console.Info("Opened %s", url)
+}
+
+//preferredListener returns the HTTPS listener if there is one, falling back to HTTP.
+func preferredListener(listeners []ELBV2.Listener) (ELBV2.Listener, bool) {
+	var (
+		found    bool
+		selected ELBV2.Listener
+	)
+
+	for _, listener := range listeners {
+		switch listener.Protocol {
+		case "HTTPS":
+			return listener, true
+		case "HTTP":
+			if !found {
+				selected = listener
+				found = true
+			}
+		}
+	}
+
+	return selected, found
+}
+
+//serviceURL builds the URL for a listener, omitting the port when it is the protocol default.
+func serviceURL(listener ELBV2.Listener, host string) string {
+	scheme := strings.ToLower(listener.Protocol)
+
+	if (scheme == "http" && listener.Port == 80) || (scheme == "https" && listener.Port == 443) {
+		return fmt.Sprintf("%s://%s/", scheme, host)
+	}
+
+	return fmt.Sprintf("%s://%s:%d/", scheme, host, listener.Port)
+}
+
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+
+	return cmd.Start()
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"testing"
+
+	ELBV2 "github.com/turnerlabs/fargate/elbv2"
+)
+
+func TestPreferredListener(t *testing.T) {
+	http := ELBV2.Listener{Protocol: "HTTP", Port: 80}
+	https := ELBV2.Listener{Protocol: "HTTPS", Port: 443}
+	tcp := ELBV2.Listener{Protocol: "TCP", Port: 22}
+
+	var tests = []struct {
+		listeners []ELBV2.Listener
+		out       ELBV2.Listener
+		ok        bool
+	}{
+		{[]ELBV2.Listener{http, https}, https, true},
+		{[]ELBV2.Listener{tcp, http}, http, true},
+		{[]ELBV2.Listener{tcp}, ELBV2.Listener{}, false},
+		{[]ELBV2.Listener{}, ELBV2.Listener{}, false},
+	}
+
+	for _, test := range tests {
+		out, ok := preferredListener(test.listeners)
+
+		if ok != test.ok || out.String() != test.out.String() {
+			t.Errorf("preferredListener(%v) => %s, %t, want %s, %t", test.listeners, out, ok, test.out, test.ok)
+		}
+	}
+}
+
+func TestServiceURL(t *testing.T) {
+	var tests = []struct {
+		listener ELBV2.Listener
+		host     string
+		out      string
+	}{
+		{ELBV2.Listener{Protocol: "HTTP", Port: 80}, "lb.example.com", "http://lb.example.com/"},
+		{ELBV2.Listener{Protocol: "HTTPS", Port: 443}, "www.example.com", "https://www.example.com/"},
+		{ELBV2.Listener{Protocol: "HTTP", Port: 8080}, "lb.example.com", "http://lb.example.com:8080/"},
+		{ELBV2.Listener{Protocol: "HTTPS", Port: 8443}, "lb.example.com", "https://lb.example.com:8443/"},
+	}
+
+	for _, test := range tests {
+		if out := serviceURL(test.listener, test.host); out != test.out {
+			t.Errorf("serviceURL(%s, %s) => %s, want %s", test.listener, test.host, out, test.out)
+		}
+	}
+}
@@ -1,9 +1,14 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/turnerlabs/fargate/console"
 	EC2 "github.com/turnerlabs/fargate/ec2"
@@ -12,15 +17,84 @@ import (
 )
 
 type ServiceProcessListOperation struct {
+	All         bool
+	ByAZ        bool
+	Deployment  string
 	ServiceName string
+	ShowNetwork bool
+	Stopped     bool
 }
 
+func (o *ServiceProcessListOperation) Validate() error {
+	if o.All && !o.Stopped {
+		return errors.New("--all can only be used with --stopped")
+	}
+
+	if o.Deployment == "" {
+		return nil
+	}
+
+	if revision, err := strconv.Atoi(o.Deployment); err != nil || revision < 1 {
+		return errors.New("--deployment must be a task definition revision number (e.g. 5)")
+	}
+
+	return nil
+}
+
+var (
+	flagServicePsAll         bool
+	flagServicePsByAZ        bool
+	flagServicePsDeployment  string
+	flagServicePsShowNetwork bool
+	flagServicePsStopped     bool
+)
+
 var servicePsCmd = &cobra.Command{
 	Use:   "ps",
 	Short: "List running tasks for a service",
+	Long: `List running tasks for a service
+
+Pass --show-network to include the subnet, security groups, and elastic
+network interface each task is using, followed by a summary of the distinct
+subnets and security groups across all of the service's tasks.
+
+Pass --deployment with a task definition revision number to only list tasks
+from that deployment. This is useful to confirm a deploy has fully rolled out.
+
+Pass --by-az to follow the list with a count of tasks in each of the service's
+availability zones, flagging tasks concentrated in a single zone or unevenly
+spread across zones.
+
+Tasks that are still running while they're stopped, such as tasks draining
+during a deploy, are listed with their status marked "(stopping)", and tasks
+that haven't started yet with "(starting)". The DESIRED column shows the
+status ECS is moving each task to. Tasks whose network interface is already
+gone, or not attached yet, show "-" for their IP and security groups.
+
+Pass --stopped to list the service's recently stopped tasks and why they
+stopped instead, most recently stopped first. Only the last 10 are listed
+unless --all is passed. ECS keeps stopped tasks for about an hour.
+
+With --output json, the tasks are written to standard output as a JSON list
+instead of a table; --show-network and --by-az are ignored. Each task includes
+"transitioning": true while its last status differs from its desired status.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		operation := &ServiceProcessListOperation{
+			All:         flagServicePsAll,
+			ByAZ:        flagServicePsByAZ,
+			Deployment:  flagServicePsDeployment,
 			ServiceName: getServiceName(),
+			ShowNetwork: flagServicePsShowNetwork,
+			Stopped:     flagServicePsStopped,
+		}
+
+		if err := operation.Validate(); err != nil {
+			console.ErrorExit(err, "Invalid command line flags")
+		}
+
+		if operation.Stopped {
+			getStoppedServiceProcessList(operation)
+			return
 		}
 
 		getServiceProcessList(operation)
@@ -28,6 +102,12 @@ var servicePsCmd = &cobra.Command{
 }
 
 func init() {
+	servicePsCmd.Flags().BoolVar(&flagServicePsAll, "all", false, "List all stopped tasks rather than the last 10 (requires --stopped)")
+	servicePsCmd.Flags().BoolVar(&flagServicePsByAZ, "by-az", false, "Summarize how tasks are spread across availability zones")
+	servicePsCmd.Flags().StringVar(&flagServicePsDeployment, "deployment", "", "Only list tasks from a deployment (task definition revision number)")
+	servicePsCmd.Flags().BoolVar(&flagServicePsShowNetwork, "show-network", false, "Show subnet, security group, and ENI details for each task")
+	servicePsCmd.Flags().BoolVar(&flagServicePsStopped, "stopped", false, "List recently stopped tasks and why they stopped")
+
 	serviceCmd.AddCommand(servicePsCmd)
 }
 
@@ -36,7 +116,12 @@ func getServiceProcessList(operation *ServiceProcessListOperation) {
 
 	ecs := ECS.New(sess, getClusterName())
 	ec2 := EC2.New(sess)
-	tasks := ecs.DescribeTasksForService(operation.ServiceName)
+	service := ecs.DescribeService(operation.ServiceName)
+	tasks := append(ecs.DescribeServiceTasks(service), ecs.DescribeStoppingServiceTasks(service)...)
+
+	if operation.Deployment != "" {
+		tasks = filterTasksByDeployment(tasks, operation.Deployment)
+	}
 
 	for _, task := range tasks {
 		if task.EniId != "" {
@@ -44,27 +129,320 @@ func getServiceProcessList(operation *ServiceProcessListOperation) {
 		}
 	}
 
-	if len(tasks) > 0 {
-		enis := ec2.DescribeNetworkInterfaces(eniIds)
+	enis := ec2.DescribeNetworkInterfaces(eniIds)
+	setTaskSecurityGroups(tasks, enis)
 
+	if getOutput() == outputJSON {
+		printJSON(tasksOrEmpty(tasks))
+		return
+	}
+
+	if len(tasks) > 0 {
 		w := new(tabwriter.Writer)
 		w.Init(os.Stdout, 0, 8, 1, '\t', 0)
-		fmt.Fprintln(w, "ID\tIMAGE\tSTATUS\tRUNNING\tIP\tCPU\tMEMORY\t")
+
+		if operation.ShowNetwork {
+			fmt.Fprintln(w, "ID\tIMAGE\tSTATUS\tDESIRED\tRUNNING\tIP\tCPU\tMEMORY\tDEPLOYMENT\tSUBNET\tSECURITY GROUPS\tENI\t")
+		} else {
+			fmt.Fprintln(w, "ID\tIMAGE\tSTATUS\tDESIRED\tRUNNING\tIP\tCPU\tMEMORY\tDEPLOYMENT\t")
+		}
 
 		for _, t := range tasks {
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t",
 				t.TaskId,
 				t.Image,
-				Humanize(t.LastStatus),
+				taskStatus(t),
+				Humanize(t.DesiredStatus),
 				t.RunningFor(),
-				enis[t.EniId].PublicIpAddress,
+				taskPublicIP(t, enis),
 				t.Cpu,
 				t.Memory,
+				t.DeploymentId,
 			)
+
+			if operation.ShowNetwork {
+				fmt.Fprintf(w, "%s\t%s\t%s\t",
+					t.SubnetId,
+					taskSecurityGroups(t, enis),
+					t.EniId,
+				)
+			}
+
+			fmt.Fprintln(w)
 		}
 
 		w.Flush()
+
+		if operation.ShowNetwork {
+			subnetIds, securityGroupIds := summarizeTaskNetwork(tasks)
+
+			fmt.Println()
+			console.KeyValue("Subnets", "%s\n", strings.Join(subnetIds, ", "))
+			console.KeyValue("Security Groups", "%s\n", strings.Join(securityGroupIds, ", "))
+		}
+
+		if operation.ByAZ {
+			printTasksByAvailabilityZone(tasks, service.SubnetIds)
+		}
+	} else if operation.Deployment != "" {
+		console.Info("No tasks found for deployment %s", operation.Deployment)
 	} else {
 		console.Info("No tasks found")
 	}
 }
+
+//setTaskSecurityGroups fills in each task's security groups from its network
+//interface, leaving them empty for tasks whose interface is gone
+func setTaskSecurityGroups(tasks []ECS.Task, enis map[string]EC2.Eni) {
+	for i, task := range tasks {
+		tasks[i].SecurityGroupIds = enis[task.EniId].SecurityGroupIds
+	}
+}
+
+func getStoppedServiceProcessList(operation *ServiceProcessListOperation) {
+	limit := ECS.StoppedTaskLimit
+
+	if operation.All {
+		limit = 0
+	}
+
+	ecs := ECS.New(sess, getClusterName())
+	stopped := ecs.DescribeStoppedTasksForService(operation.ServiceName)
+
+	if operation.Deployment != "" {
+		stopped = filterTasksByDeployment(stopped, operation.Deployment)
+	}
+
+	tasks := ECS.LatestStoppedTasks(stopped, limit)
+
+	if getOutput() == outputJSON {
+		printJSON(tasksOrEmpty(tasks))
+		return
+	}
+
+	if len(tasks) == 0 {
+		console.Info("No stopped tasks found")
+		return
+	}
+
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 0, 8, 1, '\t', 0)
+	fmt.Fprintln(w, "ID\tIMAGE\tSTATUS\tSTOPPED\tDEPLOYMENT\tREASON\t")
+
+	for _, t := range tasks {
+		stoppedAgo := "-"
+
+		if !t.StoppedAt.IsZero() {
+			stoppedAgo = fmt.Sprintf("%s ago", time.Since(t.StoppedAt).Truncate(time.Second))
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t\n",
+			t.TaskId,
+			t.Image,
+			taskStatus(t),
+			stoppedAgo,
+			t.DeploymentId,
+			t.StoppedReason,
+		)
+	}
+
+	w.Flush()
+
+	if len(tasks) < len(stopped) {
+		console.Info("Showing the last %d of %d stopped tasks, pass --all to list them all", len(tasks), len(stopped))
+	}
+}
+
+//taskStatus returns a task's last status, noting when it is starting or stopping.
+func taskStatus(t ECS.Task) string {
+	if transition := t.Transition(); transition != "" {
+		return fmt.Sprintf("%s (%s)", Humanize(t.LastStatus), transition)
+	}
+
+	return Humanize(t.LastStatus)
+}
+
+//taskPublicIP returns a task's public IP, or - when its network interface is
+//gone (e.g. the task has stopped) or not attached yet
+func taskPublicIP(t ECS.Task, enis map[string]EC2.Eni) string {
+	eni, ok := enis[t.EniId]
+
+	if !ok {
+		return "-"
+	}
+
+	return eni.PublicIpAddress
+}
+
+//taskSecurityGroups returns a task's security groups, or - when its network
+//interface is gone
+func taskSecurityGroups(t ECS.Task, enis map[string]EC2.Eni) string {
+	if _, ok := enis[t.EniId]; !ok {
+		return "-"
+	}
+
+	return strings.Join(t.SecurityGroupIds, ", ")
+}
+
+//tasksOrEmpty returns tasks, or an empty list rather than nil so it encodes as [].
+func tasksOrEmpty(tasks []ECS.Task) []ECS.Task {
+	if tasks == nil {
+		return []ECS.Task{}
+	}
+
+	return tasks
+}
+
+//filterTasksByDeployment returns the tasks running the given task definition revision.
+func filterTasksByDeployment(tasks []ECS.Task, deployment string) []ECS.Task {
+	var filtered []ECS.Task
+
+	for _, task := range tasks {
+		if task.DeploymentId == deployment {
+			filtered = append(filtered, task)
+		}
+	}
+
+	return filtered
+}
+
+//summarizeTaskNetwork returns the distinct subnets and security groups used by tasks.
+func summarizeTaskNetwork(tasks []ECS.Task) ([]string, []string) {
+	var subnetIds, securityGroupIds []string
+
+	for _, task := range tasks {
+		if task.SubnetId != "" && !containsString(subnetIds, task.SubnetId) {
+			subnetIds = append(subnetIds, task.SubnetId)
+		}
+
+		for _, securityGroupId := range task.SecurityGroupIds {
+			if !containsString(securityGroupIds, securityGroupId) {
+				securityGroupIds = append(securityGroupIds, securityGroupId)
+			}
+		}
+	}
+
+	return subnetIds, securityGroupIds
+}
+
+type availabilityZoneTasks struct {
+	AvailabilityZone string
+	SubnetIds        []string
+	Tasks            int
+}
+
+func printTasksByAvailabilityZone(tasks []ECS.Task, serviceSubnetIds []string) {
+	subnetIds := append([]string{}, serviceSubnetIds...)
+
+	for _, task := range tasks {
+		if task.SubnetId != "" && !containsString(subnetIds, task.SubnetId) {
+			subnetIds = append(subnetIds, task.SubnetId)
+		}
+	}
+
+	zones, err := EC2.New(sess).GetSubnetAvailabilityZones(subnetIds)
+
+	if err != nil {
+		console.ErrorExit(err, "Could not determine subnet availability zones")
+	}
+
+	counts := tasksByAvailabilityZone(tasks, subnetIds, zones)
+
+	fmt.Println()
+
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 0, 8, 1, '\t', 0)
+	fmt.Fprintln(w, "AVAILABILITY ZONE\tSUBNETS\tTASKS\t")
+
+	for _, count := range counts {
+		fmt.Fprintf(w, "%s\t%s\t%d\t\n", count.AvailabilityZone, strings.Join(count.SubnetIds, ", "), count.Tasks)
+	}
+
+	w.Flush()
+
+	if imbalance := availabilityZoneImbalance(counts); imbalance != "" {
+		console.Issue(imbalance)
+	} else {
+		console.Info("Tasks are evenly spread across %d availability zones", len(counts))
+	}
+}
+
+//tasksByAvailabilityZone counts tasks in each availability zone of the given subnets, sorted by zone.
+func tasksByAvailabilityZone(tasks []ECS.Task, subnetIds []string, zones map[string]string) []availabilityZoneTasks {
+	var counts []availabilityZoneTasks
+
+	index := make(map[string]int)
+
+	add := func(subnetId string) int {
+		zone := zones[subnetId]
+
+		if zone == "" {
+			zone = "unknown"
+		}
+
+		i, ok := index[zone]
+
+		if !ok {
+			i = len(counts)
+			index[zone] = i
+			counts = append(counts, availabilityZoneTasks{AvailabilityZone: zone})
+		}
+
+		if !containsString(counts[i].SubnetIds, subnetId) {
+			counts[i].SubnetIds = append(counts[i].SubnetIds, subnetId)
+		}
+
+		return i
+	}
+
+	for _, subnetId := range subnetIds {
+		add(subnetId)
+	}
+
+	for _, task := range tasks {
+		if task.SubnetId != "" {
+			counts[add(task.SubnetId)].Tasks++
+		}
+	}
+
+	sort.SliceStable(counts, func(i, j int) bool {
+		return counts[i].AvailabilityZone < counts[j].AvailabilityZone
+	})
+
+	return counts
+}
+
+//availabilityZoneImbalance describes tasks concentrated in one availability zone or unevenly
+//spread across zones, or returns an empty string if they're balanced.
+func availabilityZoneImbalance(counts []availabilityZoneTasks) string {
+	var total, min, max int
+
+	for i, count := range counts {
+		total += count.Tasks
+
+		if i == 0 || count.Tasks < min {
+			min = count.Tasks
+		}
+
+		if count.Tasks > max {
+			max = count.Tasks
+		}
+	}
+
+	switch {
+	case total < 2:
+		return ""
+	case len(counts) == 1:
+		return fmt.Sprintf("All %d tasks are in %s; the service's subnets are all in one availability zone", total, counts[0].AvailabilityZone)
+	case max == total:
+		for _, count := range counts {
+			if count.Tasks == total {
+				return fmt.Sprintf("All %d tasks are in %s", total, count.AvailabilityZone)
+			}
+		}
+	case max-min > 1:
+		return fmt.Sprintf("Tasks are unevenly spread across availability zones (%d to %d tasks per zone)", min, max)
+	}
+
+	return ""
+}
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	EC2 "github.com/turnerlabs/fargate/ec2"
+	ECS "github.com/turnerlabs/fargate/ecs"
+)
+
+func TestSummarizeTaskNetwork(t *testing.T) {
+	tasks := []ECS.Task{
+		{SubnetId: "subnet-1", SecurityGroupIds: []string{"sg-1"}},
+		{SubnetId: "subnet-2", SecurityGroupIds: []string{"sg-1", "sg-2"}},
+		{SubnetId: "subnet-1"},
+	}
+
+	subnetIds, securityGroupIds := summarizeTaskNetwork(tasks)
+
+	if !reflect.DeepEqual(subnetIds, []string{"subnet-1", "subnet-2"}) {
+		t.Errorf("expected subnet-1, subnet-2, got %v", subnetIds)
+	}
+
+	if !reflect.DeepEqual(securityGroupIds, []string{"sg-1", "sg-2"}) {
+		t.Errorf("expected sg-1, sg-2, got %v", securityGroupIds)
+	}
+}
+
+func TestSetTaskSecurityGroups(t *testing.T) {
+	tasks := []ECS.Task{
+		{TaskId: "running", EniId: "eni-1"},
+		{TaskId: "stopped", EniId: "eni-2"},
+		{TaskId: "pending"},
+	}
+	enis := map[string]EC2.Eni{
+		"eni-1": {EniId: "eni-1", SecurityGroupIds: []string{"sg-1", "sg-2"}},
+	}
+
+	setTaskSecurityGroups(tasks, enis)
+
+	if !reflect.DeepEqual(tasks[0].SecurityGroupIds, []string{"sg-1", "sg-2"}) {
+		t.Errorf("expected sg-1, sg-2, got %v", tasks[0].SecurityGroupIds)
+	}
+
+	if len(tasks[1].SecurityGroupIds) != 0 || len(tasks[2].SecurityGroupIds) != 0 {
+		t.Errorf("expected no security groups without an interface, got %v and %v", tasks[1].SecurityGroupIds, tasks[2].SecurityGroupIds)
+	}
+}
+
+func TestTaskPublicIPAndSecurityGroups(t *testing.T) {
+	enis := map[string]EC2.Eni{
+		"eni-public":  {EniId: "eni-public", PublicIpAddress: "203.0.113.10"},
+		"eni-private": {EniId: "eni-private"},
+	}
+
+	var tests = []struct {
+		task           ECS.Task
+		ip             string
+		securityGroups string
+	}{
+		{ECS.Task{EniId: "eni-public", SecurityGroupIds: []string{"sg-1", "sg-2"}}, "203.0.113.10", "sg-1, sg-2"},
+		{ECS.Task{EniId: "eni-private", SecurityGroupIds: []string{"sg-1"}}, "", "sg-1"},
+		{ECS.Task{EniId: "eni-stopped"}, "-", "-"},
+		{ECS.Task{}, "-", "-"},
+	}
+
+	for _, test := range tests {
+		if ip := taskPublicIP(test.task, enis); ip != test.ip {
+			t.Errorf("expected IP %q for %s, got %q", test.ip, test.task.EniId, ip)
+		}
+
+		if securityGroups := taskSecurityGroups(test.task, enis); securityGroups != test.securityGroups {
+			t.Errorf("expected security groups %q for %s, got %q", test.securityGroups, test.task.EniId, securityGroups)
+		}
+	}
+}
+
+func TestFilterTasksByDeployment(t *testing.T) {
+	tasks := []ECS.Task{
+		{TaskId: "old", DeploymentId: "4"},
+		{TaskId: "new-1", DeploymentId: "5"},
+		{TaskId: "new-2", DeploymentId: "5"},
+	}
+
+	filtered := filterTasksByDeployment(tasks, "5")
+
+	if len(filtered) != 2 || filtered[0].TaskId != "new-1" || filtered[1].TaskId != "new-2" {
+		t.Errorf("expected new-1, new-2, got %v", filtered)
+	}
+
+	if filtered := filterTasksByDeployment(tasks, "3"); len(filtered) != 0 {
+		t.Errorf("expected no tasks, got %v", filtered)
+	}
+}
+
+func TestServiceProcessListOperationValidate(t *testing.T) {
+	var tests = []struct {
+		deployment string
+		valid      bool
+	}{
+		{"", true},
+		{"5", true},
+		{"0", false},
+		{"-1", false},
+		{"web:5", false},
+		{"latest", false},
+	}
+
+	for _, test := range tests {
+		operation := &ServiceProcessListOperation{Deployment: test.deployment}
+		err := operation.Validate()
+
+		if test.valid && err != nil {
+			t.Errorf("expected %q to be valid, got %v", test.deployment, err)
+		}
+
+		if !test.valid && err == nil {
+			t.Errorf("expected %q to be invalid", test.deployment)
+		}
+	}
+}
+
+func TestServiceProcessListOperationValidateAll(t *testing.T) {
+	if err := (&ServiceProcessListOperation{All: true, Stopped: true}).Validate(); err != nil {
+		t.Errorf("expected --all with --stopped to be valid, got %v", err)
+	}
+
+	if err := (&ServiceProcessListOperation{All: true}).Validate(); err == nil {
+		t.Error("expected --all without --stopped to be invalid")
+	}
+}
+
+func TestTasksByAvailabilityZone(t *testing.T) {
+	zones := map[string]string{
+		"subnet-a1": "us-east-1a",
+		"subnet-a2": "us-east-1a",
+		"subnet-b":  "us-east-1b",
+		"subnet-c":  "us-east-1c",
+	}
+	tasks := []ECS.Task{
+		{SubnetId: "subnet-b"},
+		{SubnetId: "subnet-a1"},
+		{SubnetId: "subnet-a2"},
+		{},
+	}
+
+	counts := tasksByAvailabilityZone(tasks, []string{"subnet-c", "subnet-b", "subnet-a1"}, zones)
+	expected := []availabilityZoneTasks{
+		{AvailabilityZone: "us-east-1a", SubnetIds: []string{"subnet-a1", "subnet-a2"}, Tasks: 2},
+		{AvailabilityZone: "us-east-1b", SubnetIds: []string{"subnet-b"}, Tasks: 1},
+		{AvailabilityZone: "us-east-1c", SubnetIds: []string{"subnet-c"}, Tasks: 0},
+	}
+
+	if !reflect.DeepEqual(counts, expected) {
+		t.Errorf("expected %v, got %v", expected, counts)
+	}
+}
+
+func TestAvailabilityZoneImbalance(t *testing.T) {
+	zone := func(name string, tasks int) availabilityZoneTasks {
+		return availabilityZoneTasks{AvailabilityZone: name, Tasks: tasks}
+	}
+
+	var tests = []struct {
+		name     string
+		counts   []availabilityZoneTasks
+		balanced bool
+	}{
+		{"even", []availabilityZoneTasks{zone("us-east-1a", 2), zone("us-east-1b", 2)}, true},
+		{"off by one", []availabilityZoneTasks{zone("us-east-1a", 2), zone("us-east-1b", 1), zone("us-east-1c", 2)}, true},
+		{"single task", []availabilityZoneTasks{zone("us-east-1a", 1), zone("us-east-1b", 0)}, true},
+		{"no tasks", []availabilityZoneTasks{zone("us-east-1a", 0), zone("us-east-1b", 0)}, true},
+		{"concentrated", []availabilityZoneTasks{zone("us-east-1a", 3), zone("us-east-1b", 0)}, false},
+		{"single zone", []availabilityZoneTasks{zone("us-east-1a", 2)}, false},
+		{"uneven", []availabilityZoneTasks{zone("us-east-1a", 4), zone("us-east-1b", 1), zone("us-east-1c", 1)}, false},
+	}
+
+	for _, test := range tests {
+		imbalance := availabilityZoneImbalance(test.counts)
+
+		if test.balanced && imbalance != "" {
+			t.Errorf("%s: expected balanced, got %q", test.name, imbalance)
+		}
+
+		if !test.balanced && imbalance == "" {
+			t.Errorf("%s: expected an imbalance", test.name)
+		}
+	}
+}
+
+func TestTasksOrEmpty(t *testing.T) {
+	if tasks := tasksOrEmpty(nil); tasks == nil || len(tasks) != 0 {
+		t.Errorf("expected an empty list, got %#v", tasks)
+	}
+}
+
+func TestTaskStatus(t *testing.T) {
+	var tests = []struct {
+		task   ECS.Task
+		status string
+	}{
+		{ECS.Task{DesiredStatus: "RUNNING", LastStatus: "RUNNING"}, "running"},
+		{ECS.Task{DesiredStatus: "STOPPED", LastStatus: "RUNNING"}, "running (stopping)"},
+		{ECS.Task{DesiredStatus: "RUNNING", LastStatus: "PENDING"}, "pending (starting)"},
+	}
+
+	for _, test := range tests {
+		if status := taskStatus(test.task); status != test.status {
+			t.Errorf("expected %s, got %s", test.status, status)
+		}
+	}
+}
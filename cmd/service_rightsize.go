@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	CW "github.com/turnerlabs/fargate/cloudwatch"
+	"github.com/turnerlabs/fargate/console"
+	ECS "github.com/turnerlabs/fargate/ecs"
+	"github.com/spf13/cobra"
+)
+
+//rightsizeTargetUtilization is the share of a task's CPU or memory its peak
+//usage should fill once right-sized, leaving headroom for spikes
+const rightsizeTargetUtilization = 0.75
+
+//rightsizeMaxDays is how far back CloudWatch keeps the hourly ECS metrics
+const rightsizeMaxDays = 455
+
+type ServiceRightsizeOperation struct {
+	ServiceName string
+	Days        int
+	Apply       bool
+}
+
+func (o *ServiceRightsizeOperation) Validate() error {
+	if o.Days < 1 || o.Days > rightsizeMaxDays {
+		return fmt.Errorf("--days must be between 1 and %d", rightsizeMaxDays)
+	}
+
+	return nil
+}
+
+var (
+	flagServiceRightsizeDays  int
+	flagServiceRightsizeApply bool
+)
+
+var serviceRightsizeCmd = &cobra.Command{
+	Use:   "rightsize [service-name]",
+	Short: "Suggest a smaller CPU and memory size for a service",
+	Long: `Suggest a smaller CPU and memory size for a service
+
+Looks up the CPU and memory utilization of the service's tasks in CloudWatch
+over the last 14 days (or --days) and, if the service is over-provisioned,
+suggests the smallest valid Fargate CPU and memory combination that is no
+larger than the current one and keeps peak usage at or under 75% of each.
+
+Nothing is changed unless --apply is passed, which updates the service to the
+suggested size like service update does. The service name defaults to
+--service.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		operation := &ServiceRightsizeOperation{
+			Days:  flagServiceRightsizeDays,
+			Apply: flagServiceRightsizeApply,
+		}
+
+		if len(args) == 1 {
+			operation.ServiceName = args[0]
+		} else {
+			operation.ServiceName = getServiceName()
+		}
+
+		if err := operation.Validate(); err != nil {
+			console.ErrorExit(err, "Invalid command line flags")
+		}
+
+		rightsizeService(operation)
+	},
+}
+
+func init() {
+	serviceRightsizeCmd.Flags().IntVar(&flagServiceRightsizeDays, "days", 14, "Number of days of utilization to base the suggestion on")
+	serviceRightsizeCmd.Flags().BoolVar(&flagServiceRightsizeApply, "apply", false, "Update the service to the suggested size")
+
+	serviceCmd.AddCommand(serviceRightsizeCmd)
+}
+
+func rightsizeService(operation *ServiceRightsizeOperation) {
+	ecs := ECS.New(sess, getClusterName())
+	service := ecs.DescribeService(operation.ServiceName)
+
+	cpuUnits, err := strconv.ParseInt(service.Cpu, 10, 64)
+
+	if err != nil {
+		console.ErrorExit(err, "Could not read CPU of service %s", operation.ServiceName)
+	}
+
+	mebibytes, err := strconv.ParseInt(service.Memory, 10, 64)
+
+	if err != nil {
+		console.ErrorExit(err, "Could not read memory of service %s", operation.ServiceName)
+	}
+
+	end := time.Now()
+	start := end.AddDate(0, 0, -operation.Days)
+	utilization, err := CW.New(sess).GetServiceUtilization(getClusterName(), operation.ServiceName, start, end)
+
+	if err != nil {
+		console.ErrorExit(err, "Could not get CloudWatch metrics for service %s", operation.ServiceName)
+	}
+
+	if utilization.CPU.Datapoints == 0 || utilization.Memory.Datapoints == 0 {
+		console.IssueExit("No CloudWatch metrics found for service %s in the last %d days", operation.ServiceName, operation.Days)
+	}
+
+	console.KeyValue("CPU", "%d units, %.1f%% average, %.1f%% peak\n", cpuUnits, utilization.CPU.Average, utilization.CPU.Maximum)
+	console.KeyValue("Memory", "%d MiB, %.1f%% average, %.1f%% peak\n", mebibytes, utilization.Memory.Average, utilization.Memory.Maximum)
+
+	suggestedCpu, suggestedMemory, smaller := rightsizeCpuAndMemory(cpuUnits, mebibytes, utilization.CPU.Maximum, utilization.Memory.Maximum)
+
+	if !smaller {
+		console.Info("Service %s is not over-provisioned", operation.ServiceName)
+		return
+	}
+
+	console.Info("Service %s is over-provisioned, suggested size: %d CPU units / %d MiB", operation.ServiceName, suggestedCpu, suggestedMemory)
+
+	if !operation.Apply {
+		console.Info("To apply, run: fargate service update --service %s --cpu %d --memory %d", operation.ServiceName, suggestedCpu, suggestedMemory)
+		return
+	}
+
+	updateService(
+		&ServiceUpdateOperation{
+			ServiceName: operation.ServiceName,
+			Cpu:         strconv.FormatInt(suggestedCpu, 10),
+			Memory:      strconv.FormatInt(suggestedMemory, 10),
+			Service:     service,
+		},
+	)
+}
+
+//rightsizeCpuAndMemory returns the smallest valid CPU and memory combination,
+//no larger than the current one, that keeps peak CPU and memory utilization
+//(as percentages of the current size) under the target, and whether it is
+//smaller than the current one
+func rightsizeCpuAndMemory(cpuUnits, mebibytes int64, peakCpu, peakMemory float64) (int64, int64, bool) {
+	neededCpu := float64(cpuUnits) * peakCpu / 100 / rightsizeTargetUtilization
+	neededMemory := float64(mebibytes) * peakMemory / 100 / rightsizeTargetUtilization
+
+	for _, combination := range cpuMemoryCombinations {
+		if combination.CpuUnits > cpuUnits {
+			break
+		}
+
+		if float64(combination.CpuUnits) < neededCpu {
+			continue
+		}
+
+		for _, m := range combination.Mebibytes() {
+			if m > mebibytes {
+				break
+			}
+
+			if float64(m) >= neededMemory {
+				return combination.CpuUnits, m, combination.CpuUnits != cpuUnits || m != mebibytes
+			}
+		}
+	}
+
+	return cpuUnits, mebibytes, false
+}
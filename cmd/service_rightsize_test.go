@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestRightsizeCpuAndMemory(t *testing.T) {
+	var tests = []struct {
+		cpuUnits   int64
+		mebibytes  int64
+		peakCpu    float64
+		peakMemory float64
+		wantCpu    int64
+		wantMemory int64
+		smaller    bool
+	}{
+		{1024, 2048, 10, 10, 256, 512, true},
+		{1024, 4096, 30, 20, 512, 2048, true},
+		{1024, 8192, 60, 20, 1024, 3072, true},
+		{2048, 4096, 20, 90, 2048, 4096, false},
+		{256, 512, 90, 90, 256, 512, false},
+		{512, 1024, 10, 80, 512, 1024, false},
+	}
+
+	for _, test := range tests {
+		cpu, memory, smaller := rightsizeCpuAndMemory(test.cpuUnits, test.mebibytes, test.peakCpu, test.peakMemory)
+
+		if cpu != test.wantCpu || memory != test.wantMemory || smaller != test.smaller {
+			t.Errorf("rightsizeCpuAndMemory(%d, %d, %.0f, %.0f) => (%d, %d, %t), want (%d, %d, %t)",
+				test.cpuUnits, test.mebibytes, test.peakCpu, test.peakMemory, cpu, memory, smaller, test.wantCpu, test.wantMemory, test.smaller)
+		}
+	}
+}
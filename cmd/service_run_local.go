@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/turnerlabs/fargate/console"
+	ECS "github.com/turnerlabs/fargate/ecs"
+	"github.com/spf13/cobra"
+)
+
+type ServiceRunLocalOperation struct {
+	ServiceName string
+	EnvVars     []ECS.EnvVar
+}
+
+func (o *ServiceRunLocalOperation) SetEnvVars(inputEnvVars []string, envVarFile string) {
+	o.EnvVars = processEnvVarArgs(inputEnvVars, envVarFile)
+}
+
+var (
+	flagServiceRunLocalEnvVars []string
+	flagServiceRunLocalEnvFile string
+)
+
+var serviceRunLocalCmd = &cobra.Command{
+	Use:   "run-local [--env <key=value>] [--file filename]",
+	Short: "Run service locally with docker",
+	Long: `Run service locally with docker
+
+Reads the image, environment variables, and port mappings from the service's
+current task definition and runs the container locally with docker run, so
+you can test the same configuration that is deployed.
+
+Secrets are not fetched from AWS. Provide local values for them (or override
+any other variable) with --env or --file, which take the same KEY=value form
+as env set. Secrets without a local value are left unset.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		operation := &ServiceRunLocalOperation{
+			ServiceName: getServiceName(),
+		}
+
+		operation.SetEnvVars(flagServiceRunLocalEnvVars, flagServiceRunLocalEnvFile)
+
+		runServiceLocally(operation)
+	},
+}
+
+func init() {
+	serviceRunLocalCmd.Flags().StringArrayVarP(&flagServiceRunLocalEnvVars, "env", "e", []string{}, "Local environment variables or secret values [e.g. KEY=value]")
+	serviceRunLocalCmd.Flags().StringVarP(&flagServiceRunLocalEnvFile, "file", "f", "", "File containing list of local environment variables, one per line, of the form KEY=value")
+
+	serviceCmd.AddCommand(serviceRunLocalCmd)
+}
+
+func runServiceLocally(operation *ServiceRunLocalOperation) {
+	ecs := ECS.New(sess, getClusterName())
+	service := ecs.DescribeService(operation.ServiceName)
+
+	if service.Status != statusActive {
+		console.InfoExit("Service not found")
+	}
+
+	taskDefinition := ecs.DescribeTaskDefinition(service.TaskDefinitionArn).TaskDefinition
+	container := ECS.PrimaryContainerDefinition(taskDefinition, service.ContainerName)
+
+	if container == nil {
+		console.IssueExit("No container found in task definition %s", service.TaskDefinitionArn)
+	}
+
+	var ports []int64
+
+	for _, portMapping := range container.PortMappings {
+		ports = append(ports, aws.Int64Value(portMapping.ContainerPort))
+	}
+
+	envVars := mergeEnvVars(ecs.GetEnvVarsFromTaskDefinition(service.TaskDefinitionArn, service.ContainerName), operation.EnvVars)
+	missing := missingSecrets(ecs.GetSecretVarsFromTaskDefinition(service.TaskDefinitionArn, service.ContainerName), envVars)
+
+	if len(missing) > 0 {
+		console.Issue("No local value for secrets %s, they will be unset", strings.Join(missing, ", "))
+	}
+
+	args := dockerRunArgs(aws.StringValue(container.Image), ports, envVars)
+
+	console.Info("Running %s locally", operation.ServiceName)
+	console.Debug("docker %s", strings.Join(args, " "))
+
+	cmd := exec.CommandContext(commandContext, "docker", args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		console.ErrorExit(err, "Could not run service locally")
+	}
+}
+
+//mergeEnvVars returns envVars with each override replacing the variable of the
+//same key, or appended if it is not already present.
+func mergeEnvVars(envVars, overrides []ECS.EnvVar) []ECS.EnvVar {
+	var result []ECS.EnvVar
+
+	for _, envVar := range envVars {
+		for _, override := range overrides {
+			if override.Key == envVar.Key {
+				envVar.Value = override.Value
+			}
+		}
+
+		result = append(result, envVar)
+	}
+
+	for _, override := range overrides {
+		found := false
+
+		for _, envVar := range result {
+			if envVar.Key == override.Key {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			result = append(result, override)
+		}
+	}
+
+	return result
+}
+
+//missingSecrets returns the keys of secrets that have no local value in envVars.
+func missingSecrets(secrets, envVars []ECS.EnvVar) []string {
+	var missing []string
+
+	for _, secret := range secrets {
+		found := false
+
+		for _, envVar := range envVars {
+			if envVar.Key == secret.Key {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			missing = append(missing, secret.Key)
+		}
+	}
+
+	return missing
+}
+
+func dockerRunArgs(image string, ports []int64, envVars []ECS.EnvVar) []string {
+	args := []string{"run", "--rm", "-it"}
+
+	for _, port := range ports {
+		args = append(args, "-p", fmt.Sprintf("%d:%d", port, port))
+	}
+
+	for _, envVar := range envVars {
+		args = append(args, "-e", fmt.Sprintf("%s=%s", envVar.Key, envVar.Value))
+	}
+
+	return append(args, image)
+}
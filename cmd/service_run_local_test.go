@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	ECS "github.com/turnerlabs/fargate/ecs"
+)
+
+func TestMergeEnvVars(t *testing.T) {
+	envVars := []ECS.EnvVar{{Key: "A", Value: "1"}, {Key: "B", Value: "2"}}
+	overrides := []ECS.EnvVar{{Key: "B", Value: "local"}, {Key: "SECRET", Value: "s3cr3t"}}
+
+	want := []ECS.EnvVar{{Key: "A", Value: "1"}, {Key: "B", Value: "local"}, {Key: "SECRET", Value: "s3cr3t"}}
+
+	if got := mergeEnvVars(envVars, overrides); !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeEnvVars => %v, want %v", got, want)
+	}
+}
+
+func TestMissingSecrets(t *testing.T) {
+	secrets := []ECS.EnvVar{{Key: "DB_PASSWORD", Value: "arn:aws:ssm:us-east-1:123456789012:parameter/db"}, {Key: "API_KEY", Value: "arn:aws:ssm:us-east-1:123456789012:parameter/api"}}
+	envVars := []ECS.EnvVar{{Key: "DB_PASSWORD", Value: "local"}}
+
+	want := []string{"API_KEY"}
+
+	if got := missingSecrets(secrets, envVars); !reflect.DeepEqual(got, want) {
+		t.Errorf("missingSecrets => %v, want %v", got, want)
+	}
+}
+
+func TestDockerRunArgs(t *testing.T) {
+	args := dockerRunArgs("nginx:latest", []int64{80, 443}, []ECS.EnvVar{{Key: "FOO", Value: "bar"}})
+
+	want := []string{"run", "--rm", "-it", "-p", "80:80", "-p", "443:443", "-e", "FOO=bar", "nginx:latest"}
+
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("dockerRunArgs => %v, want %v", args, want)
+	}
+}
@@ -15,6 +15,7 @@ const validScalePattern = "[-\\+]?[0-9]+"
 type ScaleServiceOperation struct {
 	ServiceName  string
 	DesiredCount int64
+	Wait         bool
 }
 
 func (o *ScaleServiceOperation) SetScale(scaleExpression string) {
@@ -52,11 +53,21 @@ var serviceScaleCmd = &cobra.Command{
 
 Changes the number of desired tasks to be run in a service by the given scale
 expression. A scale expression can either be an absolute number or a delta
-specified with a sign such as +5 or -2.`,
+specified with a sign such as +5 or -2. Scaling to 0 stops all of the
+service's tasks, pausing it without deleting it.
+
+Pass --wait to block until the service is running the new number of tasks
+with no other deployment still rolling out (and, for services behind a load
+balancer, that many targets are healthy). Progress, including how many tasks
+are in each status, is printed as it changes. If the service doesn't settle
+within 10 minutes, tasks still provisioning or pending are counted, recent
+service events are shown, and the command exits non-zero. The global --timeout, if set, replaces
+the 10 minute limit.`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		operation := &ScaleServiceOperation{
 			ServiceName: getServiceName(),
+			Wait:        flagServiceScaleWait,
 		}
 
 		operation.SetScale(args[0])
@@ -65,13 +76,22 @@ specified with a sign such as +5 or -2.`,
 	},
 }
 
+var flagServiceScaleWait bool
+
 func init() {
+	serviceScaleCmd.Flags().BoolVarP(&flagServiceScaleWait, "wait", "w", false, "Wait for the service to be running the desired number of tasks")
+
 	serviceCmd.AddCommand(serviceScaleCmd)
 }
 
 func scaleService(operation *ScaleServiceOperation) {
 	ecs := ECS.New(sess, getClusterName())
+	previousDesiredCount := ecs.GetDesiredCount(operation.ServiceName)
 
 	ecs.SetDesiredCount(operation.ServiceName, operation.DesiredCount)
-	console.Info("Scaled service %s to %d", operation.ServiceName, operation.DesiredCount)
+	console.Info("Scaled service %s from %d to %d", operation.ServiceName, previousDesiredCount, operation.DesiredCount)
+
+	if operation.Wait {
+		waitForServiceCount(operation.ServiceName, operation.DesiredCount)
+	}
 }
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	AAS "github.com/turnerlabs/fargate/applicationautoscaling"
+	"github.com/turnerlabs/fargate/console"
+	"github.com/spf13/cobra"
+)
+
+var businessHoursPattern = regexp.MustCompile(`^([01]?[0-9]|2[0-3]):([0-5][0-9])-([01]?[0-9]|2[0-3]):([0-5][0-9])$`)
+
+type ServiceScheduleOperation struct {
+	ServiceName   string
+	BusinessHours string
+	Timezone      string
+	Peak          int64
+	OffPeak       int64
+	Weekdays      bool
+}
+
+func (o *ServiceScheduleOperation) Validate() error {
+	if _, _, err := parseBusinessHours(o.BusinessHours); err != nil {
+		return err
+	}
+
+	if _, err := time.LoadLocation(o.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone %s [e.g. America/New_York]", o.Timezone)
+	}
+
+	if o.OffPeak < 0 {
+		return errors.New("--offpeak must be 0 or greater")
+	}
+
+	if o.Peak < 1 || o.Peak < o.OffPeak {
+		return errors.New("--peak must be at least 1 and no less than --offpeak")
+	}
+
+	return nil
+}
+
+var (
+	flagServiceScheduleBusinessHours string
+	flagServiceScheduleTimezone      string
+	flagServiceSchedulePeak          int64
+	flagServiceScheduleOffPeak       int64
+	flagServiceScheduleWeekdays      bool
+)
+
+var serviceScheduleCmd = &cobra.Command{
+	Use:   "schedule --business-hours <HH:MM-HH:MM> --peak <count> [--offpeak <count>]",
+	Short: "Scale a service on a daily schedule",
+	Long: `Scale a service on a daily schedule
+
+Runs --peak tasks during business hours and --offpeak tasks (0 by default)
+the rest of the time. Business hours are given as a 24 hour HH:MM-HH:MM range
+in the --tz timezone (UTC by default). Pass --weekdays to only scale up Monday
+through Friday.
+
+The schedule is created as two Application Auto Scaling scheduled actions on
+the service, which replace any from a previous run of this command.`,
+	Example: `
+fargate service schedule --business-hours 08:00-20:00 --tz America/New_York --peak 4
+fargate service schedule --business-hours 07:30-18:00 --peak 2 --offpeak 1 --weekdays
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		operation := &ServiceScheduleOperation{
+			ServiceName:   getServiceName(),
+			BusinessHours: flagServiceScheduleBusinessHours,
+			Timezone:      flagServiceScheduleTimezone,
+			Peak:          flagServiceSchedulePeak,
+			OffPeak:       flagServiceScheduleOffPeak,
+			Weekdays:      flagServiceScheduleWeekdays,
+		}
+
+		if err := operation.Validate(); err != nil {
+			console.ErrorExit(err, "Invalid command line flags")
+		}
+
+		scheduleService(operation)
+	},
+}
+
+func init() {
+	serviceScheduleCmd.Flags().StringVar(&flagServiceScheduleBusinessHours, "business-hours", "", "Hours to run peak tasks [e.g. 08:00-20:00]")
+	serviceScheduleCmd.Flags().StringVar(&flagServiceScheduleTimezone, "tz", "UTC", "Timezone of business hours [e.g. America/New_York]")
+	serviceScheduleCmd.Flags().Int64Var(&flagServiceSchedulePeak, "peak", 0, "Number of tasks to run during business hours")
+	serviceScheduleCmd.Flags().Int64Var(&flagServiceScheduleOffPeak, "offpeak", 0, "Number of tasks to run outside of business hours")
+	serviceScheduleCmd.Flags().BoolVar(&flagServiceScheduleWeekdays, "weekdays", false, "Only run peak tasks Monday through Friday")
+
+	serviceCmd.AddCommand(serviceScheduleCmd)
+}
+
+func scheduleService(operation *ServiceScheduleOperation) {
+	aas := AAS.New(sess)
+	resourceID := AAS.ServiceResourceID(getClusterName(), operation.ServiceName)
+	start, end, _ := parseBusinessHours(operation.BusinessHours)
+
+	days := "*"
+	if operation.Weekdays {
+		days = "MON-FRI"
+	}
+
+	if err := aas.RegisterServiceScalableTarget(resourceID, operation.OffPeak, operation.Peak); err != nil {
+		console.ErrorExit(err, "Could not register service %s for scaling", operation.ServiceName)
+	}
+
+	actions := []AAS.ScheduledAction{
+		AAS.ScheduledAction{
+			Name:     fmt.Sprintf("fargate-%s-peak", operation.ServiceName),
+			Schedule: scheduleCron(start, days),
+			Timezone: operation.Timezone,
+			Count:    operation.Peak,
+		},
+		AAS.ScheduledAction{
+			Name:     fmt.Sprintf("fargate-%s-offpeak", operation.ServiceName),
+			Schedule: scheduleCron(end, "*"),
+			Timezone: operation.Timezone,
+			Count:    operation.OffPeak,
+		},
+	}
+
+	for _, action := range actions {
+		if err := aas.PutServiceScheduledAction(resourceID, action); err != nil {
+			console.ErrorExit(err, "Could not create scheduled action %s", action.Name)
+		}
+	}
+
+	console.Info("Scheduled service %s to run %d tasks from %s (%s) and %d tasks otherwise",
+		operation.ServiceName, operation.Peak, operation.BusinessHours, operation.Timezone, operation.OffPeak)
+}
+
+//parseBusinessHours parses an HH:MM-HH:MM range into its start and end times of day.
+func parseBusinessHours(hours string) (time.Duration, time.Duration, error) {
+	matches := businessHoursPattern.FindStringSubmatch(hours)
+
+	if matches == nil {
+		return 0, 0, fmt.Errorf("invalid business hours %s [expected HH:MM-HH:MM, e.g. 08:00-20:00]", hours)
+	}
+
+	var parts [4]time.Duration
+
+	for i := range parts {
+		n, _ := strconv.Atoi(matches[i+1])
+		parts[i] = time.Duration(n)
+	}
+
+	start := parts[0]*time.Hour + parts[1]*time.Minute
+	end := parts[2]*time.Hour + parts[3]*time.Minute
+
+	if start >= end {
+		return 0, 0, fmt.Errorf("invalid business hours %s: start must be before end", hours)
+	}
+
+	return start, end, nil
+}
+
+//scheduleCron returns an Application Auto Scaling cron expression for a time of day on the given days.
+func scheduleCron(timeOfDay time.Duration, days string) string {
+	hours := int(timeOfDay / time.Hour)
+	minutes := int((timeOfDay % time.Hour) / time.Minute)
+
+	return fmt.Sprintf("cron(%d %d ? * %s *)", minutes, hours, days)
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseBusinessHours(t *testing.T) {
+	var tests = []struct {
+		hours string
+		start time.Duration
+		end   time.Duration
+		valid bool
+	}{
+		{"08:00-20:00", 8 * time.Hour, 20 * time.Hour, true},
+		{"7:30-18:15", 7*time.Hour + 30*time.Minute, 18*time.Hour + 15*time.Minute, true},
+		{"00:00-23:59", 0, 23*time.Hour + 59*time.Minute, true},
+		{"20:00-08:00", 0, 0, false},
+		{"08:00-08:00", 0, 0, false},
+		{"24:00-25:00", 0, 0, false},
+		{"08:60-20:00", 0, 0, false},
+		{"8am-8pm", 0, 0, false},
+		{"", 0, 0, false},
+	}
+
+	for _, test := range tests {
+		start, end, err := parseBusinessHours(test.hours)
+
+		if test.valid && err != nil {
+			t.Errorf("expected %s to be valid, got %v", test.hours, err)
+		}
+
+		if !test.valid && err == nil {
+			t.Errorf("expected %s to be invalid", test.hours)
+		}
+
+		if start != test.start || end != test.end {
+			t.Errorf("%s: expected %s-%s, got %s-%s", test.hours, test.start, test.end, start, end)
+		}
+	}
+}
+
+func TestScheduleCron(t *testing.T) {
+	if cron := scheduleCron(8*time.Hour, "*"); cron != "cron(0 8 ? * * *)" {
+		t.Errorf("expected cron(0 8 ? * * *), got %s", cron)
+	}
+
+	if cron := scheduleCron(18*time.Hour+30*time.Minute, "MON-FRI"); cron != "cron(30 18 ? * MON-FRI *)" {
+		t.Errorf("expected cron(30 18 ? * MON-FRI *), got %s", cron)
+	}
+}
+
+func TestServiceScheduleOperationValidate(t *testing.T) {
+	valid := ServiceScheduleOperation{BusinessHours: "08:00-20:00", Timezone: "UTC", Peak: 4}
+
+	var tests = []struct {
+		name   string
+		modify func(*ServiceScheduleOperation)
+		valid  bool
+	}{
+		{"valid", func(o *ServiceScheduleOperation) {}, true},
+		{"equal peak and offpeak", func(o *ServiceScheduleOperation) { o.OffPeak = 4 }, true},
+		{"bad hours", func(o *ServiceScheduleOperation) { o.BusinessHours = "8-20" }, false},
+		{"bad timezone", func(o *ServiceScheduleOperation) { o.Timezone = "Mars/Olympus_Mons" }, false},
+		{"no peak", func(o *ServiceScheduleOperation) { o.Peak = 0 }, false},
+		{"negative offpeak", func(o *ServiceScheduleOperation) { o.OffPeak = -1 }, false},
+		{"offpeak above peak", func(o *ServiceScheduleOperation) { o.OffPeak = 5 }, false},
+	}
+
+	for _, test := range tests {
+		operation := valid
+		test.modify(&operation)
+		err := operation.Validate()
+
+		if test.valid && err != nil {
+			t.Errorf("%s: expected no error, got %v", test.name, err)
+		}
+
+		if !test.valid && err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
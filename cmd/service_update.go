@@ -2,27 +2,68 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/turnerlabs/fargate/console"
 	ECS "github.com/turnerlabs/fargate/ecs"
+	ELBV2 "github.com/turnerlabs/fargate/elbv2"
+	IAM "github.com/turnerlabs/fargate/iam"
 	"github.com/spf13/cobra"
 )
 
 type ServiceUpdateOperation struct {
-	ServiceName string
-	Cpu         string
-	Memory      string
-	Service     ECS.Service
+	ServiceName           string
+	Cpu                   string
+	Memory                string
+	EnableExecuteCommand  bool
+	DisableExecuteCommand bool
+	EnableManagedTags     bool
+	DisableManagedTags    bool
+	PropagateTags         string
+	HealthCheckPort       string
+	Service               ECS.Service
 }
 
 func (o *ServiceUpdateOperation) Validate() {
 	ecs := ECS.New(sess, getClusterName())
 
-	if o.Cpu == "" && o.Memory == "" {
-		console.ErrorExit(fmt.Errorf("--cpu and/or --memory must be supplied"), "Invalid command line arguments")
+	if o.Cpu == "" && o.Memory == "" && !o.EnableExecuteCommand && !o.DisableExecuteCommand &&
+		!o.EnableManagedTags && !o.DisableManagedTags && o.PropagateTags == "" && o.HealthCheckPort == "" {
+		console.ErrorExit(fmt.Errorf("--cpu, --memory, --enable-execute-command, --disable-execute-command, --enable-managed-tags, --disable-managed-tags, --propagate-tags, or --health-check-port must be supplied"), "Invalid command line arguments")
+	}
+
+	if o.EnableExecuteCommand && o.DisableExecuteCommand {
+		console.ErrorExit(fmt.Errorf("--enable-execute-command and --disable-execute-command can't be used together"), "Invalid command line arguments")
+	}
+
+	if o.EnableManagedTags && o.DisableManagedTags {
+		console.ErrorExit(fmt.Errorf("--enable-managed-tags and --disable-managed-tags can't be used together"), "Invalid command line arguments")
+	}
+
+	o.PropagateTags = strings.ToUpper(o.PropagateTags)
+
+	if err := ECS.ValidatePropagateTags(o.PropagateTags); err != nil {
+		console.ErrorExit(err, "Invalid command line arguments")
+	}
+
+	if o.HealthCheckPort != "" {
+		if err := ELBV2.ValidateHealthCheckPort(o.HealthCheckPort); err != nil {
+			console.ErrorExit(err, "Invalid command line arguments")
+		}
 	}
 
 	o.Service = ecs.DescribeService(o.ServiceName)
+
+	if o.HealthCheckPort != "" && o.Service.TargetGroupArn == "" {
+		console.IssueExit("Service %s has no load balancer target group to health check", o.ServiceName)
+	}
+
+	if o.Cpu != "" || o.Memory != "" {
+		o.validateCpuAndMemory(&ecs)
+	}
+}
+
+func (o *ServiceUpdateOperation) validateCpuAndMemory(ecs *ECS.ECS) {
 	cpu, memory := ecs.GetCpuAndMemoryFromTaskDefinition(o.Service.TaskDefinitionArn)
 
 	if o.Cpu == "" {
@@ -33,7 +74,17 @@ func (o *ServiceUpdateOperation) Validate() {
 		o.Memory = memory
 	}
 
-	err := validateCpuAndMemory(o.Cpu, o.Memory)
+	var err error
+
+	if o.Cpu, err = normalizeCpu(o.Cpu); err != nil {
+		console.ErrorExit(err, "Invalid command line arguments")
+	}
+
+	if o.Memory, err = normalizeMemory(o.Memory); err != nil {
+		console.ErrorExit(err, "Invalid command line arguments")
+	}
+
+	err = validateCpuAndMemory(o.Cpu, o.Memory)
 
 	if err != nil {
 		console.ErrorExit(err, "Invalid settings: %s CPU units / %s MiB", o.Cpu, o.Memory)
@@ -41,34 +92,74 @@ func (o *ServiceUpdateOperation) Validate() {
 }
 
 var (
-	flagServiceUpdateCpu    string
-	flagServiceUpdateMemory string
+	flagServiceUpdateCpu                   string
+	flagServiceUpdateMemory                string
+	flagServiceUpdateEnableExecuteCommand  bool
+	flagServiceUpdateDisableExecuteCommand bool
+	flagServiceUpdateEnableManagedTags     bool
+	flagServiceUpdateDisableManagedTags    bool
+	flagServiceUpdatePropagateTags         string
+	flagServiceUpdateHealthCheckPort       string
 )
 
 var serviceUpdateCmd = &cobra.Command{
-	Use:   "update --cpu <cpu-units> | --memory <MiB>",
+	Use:   "update --cpu <cpu-units> | --memory <MiB> | --enable-execute-command | --disable-execute-command | --enable-managed-tags | --disable-managed-tags | --propagate-tags <source> | --health-check-port <port>",
 	Short: "Update service configuration",
 	Long: `Update service configuration
 
 CPU and memory settings are specified as CPU units and mebibytes respectively
 using the --cpu and --memory flags. Every 1024 CPU units is equivilent to a
-single vCPU. AWS Fargate only supports certain combinations of CPU and memory
-configurations:
-
-| CPU (CPU Units) | Memory (MiB)                          |
-| --------------- | ------------------------------------- |
-| 256             | 512, 1024, or 2048                    |
-| 512             | 1024 through 4096 in 1GiB increments  |
-| 1024            | 2048 through 8192 in 1GiB increments  |
-| 2048            | 4096 through 16384 in 1GiB increments |
-| 4096            | 8192 through 30720 in 1GiB increments |
-
-At least one of --cpu or --memory must be specified.`,
+single vCPU. Either may also be given in friendlier units, such as 0.25vcpu
+for CPU or 0.5GB for memory. GB is read as GiB (1024 MiB), as in the Fargate
+task size table, so 0.5GB is 512 MiB. AWS Fargate only supports certain
+combinations of CPU and memory configurations:
+
+| CPU (CPU Units) | Memory (MiB)                            |
+| --------------- | --------------------------------------- |
+| 256             | 512, 1024, or 2048                      |
+| 512             | 1024 through 4096 in 1GiB increments    |
+| 1024            | 2048 through 8192 in 1GiB increments    |
+| 2048            | 4096 through 16384 in 1GiB increments   |
+| 4096            | 8192 through 30720 in 1GiB increments   |
+| 8192            | 16384 through 61440 in 4GiB increments  |
+| 16384           | 32768 through 122880 in 8GiB increments |
+
+Pass --enable-execute-command to allow commands to be run in the service's
+tasks with fargate task exec. The service's task role is granted the
+ssmmessages permissions ECS Exec needs, so the service must have a task role.
+--disable-execute-command turns it off again. Either starts a new deployment,
+as only new tasks pick up the setting.
+
+Pass --enable-managed-tags to have ECS tag the service's tasks with
+aws:ecs:clusterName and aws:ecs:serviceName, and --disable-managed-tags to
+stop. --propagate-tags copies tags to the service's tasks from the service
+(SERVICE) or from the task definition revision they run (TASK_DEFINITION), or
+stops copying them (NONE). Tagged tasks let costs be broken down by service.
+These also start a new deployment, as only new tasks are tagged.
+
+--health-check-port sets the port the load balancer health checks the
+service's tasks on, such as a separate admin or metrics port, or traffic-port
+to check the port the tasks receive traffic on. The service must have a
+target group. The running tasks are health checked on the new port straight
+away.
+
+At least one of --cpu, --memory, --enable-execute-command,
+--disable-execute-command, --enable-managed-tags, --disable-managed-tags,
+--propagate-tags, or --health-check-port must be specified.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		operation := &ServiceUpdateOperation{
 			ServiceName: getServiceName(),
 			Cpu:         flagServiceUpdateCpu,
 			Memory:      flagServiceUpdateMemory,
+
+			EnableExecuteCommand:  flagServiceUpdateEnableExecuteCommand,
+			DisableExecuteCommand: flagServiceUpdateDisableExecuteCommand,
+
+			EnableManagedTags:  flagServiceUpdateEnableManagedTags,
+			DisableManagedTags: flagServiceUpdateDisableManagedTags,
+			PropagateTags:      flagServiceUpdatePropagateTags,
+
+			HealthCheckPort: flagServiceUpdateHealthCheckPort,
 		}
 
 		operation.Validate()
@@ -80,19 +171,69 @@ At least one of --cpu or --memory must be specified.`,
 func init() {
 	serviceCmd.AddCommand(serviceUpdateCmd)
 
-	serviceUpdateCmd.Flags().StringVar(&flagServiceUpdateCpu, "cpu", "", "Amount of cpu units to allocate for each task")
-	serviceUpdateCmd.Flags().StringVarP(&flagServiceUpdateMemory, "memory", "m", "", "Amount of MiB to allocate for each task")
+	serviceUpdateCmd.Flags().StringVar(&flagServiceUpdateCpu, "cpu", "", "Amount of cpu units (or vCPUs, e.g. 0.25vcpu) to allocate for each task")
+	serviceUpdateCmd.Flags().StringVarP(&flagServiceUpdateMemory, "memory", "m", "", "Amount of MiB (or GiB, e.g. 0.5GB, where GB means GiB) to allocate for each task")
+	serviceUpdateCmd.Flags().BoolVar(&flagServiceUpdateEnableExecuteCommand, "enable-execute-command", false, "Allow commands to be run in the service's tasks with task exec")
+	serviceUpdateCmd.Flags().BoolVar(&flagServiceUpdateDisableExecuteCommand, "disable-execute-command", false, "Stop allowing commands to be run in the service's tasks")
+	serviceUpdateCmd.Flags().BoolVar(&flagServiceUpdateEnableManagedTags, "enable-managed-tags", false, "Tag the service's tasks with their cluster and service names")
+	serviceUpdateCmd.Flags().BoolVar(&flagServiceUpdateDisableManagedTags, "disable-managed-tags", false, "Stop tagging the service's tasks with their cluster and service names")
+	serviceUpdateCmd.Flags().StringVar(&flagServiceUpdatePropagateTags, "propagate-tags", "", "Where the service's tasks copy their tags from [SERVICE, TASK_DEFINITION, or NONE]")
+	serviceUpdateCmd.Flags().StringVar(&flagServiceUpdateHealthCheckPort, "health-check-port", "", "Port to health check the service's tasks on [port number or traffic-port]")
 }
 
 func updateService(operation *ServiceUpdateOperation) {
 	ecs := ECS.New(sess, getClusterName())
 
-	newTaskDefinitionArn := ecs.UpdateTaskDefinitionCpuAndMemory(
-		operation.Service.TaskDefinitionArn,
-		operation.Cpu,
-		operation.Memory,
-	)
+	if operation.Cpu != "" || operation.Memory != "" {
+		newTaskDefinitionArn := ecs.UpdateTaskDefinitionCpuAndMemory(
+			operation.Service.TaskDefinitionArn,
+			operation.Cpu,
+			operation.Memory,
+		)
+
+		ecs.UpdateServiceTaskDefinition(operation.ServiceName, newTaskDefinitionArn)
+		console.Info("Updated service %s to %s CPU units / %s MiB", operation.ServiceName, operation.Cpu, operation.Memory)
+	}
+
+	if operation.EnableExecuteCommand {
+		if operation.Service.TaskRole == "" {
+			console.IssueExit("Service %s has no task role, which ECS Exec needs to open sessions in its tasks", operation.ServiceName)
+		}
+
+		if err := IAM.New(sess).GrantExecuteCommand(operation.Service.TaskRole); err != nil {
+			console.ErrorExit(err, "Could not grant ECS Exec permissions to %s", operation.Service.TaskRole)
+		}
+
+		ecs.SetEnableExecuteCommand(operation.ServiceName, true)
+		console.Info("Enabled execute command for service %s, new tasks are being started", operation.ServiceName)
+	}
+
+	if operation.DisableExecuteCommand {
+		ecs.SetEnableExecuteCommand(operation.ServiceName, false)
+		console.Info("Disabled execute command for service %s, new tasks are being started", operation.ServiceName)
+	}
+
+	if operation.EnableManagedTags || operation.DisableManagedTags || operation.PropagateTags != "" {
+		enableManagedTags := operation.Service.EnableManagedTags
+		propagateTags := operation.Service.PropagateTags
+
+		if operation.EnableManagedTags || operation.DisableManagedTags {
+			enableManagedTags = operation.EnableManagedTags
+		}
+
+		if operation.PropagateTags != "" {
+			propagateTags = operation.PropagateTags
+		}
 
-	ecs.UpdateServiceTaskDefinition(operation.ServiceName, newTaskDefinitionArn)
-	console.Info("Updated service %s to %s CPU units / %s MiB", operation.ServiceName, operation.Cpu, operation.Memory)
+		ecs.SetTagPropagation(operation.ServiceName, enableManagedTags, propagateTags)
+		console.Info("Updated tagging for service %s, new tasks are being started", operation.ServiceName)
+	}
+
+	if operation.HealthCheckPort != "" {
+		if err := ELBV2.New(sess).SetHealthCheckPort(operation.Service.TargetGroupArn, operation.HealthCheckPort); err != nil {
+			console.ErrorExit(err, "Could not update health check port for service %s", operation.ServiceName)
+		}
+
+		console.Info("Updated health check port for service %s to %s", operation.ServiceName, operation.HealthCheckPort)
+	}
 }
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	ECS "github.com/turnerlabs/fargate/ecs"
+)
+
+type ServiceValidateOperation struct {
+	ServiceName     string
+	Image           string
+	Cpu             string
+	Memory          string
+	Ports           []string
+	EnvVars         []string
+	SecretVars      []string
+	Sidecars        []string
+	SidecarEnvVars  []string
+	SidecarPorts    []string
+	EFSVolumes      []string
+	PlatformVersion string
+	TaskRole        string
+}
+
+var (
+	flagServiceValidateImage           string
+	flagServiceValidateCpu             string
+	flagServiceValidateMemory          string
+	flagServiceValidatePorts           []string
+	flagServiceValidateEnvVars         []string
+	flagServiceValidateSecretVars      []string
+	flagServiceValidateSidecars        []string
+	flagServiceValidateSidecarEnvVars  []string
+	flagServiceValidateSidecarPorts    []string
+	flagServiceValidateEFSVolumes      []string
+	flagServiceValidatePlatformVersion string
+	flagServiceValidateTaskRole        string
+)
+
+var serviceValidateCmd = &cobra.Command{
+	Use:   "validate [service-name]",
+	Short: "Validate a service's task definition without calling AWS",
+	Long: `Validate a service's task definition without calling AWS
+
+Checks that the given flags describe a task definition Fargate would accept
+and prints the task definition as JSON, without calling AWS, so it can run in
+pre-commit hooks and CI. Every problem found is listed and the command exits
+non-zero.
+
+The checks are those run when a task definition is built: the CPU and memory
+combination is one Fargate supports, the port and protocol are valid, the
+sidecars are well formed and don't share the task container's name, EFS
+volumes are well formed and --platform-version is 1.4.0 or later when any are
+mounted, and the task has an essential container with an image.
+
+Secrets are printed as given, and the task role is used as is, since resolving
+names to ARNs requires AWS. The service name defaults to --service. No AWS
+credentials are needed.
+
+--cpu takes CPU units (256) or vCPUs (0.25vcpu) and defaults to 256, and
+--memory takes MiB (512) or GiB (0.5GB) and defaults to 512.`,
+	Example: `
+fargate service validate web --image 123456789.dkr.ecr.us-east-1.amazonaws.com/web:0.1.0 --cpu 512 --memory 1024 --port 80
+fargate service validate web --image web:0.1.0 --efs fs-12345678:/data --platform-version 1.4.0
+`,
+	Args:        cobra.MaximumNArgs(1),
+	Annotations: map[string]string{annotationOffline: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		operation := &ServiceValidateOperation{
+			Image:           flagServiceValidateImage,
+			Cpu:             flagServiceValidateCpu,
+			Memory:          flagServiceValidateMemory,
+			Ports:           flagServiceValidatePorts,
+			EnvVars:         flagServiceValidateEnvVars,
+			SecretVars:      flagServiceValidateSecretVars,
+			Sidecars:        flagServiceValidateSidecars,
+			SidecarEnvVars:  flagServiceValidateSidecarEnvVars,
+			SidecarPorts:    flagServiceValidateSidecarPorts,
+			EFSVolumes:      flagServiceValidateEFSVolumes,
+			PlatformVersion: flagServiceValidatePlatformVersion,
+			TaskRole:        flagServiceValidateTaskRole,
+		}
+
+		if len(args) == 1 {
+			operation.ServiceName = args[0]
+		} else {
+			operation.ServiceName = getServiceName()
+		}
+
+		validateService(operation)
+	},
+}
+
+func init() {
+	serviceValidateCmd.Flags().StringVarP(&flagServiceValidateImage, "image", "i", "", "Docker image to run in the service")
+	serviceValidateCmd.Flags().StringVar(&flagServiceValidateCpu, "cpu", "256", "Amount of cpu units (or vCPUs, e.g. 0.25vcpu) to allocate for each task")
+	serviceValidateCmd.Flags().StringVarP(&flagServiceValidateMemory, "memory", "m", "512", "Amount of MiB (or GiB, e.g. 0.5GB, where GB means GiB) to allocate for each task")
+	serviceValidateCmd.Flags().StringArrayVarP(&flagServiceValidatePorts, "port", "p", []string{}, "Port to listen on [e.g., 80, 443, http:8080, https:8443, tcp:1935, udp:53]")
+	serviceValidateCmd.Flags().StringArrayVarP(&flagServiceValidateEnvVars, "env", "e", []string{}, "Environment variables to set [e.g. -e KEY=value -e KEY2=value]")
+	serviceValidateCmd.Flags().StringArrayVar(&flagServiceValidateSecretVars, "secret", []string{}, "Secret variables to set [e.g. --secret KEY=valueFrom --secret KEY2=valueFrom]")
+	serviceValidateCmd.Flags().StringArrayVar(&flagServiceValidateSidecars, "sidecar", []string{}, "Sidecar container to add [e.g. --sidecar name=image:tag]")
+	serviceValidateCmd.Flags().StringArrayVar(&flagServiceValidateSidecarEnvVars, "sidecar-env", []string{}, "Sidecar environment variables to set [e.g. --sidecar-env name:KEY=value]")
+	serviceValidateCmd.Flags().StringArrayVar(&flagServiceValidateSidecarPorts, "sidecar-port", []string{}, "Sidecar port to map [e.g. --sidecar-port name=8126]")
+	serviceValidateCmd.Flags().StringArrayVar(&flagServiceValidateEFSVolumes, "efs", []string{}, "EFS file system to mount [e.g. --efs fs-12345678:/data[:fsap-0123456789abcdef0]]")
+	serviceValidateCmd.Flags().StringVar(&flagServiceValidatePlatformVersion, "platform-version", "", "Fargate platform version the service will run on [e.g. 1.4.0, LATEST]")
+	serviceValidateCmd.Flags().StringVar(&flagServiceValidateTaskRole, "task-role", "", "IAM role ARN for the task's containers to run as")
+
+	serviceCmd.AddCommand(serviceValidateCmd)
+}
+
+func validateService(operation *ServiceValidateOperation) {
+	input, errs := operation.TaskDefinitionInput()
+
+	if len(errs) > 0 {
+		output.Fatals(errs, "Invalid task definition for service %s", operation.ServiceName)
+		return
+	}
+
+	registerInput, err := input.RegisterTaskDefinitionInput()
+
+	if err != nil {
+		output.Fatal(err, "Invalid task definition for service %s", operation.ServiceName)
+		return
+	}
+
+	printJSON(registerInput)
+}
+
+//TaskDefinitionInput runs every task definition check against the operation
+//and returns the input it describes, along with every problem found
+func (o *ServiceValidateOperation) TaskDefinitionInput() (*ECS.CreateTaskDefinitionInput, []error) {
+	var errs []error
+
+	input := &ECS.CreateTaskDefinitionInput{
+		EnvVars:      processEnvVarArgs(o.EnvVars, ""),
+		Image:        o.Image,
+		Name:         o.ServiceName,
+		LogGroupName: fmt.Sprintf(serviceLogGroupFormat, o.ServiceName),
+		LogRegion:    region,
+		SecretVars:   processSecretVarArgs(o.SecretVars, ""),
+		TaskRole:     o.TaskRole,
+		Type:         typeService,
+	}
+
+	if cpu, err := normalizeCpu(o.Cpu); err != nil {
+		errs = append(errs, err)
+	} else if memory, err := normalizeMemory(o.Memory); err != nil {
+		errs = append(errs, err)
+	} else if err := validateCpuAndMemory(cpu, memory); err != nil {
+		errs = append(errs, err)
+	} else {
+		input.Cpu, input.Memory = cpu, memory
+	}
+
+	ports, portErrs := inflatePorts(o.Ports)
+	errs = append(errs, portErrs...)
+
+	if len(ports) > 1 {
+		errs = append(errs, fmt.Errorf("only one port can be given"))
+	}
+
+	for _, port := range ports {
+		if portErrs := validatePort(port); len(portErrs) > 0 {
+			errs = append(errs, portErrs...)
+		} else {
+			input.Port, input.PortProtocol = port.Number, port.Protocol
+		}
+	}
+
+	sidecars, err := parseSidecars(o.Sidecars, o.SidecarEnvVars, o.SidecarPorts)
+
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	input.Sidecars = sidecars
+
+	efsVolumes, err := parseEFSVolumes(o.EFSVolumes, false)
+
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, volume := range efsVolumes {
+		input.Volumes = append(input.Volumes, volume.Volume())
+		input.MountPoints = append(input.MountPoints, volume.MountPoint())
+	}
+
+	if len(efsVolumes) > 0 {
+		err = ECS.ValidateEFSPlatformVersion(o.PlatformVersion)
+	} else {
+		err = ECS.ValidatePlatformVersion(o.PlatformVersion)
+	}
+
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := input.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return input, errs
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestServiceValidateOperationTaskDefinitionInput(t *testing.T) {
+	operation := &ServiceValidateOperation{
+		ServiceName:     "web",
+		Image:           "web:1.0",
+		Cpu:             "512",
+		Memory:          "1024",
+		Ports:           []string{"udp:53"},
+		EnvVars:         []string{"FOO=bar"},
+		Sidecars:        []string{"datadog=datadog/agent:7"},
+		EFSVolumes:      []string{"fs-12345678:/data"},
+		PlatformVersion: "1.4.0",
+	}
+
+	input, errs := operation.TaskDefinitionInput()
+
+	if len(errs) > 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+
+	if input.Cpu != "512" || input.Memory != "1024" {
+		t.Errorf("expected 512 CPU units and 1024 MiB, got %s and %s", input.Cpu, input.Memory)
+	}
+
+	if input.Port != 53 || input.PortProtocol != "UDP" {
+		t.Errorf("expected port UDP:53, got %s:%d", input.PortProtocol, input.Port)
+	}
+
+	if input.Type != typeService || input.Name != "web" {
+		t.Errorf("expected service web, got %s %s", input.Type, input.Name)
+	}
+
+	if len(input.Sidecars) != 1 || len(input.Volumes) != 1 || len(input.MountPoints) != 1 {
+		t.Errorf("expected 1 sidecar, volume, and mount point, got %d, %d, and %d", len(input.Sidecars), len(input.Volumes), len(input.MountPoints))
+	}
+}
+
+func TestServiceValidateOperationTaskDefinitionInputInvalid(t *testing.T) {
+	operation := &ServiceValidateOperation{
+		ServiceName:     "web",
+		Cpu:             "256",
+		Memory:          "4096",
+		Ports:           []string{"sctp:80"},
+		EFSVolumes:      []string{"fs-12345678:/data"},
+		PlatformVersion: "1.3.0",
+	}
+
+	//cpu and memory, port protocol, platform version, and no image
+	_, errs := operation.TaskDefinitionInput()
+
+	if len(errs) != 4 {
+		t.Errorf("expected 4 errors, got %d: %v", len(errs), errs)
+	}
+}
+
+func TestServiceValidateCommandWithoutCredentials(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	t.Setenv("AWS_CONFIG_FILE", "/nonexistent")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/nonexistent")
+
+	stdout, err := ioutil.TempFile("", "service-validate")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.Remove(stdout.Name())
+
+	originalStdout := os.Stdout
+	os.Stdout = stdout
+	sess = nil
+
+	defer func() {
+		os.Stdout = originalStdout
+		rootCmd.SetArgs(nil)
+		rootCmd.PersistentFlags().Set(keyCluster, "")
+		serviceValidateCmd.Flags().Set("image", "")
+		serviceValidateCmd.Flags().Set("cpu", "256")
+		serviceValidateCmd.Flags().Set("memory", "512")
+	}()
+
+	//-c is --cluster, so it can be passed alongside --cpu
+	rootCmd.SetArgs([]string{"service", "validate", "web", "--image", "web:1.0", "--cpu", "0.5vcpu", "--memory", "1GB", "-c", "my-cluster"})
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	os.Stdout = originalStdout
+
+	if sess != nil {
+		t.Error("expected no AWS session to be created")
+	}
+
+	if cluster := rootCmd.PersistentFlags().Lookup(keyCluster).Value.String(); cluster != "my-cluster" {
+		t.Errorf("expected -c to set the cluster to my-cluster, got %s", cluster)
+	}
+
+	printed, err := ioutil.ReadFile(stdout.Name())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(printed), `"Cpu": "512"`) || !strings.Contains(string(printed), `"Memory": "1024"`) {
+		t.Errorf("expected the task definition with 512 CPU units and 1024 MiB, got %s", printed)
+	}
+}
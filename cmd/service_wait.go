@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/turnerlabs/fargate/console"
+	ECS "github.com/turnerlabs/fargate/ecs"
+	ELBV2 "github.com/turnerlabs/fargate/elbv2"
+	"github.com/spf13/cobra"
+)
+
+type ServiceWaitOperation struct {
+	MinHealthy  int64
+	ServiceName string
+}
+
+func (o *ServiceWaitOperation) Validate() error {
+	if o.MinHealthy < 0 {
+		return errors.New("--min-healthy must be 0 or greater")
+	}
+
+	return nil
+}
+
+var flagServiceWaitMinHealthy int64
+
+var serviceWaitCmd = &cobra.Command{
+	Use:   "wait",
+	Short: "Wait for a service to reach a steady state",
+	Long: `Wait for a service to reach a steady state
+
+Blocks until the service has a single deployment running its desired number of
+tasks with none pending, printing the deployment state, how many tasks are in
+each status, and target health as they change.
+
+ECS can consider a service stable before slow starting tasks pass their load
+balancer health checks. Pass --min-healthy to also require at least that many
+healthy targets in the service's target group.
+
+If the service doesn't settle within 10 minutes (or --timeout, if set), recent
+service events are printed and the command exits with a non-zero status.`,
+	Example: `
+fargate service wait
+fargate service wait --min-healthy 3
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		operation := &ServiceWaitOperation{
+			MinHealthy:  flagServiceWaitMinHealthy,
+			ServiceName: getServiceName(),
+		}
+
+		if err := operation.Validate(); err != nil {
+			console.ErrorExit(err, "Invalid command line flags")
+		}
+
+		waitForService(operation)
+	},
+}
+
+func init() {
+	serviceWaitCmd.Flags().Int64Var(&flagServiceWaitMinHealthy, "min-healthy", 0, "Minimum number of healthy load balancer targets to wait for")
+
+	serviceCmd.AddCommand(serviceWaitCmd)
+}
+
+func waitForService(operation *ServiceWaitOperation) {
+	ecs := ECS.New(sess, getClusterName())
+	elbv2 := ELBV2.New(sess)
+	ctx, cancel := waitContext()
+	lastProgress := ""
+
+	defer cancel()
+
+	console.Info("Waiting for service %s to reach a steady state...", operation.ServiceName)
+
+	for {
+		service := ecs.DescribeService(operation.ServiceName)
+		tasks := ecs.DescribeTasksForService(operation.ServiceName)
+		progress := deploymentProgress(service) + ", " + taskStatusProgress(tasks)
+		healthy := true
+
+		if operation.MinHealthy > 0 {
+			if service.TargetGroupArn == "" {
+				console.IssueExit("Service %s is not behind a load balancer, --min-healthy can't be used", operation.ServiceName)
+			}
+
+			targetHealths, err := elbv2.DescribeTargetHealth(service.TargetGroupArn)
+
+			if err != nil {
+				console.ErrorExit(err, "Could not describe ELB target health")
+			}
+
+			healthy = int64(targetHealths.Healthy()) >= operation.MinHealthy
+			progress += fmt.Sprintf(", Healthy targets: %d/%d", targetHealths.Healthy(), operation.MinHealthy)
+		}
+
+		if progress != lastProgress {
+			console.Info(progress)
+			lastProgress = progress
+		}
+
+		if serviceStable(service) && healthy {
+			console.Info("Service %s is stable.", operation.ServiceName)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			timeoutExpired()
+			console.Issue("Timed out after %s waiting for service %s to reach a steady state", waitTimeout(), operation.ServiceName)
+			printStuckTasks(tasks)
+			console.Header("Events")
+			printServiceEvents(service.Events)
+			printStoppedTaskReasons(ecs, operation.ServiceName)
+			console.Exit(1)
+		case <-time.After(waitPollInterval):
+		}
+	}
+}
+
+//serviceStable returns whether a service has a single deployment running all of its desired tasks.
+func serviceStable(service ECS.Service) bool {
+	return len(service.Deployments) == 1 &&
+		service.RunningCount == service.DesiredCount &&
+		service.PendingCount == 0
+}
+
+//taskLifecycle is the order tasks move through their last statuses.
+var taskLifecycle = []string{
+	"PROVISIONING", "PENDING", "ACTIVATING", "RUNNING",
+	"DEACTIVATING", "STOPPING", "DEPROVISIONING", "STOPPED",
+}
+
+//taskStatusProgress counts a service's tasks in each last status, in lifecycle order.
+func taskStatusProgress(tasks []ECS.Task) string {
+	var statuses []string
+
+	counts := make(map[string]int)
+
+	for _, task := range tasks {
+		if counts[task.LastStatus] == 0 {
+			statuses = append(statuses, task.LastStatus)
+		}
+
+		counts[task.LastStatus]++
+	}
+
+	if len(statuses) == 0 {
+		return "Tasks: none"
+	}
+
+	order := func(status string) int {
+		for i, s := range taskLifecycle {
+			if s == status {
+				return i
+			}
+		}
+
+		return len(taskLifecycle)
+	}
+
+	sort.SliceStable(statuses, func(i, j int) bool {
+		return order(statuses[i]) < order(statuses[j])
+	})
+
+	parts := make([]string, len(statuses))
+
+	for i, status := range statuses {
+		parts[i] = fmt.Sprintf("%d %s", counts[status], Humanize(status))
+	}
+
+	return "Tasks: " + strings.Join(parts, ", ")
+}
+
+//printStuckTasks reports tasks that never got past provisioning or pending.
+func printStuckTasks(tasks []ECS.Task) {
+	stuck := 0
+
+	for _, task := range tasks {
+		if task.LastStatus == "PROVISIONING" || task.LastStatus == "PENDING" {
+			stuck++
+		}
+	}
+
+	if stuck > 0 {
+		console.Issue("%d tasks are still provisioning or pending", stuck)
+	}
+}
+
+//deploymentProgress summarizes the state of each of a service's deployments.
+func deploymentProgress(service ECS.Service) string {
+	progress := fmt.Sprintf("Deployments: %d", len(service.Deployments))
+
+	for _, d := range service.Deployments {
+		progress += fmt.Sprintf(", %s %s: %d/%d running, %d pending",
+			Humanize(d.Status), d.Id, d.RunningCount, d.DesiredCount, d.PendingCount)
+	}
+
+	return progress
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"testing"
+
+	ECS "github.com/turnerlabs/fargate/ecs"
+)
+
+func TestServiceStable(t *testing.T) {
+	primary := ECS.Deployment{Id: "5", Status: "PRIMARY", DesiredCount: 2, RunningCount: 2}
+	active := ECS.Deployment{Id: "4", Status: "ACTIVE", DesiredCount: 0, RunningCount: 1}
+
+	var tests = []struct {
+		name    string
+		service ECS.Service
+		want    bool
+	}{
+		{"stable", ECS.Service{DesiredCount: 2, RunningCount: 2, Deployments: []ECS.Deployment{primary}}, true},
+		{"rolling out", ECS.Service{DesiredCount: 2, RunningCount: 3, Deployments: []ECS.Deployment{primary, active}}, false},
+		{"pending", ECS.Service{DesiredCount: 2, RunningCount: 1, PendingCount: 1, Deployments: []ECS.Deployment{primary}}, false},
+		{"no deployments", ECS.Service{}, false},
+	}
+
+	for _, test := range tests {
+		if got := serviceStable(test.service); got != test.want {
+			t.Errorf("%s: expected %t, got %t", test.name, test.want, got)
+		}
+	}
+}
+
+func TestDeploymentProgress(t *testing.T) {
+	service := ECS.Service{
+		Deployments: []ECS.Deployment{
+			{Id: "5", Status: "PRIMARY", DesiredCount: 2, RunningCount: 1, PendingCount: 1},
+			{Id: "4", Status: "ACTIVE", DesiredCount: 0, RunningCount: 2},
+		},
+	}
+
+	expected := "Deployments: 2, primary 5: 1/2 running, 1 pending, active 4: 2/0 running, 0 pending"
+
+	if progress := deploymentProgress(service); progress != expected {
+		t.Errorf("expected %q, got %q", expected, progress)
+	}
+}
+
+func TestServiceWaitOperationValidate(t *testing.T) {
+	if err := (&ServiceWaitOperation{MinHealthy: -1}).Validate(); err == nil {
+		t.Error("expected an error for a negative --min-healthy")
+	}
+
+	if err := (&ServiceWaitOperation{MinHealthy: 3}).Validate(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestTaskStatusProgress(t *testing.T) {
+	tasks := []ECS.Task{
+		{LastStatus: "RUNNING"},
+		{LastStatus: "PENDING"},
+		{LastStatus: "DEACTIVATING"},
+		{LastStatus: "RUNNING"},
+		{LastStatus: "PROVISIONING"},
+	}
+
+	expected := "Tasks: 1 provisioning, 1 pending, 2 running, 1 deactivating"
+
+	if got := taskStatusProgress(tasks); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	if got := taskStatusProgress(nil); got != "Tasks: none" {
+		t.Errorf("expected no tasks, got %q", got)
+	}
+}
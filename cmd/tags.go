@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	ECS "github.com/turnerlabs/fargate/ecs"
+)
+
+//parseTags parses --tag Key=Value flags, adding managedBy=fargate to mark the
+//resource as created by this tool unless managedBy is given
+func parseTags(args []string) (map[string]string, error) {
+	tags := map[string]string{ECS.ManagedByTagKey: ECS.ManagedByTagValue}
+
+	for _, arg := range args {
+		parts := strings.SplitN(arg, "=", 2)
+
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid tag %s [expected Key=Value]", arg)
+		}
+
+		tags[strings.TrimSpace(parts[0])] = parts[1]
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTags(t *testing.T) {
+	tags, err := parseTags([]string{"team=web", "cost-center=1234"})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := map[string]string{"managedBy": "fargate", "team": "web", "cost-center": "1234"}
+
+	if !reflect.DeepEqual(tags, expected) {
+		t.Errorf("expected %v, got %v", expected, tags)
+	}
+
+	if tags, _ := parseTags([]string{"managedBy=terraform"}); tags["managedBy"] != "terraform" {
+		t.Errorf("expected managedBy to be overridden, got %v", tags)
+	}
+
+	for _, arg := range []string{"team", "=web"} {
+		if _, err := parseTags([]string{arg}); err == nil {
+			t.Errorf("expected %q to be invalid", arg)
+		}
+	}
+}
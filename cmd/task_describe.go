@@ -34,15 +34,15 @@ func describe(cmd *cobra.Command, args []string) {
 
 	//lookup latest/active task definition from family
 	td := ecs.DescribeTaskDefinition(getTaskName()).TaskDefinition
-	if len(td.ContainerDefinitions) == 0 {
+	container := ECS.PrimaryContainerDefinition(td, "")
+	if container == nil {
 		console.IssueExit("No container found in task definition")
 	}
-	container := td.ContainerDefinitions[0]
 
 	//initialize a new compose file
 	composeFile := dockercompose.New("")
 
-	//add service for the 1st container
+	//add service for the primary container
 	service := composeFile.AddService(*container.Name)
 	service.Image = *container.Image
 
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+	"github.com/turnerlabs/fargate/console"
+	ECS "github.com/turnerlabs/fargate/ecs"
+)
+
+const sessionManagerPlugin = "session-manager-plugin"
+
+type TaskExecOperation struct {
+	TaskID        string
+	ContainerName string
+	Command       string
+}
+
+var (
+	flagTaskExecCommand   string
+	flagTaskExecContainer string
+)
+
+var taskExecCmd = &cobra.Command{
+	Use:   "exec <task-id> [--command <command>] [--container <container-name>]",
+	Short: "Run a command in a running task",
+	Long: `Run a command in a running task
+
+Opens an interactive session in a running task's container with ECS Exec, e.g.
+to debug a migration or maintenance job. The command defaults to /bin/sh. Pass
+--container to choose the container in tasks with more than one.
+
+The task must have been started with execute command enabled, its task role
+must allow the ssmmessages actions ECS Exec uses (fargate service update
+--enable-execute-command sets up both for a service, and fargate task run
+--enable-execute-command starts one-off tasks with it enabled), and the
+Session Manager plugin for the AWS CLI must be installed. Control-C is sent to
+the command rather than ending the session, and fargate exits with the
+session's exit code.`,
+	Example: `
+fargate task exec 0123456789abcdef0123456789abcdef
+fargate task exec 0123456789abcdef0123456789abcdef --command "bin/rails console"
+fargate task exec 0123456789abcdef0123456789abcdef --container app --command "cat /etc/hosts"
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		operation := &TaskExecOperation{
+			TaskID:        args[0],
+			ContainerName: flagTaskExecContainer,
+			Command:       flagTaskExecCommand,
+		}
+
+		execTask(operation)
+	},
+}
+
+func init() {
+	taskExecCmd.Flags().StringVar(&flagTaskExecCommand, "command", "/bin/sh", "Command to run")
+	taskExecCmd.Flags().StringVar(&flagTaskExecContainer, "container", "", "Container to run the command in (defaults to the task's only container)")
+
+	taskCmd.AddCommand(taskExecCmd)
+}
+
+func execTask(operation *TaskExecOperation) {
+	plugin, err := exec.LookPath(sessionManagerPlugin)
+
+	if err != nil {
+		console.IssueExit("Could not find %s, which is required to run commands in tasks. See https://docs.aws.amazon.com/systems-manager/latest/userguide/session-manager-working-with-install-plugin.html", sessionManagerPlugin)
+	}
+
+	ecs := ECS.New(sess, getClusterName())
+	session, err := ecs.ExecuteCommand(operation.TaskID, operation.ContainerName, operation.Command)
+
+	if err != nil {
+		console.ErrorExit(err, "Could not run command in task %s", operation.TaskID)
+	}
+
+	args, err := sessionManagerPluginArgs(session)
+
+	if err != nil {
+		console.ErrorExit(err, "Could not start session in task %s", operation.TaskID)
+	}
+
+	cmd := exec.CommandContext(commandContext, plugin, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	//Control-C is for the remote command, the plugin forwards it
+	signal.Ignore(os.Interrupt)
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+		}
+
+		console.ErrorExit(err, "Could not start session in task %s", operation.TaskID)
+	}
+}
+
+//sessionManagerPluginArgs returns the arguments the Session Manager plugin
+//takes to attach to a session, the same as the AWS CLI passes it
+func sessionManagerPluginArgs(session *ECS.ExecSession) ([]string, error) {
+	sessionJSON, err := json.Marshal(session.Session)
+
+	if err != nil {
+		return nil, err
+	}
+
+	targetJSON, err := json.Marshal(map[string]string{"Target": session.Target})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{string(sessionJSON), session.Region, "StartSession", "", string(targetJSON), session.Endpoint}, nil
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsecs "github.com/aws/aws-sdk-go/service/ecs"
+	ECS "github.com/turnerlabs/fargate/ecs"
+)
+
+func TestSessionManagerPluginArgs(t *testing.T) {
+	session := &ECS.ExecSession{
+		Session: &awsecs.Session{
+			SessionId:  aws.String("ecs-execute-command-0123"),
+			StreamUrl:  aws.String("wss://ssmmessages.us-east-1.amazonaws.com/v1/data-channel/ecs-execute-command-0123"),
+			TokenValue: aws.String("token"),
+		},
+		Endpoint: "https://ecs.us-east-1.amazonaws.com",
+		Region:   "us-east-1",
+		Target:   "ecs:my-cluster_0123456789abcdef_abc-123",
+	}
+
+	args, err := sessionManagerPluginArgs(session)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := []string{
+		`{"SessionId":"ecs-execute-command-0123","StreamUrl":"wss://ssmmessages.us-east-1.amazonaws.com/v1/data-channel/ecs-execute-command-0123","TokenValue":"token"}`,
+		"us-east-1",
+		"StartSession",
+		"",
+		`{"Target":"ecs:my-cluster_0123456789abcdef_abc-123"}`,
+		"https://ecs.us-east-1.amazonaws.com",
+	}
+
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected %v, got %v", expected, args)
+	}
+}
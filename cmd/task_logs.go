@@ -8,6 +8,8 @@ import (
 
 var (
 	flagTaskLogsFilter            string
+	flagTaskLogsGrep              string
+	flagTaskLogsInvert            bool
 	flagTaskLogsEndTime           string
 	flagTaskLogsStartTime         string
 	flagTaskLogsFollow            bool
@@ -47,11 +49,16 @@ timestamp:
 		thirty seconds ago], 2h [two hours from now])
 	- Timestamp with optional timezone in the format of YYYY-MM-DD HH:MM:SS [TZ];
 		timezone will default to UTC if omitted (e.g. 2017-12-22 15:10:03 EST)
+	- RFC3339 timestamp (e.g. 2017-12-22T15:10:03-05:00)
 
 You can filter logs for specific term by passing a filter expression via the
 --filter flag. Pass a single term to search for that term, pass multiple terms
 to search for log messages that include all terms.
 
+--grep searches for log messages containing an exact phrase and highlights it
+in the output. Add --invert to instead exclude messages containing the phrase.
+--grep cannot be combined with --filter.
+
 --time includes the log timestamp in the output
 
 --no-prefix excludes the log stream prefix from the output
@@ -68,6 +75,8 @@ fargate task logs --task my-task-dev --container-name my-container
 		operation := &GetLogsOperation{
 			LogGroupName:      fmt.Sprintf(taskLogGroupFormat, getTaskName()),
 			Filter:            flagTaskLogsFilter,
+			Grep:              flagTaskLogsGrep,
+			Invert:            flagTaskLogsInvert,
 			Follow:            flagTaskLogsFollow,
 			Namespace:         flagTaskLogsContainerName,
 			IncludeTime:       flagTaskLogsTime,
@@ -77,6 +86,7 @@ fargate task logs --task my-task-dev --container-name my-container
 		operation.AddTasks(flagTaskLogsTasks)
 		operation.AddStartTime(flagTaskLogsStartTime)
 		operation.AddEndTime(flagTaskLogsEndTime)
+		operation.Validate()
 
 		GetLogs(operation)
 	},
@@ -87,6 +97,8 @@ func init() {
 
 	taskLogsCmd.Flags().BoolVarP(&flagTaskLogsFollow, "follow", "f", false, "Poll logs and continuously print new events")
 	taskLogsCmd.Flags().StringVar(&flagTaskLogsFilter, "filter", "", "Filter pattern to apply")
+	taskLogsCmd.Flags().StringVar(&flagTaskLogsGrep, "grep", "", "Only show log messages containing a phrase, highlighting it")
+	taskLogsCmd.Flags().BoolVar(&flagTaskLogsInvert, "invert", false, "With --grep, exclude log messages containing the phrase")
 	taskLogsCmd.Flags().StringVar(&flagTaskLogsStartTime, "start", "", "Earliest time to return logs (e.g. -1h, 2018-01-01 09:36:00 EST")
 	taskLogsCmd.Flags().StringVar(&flagTaskLogsEndTime, "end", "", "Latest time to return logs (e.g. 3y, 2021-01-20 12:00:00 EST")
 	taskLogsCmd.Flags().StringSliceVarP(&flagTaskLogsTasks, "task", "t", []string{}, "Show logs from specific task (can be specified multiple times)")
@@ -2,17 +2,38 @@ package cmd
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/spf13/cobra"
+	ARN "github.com/turnerlabs/fargate/arn"
+	"github.com/turnerlabs/fargate/console"
 	ECS "github.com/turnerlabs/fargate/ecs"
+	IAM "github.com/turnerlabs/fargate/iam"
+	"github.com/turnerlabs/fargate/sts"
 )
 
+var s3EnvFilePattern = regexp.MustCompile(`^s3://([a-z0-9][a-z0-9.-]{1,61}[a-z0-9])/(.+\.env)$`)
+
 var flagTaskRegisterImage string
 var flagTaskRegisterDockerComposeFile string
 var flagTaskRegisterEnvVars []string
 var flagTaskRegisterEnvFile string
 var flagTaskRegisterSecretVars []string
 var flagTaskRegisterSecretFile string
+var flagTaskRegisterEnvS3Files []string
+var flagTaskRegisterWarnOverrides bool
+var flagTaskRegisterSidecars []string
+var flagTaskRegisterSidecarEnvVars []string
+var flagTaskRegisterSidecarPorts []string
+var flagTaskRegisterTags []string
+var flagTaskRegisterEFSVolumes []string
+var flagTaskRegisterEFSIAM bool
+var flagTaskRegisterRepositoryCredentials string
+var flagTaskRegisterTaskRole string
+var flagTaskRegisterPidMode string
 
 //represents a task register operation
 type taskRegisterOperation struct {
@@ -24,6 +45,24 @@ type taskRegisterOperation struct {
 	ComposeFile string
 	SecretVars  []string
 	SecretFile  string
+	EnvS3Files  []string
+
+	WarnOverrides bool
+
+	Sidecars       []string
+	SidecarEnvVars []string
+	SidecarPorts   []string
+
+	Tags []string
+
+	EFSVolumes []string
+	EFSIAM     bool
+
+	RepositoryCredentials string
+
+	TaskRole string
+
+	PidMode string
 }
 
 var taskRegisterCmd = &cobra.Command{
@@ -40,6 +79,24 @@ var taskRegisterCmd = &cobra.Command{
 			ComposeFile: flagTaskRegisterDockerComposeFile,
 			SecretVars:  flagTaskRegisterSecretVars,
 			SecretFile:  flagTaskRegisterSecretFile,
+			EnvS3Files:  flagTaskRegisterEnvS3Files,
+
+			WarnOverrides: flagTaskRegisterWarnOverrides,
+
+			Sidecars:       flagTaskRegisterSidecars,
+			SidecarEnvVars: flagTaskRegisterSidecarEnvVars,
+			SidecarPorts:   flagTaskRegisterSidecarPorts,
+
+			Tags: flagTaskRegisterTags,
+
+			EFSVolumes: flagTaskRegisterEFSVolumes,
+			EFSIAM:     flagTaskRegisterEFSIAM,
+
+			RepositoryCredentials: flagTaskRegisterRepositoryCredentials,
+
+			TaskRole: flagTaskRegisterTaskRole,
+
+			PidMode: flagTaskRegisterPidMode,
 		}
 
 		//valid cli arg combinations
@@ -47,10 +104,15 @@ var taskRegisterCmd = &cobra.Command{
 			len(flagTaskRegisterEnvVars) > 0 ||
 			flagTaskRegisterEnvFile != "" ||
 			len(flagTaskRegisterSecretVars) > 0 ||
-			flagTaskRegisterSecretFile != "")
+			flagTaskRegisterSecretFile != "" ||
+			len(flagTaskRegisterEnvS3Files) > 0 ||
+			len(flagTaskRegisterSidecars) > 0 ||
+			len(flagTaskRegisterEFSVolumes) > 0 ||
+			flagTaskRegisterRepositoryCredentials != "")
 
+		//a task role and pid mode can be set on their own or along with a compose file
 		if (flagTaskRegisterDockerComposeFile != "" && nonComposeOptions) ||
-			(flagTaskRegisterDockerComposeFile == "" && !nonComposeOptions) {
+			(flagTaskRegisterDockerComposeFile == "" && !nonComposeOptions && flagTaskRegisterTaskRole == "" && flagTaskRegisterPidMode == "") {
 			cmd.Help()
 			return
 		}
@@ -63,8 +125,68 @@ fargate task register --image 123456789.dkr.ecr.us-east-1.amazonaws.com/my-app:0
 fargate task register --image 123456789.dkr.ecr.us-east-1.amazonaws.com/my-app:0.1.0 --env FOO=bar --secret BAZ=qux
 fargate task register --env-file dev.env
 fargate task register --secret-file secrets.env
+fargate task register --secret DB_PASSWORD=/my-app/db-password
+fargate task register --env-s3 s3://my-bucket/app.env --env LOG_LEVEL=debug
+fargate task register --sidecar datadog=public.ecr.aws/datadog/agent:7 --sidecar-env datadog:DD_SITE=datadoghq.com --sidecar-port datadog=8126
+fargate task register --efs fs-12345678:/data --efs fs-87654321:/shared:fsap-0123456789abcdef0 --efs-iam
+fargate task register --image registry.example.com/my-app:0.1.0 --repository-credentials arn:aws:secretsmanager:us-east-1:123456789012:secret:registry-AbCdEf
 fargate task register --file docker-compose.yml
+fargate task register --task-role my-app-task
+fargate task register --sidecar datadog=public.ecr.aws/datadog/agent:7 --pid-mode task
+fargate task register --image 123456789.dkr.ecr.us-east-1.amazonaws.com/my-app:0.1.0 --tag team=web --tag cost-center=1234
 `,
+	Long: `Registers a new task definition revision for the specified docker image or environment variables based on the latest revision of the task family and returns the new revision number.
+
+--env-s3 loads environment variables from a .env file in S3 when the task
+starts, which suits large or shared configuration managed outside of fargate.
+The task's execution role is granted s3:GetObject on the file.
+
+--secret and --secret-file values are SSM parameter names or ARNs, or Secrets
+Manager secret ARNs. Parameter names are resolved to ARNs in the current
+region and account. The task's execution role is granted read access to each
+secret.
+
+When the same variable is set more than once, --env and --secret take
+precedence over --env-file and --secret-file, which take precedence over S3
+environment files. Pass --warn-overrides to list each variable that was
+overridden and by which source (S3 environment files are read by ECS when the
+task starts, so overrides of their variables aren't listed).
+
+--sidecar adds a container to run alongside the task's container, such as a
+log router, proxy, or metrics agent, or replaces the container with the same
+name. Set a sidecar's environment variables with --sidecar-env name:KEY=value
+and its port with --sidecar-port name=port. Sidecars aren't essential, so the
+task keeps running if one stops, and they log to the same place as the task's
+container.
+
+--tag adds a tag to the new revision, along with managedBy=fargate unless a
+managedBy tag is given. Later revisions registered by fargate keep the tags.
+
+--efs mounts an EFS file system into the task's container, given as
+fsid:/container/path, optionally followed by :accesspointid to mount through
+an access point. Pass --efs-iam to authorize mounts with the task's role,
+which is granted elasticfilesystem:ClientMount and ClientWrite on each file
+system. Encryption in transit is enabled for access points and IAM
+authorization. The file system's security group must allow NFS traffic (TCP
+port 2049) from the task.
+
+--repository-credentials pulls the task's image from a private registry, such
+as Docker Hub or GitHub Container Registry, with the username and password in
+a Secrets Manager secret, given by ARN. The secret must be a JSON object of
+the form {"username":"<user>","password":"<password or token>"}. The task's
+execution role is granted read access to the secret.
+
+--task-role sets the IAM role the task's containers run as, by name or ARN,
+so the application can call AWS APIs such as S3 or DynamoDB without
+credentials baked into the image. The execution role, which ECS uses to pull
+the image and fetch secrets, is unchanged. The role's trust policy must allow
+ecs-tasks.amazonaws.com to assume it. --task-role can be used on its own or
+with --file.
+
+--pid-mode task shares a process namespace between the task's containers, so
+a sidecar such as an APM or security agent can see the processes of the
+task's container. task is the only PID mode Fargate supports. --pid-mode can
+be used on its own or with --file.`,
 }
 
 func init() {
@@ -80,6 +202,28 @@ func init() {
 
 	taskRegisterCmd.Flags().StringVar(&flagTaskRegisterSecretFile, "secret-file", "", "File containing list of secret variables to set, one per line, of the form KEY=valueFrom")
 
+	taskRegisterCmd.Flags().StringArrayVar(&flagTaskRegisterEnvS3Files, "env-s3", []string{}, "S3 environment file to load variables from [e.g. --env-s3 s3://bucket/app.env]")
+
+	taskRegisterCmd.Flags().BoolVar(&flagTaskRegisterWarnOverrides, "warn-overrides", false, "Warn about variables set by more than one source")
+
+	taskRegisterCmd.Flags().StringArrayVar(&flagTaskRegisterSidecars, "sidecar", []string{}, "Sidecar container to add [e.g. --sidecar name=image:tag]")
+
+	taskRegisterCmd.Flags().StringArrayVar(&flagTaskRegisterSidecarEnvVars, "sidecar-env", []string{}, "Sidecar environment variables to set [e.g. --sidecar-env name:KEY=value]")
+
+	taskRegisterCmd.Flags().StringArrayVar(&flagTaskRegisterSidecarPorts, "sidecar-port", []string{}, "Sidecar port to map [e.g. --sidecar-port name=8126]")
+
+	taskRegisterCmd.Flags().StringArrayVar(&flagTaskRegisterTags, "tag", []string{}, "Tag to add to the new revision [e.g. --tag team=web]")
+
+	taskRegisterCmd.Flags().StringArrayVar(&flagTaskRegisterEFSVolumes, "efs", []string{}, "EFS file system to mount [e.g. --efs fs-12345678:/data[:fsap-0123456789abcdef0]]")
+
+	taskRegisterCmd.Flags().BoolVar(&flagTaskRegisterEFSIAM, "efs-iam", false, "Authorize EFS mounts with the task's role")
+
+	taskRegisterCmd.Flags().StringVar(&flagTaskRegisterRepositoryCredentials, "repository-credentials", "", "Secrets Manager secret ARN with credentials for a private registry")
+
+	taskRegisterCmd.Flags().StringVar(&flagTaskRegisterTaskRole, "task-role", "", "IAM role (name or ARN) for the task's containers to run as")
+
+	taskRegisterCmd.Flags().StringVar(&flagTaskRegisterPidMode, "pid-mode", "", "Process namespace for the task's containers to share [Fargate supports: task]")
+
 	taskCmd.AddCommand(taskRegisterCmd)
 }
 
@@ -89,8 +233,20 @@ func registerTask(op taskRegisterOperation) {
 	image := op.Image
 	var envvars []ECS.EnvVar
 	var secrets []ECS.Secret
+	var envFiles []string
+	var sidecars []ECS.Sidecar
+	var efsVolumes []ECS.EFSVolume
 	replaceVars := false
 
+	tags, err := parseTags(op.Tags)
+	if err != nil {
+		console.ErrorExit(err, "Invalid command line flags")
+	}
+
+	if err := ECS.ValidatePidMode(op.PidMode); err != nil {
+		console.ErrorExit(err, "Invalid command line flags")
+	}
+
 	if op.ComposeFile != "" {
 		dockerService := getDockerServiceFromComposeFile(op.ComposeFile)
 		image = dockerService.Image
@@ -99,20 +255,246 @@ func registerTask(op taskRegisterOperation) {
 		replaceVars = true
 
 	} else {
-		//read env file (if specified) and combine with other envvars
-		envvars = processEnvVarArgs(op.EnvVars, op.EnvFile)
+		//command line vars take precedence over those in env and secret files
+		var sources envVarSources
+		var overrides []envVarOverride
+
+		sources.Add(envSourceCommandLine, processEnvVarArgs(op.EnvVars, ""), processSecretVarArgs(op.SecretVars, ""))
+		sources.Add(envSourceFile, processEnvVarArgs(nil, op.EnvFile), processSecretVarArgs(nil, op.SecretFile))
+
+		envvars, secrets, overrides = sources.Merge()
+		secrets = resolveSecrets(secrets)
+
+		if op.WarnOverrides {
+			printEnvVarOverrides(overrides)
+		}
 
-		//read secrets file (if specified) and combine with other secret vars
-		secrets = processSecretVarArgs(op.SecretVars, op.SecretFile)
+		//convert s3 uris to object arns
+		for _, uri := range op.EnvS3Files {
+			objectArn, err := parseS3EnvFile(uri)
+			if err != nil {
+				console.ErrorExit(err, "Invalid command line flags")
+			}
+
+			envFiles = append(envFiles, objectArn)
+		}
+
+		sidecars, err = parseSidecars(op.Sidecars, op.SidecarEnvVars, op.SidecarPorts)
+		if err != nil {
+			console.ErrorExit(err, "Invalid command line flags")
+		}
+
+		efsVolumes, err = parseEFSVolumes(op.EFSVolumes, op.EFSIAM)
+		if err != nil {
+			console.ErrorExit(err, "Invalid command line flags")
+		}
 
 		//don't replace, just add, update where exists
 		replaceVars = false
 	}
 
-	//update and register new task definition
 	ecs := ECS.New(sess, op.Cluster)
-	newTD := ecs.UpdateTaskDefinitionImageAndEnvVars(op.Task, image, envvars, replaceVars, secrets)
+
+	var taskRoleArn string
+
+	if op.TaskRole != "" {
+		taskRoleArn = resolveTaskRole(op.TaskRole)
+	}
+
+	//the execution role fetches environment files, so it needs to be able to read them
+	if len(envFiles) > 0 {
+		dtd := ecs.DescribeTaskDefinition(op.Task)
+		executionRoleArn := aws.StringValue(dtd.TaskDefinition.ExecutionRoleArn)
+
+		if executionRoleArn == "" {
+			console.IssueExit("Task definition %s has no execution role, which is required to load S3 environment files", op.Task)
+		}
+
+		if err := IAM.New(sess).GrantS3GetObject(executionRoleArn, envFiles); err != nil {
+			console.ErrorExit(err, "Could not grant execution role %s access to S3 environment files", IAM.RoleName(executionRoleArn))
+		}
+	}
+
+	//the execution role fetches secrets, so it needs to be able to read them
+	if op.ComposeFile == "" {
+		grantSecretsRead(ecs, op.Task, secrets)
+	}
+
+	//the execution role pulls the image, so it needs to read the registry credentials
+	if op.RepositoryCredentials != "" {
+		if err := ECS.ValidateRepositoryCredentials(op.RepositoryCredentials); err != nil {
+			console.ErrorExit(err, "Invalid command line flags")
+		}
+
+		grantSecretsRead(ecs, op.Task, []ECS.Secret{{Key: "repositoryCredentials", ValueFrom: op.RepositoryCredentials}})
+	}
+
+	//the task role authorizes EFS mounts, so it needs to be able to mount them
+	if op.EFSIAM {
+		grantEFSClientAccess(ecs, op.Task, taskRoleArn, efsVolumes)
+	}
+
+	//update and register new task definition
+	newTD := ecs.UpdateTaskDefinitionImageAndEnvVars(op.Task, image, envvars, replaceVars,
+		ECS.TaskDefinitionUpdate{
+			Secrets:               secrets,
+			EnvironmentFiles:      envFiles,
+			Sidecars:              sidecars,
+			EFSVolumes:            efsVolumes,
+			RepositoryCredentials: op.RepositoryCredentials,
+			TaskRoleArn:           taskRoleArn,
+			PidMode:               op.PidMode,
+		},
+	)
+
+	if len(op.Tags) > 0 {
+		if err := ecs.TagResource(newTD, tags); err != nil {
+			console.ErrorExit(err, "Could not tag ECS task definition")
+		}
+	}
 
 	//output new revision
 	fmt.Println(ecs.GetRevisionNumber(newTD))
 }
+
+//parseS3EnvFile validates an s3://bucket/key.env uri and returns the object's arn
+func parseS3EnvFile(uri string) (string, error) {
+	matches := s3EnvFilePattern.FindStringSubmatch(uri)
+
+	if matches == nil {
+		return "", fmt.Errorf("invalid S3 environment file %s [expected s3://bucket/path/file.env]", uri)
+	}
+
+	return fmt.Sprintf("arn:aws:s3:::%s/%s", matches[1], matches[2]), nil
+}
+
+//parseSidecars parses --sidecar name=image, --sidecar-env name:KEY=value, and
+//--sidecar-port name=port flags into sidecars, in the order they were given
+func parseSidecars(sidecarArgs, envVarArgs, portArgs []string) ([]ECS.Sidecar, error) {
+	var sidecars []ECS.Sidecar
+
+	index := make(map[string]int)
+
+	for _, arg := range sidecarArgs {
+		parts := strings.SplitN(arg, "=", 2)
+
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid sidecar %s [expected name=image]", arg)
+		}
+
+		if _, ok := index[parts[0]]; ok {
+			return nil, fmt.Errorf("sidecar %s is specified more than once", parts[0])
+		}
+
+		index[parts[0]] = len(sidecars)
+		sidecars = append(sidecars, ECS.Sidecar{Name: parts[0], Image: parts[1]})
+	}
+
+	for _, arg := range envVarArgs {
+		parts := strings.SplitN(arg, ":", 2)
+		i, ok := index[parts[0]]
+
+		if len(parts) != 2 || !ok {
+			return nil, fmt.Errorf("invalid sidecar environment variable %s [expected name:KEY=value for a --sidecar name]", arg)
+		}
+
+		sidecars[i].EnvVars = append(sidecars[i].EnvVars, extractEnvVars([]string{parts[1]})...)
+	}
+
+	for _, arg := range portArgs {
+		parts := strings.SplitN(arg, "=", 2)
+		i, ok := index[parts[0]]
+
+		if len(parts) != 2 || !ok {
+			return nil, fmt.Errorf("invalid sidecar port %s [expected name=port for a --sidecar name]", arg)
+		}
+
+		port, err := strconv.ParseInt(parts[1], 10, 64)
+
+		if err != nil || port < 1 || port > 65535 {
+			return nil, fmt.Errorf("invalid sidecar port %s [expected a port number]", arg)
+		}
+
+		sidecars[i].Port = port
+	}
+
+	for _, sidecar := range sidecars {
+		if err := sidecar.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return sidecars, nil
+}
+
+//parseEFSVolumes parses --efs fsid:/container/path[:accesspointid] flags
+func parseEFSVolumes(args []string, iam bool) ([]ECS.EFSVolume, error) {
+	var volumes []ECS.EFSVolume
+
+	if iam && len(args) == 0 {
+		return nil, fmt.Errorf("--efs-iam requires --efs")
+	}
+
+	for _, arg := range args {
+		volume, err := ECS.ParseEFSVolume(arg)
+
+		if err != nil {
+			return nil, err
+		}
+
+		volume.IAM = iam
+		volumes = append(volumes, volume)
+	}
+
+	return volumes, nil
+}
+
+//resolveTaskRole returns the ARN of a task role given by name or ARN, exiting
+//if ECS tasks can't assume it
+func resolveTaskRole(nameOrArn string) string {
+	iam := IAM.New(sess)
+	roleArn, err := iam.RoleArn(nameOrArn)
+
+	if err != nil {
+		console.ErrorExit(err, "Could not find IAM role %s", nameOrArn)
+	}
+
+	ok, err := iam.CanBeAssumedByECSTasks(roleArn)
+
+	if err != nil {
+		console.ErrorExit(err, "Could not check trust policy of IAM role %s", IAM.RoleName(roleArn))
+	}
+
+	if !ok {
+		console.IssueExit("IAM role %s can't be used as a task role, its trust policy must allow %s to assume it", IAM.RoleName(roleArn), IAM.ECSTasksPrincipal)
+	}
+
+	return roleArn
+}
+
+//grantEFSClientAccess allows a task's role, which ECS uses to authorize EFS
+//mounts, to mount and write to the given file systems. The task definition's
+//role is used unless taskRoleArn is given.
+func grantEFSClientAccess(ecs ECS.ECS, taskDefinition, taskRoleArn string, volumes []ECS.EFSVolume) {
+	if taskRoleArn == "" {
+		dtd := ecs.DescribeTaskDefinition(taskDefinition)
+		taskRoleArn = aws.StringValue(dtd.TaskDefinition.TaskRoleArn)
+	}
+
+	if taskRoleArn == "" {
+		console.IssueExit("Task definition %s has no task role, which is required to mount EFS file systems with IAM authorization", taskDefinition)
+	}
+
+	sts := sts.New(sess)
+	account := sts.GetCallerIdentity().Account
+
+	var fileSystemArns []string
+
+	for _, volume := range volumes {
+		fileSystemArns = append(fileSystemArns, volume.FileSystemArn(ARN.Partition(region), region, account))
+	}
+
+	if err := IAM.New(sess).GrantEFSClientAccess(taskRoleArn, fileSystemArns); err != nil {
+		console.ErrorExit(err, "Could not grant task role %s access to EFS file systems", IAM.RoleName(taskRoleArn))
+	}
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	ECS "github.com/turnerlabs/fargate/ecs"
+)
+
+func TestParseS3EnvFile(t *testing.T) {
+	var tests = []struct {
+		uri   string
+		arn   string
+		valid bool
+	}{
+		{"s3://my-bucket/app.env", "arn:aws:s3:::my-bucket/app.env", true},
+		{"s3://my.bucket/config/prod/app.env", "arn:aws:s3:::my.bucket/config/prod/app.env", true},
+		{"s3://my-bucket/app.txt", "", false},
+		{"s3://my-bucket/", "", false},
+		{"s3://My_Bucket/app.env", "", false},
+		{"https://my-bucket.s3.amazonaws.com/app.env", "", false},
+		{"my-bucket/app.env", "", false},
+	}
+
+	for _, test := range tests {
+		arn, err := parseS3EnvFile(test.uri)
+
+		if test.valid && err != nil {
+			t.Errorf("expected %s to be valid, got %v", test.uri, err)
+		}
+
+		if !test.valid && err == nil {
+			t.Errorf("expected %s to be invalid", test.uri)
+		}
+
+		if arn != test.arn {
+			t.Errorf("expected %s, got %s", test.arn, arn)
+		}
+	}
+}
+
+func TestParseSidecars(t *testing.T) {
+	sidecars, err := parseSidecars(
+		[]string{"datadog=public.ecr.aws/datadog/agent:7", "log-router=amazon/aws-for-fluent-bit"},
+		[]string{"datadog:DD_SITE=datadoghq.com", "datadog:DD_APM_ENABLED=true"},
+		[]string{"datadog=8126"},
+	)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := []ECS.Sidecar{
+		{
+			Name:    "datadog",
+			Image:   "public.ecr.aws/datadog/agent:7",
+			EnvVars: []ECS.EnvVar{{Key: "DD_SITE", Value: "datadoghq.com"}, {Key: "DD_APM_ENABLED", Value: "true"}},
+			Port:    8126,
+		},
+		{Name: "log-router", Image: "amazon/aws-for-fluent-bit"},
+	}
+
+	if !reflect.DeepEqual(sidecars, expected) {
+		t.Errorf("expected %+v, got %+v", expected, sidecars)
+	}
+}
+
+func TestParseSidecarsInvalid(t *testing.T) {
+	var tests = []struct {
+		name     string
+		sidecars []string
+		envVars  []string
+		ports    []string
+	}{
+		{"missing image", []string{"datadog"}, nil, nil},
+		{"duplicate", []string{"datadog=agent:7", "datadog=agent:6"}, nil, nil},
+		{"invalid name", []string{"data dog=agent:7"}, nil, nil},
+		{"env for unknown sidecar", []string{"datadog=agent:7"}, []string{"envoy:FOO=bar"}, nil},
+		{"port for unknown sidecar", []string{"datadog=agent:7"}, nil, []string{"envoy=9901"}},
+		{"invalid port", []string{"datadog=agent:7"}, nil, []string{"datadog=http"}},
+	}
+
+	for _, test := range tests {
+		if _, err := parseSidecars(test.sidecars, test.envVars, test.ports); err == nil {
+			t.Errorf("%s: expected error, got none", test.name)
+		}
+	}
+}
+
+func TestParseEFSVolumes(t *testing.T) {
+	volumes, err := parseEFSVolumes([]string{"fs-12345678:/data", "fs-87654321:/shared:fsap-0123456789abcdef0"}, true)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := []ECS.EFSVolume{
+		{FileSystemID: "fs-12345678", ContainerPath: "/data", IAM: true},
+		{FileSystemID: "fs-87654321", ContainerPath: "/shared", AccessPointID: "fsap-0123456789abcdef0", IAM: true},
+	}
+
+	if !reflect.DeepEqual(volumes, expected) {
+		t.Errorf("expected %+v, got %+v", expected, volumes)
+	}
+
+	if _, err := parseEFSVolumes([]string{"fs-12345678"}, false); err == nil {
+		t.Error("expected a volume without a container path to be an error")
+	}
+
+	if _, err := parseEFSVolumes(nil, true); err == nil {
+		t.Error("expected --efs-iam without --efs to be an error")
+	}
+}
@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/turnerlabs/fargate/console"
+	EC2 "github.com/turnerlabs/fargate/ec2"
+	ECS "github.com/turnerlabs/fargate/ecs"
+	IAM "github.com/turnerlabs/fargate/iam"
+)
+
+//ECS starts at most 10 tasks per RunTask call
+const taskRunMaxCount = 10
+
+type TaskRunOperation struct {
+	AssignPublicIp       bool
+	CapacityProviders    []ECS.CapacityProviderStrategyItem
+	Count                int64
+	EC2                  EC2.Client
+	EnableExecuteCommand bool
+	PlatformVersion      string
+	SecurityGroupIDs     []string
+	SubnetIDs            []string
+	TaskDefinition       string
+	TaskGroupName        string
+	TaskRoleArn          string
+	VPCID                string
+}
+
+func (o *TaskRunOperation) Validate() error {
+	if o.Count < 1 || o.Count > taskRunMaxCount {
+		return fmt.Errorf("--count must be between 1 and %d", taskRunMaxCount)
+	}
+
+	if err := ECS.ValidatePlatformVersion(o.PlatformVersion); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+//SetSpot runs the tasks on Fargate Spot, with the first base tasks on Fargate
+//and, with a spotWeight, one task on Fargate for every spotWeight on Spot
+func (o *TaskRunOperation) SetSpot(base, spotWeight int64) error {
+	strategy, err := ECS.SpotStrategy(base, spotWeight)
+
+	if err != nil {
+		return err
+	}
+
+	o.CapacityProviders = strategy
+
+	return nil
+}
+
+//SetNetwork fills in the subnets and security group when none were given,
+//then checks the subnets and security groups exist and share a VPC. Without
+//subnets, the tasks run in every subnet of the given VPC or the default
+//subnets.
+func (o *TaskRunOperation) SetNetwork() error {
+	if len(o.SubnetIDs) == 0 && o.VPCID != "" {
+		subnetIDs, err := o.EC2.GetVPCSubnetIDs(o.VPCID)
+
+		if err != nil {
+			return err
+		}
+
+		o.SubnetIDs = subnetIDs
+	}
+
+	if len(o.SubnetIDs) == 0 {
+		subnetIDs, err := o.EC2.GetDefaultSubnetIDs()
+
+		if err != nil {
+			return err
+		}
+
+		if len(subnetIDs) == 0 {
+			return errors.New("could not find any default subnets, pass --subnet-id")
+		}
+
+		o.SubnetIDs = subnetIDs
+	}
+
+	if len(o.SecurityGroupIDs) == 0 {
+		groupID, err := o.EC2.GetDefaultSecurityGroupID()
+
+		if err != nil {
+			return err
+		}
+
+		if groupID == "" {
+			return errors.New("could not find the default security group, pass --security-group-id")
+		}
+
+		o.SecurityGroupIDs = []string{groupID}
+	}
+
+	vpcID, err := o.EC2.GetSubnetsVPCID(o.SubnetIDs)
+
+	if err != nil {
+		return err
+	}
+
+	if o.VPCID != "" && vpcID != o.VPCID {
+		return fmt.Errorf("subnets are in %s, expected %s", vpcID, o.VPCID)
+	}
+
+	return o.EC2.ValidateSecurityGroupsVPC(o.SecurityGroupIDs, vpcID)
+}
+
+//RunTaskInput returns the input to start the tasks in the given cluster
+func (o *TaskRunOperation) RunTaskInput(clusterName, namespace string) *ECS.RunTaskInput {
+	return &ECS.RunTaskInput{
+		AssignPublicIp:       o.AssignPublicIp,
+		CapacityProviders:    o.CapacityProviders,
+		ClusterName:          clusterName,
+		Count:                o.Count,
+		EnableExecuteCommand: o.EnableExecuteCommand,
+		Namespace:            namespace,
+		PlatformVersion:      o.PlatformVersion,
+		SecurityGroupIds:     o.SecurityGroupIDs,
+		SubnetIds:            o.SubnetIDs,
+		TaskDefinitionArn:    o.TaskDefinition,
+		TaskName:             o.TaskGroupName,
+		TaskRoleArn:          o.TaskRoleArn,
+	}
+}
+
+var (
+	flagTaskRunBase                 int64
+	flagTaskRunCount                int64
+	flagTaskRunEnableExecuteCommand bool
+	flagTaskRunFromService          string
+	flagTaskRunNoPublicIP           bool
+	flagTaskRunPlatformVersion      string
+	flagTaskRunSecurityGroupIDs     []string
+	flagTaskRunSpot                 bool
+	flagTaskRunSpotWeight           int64
+	flagTaskRunSubnetIDs            []string
+	flagTaskRunVPCID                string
+)
+
+var taskRunCmd = &cobra.Command{
+	Use:   "run [task-group-name]",
+	Short: "Run one-off tasks",
+	Long: `Run one-off tasks
+
+Starts tasks from the latest revision of the task definition family, or the
+revision given with --task family:revision, e.g. to run a database migration.
+Tasks are started in a task group named after the family unless a task group
+name is given.
+
+Tasks run in the default subnets with the fargate-default security group. Pass
+--subnet-id and --security-group-id, each one or many times, to run them
+elsewhere, such as the private subnets a database is reachable from. The
+subnets and security groups must all be in the same VPC. Pass --vpc-id instead
+of --subnet-id to run the tasks in any of a VPC's subnets.
+
+Tasks get a public IP, which tasks in public subnets need to pull their image.
+Pass --no-public-ip for tasks in private subnets, which need a NAT gateway or
+VPC endpoints to pull their image instead.
+
+--spot runs the tasks on Fargate Spot, which costs less but can be interrupted.
+Add --base to run the first tasks on Fargate, and --spot-weight to run one of
+the rest on Fargate for every so many on Spot. The cluster must have the
+FARGATE and FARGATE_SPOT capacity providers.
+
+--platform-version pins the Fargate platform version the tasks run on, e.g.
+1.4.0, so a new LATEST version doesn't change their behavior unexpectedly.
+
+--from-service runs the tasks with a service's task role instead of the task
+definition's, so a migration has the same permissions as the application. The
+role must trust ecs-tasks.amazonaws.com.
+
+--enable-execute-command allows commands to be run in the tasks with fargate
+task exec. The task role must allow the ssmmessages actions ECS Exec uses,
+which fargate service update --enable-execute-command grants to a service's
+task role for use with --from-service.`,
+	Example: `
+fargate task run -t my-app
+fargate task run migrate -t my-app:42 --count 1 --platform-version 1.4.0
+fargate task run report -t my-app --count 4 --spot --base 1
+fargate task run migrate -t my-app --from-service web
+fargate task run debug -t my-app --from-service web --enable-execute-command
+fargate task run migrate -t my-app --subnet-id subnet-1234567 --subnet-id subnet-abcdef1 --security-group-id sg-1234567
+fargate task run migrate -t my-app --vpc-id vpc-1234567 --security-group-id sg-1234567 --no-public-ip
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		operation := &TaskRunOperation{
+			AssignPublicIp:       !flagTaskRunNoPublicIP,
+			Count:                flagTaskRunCount,
+			EnableExecuteCommand: flagTaskRunEnableExecuteCommand,
+			EC2:                  EC2.New(sess),
+			PlatformVersion:      flagTaskRunPlatformVersion,
+			SecurityGroupIDs:     flagTaskRunSecurityGroupIDs,
+			SubnetIDs:            flagTaskRunSubnetIDs,
+			TaskDefinition:       getTaskName(),
+			VPCID:                flagTaskRunVPCID,
+		}
+
+		if flagTaskRunSpot {
+			if err := operation.SetSpot(flagTaskRunBase, flagTaskRunSpotWeight); err != nil {
+				console.ErrorExit(err, "Invalid command line flags")
+			}
+		} else if cmd.Flags().Changed("base") || cmd.Flags().Changed("spot-weight") {
+			console.IssueExit("--base and --spot-weight require --spot")
+		}
+
+		if len(args) == 1 {
+			operation.TaskGroupName = args[0]
+		} else {
+			operation.TaskGroupName = strings.Split(operation.TaskDefinition, ":")[0]
+		}
+
+		if err := operation.Validate(); err != nil {
+			console.ErrorExit(err, "Invalid command line flags")
+		}
+
+		if err := operation.SetNetwork(); err != nil {
+			console.ErrorExit(err, "Invalid task network configuration")
+		}
+
+		if flagTaskRunFromService != "" {
+			operation.TaskRoleArn = getServiceTaskRole(flagTaskRunFromService)
+		}
+
+		runTask(operation)
+	},
+}
+
+func init() {
+	taskRunCmd.Flags().Int64Var(&flagTaskRunCount, "count", 1, fmt.Sprintf("Number of tasks to run [1 to %d]", taskRunMaxCount))
+	taskRunCmd.Flags().BoolVar(&flagTaskRunNoPublicIP, "no-public-ip", false, "Run the tasks without a public IP")
+	taskRunCmd.Flags().StringVar(&flagTaskRunPlatformVersion, "platform-version", ECS.PlatformVersionLatest, "Fargate platform version to run the tasks on [e.g. 1.4.0]")
+	taskRunCmd.Flags().BoolVar(&flagTaskRunSpot, "spot", false, "Run the tasks on Fargate Spot")
+	taskRunCmd.Flags().Int64Var(&flagTaskRunBase, "base", 0, "Number of tasks to run on Fargate before using Fargate Spot (requires --spot)")
+	taskRunCmd.Flags().Int64Var(&flagTaskRunSpotWeight, "spot-weight", 0, "Number of tasks to run on Fargate Spot for every task on Fargate after the base (requires --spot)")
+	taskRunCmd.Flags().BoolVar(&flagTaskRunEnableExecuteCommand, "enable-execute-command", false, "Allow commands to be run in the tasks with task exec")
+	taskRunCmd.Flags().StringVar(&flagTaskRunFromService, "from-service", "", "Name of a service whose task role the tasks run with")
+	taskRunCmd.Flags().StringArrayVar(&flagTaskRunSubnetIDs, "subnet-id", []string{}, "ID of a subnet to run the tasks in (defaults to the default subnets)")
+	taskRunCmd.Flags().StringArrayVar(&flagTaskRunSecurityGroupIDs, "security-group-id", []string{}, "ID of a security group to run the tasks with (defaults to fargate-default)")
+	taskRunCmd.Flags().StringVar(&flagTaskRunVPCID, "vpc-id", "", "ID of the VPC to run the tasks in (defaults to the VPC of the subnets)")
+
+	taskCmd.AddCommand(taskRunCmd)
+}
+
+func runTask(operation *TaskRunOperation) {
+	ecs := ECS.New(sess, getClusterName())
+
+	ecs.RunTask(operation.RunTaskInput(ecs.ClusterName, ecs.Namespace))
+
+	console.Info("Running %d task(s) of %s in task group %s", operation.Count, operation.TaskDefinition, operation.TaskGroupName)
+}
+
+//getServiceTaskRole returns a service's task role after checking ECS tasks can
+//assume it
+func getServiceTaskRole(serviceName string) string {
+	ecs := ECS.New(sess, getClusterName())
+	roleArn := ecs.DescribeService(serviceName).TaskRole
+
+	if roleArn == "" {
+		console.IssueExit("Service %s has no task role", serviceName)
+	}
+
+	assumable, err := IAM.New(sess).CanBeAssumedByECSTasks(roleArn)
+
+	if err != nil {
+		console.ErrorExit(err, "Could not check the trust policy of task role %s", roleArn)
+	}
+
+	if !assumable {
+		console.IssueExit("Task role %s of service %s doesn't trust %s", roleArn, serviceName, IAM.ECSTasksPrincipal)
+	}
+
+	return roleArn
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	EC2Client "github.com/turnerlabs/fargate/ec2/mock/client"
+	ECS "github.com/turnerlabs/fargate/ecs"
+)
+
+func TestTaskRunOperationValidate(t *testing.T) {
+	var tests = []struct {
+		count int64
+		valid bool
+	}{
+		{1, true},
+		{10, true},
+		{0, false},
+		{11, false},
+	}
+
+	for _, test := range tests {
+		err := (&TaskRunOperation{Count: test.count}).Validate()
+
+		if test.valid && err != nil {
+			t.Errorf("expected count %d to be valid, got %v", test.count, err)
+		}
+
+		if !test.valid && err == nil {
+			t.Errorf("expected count %d to be invalid", test.count)
+		}
+	}
+}
+
+func TestTaskRunOperationValidatePlatformVersion(t *testing.T) {
+	var tests = []struct {
+		platformVersion string
+		valid           bool
+	}{
+		{"LATEST", true},
+		{"1.4.0", true},
+		{"latest", false},
+		{"1.4", false},
+	}
+
+	for _, test := range tests {
+		err := (&TaskRunOperation{Count: 1, PlatformVersion: test.platformVersion}).Validate()
+
+		if test.valid && err != nil {
+			t.Errorf("expected platform version %s to be valid, got %v", test.platformVersion, err)
+		}
+
+		if !test.valid && err == nil {
+			t.Errorf("expected platform version %s to be invalid", test.platformVersion)
+		}
+	}
+}
+
+func TestTaskRunOperationSetNetwork(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockClient := EC2Client.NewMockClient(mockCtrl)
+	operation := &TaskRunOperation{
+		EC2:              mockClient,
+		SecurityGroupIDs: []string{"sg-1234567"},
+		SubnetIDs:        []string{"subnet-1234567", "subnet-abcdef1"},
+	}
+
+	mockClient.EXPECT().GetSubnetsVPCID([]string{"subnet-1234567", "subnet-abcdef1"}).Return("vpc-1234567", nil)
+	mockClient.EXPECT().ValidateSecurityGroupsVPC([]string{"sg-1234567"}, "vpc-1234567").Return(nil)
+
+	if err := operation.SetNetwork(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestTaskRunOperationSetNetworkDefaults(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockClient := EC2Client.NewMockClient(mockCtrl)
+	operation := &TaskRunOperation{EC2: mockClient}
+
+	mockClient.EXPECT().GetDefaultSubnetIDs().Return([]string{"subnet-1234567"}, nil)
+	mockClient.EXPECT().GetDefaultSecurityGroupID().Return("sg-1234567", nil)
+	mockClient.EXPECT().GetSubnetsVPCID([]string{"subnet-1234567"}).Return("vpc-1234567", nil)
+	mockClient.EXPECT().ValidateSecurityGroupsVPC([]string{"sg-1234567"}, "vpc-1234567").Return(nil)
+
+	if err := operation.SetNetwork(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !reflect.DeepEqual(operation.SubnetIDs, []string{"subnet-1234567"}) {
+		t.Errorf("expected the default subnets, got %v", operation.SubnetIDs)
+	}
+
+	if !reflect.DeepEqual(operation.SecurityGroupIDs, []string{"sg-1234567"}) {
+		t.Errorf("expected the default security group, got %v", operation.SecurityGroupIDs)
+	}
+}
+
+func TestTaskRunOperationSetNetworkVPC(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockClient := EC2Client.NewMockClient(mockCtrl)
+	operation := &TaskRunOperation{
+		EC2:              mockClient,
+		SecurityGroupIDs: []string{"sg-1234567"},
+		VPCID:            "vpc-1234567",
+	}
+
+	mockClient.EXPECT().GetVPCSubnetIDs("vpc-1234567").Return([]string{"subnet-1234567", "subnet-abcdef1"}, nil)
+	mockClient.EXPECT().GetSubnetsVPCID([]string{"subnet-1234567", "subnet-abcdef1"}).Return("vpc-1234567", nil)
+	mockClient.EXPECT().ValidateSecurityGroupsVPC([]string{"sg-1234567"}, "vpc-1234567").Return(nil)
+
+	if err := operation.SetNetwork(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !reflect.DeepEqual(operation.SubnetIDs, []string{"subnet-1234567", "subnet-abcdef1"}) {
+		t.Errorf("expected the VPC's subnets, got %v", operation.SubnetIDs)
+	}
+}
+
+func TestTaskRunOperationSetNetworkSubnetsInAnotherVPC(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockClient := EC2Client.NewMockClient(mockCtrl)
+	operation := &TaskRunOperation{
+		EC2:              mockClient,
+		SecurityGroupIDs: []string{"sg-1234567"},
+		SubnetIDs:        []string{"subnet-1234567"},
+		VPCID:            "vpc-abcdef1",
+	}
+
+	mockClient.EXPECT().GetSubnetsVPCID([]string{"subnet-1234567"}).Return("vpc-1234567", nil)
+
+	if err := operation.SetNetwork(); err == nil {
+		t.Error("expected an error, got none")
+	}
+}
+
+func TestTaskRunOperationSetNetworkSecurityGroupInAnotherVPC(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockClient := EC2Client.NewMockClient(mockCtrl)
+	operation := &TaskRunOperation{
+		EC2:              mockClient,
+		SecurityGroupIDs: []string{"sg-1234567"},
+		SubnetIDs:        []string{"subnet-1234567"},
+	}
+
+	mockClient.EXPECT().GetSubnetsVPCID([]string{"subnet-1234567"}).Return("vpc-1234567", nil)
+	mockClient.EXPECT().ValidateSecurityGroupsVPC([]string{"sg-1234567"}, "vpc-1234567").Return(errors.New("security group sg-1234567 is in vpc-abcdef1, expected vpc-1234567"))
+
+	if err := operation.SetNetwork(); err == nil {
+		t.Error("expected an error, got none")
+	}
+}
+
+func TestTaskRunOperationSetSpot(t *testing.T) {
+	operation := &TaskRunOperation{}
+
+	if err := operation.SetSpot(1, 3); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := []ECS.CapacityProviderStrategyItem{
+		{CapacityProvider: "FARGATE", Base: 1, Weight: 1},
+		{CapacityProvider: "FARGATE_SPOT", Weight: 3},
+	}
+
+	if !reflect.DeepEqual(operation.CapacityProviders, expected) {
+		t.Errorf("expected %+v, got %+v", expected, operation.CapacityProviders)
+	}
+
+	if err := operation.SetSpot(-1, 0); err == nil {
+		t.Error("expected a negative base to be an error")
+	}
+}
+
+func TestTaskRunOperationRunTaskInput(t *testing.T) {
+	operation := &TaskRunOperation{
+		AssignPublicIp:       true,
+		CapacityProviders:    []ECS.CapacityProviderStrategyItem{{CapacityProvider: "FARGATE_SPOT", Weight: 1}},
+		Count:                2,
+		EnableExecuteCommand: true,
+		PlatformVersion:      "1.4.0",
+		SecurityGroupIDs:     []string{"sg-1234567"},
+		SubnetIDs:            []string{"subnet-1234567"},
+		TaskDefinition:       "my-app:42",
+		TaskGroupName:        "migrate",
+		TaskRoleArn:          "arn:aws:iam::123456789012:role/web-task",
+	}
+
+	expected := &ECS.RunTaskInput{
+		AssignPublicIp:       true,
+		CapacityProviders:    []ECS.CapacityProviderStrategyItem{{CapacityProvider: "FARGATE_SPOT", Weight: 1}},
+		ClusterName:          "my-cluster",
+		Count:                2,
+		EnableExecuteCommand: true,
+		Namespace:            "staging",
+		PlatformVersion:      "1.4.0",
+		SecurityGroupIds:     []string{"sg-1234567"},
+		SubnetIds:            []string{"subnet-1234567"},
+		TaskDefinitionArn:    "my-app:42",
+		TaskName:             "migrate",
+		TaskRoleArn:          "arn:aws:iam::123456789012:role/web-task",
+	}
+
+	if input := operation.RunTaskInput("my-cluster", "staging"); !reflect.DeepEqual(input, expected) {
+		t.Errorf("expected %+v, got %+v", expected, input)
+	}
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const cpuUnitsInVcpu = 1024
+
+var (
+	cpuExpression    = regexp.MustCompile(`(?i)\A\s*([0-9]*\.?[0-9]+)\s*(vcpus?)?\s*\z`)
+	memoryExpression = regexp.MustCompile(`(?i)\A\s*([0-9]*\.?[0-9]+)\s*(gib|gb|g|mib|mb|m)?\s*\z`)
+)
+
+//normalizeCpu converts a CPU expression such as 256 or 0.25vcpu into the
+//CPU units string expected by the ECS API.
+func normalizeCpu(cpu string) (string, error) {
+	matches := cpuExpression.FindStringSubmatch(cpu)
+
+	if matches == nil {
+		return "", fmt.Errorf("could not parse CPU from %s, use CPU units (256) or vCPUs (0.25vcpu)", cpu)
+	}
+
+	multiplier := 1.0
+
+	if matches[2] != "" {
+		multiplier = cpuUnitsInVcpu
+	}
+
+	return formatUnits(cpu, matches[1], multiplier)
+}
+
+//normalizeMemory converts a memory expression such as 512, 512MiB or 0.5GB
+//into the MiB string expected by the ECS API. GB is treated as GiB.
+func normalizeMemory(memory string) (string, error) {
+	matches := memoryExpression.FindStringSubmatch(memory)
+
+	if matches == nil {
+		return "", fmt.Errorf("could not parse memory from %s, use MiB (512) or GiB (0.5GB)", memory)
+	}
+
+	multiplier := 1.0
+
+	if strings.HasPrefix(strings.ToLower(matches[2]), "g") {
+		multiplier = mebibytesInGibibyte
+	}
+
+	return formatUnits(memory, matches[1], multiplier)
+}
+
+func formatUnits(input, number string, multiplier float64) (string, error) {
+	value, err := strconv.ParseFloat(number, 64)
+
+	if err != nil {
+		return "", err
+	}
+
+	units := value * multiplier
+
+	if units != float64(int64(units)) {
+		return "", fmt.Errorf("%s is not a whole number of units", input)
+	}
+
+	return strconv.FormatInt(int64(units), 10), nil
+}
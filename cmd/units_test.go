@@ -0,0 +1,76 @@
+package cmd
+
+import "testing"
+
+func TestNormalizeCpu(t *testing.T) {
+	var tests = []struct {
+		in    string
+		out   string
+		valid bool
+	}{
+		{"256", "256", true},
+		{"1024", "1024", true},
+		{"0.25vcpu", "256", true},
+		{"0.5vCPU", "512", true},
+		{"1 vcpu", "1024", true},
+		{"2vcpus", "2048", true},
+		{".25vcpu", "256", true},
+		{"0.1vcpu", "", false},
+		{"256.5", "", false},
+		{"", "", false},
+		{"abc", "", false},
+		{"1gb", "", false},
+	}
+
+	for _, test := range tests {
+		out, err := normalizeCpu(test.in)
+
+		if test.valid && err != nil {
+			t.Errorf("normalizeCpu(%q) returned error %s, want %s", test.in, err, test.out)
+		}
+
+		if !test.valid && err == nil {
+			t.Errorf("normalizeCpu(%q) => %s, want error", test.in, out)
+		}
+
+		if out != test.out {
+			t.Errorf("normalizeCpu(%q) => %q, want %q", test.in, out, test.out)
+		}
+	}
+}
+
+func TestNormalizeMemory(t *testing.T) {
+	var tests = []struct {
+		in    string
+		out   string
+		valid bool
+	}{
+		{"512", "512", true},
+		{"512MiB", "512", true},
+		{"512mb", "512", true},
+		{"0.5GB", "512", true},
+		{"0.5 GiB", "512", true},
+		{"2g", "2048", true},
+		{"30gb", "30720", true},
+		{"0.3gb", "", false},
+		{"1.5", "", false},
+		{"", "", false},
+		{"1vcpu", "", false},
+	}
+
+	for _, test := range tests {
+		out, err := normalizeMemory(test.in)
+
+		if test.valid && err != nil {
+			t.Errorf("normalizeMemory(%q) returned error %s, want %s", test.in, err, test.out)
+		}
+
+		if !test.valid && err == nil {
+			t.Errorf("normalizeMemory(%q) => %s, want error", test.in, out)
+		}
+
+		if out != test.out {
+			t.Errorf("normalizeMemory(%q) => %q, want %q", test.in, out, test.out)
+		}
+	}
+}
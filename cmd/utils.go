@@ -1,8 +1,12 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
+	"os"
+
+	"github.com/turnerlabs/fargate/console"
 )
 
 var okayResponses = []string{"y", "Y", "yes", "Yes", "YES"}
@@ -29,6 +33,13 @@ func askForConfirmation() bool {
 	}
 }
 
+// stdinIsTerminal returns whether stdin is interactive, i.e. whether the user can be asked questions
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
 // containsString returns true iff slice contains element
 func containsString(slice []string, element string) bool {
 	return !(posString(slice, element) == -1)
@@ -44,3 +55,13 @@ func posString(slice []string, element string) int {
 	}
 	return -1
 }
+
+// printJSON writes v to standard output as indented JSON.
+func printJSON(v interface{}) {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(v); err != nil {
+		console.ErrorExit(err, "Could not encode JSON output")
+	}
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/turnerlabs/fargate/console"
+	ECS "github.com/turnerlabs/fargate/ecs"
+	ELBV2 "github.com/turnerlabs/fargate/elbv2"
+)
+
+const (
+	waitPollInterval   = 5 * time.Second
+	defaultWaitTimeout = 10 * time.Minute
+)
+
+//waitTimeout is how long to wait for a service to settle: the global
+//--timeout if one is set, otherwise defaultWaitTimeout
+func waitTimeout() time.Duration {
+	if t := getTimeout(); t > 0 {
+		return t
+	}
+
+	return defaultWaitTimeout
+}
+
+//waitContext returns a context that is done once a wait for a service to
+//settle has run for waitTimeout, through commandContext when --timeout is set
+func waitContext() (context.Context, context.CancelFunc) {
+	if getTimeout() > 0 {
+		return context.WithCancel(commandContext)
+	}
+
+	return context.WithTimeout(commandContext, defaultWaitTimeout)
+}
+
+//waitForServiceCount polls a service until its running count matches the
+//desired count with no rollout in flight (and, for load balanced services,
+//that many targets are healthy), printing progress as it changes and the
+//service events on timeout
+func waitForServiceCount(serviceName string, desiredCount int64) {
+	ecs := ECS.New(sess, getClusterName())
+	elbv2 := ELBV2.New(sess)
+	ctx, cancel := waitContext()
+	lastProgress := ""
+
+	defer cancel()
+
+	console.Info("Waiting for service %s to reach %d running tasks...", serviceName, desiredCount)
+
+	for {
+		service := ecs.DescribeService(serviceName)
+		tasks := ecs.DescribeTasksForService(serviceName)
+		progress := fmt.Sprintf("Running: %d/%d, Pending: %d, %s", service.RunningCount, desiredCount, service.PendingCount, taskStatusProgress(tasks))
+		healthy := true
+
+		if service.TargetGroupArn != "" {
+			targetHealths, err := elbv2.DescribeTargetHealth(service.TargetGroupArn)
+
+			if err != nil {
+				console.ErrorExit(err, "Could not describe ELB target health")
+			}
+
+			healthy = int64(targetHealths.Healthy()) == desiredCount
+			progress += fmt.Sprintf(", Healthy targets: %d/%d", targetHealths.Healthy(), desiredCount)
+		}
+
+		if progress != lastProgress {
+			console.Info(progress)
+			lastProgress = progress
+		}
+
+		if len(service.Deployments) <= 1 && service.RunningCount == desiredCount && service.PendingCount == 0 && healthy {
+			console.Info("Service %s is running %d tasks.", serviceName, desiredCount)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			timeoutExpired()
+			console.Issue("Timed out after %s waiting for service %s to reach %d running tasks", waitTimeout(), serviceName, desiredCount)
+			printStuckTasks(tasks)
+			console.Header("Events")
+			printServiceEvents(service.Events)
+			printStoppedTaskReasons(ecs, serviceName)
+			console.Exit(1)
+		case <-time.After(waitPollInterval):
+		}
+	}
+}
+
+//printStoppedTaskReasons prints why a service's most recently stopped tasks
+//stopped, with a hint when they couldn't reach AWS to start
+func printStoppedTaskReasons(ecs ECS.ECS, serviceName string) {
+	tasks := ECS.LatestStoppedTasks(ecs.DescribeStoppedTasksForService(serviceName), ECS.StoppedTaskLimit)
+
+	if len(tasks) == 0 {
+		return
+	}
+
+	hint := ""
+	console.Header("Stopped Tasks")
+
+	for _, task := range tasks {
+		console.KeyValue(task.TaskId, "%s\n", task.StoppedReason)
+
+		if hint == "" {
+			hint = ECS.NetworkFailureHint(task.StoppedReason)
+		}
+	}
+
+	if hint != "" {
+		console.Issue(hint)
+	}
+}
@@ -55,6 +55,15 @@ func LogLine(prefix, msg string, color int, time string, excludePrefix bool) {
 	fmt.Println(strings.TrimSpace(payload))
 }
 
+//Highlight emphasizes part of a message, e.g. a search match
+func Highlight(s string) string {
+	if Color {
+		return yellow + s + reset
+	}
+
+	return s
+}
+
 func KeyValue(key, value string, a ...interface{}) {
 	if Color {
 		fmt.Fprintf(os.Stdout, white+key+reset+": "+value, a...)
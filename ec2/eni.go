@@ -6,39 +6,60 @@ import (
 	"github.com/turnerlabs/fargate/console"
 )
 
+// describeNetworkInterfacesBatchSize is the most values an EC2 filter accepts.
+const describeNetworkInterfacesBatchSize = 200
+
 type Eni struct {
 	PublicIpAddress  string
 	EniId            string
 	SecurityGroupIds []string
 }
 
+// DescribeNetworkInterfaces returns the given network interfaces keyed by ID. Interfaces that no
+// longer exist, such as those of stopped tasks, are left out rather than being an error.
 func (ec2 SDKClient) DescribeNetworkInterfaces(eniIds []string) map[string]Eni {
 	enis := make(map[string]Eni)
 
-	resp, err := ec2.client.DescribeNetworkInterfaces(
-		&awsec2.DescribeNetworkInterfacesInput{
-			NetworkInterfaceIds: aws.StringSlice(eniIds),
-		},
-	)
+	for start := 0; start < len(eniIds); start += describeNetworkInterfacesBatchSize {
+		end := start + describeNetworkInterfacesBatchSize
 
-	if err != nil {
-		console.ErrorExit(err, "Could not describe network interfaces")
-	}
+		if end > len(eniIds) {
+			end = len(eniIds)
+		}
+
+		//filter rather than pass IDs so interfaces that no longer exist aren't an error
+		eniFilter := &awsec2.Filter{
+			Name:   aws.String("network-interface-id"),
+			Values: aws.StringSlice(eniIds[start:end]),
+		}
 
-	for _, e := range resp.NetworkInterfaces {
-		var securityGroupIds []*string
+		resp, err := ec2.client.DescribeNetworkInterfaces(
+			&awsec2.DescribeNetworkInterfacesInput{
+				Filters: []*awsec2.Filter{eniFilter},
+			},
+		)
 
-		for _, group := range e.Groups {
-			securityGroupIds = append(securityGroupIds, group.GroupId)
+		if err != nil {
+			console.ErrorExit(err, "Could not describe network interfaces")
 		}
 
-		if e.Association != nil {
+		for _, e := range resp.NetworkInterfaces {
+			var securityGroupIds []*string
+
+			for _, group := range e.Groups {
+				securityGroupIds = append(securityGroupIds, group.GroupId)
+			}
+
 			eni := Eni{
 				EniId:            aws.StringValue(e.NetworkInterfaceId),
-				PublicIpAddress:  aws.StringValue(e.Association.PublicIp),
 				SecurityGroupIds: aws.StringValueSlice(securityGroupIds),
 			}
 
+			//only ENIs in public subnets with a public IP have an association
+			if e.Association != nil {
+				eni.PublicIpAddress = aws.StringValue(e.Association.PublicIp)
+			}
+
 			enis[eni.EniId] = eni
 		}
 	}
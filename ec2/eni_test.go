@@ -0,0 +1,92 @@
+package ec2
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsec2 "github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/mock/gomock"
+	"github.com/turnerlabs/fargate/ec2/mock/sdk"
+)
+
+func TestDescribeNetworkInterfaces(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	filter := &awsec2.Filter{
+		Name:   aws.String("network-interface-id"),
+		Values: aws.StringSlice([]string{"eni-1", "eni-2"}),
+	}
+	input := &awsec2.DescribeNetworkInterfacesInput{
+		Filters: []*awsec2.Filter{filter},
+	}
+	output := &awsec2.DescribeNetworkInterfacesOutput{
+		NetworkInterfaces: []*awsec2.NetworkInterface{
+			&awsec2.NetworkInterface{
+				NetworkInterfaceId: aws.String("eni-1"),
+				Association:        &awsec2.NetworkInterfaceAssociation{PublicIp: aws.String("203.0.113.10")},
+				Groups: []*awsec2.GroupIdentifier{
+					&awsec2.GroupIdentifier{GroupId: aws.String("sg-1")},
+					&awsec2.GroupIdentifier{GroupId: aws.String("sg-2")},
+				},
+			},
+		},
+	}
+
+	mockEC2Client := sdk.NewMockEC2API(mockCtrl)
+	ec2 := SDKClient{client: mockEC2Client}
+
+	mockEC2Client.EXPECT().DescribeNetworkInterfaces(input).Return(output, nil)
+
+	enis := ec2.DescribeNetworkInterfaces([]string{"eni-1", "eni-2"})
+
+	if eni := enis["eni-1"]; eni.PublicIpAddress != "203.0.113.10" || len(eni.SecurityGroupIds) != 2 || eni.SecurityGroupIds[1] != "sg-2" {
+		t.Errorf("expected eni-1 with its public IP and security groups, got %+v", eni)
+	}
+
+	if eni, ok := enis["eni-2"]; ok {
+		t.Errorf("expected the missing eni-2 to be left out, got %+v", eni)
+	}
+}
+
+func TestDescribeNetworkInterfacesBatches(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEC2Client := sdk.NewMockEC2API(mockCtrl)
+	ec2 := SDKClient{client: mockEC2Client}
+
+	var batchSizes []int
+
+	mockEC2Client.EXPECT().DescribeNetworkInterfaces(gomock.Any()).Times(2).DoAndReturn(
+		func(input *awsec2.DescribeNetworkInterfacesInput) (*awsec2.DescribeNetworkInterfacesOutput, error) {
+			batchSizes = append(batchSizes, len(input.Filters[0].Values))
+			return &awsec2.DescribeNetworkInterfacesOutput{}, nil
+		},
+	)
+
+	var eniIds []string
+
+	for i := 0; i < 250; i++ {
+		eniIds = append(eniIds, fmt.Sprintf("eni-%d", i))
+	}
+
+	ec2.DescribeNetworkInterfaces(eniIds)
+
+	if len(batchSizes) != 2 || batchSizes[0] != 200 || batchSizes[1] != 50 {
+		t.Errorf("expected batches of 200 and 50 interfaces, got %v", batchSizes)
+	}
+}
+
+func TestDescribeNetworkInterfacesNone(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEC2Client := sdk.NewMockEC2API(mockCtrl)
+	ec2 := SDKClient{client: mockEC2Client}
+
+	if enis := ec2.DescribeNetworkInterfaces(nil); len(enis) != 0 {
+		t.Errorf("expected no interfaces, got %v", enis)
+	}
+}
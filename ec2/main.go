@@ -15,7 +15,11 @@ type Client interface {
 	CreateDefaultSecurityGroup() (string, error)
 	GetDefaultSecurityGroupID() (string, error)
 	GetDefaultSubnetIDs() ([]string, error)
+	GetSubnetAvailabilityZones([]string) (map[string]string, error)
 	GetSubnetVPCID(string) (string, error)
+	GetSubnetsVPCID([]string) (string, error)
+	GetVPCSubnetIDs(string) ([]string, error)
+	ValidateSecurityGroupsVPC([]string, string) error
 }
 
 // SDKClient implements access to EC2 via the AWS SDK.
@@ -92,6 +92,21 @@ func (mr *MockClientMockRecorder) GetDefaultSubnetIDs() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDefaultSubnetIDs", reflect.TypeOf((*MockClient)(nil).GetDefaultSubnetIDs))
 }
 
+// GetSubnetAvailabilityZones mocks base method.
+func (m *MockClient) GetSubnetAvailabilityZones(arg0 []string) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubnetAvailabilityZones", arg0)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubnetAvailabilityZones indicates an expected call of GetSubnetAvailabilityZones.
+func (mr *MockClientMockRecorder) GetSubnetAvailabilityZones(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubnetAvailabilityZones", reflect.TypeOf((*MockClient)(nil).GetSubnetAvailabilityZones), arg0)
+}
+
 // GetSubnetVPCID mocks base method.
 func (m *MockClient) GetSubnetVPCID(arg0 string) (string, error) {
 	m.ctrl.T.Helper()
@@ -106,3 +121,47 @@ func (mr *MockClientMockRecorder) GetSubnetVPCID(arg0 interface{}) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubnetVPCID", reflect.TypeOf((*MockClient)(nil).GetSubnetVPCID), arg0)
 }
+
+// GetSubnetsVPCID mocks base method.
+func (m *MockClient) GetSubnetsVPCID(arg0 []string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubnetsVPCID", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubnetsVPCID indicates an expected call of GetSubnetsVPCID.
+func (mr *MockClientMockRecorder) GetSubnetsVPCID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubnetsVPCID", reflect.TypeOf((*MockClient)(nil).GetSubnetsVPCID), arg0)
+}
+
+// GetVPCSubnetIDs mocks base method.
+func (m *MockClient) GetVPCSubnetIDs(arg0 string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVPCSubnetIDs", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVPCSubnetIDs indicates an expected call of GetVPCSubnetIDs.
+func (mr *MockClientMockRecorder) GetVPCSubnetIDs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVPCSubnetIDs", reflect.TypeOf((*MockClient)(nil).GetVPCSubnetIDs), arg0)
+}
+
+// ValidateSecurityGroupsVPC mocks base method.
+func (m *MockClient) ValidateSecurityGroupsVPC(arg0 []string, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateSecurityGroupsVPC", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ValidateSecurityGroupsVPC indicates an expected call of ValidateSecurityGroupsVPC.
+func (mr *MockClientMockRecorder) ValidateSecurityGroupsVPC(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateSecurityGroupsVPC", reflect.TypeOf((*MockClient)(nil).ValidateSecurityGroupsVPC), arg0, arg1)
+}
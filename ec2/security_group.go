@@ -0,0 +1,140 @@
+package ec2
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsec2 "github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// IngressRule allows inbound traffic on a range of ports from a CIDR block.
+type IngressRule struct {
+	Protocol string
+	FromPort int64
+	ToPort   int64
+	CIDR     string
+}
+
+// ParseIngressRule parses an ingress rule of the form protocol:port:cidr, such as tcp:443:0.0.0.0/0.
+// The port may also be a range, such as tcp:8000-8080:10.0.0.0/16.
+func ParseIngressRule(rule string) (IngressRule, error) {
+	parts := strings.SplitN(rule, ":", 3)
+
+	if len(parts) != 3 {
+		return IngressRule{}, fmt.Errorf("invalid ingress rule %s [expected protocol:port:cidr, e.g. tcp:443:0.0.0.0/0]", rule)
+	}
+
+	protocol := strings.ToLower(parts[0])
+
+	if protocol != "tcp" && protocol != "udp" {
+		return IngressRule{}, fmt.Errorf("invalid protocol %s in ingress rule %s [valid values: tcp, udp]", parts[0], rule)
+	}
+
+	ports := strings.SplitN(parts[1], "-", 2)
+	fromPort, err := parsePort(ports[0])
+
+	if err != nil {
+		return IngressRule{}, fmt.Errorf("invalid port in ingress rule %s: %v", rule, err)
+	}
+
+	toPort := fromPort
+
+	if len(ports) == 2 {
+		if toPort, err = parsePort(ports[1]); err != nil {
+			return IngressRule{}, fmt.Errorf("invalid port in ingress rule %s: %v", rule, err)
+		}
+
+		if toPort < fromPort {
+			return IngressRule{}, fmt.Errorf("invalid port range %s in ingress rule %s", parts[1], rule)
+		}
+	}
+
+	if _, _, err := net.ParseCIDR(parts[2]); err != nil {
+		return IngressRule{}, fmt.Errorf("invalid CIDR block %s in ingress rule %s", parts[2], rule)
+	}
+
+	return IngressRule{Protocol: protocol, FromPort: fromPort, ToPort: toPort, CIDR: parts[2]}, nil
+}
+
+// String returns the rule in the form ParseIngressRule accepts.
+func (r IngressRule) String() string {
+	ports := strconv.FormatInt(r.FromPort, 10)
+
+	if r.ToPort != r.FromPort {
+		ports += "-" + strconv.FormatInt(r.ToPort, 10)
+	}
+
+	return fmt.Sprintf("%s:%s:%s", r.Protocol, ports, r.CIDR)
+}
+
+func (r IngressRule) permission() *awsec2.IpPermission {
+	permission := &awsec2.IpPermission{
+		IpProtocol: aws.String(r.Protocol),
+		FromPort:   aws.Int64(r.FromPort),
+		ToPort:     aws.Int64(r.ToPort),
+	}
+
+	if strings.Contains(r.CIDR, ":") {
+		permission.Ipv6Ranges = []*awsec2.Ipv6Range{&awsec2.Ipv6Range{CidrIpv6: aws.String(r.CIDR)}}
+	} else {
+		permission.IpRanges = []*awsec2.IpRange{&awsec2.IpRange{CidrIp: aws.String(r.CIDR)}}
+	}
+
+	return permission
+}
+
+func parsePort(port string) (int64, error) {
+	number, err := strconv.ParseInt(port, 10, 64)
+
+	if err != nil || number < 1 || number > 65535 {
+		return 0, fmt.Errorf("%s is not a port number (1-65535)", port)
+	}
+
+	return number, nil
+}
+
+// CreateSecurityGroup creates a security group in the given VPC and returns its ID.
+func (ec2 SDKClient) CreateSecurityGroup(name, description, vpcID string) (string, error) {
+	resp, err := ec2.client.CreateSecurityGroup(
+		&awsec2.CreateSecurityGroupInput{
+			GroupName:   aws.String(name),
+			Description: aws.String(description),
+			VpcId:       aws.String(vpcID),
+		},
+	)
+
+	if err != nil {
+		return "", fmt.Errorf("could not create security group %s in VPC %s: %v", name, vpcID, err)
+	}
+
+	return aws.StringValue(resp.GroupId), nil
+}
+
+// AuthorizeSecurityGroupIngressRules allows inbound traffic to a security group matching any of the given rules.
+func (ec2 SDKClient) AuthorizeSecurityGroupIngressRules(groupID string, rules []IngressRule) error {
+	var permissions []*awsec2.IpPermission
+
+	if len(rules) == 0 {
+		return nil
+	}
+
+	for _, rule := range rules {
+		permissions = append(permissions, rule.permission())
+	}
+
+	_, err := ec2.client.AuthorizeSecurityGroupIngress(
+		&awsec2.AuthorizeSecurityGroupIngressInput{
+			GroupId:       aws.String(groupID),
+			IpPermissions: permissions,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("could not authorize ingress to security group %s: %v", groupID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package ec2
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsec2 "github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/golang/mock/gomock"
+	"github.com/turnerlabs/fargate/ec2/mock/sdk"
+)
+
+func TestParseIngressRule(t *testing.T) {
+	var tests = []struct {
+		rule  string
+		valid bool
+		out   IngressRule
+	}{
+		{"tcp:443:0.0.0.0/0", true, IngressRule{Protocol: "tcp", FromPort: 443, ToPort: 443, CIDR: "0.0.0.0/0"}},
+		{"UDP:8000-8080:10.0.0.0/16", true, IngressRule{Protocol: "udp", FromPort: 8000, ToPort: 8080, CIDR: "10.0.0.0/16"}},
+		{"tcp:80:::/0", true, IngressRule{Protocol: "tcp", FromPort: 80, ToPort: 80, CIDR: "::/0"}},
+		{"icmp:443:0.0.0.0/0", false, IngressRule{}},
+		{"tcp:0:0.0.0.0/0", false, IngressRule{}},
+		{"tcp:70000:0.0.0.0/0", false, IngressRule{}},
+		{"tcp:8080-80:0.0.0.0/0", false, IngressRule{}},
+		{"tcp:443:10.0.0.0", false, IngressRule{}},
+		{"tcp:443", false, IngressRule{}},
+	}
+
+	for _, test := range tests {
+		out, err := ParseIngressRule(test.rule)
+
+		if test.valid && err != nil {
+			t.Errorf("expected %s to be valid, got %v", test.rule, err)
+		}
+
+		if !test.valid && err == nil {
+			t.Errorf("expected %s to be invalid", test.rule)
+		}
+
+		if out != test.out {
+			t.Errorf("ParseIngressRule(%s) => %+v, want %+v", test.rule, out, test.out)
+		}
+	}
+}
+
+func TestIngressRuleString(t *testing.T) {
+	if got := (IngressRule{Protocol: "tcp", FromPort: 443, ToPort: 443, CIDR: "0.0.0.0/0"}).String(); got != "tcp:443:0.0.0.0/0" {
+		t.Errorf("expected tcp:443:0.0.0.0/0, got %s", got)
+	}
+
+	if got := (IngressRule{Protocol: "udp", FromPort: 8000, ToPort: 8080, CIDR: "10.0.0.0/16"}).String(); got != "udp:8000-8080:10.0.0.0/16" {
+		t.Errorf("expected udp:8000-8080:10.0.0.0/16, got %s", got)
+	}
+}
+
+func TestCreateSecurityGroup(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	input := &awsec2.CreateSecurityGroupInput{
+		GroupName:   aws.String("web"),
+		Description: aws.String("Web traffic"),
+		VpcId:       aws.String("vpc-1234567"),
+	}
+
+	mockEC2Client := sdk.NewMockEC2API(mockCtrl)
+	ec2 := SDKClient{client: mockEC2Client}
+
+	mockEC2Client.EXPECT().CreateSecurityGroup(input).Return(&awsec2.CreateSecurityGroupOutput{GroupId: aws.String("sg-abcdef")}, nil)
+
+	groupID, err := ec2.CreateSecurityGroup("web", "Web traffic", "vpc-1234567")
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if groupID != "sg-abcdef" {
+		t.Errorf("expected sg-abcdef, got %s", groupID)
+	}
+}
+
+func TestCreateSecurityGroupError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEC2Client := sdk.NewMockEC2API(mockCtrl)
+	ec2 := SDKClient{client: mockEC2Client}
+
+	mockEC2Client.EXPECT().CreateSecurityGroup(gomock.Any()).Return(&awsec2.CreateSecurityGroupOutput{}, errors.New("boom"))
+
+	if _, err := ec2.CreateSecurityGroup("web", "Web traffic", "vpc-1234567"); err == nil {
+		t.Errorf("expected error, got none")
+	}
+}
+
+func TestAuthorizeSecurityGroupIngressRules(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	input := &awsec2.AuthorizeSecurityGroupIngressInput{
+		GroupId: aws.String("sg-abcdef"),
+		IpPermissions: []*awsec2.IpPermission{
+			&awsec2.IpPermission{
+				IpProtocol: aws.String("tcp"),
+				FromPort:   aws.Int64(443),
+				ToPort:     aws.Int64(443),
+				IpRanges:   []*awsec2.IpRange{&awsec2.IpRange{CidrIp: aws.String("0.0.0.0/0")}},
+			},
+			&awsec2.IpPermission{
+				IpProtocol: aws.String("tcp"),
+				FromPort:   aws.Int64(443),
+				ToPort:     aws.Int64(443),
+				Ipv6Ranges: []*awsec2.Ipv6Range{&awsec2.Ipv6Range{CidrIpv6: aws.String("::/0")}},
+			},
+		},
+	}
+
+	mockEC2Client := sdk.NewMockEC2API(mockCtrl)
+	ec2 := SDKClient{client: mockEC2Client}
+
+	mockEC2Client.EXPECT().AuthorizeSecurityGroupIngress(input).Return(&awsec2.AuthorizeSecurityGroupIngressOutput{}, nil)
+
+	err := ec2.AuthorizeSecurityGroupIngressRules(
+		"sg-abcdef",
+		[]IngressRule{
+			{Protocol: "tcp", FromPort: 443, ToPort: 443, CIDR: "0.0.0.0/0"},
+			{Protocol: "tcp", FromPort: 443, ToPort: 443, CIDR: "::/0"},
+		},
+	)
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package ec2
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
@@ -41,6 +42,38 @@ func (ec2 SDKClient) GetDefaultSubnetIDs() ([]string, error) {
 	return subnetIDs, nil
 }
 
+// GetVPCSubnetIDs returns the IDs of the subnets in the given VPC.
+func (ec2 SDKClient) GetVPCSubnetIDs(vpcID string) ([]string, error) {
+	var subnetIDs []string
+
+	vpcFilter := &awsec2.Filter{
+		Name:   aws.String("vpc-id"),
+		Values: aws.StringSlice([]string{vpcID}),
+	}
+
+	err := ec2.client.DescribeSubnetsPages(
+		&awsec2.DescribeSubnetsInput{
+			Filters: []*awsec2.Filter{vpcFilter},
+		},
+		func(resp *awsec2.DescribeSubnetsOutput, lastPage bool) bool {
+			for _, subnet := range resp.Subnets {
+				subnetIDs = append(subnetIDs, aws.StringValue(subnet.SubnetId))
+			}
+
+			return true
+		},
+	)
+
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("could not retrieve subnet IDs for VPC %s: %v", vpcID, err)
+	case len(subnetIDs) == 0:
+		return nil, fmt.Errorf("could not find any subnets in VPC %s", vpcID)
+	default:
+		return subnetIDs, nil
+	}
+}
+
 // GetDefaultSecurityGroupID returns the ID of the permissive security group created by default.
 func (ec2 SDKClient) GetDefaultSecurityGroupID() (string, error) {
 	resp, err := ec2.client.DescribeSecurityGroups(
@@ -108,3 +141,113 @@ func (ec2 SDKClient) AuthorizeAllSecurityGroupIngress(groupID string) error {
 
 	return err
 }
+
+// GetSubnetsVPCID returns the VPC ID shared by the given subnet IDs. An error is returned if any
+// subnet cannot be found or if the subnets span more than one VPC.
+func (ec2 SDKClient) GetSubnetsVPCID(subnetIDs []string) (string, error) {
+	var vpcID string
+
+	if len(subnetIDs) == 0 {
+		return "", fmt.Errorf("no subnet IDs given")
+	}
+
+	resp, err := ec2.client.DescribeSubnets(
+		&awsec2.DescribeSubnetsInput{
+			SubnetIds: aws.StringSlice(subnetIDs),
+		},
+	)
+
+	if err != nil {
+		return "", fmt.Errorf("could not describe subnets %s: %v", strings.Join(subnetIDs, ", "), err)
+	}
+
+	if len(resp.Subnets) != len(subnetIDs) {
+		return "", fmt.Errorf("could not find all subnets: %s", strings.Join(subnetIDs, ", "))
+	}
+
+	for _, subnet := range resp.Subnets {
+		subnetVPCID := aws.StringValue(subnet.VpcId)
+
+		if vpcID == "" {
+			vpcID = subnetVPCID
+		} else if subnetVPCID != vpcID {
+			return "", fmt.Errorf("subnets span multiple VPCs: %s is in %s, expected %s", aws.StringValue(subnet.SubnetId), subnetVPCID, vpcID)
+		}
+	}
+
+	return vpcID, nil
+}
+
+// GetSubnetAvailabilityZones returns the availability zone of each of the given subnet IDs, keyed
+// by subnet ID.
+func (ec2 SDKClient) GetSubnetAvailabilityZones(subnetIDs []string) (map[string]string, error) {
+	zones := make(map[string]string)
+
+	if len(subnetIDs) == 0 {
+		return zones, nil
+	}
+
+	resp, err := ec2.client.DescribeSubnets(
+		&awsec2.DescribeSubnetsInput{
+			SubnetIds: aws.StringSlice(subnetIDs),
+		},
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not describe subnets %s: %v", strings.Join(subnetIDs, ", "), err)
+	}
+
+	for _, subnet := range resp.Subnets {
+		zones[aws.StringValue(subnet.SubnetId)] = aws.StringValue(subnet.AvailabilityZone)
+	}
+
+	return zones, nil
+}
+
+// ValidateSecurityGroupsVPC ensures that each of the given security groups exists and belongs to
+// the given VPC. A group given more than once is only checked once.
+func (ec2 SDKClient) ValidateSecurityGroupsVPC(groupIDs []string, vpcID string) error {
+	if len(groupIDs) == 0 {
+		return nil
+	}
+
+	groupIDs = uniqueStrings(groupIDs)
+
+	resp, err := ec2.client.DescribeSecurityGroups(
+		&awsec2.DescribeSecurityGroupsInput{
+			GroupIds: aws.StringSlice(groupIDs),
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("could not describe security groups %s: %v", strings.Join(groupIDs, ", "), err)
+	}
+
+	if len(resp.SecurityGroups) != len(groupIDs) {
+		return fmt.Errorf("could not find all security groups: %s", strings.Join(groupIDs, ", "))
+	}
+
+	for _, group := range resp.SecurityGroups {
+		if groupVPCID := aws.StringValue(group.VpcId); groupVPCID != vpcID {
+			return fmt.Errorf("security group %s is in %s, expected %s", aws.StringValue(group.GroupId), groupVPCID, vpcID)
+		}
+	}
+
+	return nil
+}
+
+// uniqueStrings returns values without duplicates, in their original order.
+func uniqueStrings(values []string) []string {
+	var unique []string
+
+	seen := make(map[string]bool)
+
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+
+	return unique
+}
@@ -294,3 +294,231 @@ func TestAuthorizeAllSecurityGroupIngress(t *testing.T) {
 		t.Errorf("expected no error, got %v", err)
 	}
 }
+
+func TestGetSubnetsVPCID(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	subnetIDs := []string{"subnet-1234567", "subnet-abcdefg"}
+	input := &awsec2.DescribeSubnetsInput{
+		SubnetIds: aws.StringSlice(subnetIDs),
+	}
+	output := &awsec2.DescribeSubnetsOutput{
+		Subnets: []*awsec2.Subnet{
+			&awsec2.Subnet{SubnetId: aws.String(subnetIDs[0]), VpcId: aws.String("vpc-1234567")},
+			&awsec2.Subnet{SubnetId: aws.String(subnetIDs[1]), VpcId: aws.String("vpc-1234567")},
+		},
+	}
+
+	mockEC2Client := sdk.NewMockEC2API(mockCtrl)
+	ec2 := SDKClient{client: mockEC2Client}
+
+	mockEC2Client.EXPECT().DescribeSubnets(input).Return(output, nil)
+
+	vpcID, err := ec2.GetSubnetsVPCID(subnetIDs)
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if vpcID != "vpc-1234567" {
+		t.Errorf("expected vpc-1234567, got %s", vpcID)
+	}
+}
+
+func TestGetSubnetsVPCIDMultipleVPCs(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	output := &awsec2.DescribeSubnetsOutput{
+		Subnets: []*awsec2.Subnet{
+			&awsec2.Subnet{SubnetId: aws.String("subnet-1234567"), VpcId: aws.String("vpc-1234567")},
+			&awsec2.Subnet{SubnetId: aws.String("subnet-abcdefg"), VpcId: aws.String("vpc-abcdefg")},
+		},
+	}
+
+	mockEC2Client := sdk.NewMockEC2API(mockCtrl)
+	ec2 := SDKClient{client: mockEC2Client}
+
+	mockEC2Client.EXPECT().DescribeSubnets(gomock.Any()).Return(output, nil)
+
+	_, err := ec2.GetSubnetsVPCID([]string{"subnet-1234567", "subnet-abcdefg"})
+
+	if err == nil {
+		t.Errorf("expected error, got none")
+	}
+}
+
+func TestGetSubnetsVPCIDSubnetNotFound(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEC2Client := sdk.NewMockEC2API(mockCtrl)
+	ec2 := SDKClient{client: mockEC2Client}
+
+	mockEC2Client.EXPECT().DescribeSubnets(gomock.Any()).Return(nil, errors.New("InvalidSubnetID.NotFound"))
+
+	_, err := ec2.GetSubnetsVPCID([]string{"subnet-1234567"})
+
+	if err == nil {
+		t.Errorf("expected error, got none")
+	}
+}
+
+func TestGetVPCSubnetIDs(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	filter := &awsec2.Filter{
+		Name:   aws.String("vpc-id"),
+		Values: aws.StringSlice([]string{"vpc-1234567"}),
+	}
+	input := &awsec2.DescribeSubnetsInput{
+		Filters: []*awsec2.Filter{filter},
+	}
+	pages := []*awsec2.DescribeSubnetsOutput{
+		&awsec2.DescribeSubnetsOutput{
+			Subnets: []*awsec2.Subnet{&awsec2.Subnet{SubnetId: aws.String("subnet-1234567")}},
+		},
+		&awsec2.DescribeSubnetsOutput{
+			Subnets: []*awsec2.Subnet{&awsec2.Subnet{SubnetId: aws.String("subnet-abcdefg")}},
+		},
+	}
+
+	mockEC2Client := sdk.NewMockEC2API(mockCtrl)
+	ec2 := SDKClient{client: mockEC2Client}
+
+	mockEC2Client.EXPECT().DescribeSubnetsPages(input, gomock.Any()).DoAndReturn(
+		func(i *awsec2.DescribeSubnetsInput, fn func(*awsec2.DescribeSubnetsOutput, bool) bool) error {
+			for n, page := range pages {
+				fn(page, n == len(pages)-1)
+			}
+
+			return nil
+		},
+	)
+
+	subnetIDs, err := ec2.GetVPCSubnetIDs("vpc-1234567")
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if len(subnetIDs) != 2 || subnetIDs[0] != "subnet-1234567" || subnetIDs[1] != "subnet-abcdefg" {
+		t.Errorf("expected subnet-1234567 and subnet-abcdefg, got %v", subnetIDs)
+	}
+}
+
+func TestGetVPCSubnetIDsNoSubnets(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEC2Client := sdk.NewMockEC2API(mockCtrl)
+	ec2 := SDKClient{client: mockEC2Client}
+
+	mockEC2Client.EXPECT().DescribeSubnetsPages(gomock.Any(), gomock.Any()).Return(nil)
+
+	_, err := ec2.GetVPCSubnetIDs("vpc-1234567")
+
+	if err == nil {
+		t.Errorf("expected error, got none")
+	}
+}
+
+func TestGetSubnetAvailabilityZones(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	subnetIDs := []string{"subnet-1234567", "subnet-abcdefg"}
+	input := &awsec2.DescribeSubnetsInput{
+		SubnetIds: aws.StringSlice(subnetIDs),
+	}
+	output := &awsec2.DescribeSubnetsOutput{
+		Subnets: []*awsec2.Subnet{
+			&awsec2.Subnet{SubnetId: aws.String(subnetIDs[0]), AvailabilityZone: aws.String("us-east-1a")},
+			&awsec2.Subnet{SubnetId: aws.String(subnetIDs[1]), AvailabilityZone: aws.String("us-east-1b")},
+		},
+	}
+
+	mockEC2Client := sdk.NewMockEC2API(mockCtrl)
+	ec2 := SDKClient{client: mockEC2Client}
+
+	mockEC2Client.EXPECT().DescribeSubnets(input).Return(output, nil)
+
+	zones, err := ec2.GetSubnetAvailabilityZones(subnetIDs)
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if zones["subnet-1234567"] != "us-east-1a" || zones["subnet-abcdefg"] != "us-east-1b" {
+		t.Errorf("expected us-east-1a and us-east-1b, got %v", zones)
+	}
+}
+
+func TestGetSubnetAvailabilityZonesError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEC2Client := sdk.NewMockEC2API(mockCtrl)
+	ec2 := SDKClient{client: mockEC2Client}
+
+	mockEC2Client.EXPECT().DescribeSubnets(gomock.Any()).Return(nil, errors.New("boom"))
+
+	_, err := ec2.GetSubnetAvailabilityZones([]string{"subnet-1234567"})
+
+	if err == nil {
+		t.Errorf("expected error, got none")
+	}
+}
+
+func TestValidateSecurityGroupsVPC(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	groupIDs := []string{"sg-1234567"}
+	input := &awsec2.DescribeSecurityGroupsInput{
+		GroupIds: aws.StringSlice(groupIDs),
+	}
+	output := &awsec2.DescribeSecurityGroupsOutput{
+		SecurityGroups: []*awsec2.SecurityGroup{
+			&awsec2.SecurityGroup{GroupId: aws.String("sg-1234567"), VpcId: aws.String("vpc-1234567")},
+		},
+	}
+
+	mockEC2Client := sdk.NewMockEC2API(mockCtrl)
+	ec2 := SDKClient{client: mockEC2Client}
+
+	mockEC2Client.EXPECT().DescribeSecurityGroups(input).Return(output, nil).Times(2)
+
+	if err := ec2.ValidateSecurityGroupsVPC(groupIDs, "vpc-1234567"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := ec2.ValidateSecurityGroupsVPC(groupIDs, "vpc-abcdefg"); err == nil {
+		t.Errorf("expected error, got none")
+	}
+}
+
+func TestValidateSecurityGroupsVPC_Duplicates(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	input := &awsec2.DescribeSecurityGroupsInput{
+		GroupIds: aws.StringSlice([]string{"sg-1234567"}),
+	}
+	output := &awsec2.DescribeSecurityGroupsOutput{
+		SecurityGroups: []*awsec2.SecurityGroup{
+			&awsec2.SecurityGroup{GroupId: aws.String("sg-1234567"), VpcId: aws.String("vpc-1234567")},
+		},
+	}
+
+	mockEC2Client := sdk.NewMockEC2API(mockCtrl)
+	ec2 := SDKClient{client: mockEC2Client}
+
+	mockEC2Client.EXPECT().DescribeSecurityGroups(input).Return(output, nil)
+
+	if err := ec2.ValidateSecurityGroupsVPC([]string{"sg-1234567", "sg-1234567"}, "vpc-1234567"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package ecr
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ecr"
+)
+
+var imageURIRegexp = regexp.MustCompile(`^(\d{12})\.dkr\.ecr\.([a-z0-9-]+)\.(amazonaws\.com(?:\.cn)?)/([^:@]+)(?::([^@]+))?(?:@(.+))?$`)
+
+// ImageURI is a parsed ECR image URI, e.g. 123456789012.dkr.ecr.us-east-1.amazonaws.com/web:1.0.
+type ImageURI struct {
+	RegistryID string
+	Region     string
+	Domain     string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseImageURI parses an ECR image URI, returning false for images in other registries.
+func ParseImageURI(image string) (ImageURI, bool) {
+	matches := imageURIRegexp.FindStringSubmatch(image)
+
+	if matches == nil {
+		return ImageURI{}, false
+	}
+
+	return ImageURI{
+		RegistryID: matches[1],
+		Region:     matches[2],
+		Domain:     matches[3],
+		Repository: matches[4],
+		Tag:        matches[5],
+		Digest:     matches[6],
+	}, true
+}
+
+// InRegion returns the URI of the same image in another region's registry.
+func (u ImageURI) InRegion(region string) ImageURI {
+	u.Region = region
+	return u
+}
+
+// SameRepository returns whether two images are in the same repository of the
+// same registry and region.
+func (u ImageURI) SameRepository(other ImageURI) bool {
+	return u.RegistryID == other.RegistryID && u.Region == other.Region && u.Repository == other.Repository
+}
+
+// String returns the image URI.
+func (u ImageURI) String() string {
+	uri := fmt.Sprintf("%s.dkr.ecr.%s.%s/%s", u.RegistryID, u.Region, u.Domain, u.Repository)
+
+	if u.Tag != "" {
+		uri += ":" + u.Tag
+	}
+
+	if u.Digest != "" {
+		uri += "@" + u.Digest
+	}
+
+	return uri
+}
+
+// imageIdentifier identifies the image by digest, else by tag. Untagged
+// images are identified by the latest tag, as docker would pull them.
+func (u ImageURI) imageIdentifier() *ecr.ImageIdentifier {
+	switch {
+	case u.Digest != "":
+		return &ecr.ImageIdentifier{ImageDigest: aws.String(u.Digest)}
+	case u.Tag != "":
+		return &ecr.ImageIdentifier{ImageTag: aws.String(u.Tag)}
+	default:
+		return &ecr.ImageIdentifier{ImageTag: aws.String("latest")}
+	}
+}
+
+// ImageExists returns whether the image is in the client's region. Untagged
+// images are looked up by the latest tag, as docker would pull them.
+func (c SDKClient) ImageExists(u ImageURI) (bool, error) {
+	_, err := c.client.DescribeImages(
+		&ecr.DescribeImagesInput{
+			RegistryId:     aws.String(u.RegistryID),
+			RepositoryName: aws.String(u.Repository),
+			ImageIds:       []*ecr.ImageIdentifier{u.imageIdentifier()},
+		},
+	)
+
+	if aerr, ok := err.(awserr.Error); ok {
+		switch aerr.Code() {
+		case ecr.ErrCodeRepositoryNotFoundException, ecr.ErrCodeImageNotFoundException:
+			return false, nil
+		}
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// DeleteRepository deletes a repository and every image in it, returning
+// false if the repository doesn't exist.
+func (c SDKClient) DeleteRepository(registryID, repositoryName string) (bool, error) {
+	_, err := c.client.DeleteRepository(
+		&ecr.DeleteRepositoryInput{
+			Force:          aws.Bool(true),
+			RegistryId:     aws.String(registryID),
+			RepositoryName: aws.String(repositoryName),
+		},
+	)
+
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == ecr.ErrCodeRepositoryNotFoundException {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,229 @@
+package ecr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsecr "github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecr/ecriface"
+)
+
+type mockECRAPI struct {
+	ecriface.ECRAPI
+	err          error
+	input        *awsecr.DescribeImagesInput
+	deleteInput  *awsecr.DeleteRepositoryInput
+	scanInput    *awsecr.DescribeImageScanFindingsInput
+	scanOutput   *awsecr.DescribeImageScanFindingsOutput
+	putScanInput *awsecr.PutImageScanningConfigurationInput
+	policyInput  *awsecr.PutLifecyclePolicyInput
+}
+
+func (m *mockECRAPI) DescribeImages(i *awsecr.DescribeImagesInput) (*awsecr.DescribeImagesOutput, error) {
+	m.input = i
+
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &awsecr.DescribeImagesOutput{}, nil
+}
+
+func (m *mockECRAPI) DeleteRepository(i *awsecr.DeleteRepositoryInput) (*awsecr.DeleteRepositoryOutput, error) {
+	m.deleteInput = i
+
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &awsecr.DeleteRepositoryOutput{}, nil
+}
+
+func (m *mockECRAPI) DescribeImageScanFindings(i *awsecr.DescribeImageScanFindingsInput) (*awsecr.DescribeImageScanFindingsOutput, error) {
+	m.scanInput = i
+
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return m.scanOutput, nil
+}
+
+func (m *mockECRAPI) PutImageScanningConfiguration(i *awsecr.PutImageScanningConfigurationInput) (*awsecr.PutImageScanningConfigurationOutput, error) {
+	m.putScanInput = i
+
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &awsecr.PutImageScanningConfigurationOutput{}, nil
+}
+
+func (m *mockECRAPI) PutLifecyclePolicy(i *awsecr.PutLifecyclePolicyInput) (*awsecr.PutLifecyclePolicyOutput, error) {
+	m.policyInput = i
+
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &awsecr.PutLifecyclePolicyOutput{}, nil
+}
+
+func TestParseImageURI(t *testing.T) {
+	var tests = []struct {
+		image string
+		ok    bool
+		want  ImageURI
+	}{
+		{
+			"123456789012.dkr.ecr.us-east-1.amazonaws.com/web:1.0", true,
+			ImageURI{RegistryID: "123456789012", Region: "us-east-1", Domain: "amazonaws.com", Repository: "web", Tag: "1.0"},
+		},
+		{
+			"123456789012.dkr.ecr.cn-north-1.amazonaws.com.cn/team/web@sha256:abc", true,
+			ImageURI{RegistryID: "123456789012", Region: "cn-north-1", Domain: "amazonaws.com.cn", Repository: "team/web", Digest: "sha256:abc"},
+		},
+		{
+			"123456789012.dkr.ecr.us-east-1.amazonaws.com/web", true,
+			ImageURI{RegistryID: "123456789012", Region: "us-east-1", Domain: "amazonaws.com", Repository: "web"},
+		},
+		{"nginx:latest", false, ImageURI{}},
+		{"ghcr.io/org/web:1.0", false, ImageURI{}},
+	}
+
+	for _, test := range tests {
+		got, ok := ParseImageURI(test.image)
+
+		if ok != test.ok || got != test.want {
+			t.Errorf("ParseImageURI(%q) => (%+v, %t), want (%+v, %t)", test.image, got, ok, test.want, test.ok)
+		}
+
+		if ok && got.String() != test.image {
+			t.Errorf("ParseImageURI(%q).String() => %s", test.image, got.String())
+		}
+	}
+}
+
+func TestImageURIInRegion(t *testing.T) {
+	uri, _ := ParseImageURI("123456789012.dkr.ecr.us-east-1.amazonaws.com/web:1.0")
+
+	if got, want := uri.InRegion("us-west-2").String(), "123456789012.dkr.ecr.us-west-2.amazonaws.com/web:1.0"; got != want {
+		t.Errorf("InRegion => %s, want %s", got, want)
+	}
+
+	if uri.Region != "us-east-1" {
+		t.Errorf("InRegion modified the original URI: %s", uri)
+	}
+}
+
+func TestImageURISameRepository(t *testing.T) {
+	uri, _ := ParseImageURI("123456789012.dkr.ecr.us-east-1.amazonaws.com/web:1.0")
+
+	var tests = []struct {
+		image    string
+		expected bool
+	}{
+		{"123456789012.dkr.ecr.us-east-1.amazonaws.com/web:2.0", true},
+		{"123456789012.dkr.ecr.us-east-1.amazonaws.com/web@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", true},
+		{"123456789012.dkr.ecr.us-west-2.amazonaws.com/web:1.0", false},
+		{"210987654321.dkr.ecr.us-east-1.amazonaws.com/web:1.0", false},
+		{"123456789012.dkr.ecr.us-east-1.amazonaws.com/api:1.0", false},
+	}
+
+	for _, test := range tests {
+		other, _ := ParseImageURI(test.image)
+
+		if got := uri.SameRepository(other); got != test.expected {
+			t.Errorf("SameRepository(%s) => %t, want %t", test.image, got, test.expected)
+		}
+	}
+}
+
+func TestImageExists(t *testing.T) {
+	mockClient := &mockECRAPI{}
+	ecr := SDKClient{client: mockClient}
+	uri, _ := ParseImageURI("123456789012.dkr.ecr.us-west-2.amazonaws.com/web:1.0")
+
+	exists, err := ecr.ImageExists(uri)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !exists {
+		t.Errorf("expected image to exist")
+	}
+
+	if got := aws.StringValue(mockClient.input.RegistryId); got != "123456789012" {
+		t.Errorf("expected registry 123456789012, got %s", got)
+	}
+
+	if got := aws.StringValue(mockClient.input.ImageIds[0].ImageTag); got != "1.0" {
+		t.Errorf("expected tag 1.0, got %s", got)
+	}
+}
+
+func TestImageExists_Latest(t *testing.T) {
+	mockClient := &mockECRAPI{}
+	ecr := SDKClient{client: mockClient}
+	uri, _ := ParseImageURI("123456789012.dkr.ecr.us-west-2.amazonaws.com/web")
+
+	ecr.ImageExists(uri)
+
+	if got := aws.StringValue(mockClient.input.ImageIds[0].ImageTag); got != "latest" {
+		t.Errorf("expected tag latest, got %s", got)
+	}
+}
+
+func TestImageExists_NotFound(t *testing.T) {
+	for _, code := range []string{awsecr.ErrCodeRepositoryNotFoundException, awsecr.ErrCodeImageNotFoundException} {
+		ecr := SDKClient{client: &mockECRAPI{err: awserr.New(code, "not found", nil)}}
+		uri, _ := ParseImageURI("123456789012.dkr.ecr.us-west-2.amazonaws.com/web:1.0")
+
+		exists, err := ecr.ImageExists(uri)
+
+		if err != nil || exists {
+			t.Errorf("%s: expected (false, nil), got (%t, %v)", code, exists, err)
+		}
+	}
+}
+
+func TestImageExists_Error(t *testing.T) {
+	ecr := SDKClient{client: &mockECRAPI{err: errors.New("boom")}}
+	uri, _ := ParseImageURI("123456789012.dkr.ecr.us-west-2.amazonaws.com/web:1.0")
+
+	if _, err := ecr.ImageExists(uri); err == nil {
+		t.Errorf("expected error, got none")
+	}
+}
+
+func TestDeleteRepository(t *testing.T) {
+	mockClient := &mockECRAPI{}
+	ecr := SDKClient{client: mockClient}
+
+	deleted, err := ecr.DeleteRepository("123456789012", "web")
+
+	if err != nil || !deleted {
+		t.Fatalf("expected (true, nil), got (%t, %v)", deleted, err)
+	}
+
+	if !aws.BoolValue(mockClient.deleteInput.Force) {
+		t.Errorf("expected repository to be deleted with its images")
+	}
+
+	if got := aws.StringValue(mockClient.deleteInput.RepositoryName); got != "web" {
+		t.Errorf("expected repository web, got %s", got)
+	}
+}
+
+func TestDeleteRepository_NotFound(t *testing.T) {
+	ecr := SDKClient{client: &mockECRAPI{err: awserr.New(awsecr.ErrCodeRepositoryNotFoundException, "not found", nil)}}
+
+	deleted, err := ecr.DeleteRepository("123456789012", "web")
+
+	if err != nil || deleted {
+		t.Errorf("expected (false, nil), got (%t, %v)", deleted, err)
+	}
+}
@@ -0,0 +1,75 @@
+package ecr
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecr"
+)
+
+type lifecyclePolicy struct {
+	Rules []lifecycleRule `json:"rules"`
+}
+
+type lifecycleRule struct {
+	RulePriority int                `json:"rulePriority"`
+	Description  string             `json:"description"`
+	Selection    lifecycleSelection `json:"selection"`
+	Action       lifecycleAction    `json:"action"`
+}
+
+type lifecycleSelection struct {
+	TagStatus   string `json:"tagStatus"`
+	CountType   string `json:"countType"`
+	CountNumber int    `json:"countNumber"`
+}
+
+type lifecycleAction struct {
+	Type string `json:"type"`
+}
+
+// KeepMostRecentPolicy returns a lifecycle policy document that expires every
+// image, tagged or not, other than the most recent count images.
+func KeepMostRecentPolicy(count int) (string, error) {
+	if count < 1 {
+		return "", fmt.Errorf("invalid number of images to keep %d [must be at least 1]", count)
+	}
+
+	policy := lifecyclePolicy{
+		Rules: []lifecycleRule{
+			{
+				RulePriority: 1,
+				Description:  fmt.Sprintf("Keep the %d most recent images", count),
+				Selection: lifecycleSelection{
+					TagStatus:   "any",
+					CountType:   "imageCountMoreThan",
+					CountNumber: count,
+				},
+				Action: lifecycleAction{Type: "expire"},
+			},
+		},
+	}
+
+	bits, err := json.Marshal(policy)
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(bits), nil
+}
+
+// PutLifecyclePolicy sets a repository's lifecycle policy, replacing any it
+// already has.
+func (c SDKClient) PutLifecyclePolicy(registryID, repositoryName, policy string) error {
+	_, err := c.client.PutLifecyclePolicy(
+		&ecr.PutLifecyclePolicyInput{
+			LifecyclePolicyText: aws.String(policy),
+			RegistryId:          aws.String(registryID),
+			RepositoryName:      aws.String(repositoryName),
+		},
+	)
+
+	return err
+}
@@ -0,0 +1,42 @@
+package ecr
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func TestKeepMostRecentPolicy(t *testing.T) {
+	policy, err := KeepMostRecentPolicy(10)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := `{"rules":[{"rulePriority":1,"description":"Keep the 10 most recent images","selection":{"tagStatus":"any","countType":"imageCountMoreThan","countNumber":10},"action":{"type":"expire"}}]}`
+
+	if policy != expected {
+		t.Errorf("expected %s, got %s", expected, policy)
+	}
+
+	if _, err := KeepMostRecentPolicy(0); err == nil {
+		t.Error("expected an error keeping 0 images")
+	}
+}
+
+func TestPutLifecyclePolicy(t *testing.T) {
+	mockClient := &mockECRAPI{}
+	ecr := SDKClient{client: mockClient}
+
+	if err := ecr.PutLifecyclePolicy("123456789012", "web", `{"rules":[]}`); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if got := aws.StringValue(mockClient.policyInput.LifecyclePolicyText); got != `{"rules":[]}` {
+		t.Errorf("expected policy to be passed as is, got %s", got)
+	}
+
+	if got := aws.StringValue(mockClient.policyInput.RepositoryName); got != "web" {
+		t.Errorf("expected repository web, got %s", got)
+	}
+}
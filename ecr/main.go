@@ -0,0 +1,19 @@
+package ecr
+
+import (
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecr/ecriface"
+)
+
+// SDKClient implements access to Amazon Elastic Container Registry via the AWS SDK.
+type SDKClient struct {
+	client ecriface.ECRAPI
+}
+
+// New returns an SDKClient configured with the given session.
+func New(sess *session.Session) SDKClient {
+	return SDKClient{
+		client: ecr.New(sess),
+	}
+}
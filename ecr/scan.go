@@ -0,0 +1,82 @@
+package ecr
+
+import (
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ecr"
+)
+
+// ErrScanNotFound is returned for images that haven't been scanned.
+var ErrScanNotFound = errors.New("image has not been scanned")
+
+// Severities are the severities ECR reports findings with, most severe first.
+var Severities = []string{
+	ecr.FindingSeverityCritical,
+	ecr.FindingSeverityHigh,
+	ecr.FindingSeverityMedium,
+	ecr.FindingSeverityLow,
+	ecr.FindingSeverityInformational,
+	ecr.FindingSeverityUndefined,
+}
+
+// ScanFindings summarizes an image scan: its status and the number of
+// findings of each severity.
+type ScanFindings struct {
+	Status         string           `json:"status"`
+	Description    string           `json:"description,omitempty"`
+	SeverityCounts map[string]int64 `json:"severityCounts"`
+}
+
+// ImageScanFindings returns the findings of the image's latest scan, or
+// ErrScanNotFound if it hasn't been scanned.
+func (c SDKClient) ImageScanFindings(u ImageURI) (ScanFindings, error) {
+	output, err := c.client.DescribeImageScanFindings(
+		&ecr.DescribeImageScanFindingsInput{
+			RegistryId:     aws.String(u.RegistryID),
+			RepositoryName: aws.String(u.Repository),
+			ImageId:        u.imageIdentifier(),
+			MaxResults:     aws.Int64(1),
+		},
+	)
+
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == ecr.ErrCodeScanNotFoundException {
+		return ScanFindings{}, ErrScanNotFound
+	}
+
+	if err != nil {
+		return ScanFindings{}, err
+	}
+
+	findings := ScanFindings{SeverityCounts: make(map[string]int64)}
+
+	if output.ImageScanStatus != nil {
+		findings.Status = aws.StringValue(output.ImageScanStatus.Status)
+		findings.Description = aws.StringValue(output.ImageScanStatus.Description)
+	}
+
+	if output.ImageScanFindings != nil {
+		for severity, count := range output.ImageScanFindings.FindingSeverityCounts {
+			findings.SeverityCounts[severity] = aws.Int64Value(count)
+		}
+	}
+
+	return findings, nil
+}
+
+// EnableScanOnPush turns on scanning of images when they're pushed to a
+// repository. Repositories that already scan on push are left as is.
+func (c SDKClient) EnableScanOnPush(registryID, repositoryName string) error {
+	_, err := c.client.PutImageScanningConfiguration(
+		&ecr.PutImageScanningConfigurationInput{
+			RegistryId:     aws.String(registryID),
+			RepositoryName: aws.String(repositoryName),
+			ImageScanningConfiguration: &ecr.ImageScanningConfiguration{
+				ScanOnPush: aws.Bool(true),
+			},
+		},
+	)
+
+	return err
+}
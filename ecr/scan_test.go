@@ -0,0 +1,69 @@
+package ecr
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsecr "github.com/aws/aws-sdk-go/service/ecr"
+)
+
+func TestImageScanFindings(t *testing.T) {
+	mockClient := &mockECRAPI{
+		scanOutput: &awsecr.DescribeImageScanFindingsOutput{
+			ImageScanStatus: &awsecr.ImageScanStatus{Status: aws.String(awsecr.ScanStatusComplete)},
+			ImageScanFindings: &awsecr.ImageScanFindings{
+				FindingSeverityCounts: map[string]*int64{
+					awsecr.FindingSeverityHigh: aws.Int64(2),
+					awsecr.FindingSeverityLow:  aws.Int64(5),
+				},
+			},
+		},
+	}
+	ecr := SDKClient{client: mockClient}
+	uri, _ := ParseImageURI("123456789012.dkr.ecr.us-east-1.amazonaws.com/web:1.0")
+
+	findings, err := ecr.ImageScanFindings(uri)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if findings.Status != awsecr.ScanStatusComplete {
+		t.Errorf("expected status COMPLETE, got %s", findings.Status)
+	}
+
+	if findings.SeverityCounts[awsecr.FindingSeverityHigh] != 2 || findings.SeverityCounts[awsecr.FindingSeverityLow] != 5 {
+		t.Errorf("expected 2 high and 5 low findings, got %v", findings.SeverityCounts)
+	}
+
+	if got := aws.StringValue(mockClient.scanInput.ImageId.ImageTag); got != "1.0" {
+		t.Errorf("expected findings for tag 1.0, got %s", got)
+	}
+}
+
+func TestImageScanFindings_NotFound(t *testing.T) {
+	ecr := SDKClient{client: &mockECRAPI{err: awserr.New(awsecr.ErrCodeScanNotFoundException, "not found", nil)}}
+	uri, _ := ParseImageURI("123456789012.dkr.ecr.us-east-1.amazonaws.com/web")
+
+	if _, err := ecr.ImageScanFindings(uri); err != ErrScanNotFound {
+		t.Errorf("expected ErrScanNotFound, got %v", err)
+	}
+}
+
+func TestEnableScanOnPush(t *testing.T) {
+	mockClient := &mockECRAPI{}
+	ecr := SDKClient{client: mockClient}
+
+	if err := ecr.EnableScanOnPush("123456789012", "web"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !aws.BoolValue(mockClient.putScanInput.ImageScanningConfiguration.ScanOnPush) {
+		t.Errorf("expected scan on push to be enabled")
+	}
+
+	if got := aws.StringValue(mockClient.putScanInput.RepositoryName); got != "web" {
+		t.Errorf("expected repository web, got %s", got)
+	}
+}
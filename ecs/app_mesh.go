@@ -0,0 +1,139 @@
+package ecs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsecs "github.com/aws/aws-sdk-go/service/ecs"
+)
+
+const (
+	appMeshEnvoyContainerName    = "envoy"
+	appMeshDefaultEnvoyImage     = "public.ecr.aws/appmesh/aws-appmesh-envoy:v1.25.1.0-prod"
+	appMeshDefaultIgnoredUID     = 1337
+	appMeshDefaultProxyIngress   = 15000
+	appMeshDefaultProxyEgress    = 15001
+	appMeshEnvoyAdminPort        = 9901
+	appMeshDefaultEgressIgnored  = "169.254.170.2,169.254.169.254"
+	appMeshVirtualNodeArnPattern = ":mesh/"
+)
+
+//AppMesh configures an App Mesh Envoy sidecar and proxy for a task definition
+type AppMesh struct {
+	VirtualNodeArn   string
+	EnvoyImage       string
+	AppPorts         []int64
+	IgnoredUID       int64
+	ProxyIngressPort int64
+	ProxyEgressPort  int64
+}
+
+//SetDefaults fills in the Envoy image, proxy ports and user ID that App Mesh
+//documents as defaults, and routes the container port through the proxy
+func (m *AppMesh) SetDefaults(containerPort int64) {
+	if m.EnvoyImage == "" {
+		m.EnvoyImage = appMeshDefaultEnvoyImage
+	}
+
+	if m.IgnoredUID == 0 {
+		m.IgnoredUID = appMeshDefaultIgnoredUID
+	}
+
+	if m.ProxyIngressPort == 0 {
+		m.ProxyIngressPort = appMeshDefaultProxyIngress
+	}
+
+	if m.ProxyEgressPort == 0 {
+		m.ProxyEgressPort = appMeshDefaultProxyEgress
+	}
+
+	if len(m.AppPorts) == 0 && containerPort != 0 {
+		m.AppPorts = []int64{containerPort}
+	}
+}
+
+//Validate checks the virtual node, ports and user ID required by App Mesh
+func (m *AppMesh) Validate() error {
+	if !strings.HasPrefix(m.VirtualNodeArn, "arn:") || !strings.Contains(m.VirtualNodeArn, appMeshVirtualNodeArnPattern) || !strings.Contains(m.VirtualNodeArn, "/virtualNode/") {
+		return fmt.Errorf("invalid virtual node %s (expected arn:aws:appmesh:<region>:<account>:mesh/<mesh>/virtualNode/<name>)", m.VirtualNodeArn)
+	}
+
+	if len(m.AppPorts) == 0 {
+		return fmt.Errorf("at least one application port is required to route through the Envoy proxy")
+	}
+
+	if m.IgnoredUID <= 0 {
+		return fmt.Errorf("invalid ignored user ID %d", m.IgnoredUID)
+	}
+
+	proxyPorts := []int64{m.ProxyIngressPort, m.ProxyEgressPort, appMeshEnvoyAdminPort}
+
+	for _, port := range append(proxyPorts, m.AppPorts...) {
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("invalid port %d (specify within 1 - 65535)", port)
+		}
+	}
+
+	if m.ProxyIngressPort == m.ProxyEgressPort {
+		return fmt.Errorf("proxy ingress and egress ports must differ (both %d)", m.ProxyIngressPort)
+	}
+
+	for _, appPort := range m.AppPorts {
+		for _, proxyPort := range proxyPorts {
+			if appPort == proxyPort {
+				return fmt.Errorf("application port %d collides with an Envoy proxy port", appPort)
+			}
+		}
+	}
+
+	return nil
+}
+
+//ProxyConfiguration returns the APPMESH proxy configuration for the task definition
+func (m *AppMesh) ProxyConfiguration() *awsecs.ProxyConfiguration {
+	var appPorts []string
+
+	for _, port := range m.AppPorts {
+		appPorts = append(appPorts, strconv.FormatInt(port, 10))
+	}
+
+	properties := []EnvVar{
+		EnvVar{Key: "AppPorts", Value: strings.Join(appPorts, ",")},
+		EnvVar{Key: "EgressIgnoredIPs", Value: appMeshDefaultEgressIgnored},
+		EnvVar{Key: "IgnoredUID", Value: strconv.FormatInt(m.IgnoredUID, 10)},
+		EnvVar{Key: "ProxyEgressPort", Value: strconv.FormatInt(m.ProxyEgressPort, 10)},
+		EnvVar{Key: "ProxyIngressPort", Value: strconv.FormatInt(m.ProxyIngressPort, 10)},
+	}
+
+	return &awsecs.ProxyConfiguration{
+		ContainerName: aws.String(appMeshEnvoyContainerName),
+		Properties:    convertEnvVars(properties),
+		Type:          aws.String(awsecs.ProxyConfigurationTypeAppmesh),
+	}
+}
+
+//EnvoyContainerDefinition returns the Envoy sidecar container definition
+func (m *AppMesh) EnvoyContainerDefinition(logConfiguration *awsecs.LogConfiguration) *awsecs.ContainerDefinition {
+	return &awsecs.ContainerDefinition{
+		Environment: convertEnvVars([]EnvVar{
+			EnvVar{Key: "APPMESH_RESOURCE_ARN", Value: m.VirtualNodeArn},
+		}),
+		Essential: aws.Bool(true),
+		HealthCheck: &awsecs.HealthCheck{
+			Command: aws.StringSlice([]string{
+				"CMD-SHELL",
+				fmt.Sprintf("curl -s http://localhost:%d/server_info | grep state | grep -q LIVE", appMeshEnvoyAdminPort),
+			}),
+			Interval:    aws.Int64(5),
+			Retries:     aws.Int64(3),
+			StartPeriod: aws.Int64(10),
+			Timeout:     aws.Int64(2),
+		},
+		Image:            aws.String(m.EnvoyImage),
+		LogConfiguration: logConfiguration,
+		Name:             aws.String(appMeshEnvoyContainerName),
+		User:             aws.String(strconv.FormatInt(m.IgnoredUID, 10)),
+	}
+}
@@ -0,0 +1,77 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+const virtualNodeArn = "arn:aws:appmesh:us-east-1:000000000000:mesh/my-mesh/virtualNode/my-app"
+
+func TestAppMeshSetDefaults(t *testing.T) {
+	mesh := &AppMesh{VirtualNodeArn: virtualNodeArn}
+	mesh.SetDefaults(8080)
+
+	if err := mesh.Validate(); err != nil {
+		t.Fatalf("expected defaults to be valid, got %v", err)
+	}
+
+	if len(mesh.AppPorts) != 1 || mesh.AppPorts[0] != 8080 {
+		t.Errorf("expected app ports [8080], got %v", mesh.AppPorts)
+	}
+
+	if mesh.IgnoredUID != 1337 {
+		t.Errorf("expected ignored UID 1337, got %d", mesh.IgnoredUID)
+	}
+}
+
+func TestAppMeshValidate(t *testing.T) {
+	var tests = []struct {
+		mesh  AppMesh
+		valid bool
+	}{
+		{AppMesh{VirtualNodeArn: "my-app"}, false},
+		{AppMesh{VirtualNodeArn: "arn:aws:appmesh:us-east-1:000000000000:mesh/my-mesh"}, false},
+		{AppMesh{VirtualNodeArn: virtualNodeArn}, false},
+		{AppMesh{VirtualNodeArn: virtualNodeArn, AppPorts: []int64{15000}}, false},
+		{AppMesh{VirtualNodeArn: virtualNodeArn, AppPorts: []int64{70000}}, false},
+		{AppMesh{VirtualNodeArn: virtualNodeArn, AppPorts: []int64{8080}, IgnoredUID: -1}, false},
+		{AppMesh{VirtualNodeArn: virtualNodeArn, AppPorts: []int64{8080}}, true},
+	}
+
+	for _, test := range tests {
+		mesh := test.mesh
+		mesh.SetDefaults(0)
+
+		err := mesh.Validate()
+
+		if test.valid && err != nil {
+			t.Errorf("expected %+v to be valid, got %v", test.mesh, err)
+		}
+
+		if !test.valid && err == nil {
+			t.Errorf("expected %+v to be invalid", test.mesh)
+		}
+	}
+}
+
+func TestAppMeshProxyConfiguration(t *testing.T) {
+	mesh := &AppMesh{VirtualNodeArn: virtualNodeArn, AppPorts: []int64{8080, 8081}}
+	mesh.SetDefaults(0)
+
+	config := mesh.ProxyConfiguration()
+
+	if aws.StringValue(config.Type) != "APPMESH" {
+		t.Errorf("expected APPMESH proxy type, got %s", aws.StringValue(config.Type))
+	}
+
+	if aws.StringValue(config.ContainerName) != "envoy" {
+		t.Errorf("expected envoy container, got %s", aws.StringValue(config.ContainerName))
+	}
+
+	for _, property := range config.Properties {
+		if aws.StringValue(property.Name) == "AppPorts" && aws.StringValue(property.Value) != "8080,8081" {
+			t.Errorf("expected AppPorts 8080,8081, got %s", aws.StringValue(property.Value))
+		}
+	}
+}
@@ -1,10 +1,105 @@
 package ecs
 
 import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	awsecs "github.com/aws/aws-sdk-go/service/ecs"
 )
 
+//PlatformVersionLatest runs tasks on the most recent Fargate platform version
+const PlatformVersionLatest = "LATEST"
+
+var platformVersionPattern = regexp.MustCompile(`^\d+\.\d+\.\d+$`)
+
+//ValidatePlatformVersion returns an error unless version is empty, LATEST, or
+//a version number such as 1.4.0
+func ValidatePlatformVersion(version string) error {
+	if version == "" || version == PlatformVersionLatest || platformVersionPattern.MatchString(version) {
+		return nil
+	}
+
+	return fmt.Errorf("invalid platform version %s, must be %s or a version such as 1.4.0", version, PlatformVersionLatest)
+}
+
+const (
+	capacityProviderFargate     = "FARGATE"
+	capacityProviderFargateSpot = "FARGATE_SPOT"
+	maxCapacityProviderWeight   = 1000
+	maxCapacityProviderBase     = 100000
+)
+
+//CapacityProviderStrategyItem places tasks on a capacity provider: the first
+//Base tasks, then Weight of every sum-of-weights tasks after that
+type CapacityProviderStrategyItem struct {
+	CapacityProvider string
+	Base             int64
+	Weight           int64
+}
+
+//SpotStrategy returns a capacity provider strategy that runs base tasks on
+//Fargate and the rest on Fargate Spot. With a spotWeight, the rest are split
+//between them, spotWeight Spot tasks for every Fargate task.
+func SpotStrategy(base, spotWeight int64) ([]CapacityProviderStrategyItem, error) {
+	if base < 0 || base > maxCapacityProviderBase {
+		return nil, fmt.Errorf("invalid base %d, must be 0-%d", base, maxCapacityProviderBase)
+	}
+
+	if spotWeight < 0 || spotWeight > maxCapacityProviderWeight {
+		return nil, fmt.Errorf("invalid spot weight %d, must be 0-%d", spotWeight, maxCapacityProviderWeight)
+	}
+
+	var strategy []CapacityProviderStrategyItem
+
+	if spotWeight == 0 {
+		if base > 0 {
+			strategy = append(strategy, CapacityProviderStrategyItem{CapacityProvider: capacityProviderFargate, Base: base})
+		}
+
+		return append(strategy, CapacityProviderStrategyItem{CapacityProvider: capacityProviderFargateSpot, Weight: 1}), nil
+	}
+
+	return []CapacityProviderStrategyItem{
+		{CapacityProvider: capacityProviderFargate, Base: base, Weight: 1},
+		{CapacityProvider: capacityProviderFargateSpot, Weight: spotWeight},
+	}, nil
+}
+
+func capacityProviderStrategy(strategy []CapacityProviderStrategyItem) []*awsecs.CapacityProviderStrategyItem {
+	var items []*awsecs.CapacityProviderStrategyItem
+
+	for _, item := range strategy {
+		items = append(
+			items,
+			&awsecs.CapacityProviderStrategyItem{
+				CapacityProvider: aws.String(item.CapacityProvider),
+				Base:             aws.Int64(item.Base),
+				Weight:           aws.Int64(item.Weight),
+			},
+		)
+	}
+
+	return items
+}
+
+//capacityProviderError explains an error from a request with a capacity
+//provider strategy the cluster isn't set up for
+func (ecs *ECS) capacityProviderError(err error) error {
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == awsecs.ErrCodeInvalidParameterException &&
+		strings.Contains(strings.ToLower(aerr.Message()), "capacity provider") {
+		return errors.New(aerr.Message() + "\n\nAssociate the FARGATE and FARGATE_SPOT capacity providers with the cluster first:\n" +
+			"  aws ecs put-cluster-capacity-providers --cluster " + ecs.ClusterName +
+			" --capacity-providers FARGATE FARGATE_SPOT --default-capacity-provider-strategy capacityProvider=FARGATE,weight=1")
+	}
+
+	return err
+}
+
 func (ecs *ECS) CreateCluster() (string, error) {
 	input := &awsecs.CreateClusterInput{
 		ClusterName: aws.String(ecs.ClusterName),
@@ -14,3 +109,63 @@ func (ecs *ECS) CreateCluster() (string, error) {
 
 	return aws.StringValue(resp.Cluster.ClusterArn), err
 }
+
+//describeClustersLimit is the most clusters DescribeClusters accepts per call
+const describeClustersLimit = 100
+
+//ListClusterNames returns the names of every active cluster in the client's
+//region, sorted by name
+func (ecs *ECS) ListClusterNames() ([]string, error) {
+	var clusterArns []string
+	var names []string
+
+	err := ecs.svc.ListClustersPages(
+		&awsecs.ListClustersInput{},
+		func(resp *awsecs.ListClustersOutput, lastPage bool) bool {
+			clusterArns = append(clusterArns, aws.StringValueSlice(resp.ClusterArns)...)
+			return true
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for start := 0; start < len(clusterArns); start += describeClustersLimit {
+		end := start + describeClustersLimit
+
+		if end > len(clusterArns) {
+			end = len(clusterArns)
+		}
+
+		resp, err := ecs.svc.DescribeClusters(
+			&awsecs.DescribeClustersInput{
+				Clusters: aws.StringSlice(clusterArns[start:end]),
+			},
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		names = append(names, activeClusterNames(resp.Clusters)...)
+	}
+
+	sort.Strings(names)
+
+	return names, nil
+}
+
+//activeClusterNames returns the names of the clusters that haven't been
+//deleted (deleted clusters are listed for a while as INACTIVE)
+func activeClusterNames(clusters []*awsecs.Cluster) []string {
+	var names []string
+
+	for _, cluster := range clusters {
+		if aws.StringValue(cluster.Status) == "ACTIVE" {
+			names = append(names, aws.StringValue(cluster.ClusterName))
+		}
+	}
+
+	return names
+}
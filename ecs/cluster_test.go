@@ -0,0 +1,93 @@
+package ecs
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsecs "github.com/aws/aws-sdk-go/service/ecs"
+)
+
+func TestActiveClusterNames(t *testing.T) {
+	clusters := []*awsecs.Cluster{
+		&awsecs.Cluster{ClusterName: aws.String("web"), Status: aws.String("ACTIVE")},
+		&awsecs.Cluster{ClusterName: aws.String("old"), Status: aws.String("INACTIVE")},
+		&awsecs.Cluster{ClusterName: aws.String("workers"), Status: aws.String("ACTIVE")},
+	}
+
+	if names := activeClusterNames(clusters); !reflect.DeepEqual(names, []string{"web", "workers"}) {
+		t.Errorf("expected [web workers], got %v", names)
+	}
+}
+
+func TestSpotStrategy(t *testing.T) {
+	var tests = []struct {
+		base       int64
+		spotWeight int64
+		strategy   []CapacityProviderStrategyItem
+	}{
+		{0, 0, []CapacityProviderStrategyItem{{CapacityProvider: "FARGATE_SPOT", Weight: 1}}},
+		{2, 0, []CapacityProviderStrategyItem{{CapacityProvider: "FARGATE", Base: 2}, {CapacityProvider: "FARGATE_SPOT", Weight: 1}}},
+		{1, 3, []CapacityProviderStrategyItem{{CapacityProvider: "FARGATE", Base: 1, Weight: 1}, {CapacityProvider: "FARGATE_SPOT", Weight: 3}}},
+	}
+
+	for _, test := range tests {
+		strategy, err := SpotStrategy(test.base, test.spotWeight)
+
+		if err != nil {
+			t.Errorf("SpotStrategy(%d, %d) => %v", test.base, test.spotWeight, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(strategy, test.strategy) {
+			t.Errorf("SpotStrategy(%d, %d) => %+v, want %+v", test.base, test.spotWeight, strategy, test.strategy)
+		}
+	}
+
+	if _, err := SpotStrategy(-1, 0); err == nil {
+		t.Error("expected a negative base to be invalid")
+	}
+
+	if _, err := SpotStrategy(0, 1001); err == nil {
+		t.Error("expected a weight over 1000 to be invalid")
+	}
+}
+
+func TestCapacityProviderError(t *testing.T) {
+	ecs := ECS{ClusterName: "fargate"}
+	err := awserr.New(awsecs.ErrCodeInvalidParameterException,
+		"The specified capacity provider strategy cannot contain a capacity provider that is not associated with the cluster.", nil)
+
+	if got := ecs.capacityProviderError(err); !strings.Contains(got.Error(), "put-cluster-capacity-providers --cluster fargate") {
+		t.Errorf("expected a hint to associate the capacity providers, got %v", got)
+	}
+
+	other := awserr.New(awsecs.ErrCodeInvalidParameterException, "Task definition does not exist", nil)
+
+	if got := ecs.capacityProviderError(other); got != other {
+		t.Errorf("expected other errors to be returned as is, got %v", got)
+	}
+}
+
+func TestValidatePlatformVersion(t *testing.T) {
+	var tests = []struct {
+		version string
+		valid   bool
+	}{
+		{"", true},
+		{"LATEST", true},
+		{"1.4.0", true},
+		{"1.3.0", true},
+		{"latest", false},
+		{"1.4", false},
+		{"v1.4.0", false},
+	}
+
+	for _, test := range tests {
+		if err := ValidatePlatformVersion(test.version); (err == nil) != test.valid {
+			t.Errorf("ValidatePlatformVersion(%q) => %v, want valid %t", test.version, err, test.valid)
+		}
+	}
+}
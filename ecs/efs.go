@@ -0,0 +1,184 @@
+package ecs
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsecs "github.com/aws/aws-sdk-go/service/ecs"
+)
+
+//efsMinPlatformMajor and efsMinPlatformMinor are the first Fargate platform
+//version to support EFS volumes, 1.4.0
+const (
+	efsMinPlatformMajor = 1
+	efsMinPlatformMinor = 4
+)
+
+var (
+	efsFileSystemIDRegexp  = regexp.MustCompile(`^fs-[0-9a-f]{8,40}$`)
+	efsAccessPointIDRegexp = regexp.MustCompile(`^fsap-[0-9a-f]{8,40}$`)
+)
+
+//EFSVolume is an EFS file system mounted into a task's container. Mounting
+//through an access point or with IAM authorization requires encryption in
+//transit, so it's enabled whenever either is used.
+type EFSVolume struct {
+	FileSystemID  string
+	ContainerPath string
+	AccessPointID string
+	IAM           bool
+}
+
+//ParseEFSVolume parses fsid:/container/path[:accesspointid], e.g.
+//fs-12345678:/data:fsap-0123456789abcdef0
+func ParseEFSVolume(value string) (EFSVolume, error) {
+	parts := strings.Split(value, ":")
+
+	if len(parts) < 2 || len(parts) > 3 {
+		return EFSVolume{}, fmt.Errorf("invalid EFS volume %s [expected fsid:/container/path[:accesspointid]]", value)
+	}
+
+	volume := EFSVolume{
+		FileSystemID:  parts[0],
+		ContainerPath: parts[1],
+	}
+
+	if len(parts) == 3 {
+		volume.AccessPointID = parts[2]
+	}
+
+	return volume, volume.Validate()
+}
+
+//Validate checks the file system and access point IDs and that the container
+//path is absolute
+func (v EFSVolume) Validate() error {
+	if !efsFileSystemIDRegexp.MatchString(v.FileSystemID) {
+		return fmt.Errorf("invalid EFS file system ID %s [e.g. fs-12345678]", v.FileSystemID)
+	}
+
+	if !path.IsAbs(v.ContainerPath) {
+		return fmt.Errorf("invalid EFS container path %s [must be an absolute path]", v.ContainerPath)
+	}
+
+	if v.AccessPointID != "" && !efsAccessPointIDRegexp.MatchString(v.AccessPointID) {
+		return fmt.Errorf("invalid EFS access point ID %s [e.g. fsap-0123456789abcdef0]", v.AccessPointID)
+	}
+
+	return nil
+}
+
+//ValidateEFSPlatformVersion returns an error if a platform version predates
+//1.4.0, the first to support EFS volumes. An empty version and LATEST are
+//always recent enough.
+func ValidateEFSPlatformVersion(version string) error {
+	if err := ValidatePlatformVersion(version); err != nil {
+		return err
+	}
+
+	if version == "" || version == PlatformVersionLatest {
+		return nil
+	}
+
+	parts := strings.Split(version, ".")
+	major, _ := strconv.Atoi(parts[0])
+	minor, _ := strconv.Atoi(parts[1])
+
+	if major < efsMinPlatformMajor || (major == efsMinPlatformMajor && minor < efsMinPlatformMinor) {
+		return fmt.Errorf("platform version %s doesn't support EFS volumes [requires %d.%d.0 or later]", version, efsMinPlatformMajor, efsMinPlatformMinor)
+	}
+
+	return nil
+}
+
+//Name returns the name of the task definition volume, which is unique per
+//file system and access point
+func (v EFSVolume) Name() string {
+	if v.AccessPointID != "" {
+		return fmt.Sprintf("efs-%s-%s", v.FileSystemID, v.AccessPointID)
+	}
+
+	return "efs-" + v.FileSystemID
+}
+
+//Volume returns the task definition volume for the file system
+func (v EFSVolume) Volume() *awsecs.Volume {
+	configuration := &awsecs.EFSVolumeConfiguration{
+		FileSystemId: aws.String(v.FileSystemID),
+	}
+
+	if v.AccessPointID != "" || v.IAM {
+		authorization := &awsecs.EFSAuthorizationConfig{
+			Iam: aws.String(awsecs.EFSAuthorizationConfigIAMDisabled),
+		}
+
+		if v.AccessPointID != "" {
+			authorization.SetAccessPointId(v.AccessPointID)
+		}
+
+		if v.IAM {
+			authorization.SetIam(awsecs.EFSAuthorizationConfigIAMEnabled)
+		}
+
+		configuration.SetAuthorizationConfig(authorization)
+		configuration.SetTransitEncryption(awsecs.EFSTransitEncryptionEnabled)
+	}
+
+	return &awsecs.Volume{
+		Name:                   aws.String(v.Name()),
+		EfsVolumeConfiguration: configuration,
+	}
+}
+
+//MountPoint returns the container mount point for the file system
+func (v EFSVolume) MountPoint() *awsecs.MountPoint {
+	return &awsecs.MountPoint{
+		ContainerPath: aws.String(v.ContainerPath),
+		ReadOnly:      aws.Bool(false),
+		SourceVolume:  aws.String(v.Name()),
+	}
+}
+
+//FileSystemArn returns the ARN of the file system in the given region and
+//account, which IAM policies grant access to
+func (v EFSVolume) FileSystemArn(partition, region, account string) string {
+	return fmt.Sprintf("arn:%s:elasticfilesystem:%s:%s:file-system/%s", partition, region, account, v.FileSystemID)
+}
+
+//setEFSVolumes replaces the volumes and mount points with the same names as
+//the EFS volumes, or appends them when there are none. A container path can
+//only be mounted once, so a mount point at the same path is replaced too.
+func setEFSVolumes(volumes []*awsecs.Volume, container *awsecs.ContainerDefinition, efsVolumes []EFSVolume) []*awsecs.Volume {
+	for _, efsVolume := range efsVolumes {
+		volume := efsVolume.Volume()
+		match := false
+
+		for i, existing := range volumes {
+			if aws.StringValue(existing.Name) == efsVolume.Name() {
+				volumes[i] = volume
+				match = true
+				break
+			}
+		}
+
+		if !match {
+			volumes = append(volumes, volume)
+		}
+
+		var mountPoints []*awsecs.MountPoint
+
+		for _, mountPoint := range container.MountPoints {
+			if aws.StringValue(mountPoint.ContainerPath) != efsVolume.ContainerPath {
+				mountPoints = append(mountPoints, mountPoint)
+			}
+		}
+
+		container.MountPoints = append(mountPoints, efsVolume.MountPoint())
+	}
+
+	return volumes
+}
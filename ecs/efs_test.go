@@ -0,0 +1,121 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsecs "github.com/aws/aws-sdk-go/service/ecs"
+)
+
+func TestParseEFSVolume(t *testing.T) {
+	var tests = []struct {
+		value  string
+		volume EFSVolume
+		valid  bool
+	}{
+		{"fs-12345678:/data", EFSVolume{FileSystemID: "fs-12345678", ContainerPath: "/data"}, true},
+		{"fs-12345678:/data:fsap-0123456789abcdef0", EFSVolume{FileSystemID: "fs-12345678", ContainerPath: "/data", AccessPointID: "fsap-0123456789abcdef0"}, true},
+		{"fs-12345678", EFSVolume{}, false},
+		{"fs-12345678:data", EFSVolume{}, false},
+		{"12345678:/data", EFSVolume{}, false},
+		{"fs-12345678:/data:ap-1", EFSVolume{}, false},
+		{"fs-12345678:/data:fsap-0123456789abcdef0:extra", EFSVolume{}, false},
+	}
+
+	for _, test := range tests {
+		volume, err := ParseEFSVolume(test.value)
+
+		if test.valid && err != nil {
+			t.Errorf("expected %s to be valid, got %v", test.value, err)
+		}
+
+		if !test.valid && err == nil {
+			t.Errorf("expected %s to be invalid", test.value)
+		}
+
+		if test.valid && volume != test.volume {
+			t.Errorf("expected %+v, got %+v", test.volume, volume)
+		}
+	}
+}
+
+func TestValidateEFSPlatformVersion(t *testing.T) {
+	var tests = []struct {
+		version string
+		valid   bool
+	}{
+		{"", true},
+		{"LATEST", true},
+		{"1.4.0", true},
+		{"1.10.0", true},
+		{"2.0.0", true},
+		{"1.3.0", false},
+		{"1.0.0", false},
+		{"1.4", false},
+	}
+
+	for _, test := range tests {
+		if err := ValidateEFSPlatformVersion(test.version); (err == nil) != test.valid {
+			t.Errorf("ValidateEFSPlatformVersion(%q) => %v, want valid %t", test.version, err, test.valid)
+		}
+	}
+}
+
+func TestEFSVolumeVolume(t *testing.T) {
+	volume := EFSVolume{FileSystemID: "fs-12345678", ContainerPath: "/data"}.Volume()
+
+	if aws.StringValue(volume.Name) != "efs-fs-12345678" {
+		t.Errorf("expected efs-fs-12345678, got %s", aws.StringValue(volume.Name))
+	}
+
+	if volume.EfsVolumeConfiguration.AuthorizationConfig != nil || volume.EfsVolumeConfiguration.TransitEncryption != nil {
+		t.Errorf("expected no authorization or transit encryption, got %s", volume.EfsVolumeConfiguration)
+	}
+
+	volume = EFSVolume{FileSystemID: "fs-12345678", ContainerPath: "/data", AccessPointID: "fsap-0123456789abcdef0", IAM: true}.Volume()
+	configuration := volume.EfsVolumeConfiguration
+
+	if aws.StringValue(configuration.AuthorizationConfig.AccessPointId) != "fsap-0123456789abcdef0" {
+		t.Errorf("expected access point fsap-0123456789abcdef0, got %s", configuration.AuthorizationConfig)
+	}
+
+	if aws.StringValue(configuration.AuthorizationConfig.Iam) != awsecs.EFSAuthorizationConfigIAMEnabled {
+		t.Errorf("expected IAM authorization, got %s", configuration.AuthorizationConfig)
+	}
+
+	if aws.StringValue(configuration.TransitEncryption) != awsecs.EFSTransitEncryptionEnabled {
+		t.Errorf("expected transit encryption, got %s", configuration)
+	}
+}
+
+func TestSetEFSVolumes(t *testing.T) {
+	container := &awsecs.ContainerDefinition{
+		MountPoints: []*awsecs.MountPoint{
+			&awsecs.MountPoint{ContainerPath: aws.String("/data"), SourceVolume: aws.String("old")},
+			&awsecs.MountPoint{ContainerPath: aws.String("/cache"), SourceVolume: aws.String("cache")},
+		},
+	}
+	volumes := []*awsecs.Volume{
+		&awsecs.Volume{Name: aws.String("old")},
+		&awsecs.Volume{Name: aws.String("cache")},
+	}
+	efsVolume := EFSVolume{FileSystemID: "fs-12345678", ContainerPath: "/data"}
+
+	volumes = setEFSVolumes(volumes, container, []EFSVolume{efsVolume})
+
+	if len(volumes) != 3 || aws.StringValue(volumes[2].Name) != efsVolume.Name() {
+		t.Errorf("expected the EFS volume to be appended, got %v", volumes)
+	}
+
+	if len(container.MountPoints) != 2 ||
+		aws.StringValue(container.MountPoints[0].SourceVolume) != "cache" ||
+		aws.StringValue(container.MountPoints[1].SourceVolume) != efsVolume.Name() {
+		t.Errorf("expected /data to be mounted from the EFS volume, got %v", container.MountPoints)
+	}
+
+	volumes = setEFSVolumes(volumes, container, []EFSVolume{efsVolume})
+
+	if len(volumes) != 3 || len(container.MountPoints) != 2 {
+		t.Errorf("expected setting the same EFS volume again to replace it, got %v and %v", volumes, container.MountPoints)
+	}
+}
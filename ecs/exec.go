@@ -0,0 +1,103 @@
+package ecs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsecs "github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/turnerlabs/fargate/arn"
+)
+
+//ExecSession is an ECS Exec session into a task's container. The Session
+//Manager plugin attaches to it to relay the command's input and output.
+type ExecSession struct {
+	Session  *awsecs.Session
+	Endpoint string
+	Region   string
+
+	//Target identifies the container to Session Manager, in the form
+	//ecs:<cluster>_<task id>_<container runtime id>
+	Target string
+}
+
+//ExecuteCommand starts an interactive command in a running task's container.
+//The task must have been started with execute command enabled. The container
+//can be omitted for tasks with a single container.
+func (ecs *ECS) ExecuteCommand(taskID, containerName, command string) (*ExecSession, error) {
+	resp, err := ecs.svc.DescribeTasks(
+		&awsecs.DescribeTasksInput{
+			Cluster: aws.String(ecs.ClusterName),
+			Tasks:   aws.StringSlice([]string{taskID}),
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Tasks) == 0 {
+		return nil, fmt.Errorf("task %s not found in cluster %s", taskID, ecs.ClusterName)
+	}
+
+	container, err := execContainer(resp.Tasks[0], containerName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := ecs.svc.ExecuteCommand(
+		&awsecs.ExecuteCommandInput{
+			Cluster:     aws.String(ecs.ClusterName),
+			Command:     aws.String(command),
+			Container:   container.Name,
+			Interactive: aws.Bool(true),
+			Task:        aws.String(taskID),
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExecSession{
+		Session:  out.Session,
+		Endpoint: ecs.svc.Endpoint,
+		Region:   ecs.Region(),
+		Target:   fmt.Sprintf("ecs:%s_%s_%s", ecs.ClusterName, taskID, aws.StringValue(container.RuntimeId)),
+	}, nil
+}
+
+//execContainer returns the running container in a task to execute a command
+//in, by name, or the only container if no name is given
+func execContainer(task *awsecs.Task, containerName string) (*awsecs.Container, error) {
+	taskID := arn.ResourceName(aws.StringValue(task.TaskArn))
+
+	if !aws.BoolValue(task.EnableExecuteCommand) {
+		return nil, fmt.Errorf("task %s wasn't started with execute command enabled (for service tasks, see service update --enable-execute-command)", taskID)
+	}
+
+	if aws.StringValue(task.LastStatus) != awsecs.DesiredStatusRunning {
+		return nil, fmt.Errorf("task %s is %s, commands can only be executed in running tasks", taskID, aws.StringValue(task.LastStatus))
+	}
+
+	var names []string
+
+	for _, container := range task.Containers {
+		names = append(names, aws.StringValue(container.Name))
+
+		if aws.StringValue(container.Name) == containerName {
+			return container, nil
+		}
+	}
+
+	if containerName == "" && len(task.Containers) == 1 {
+		return task.Containers[0], nil
+	}
+
+	if containerName == "" {
+		return nil, fmt.Errorf("task %s has more than one container, choose one of: %s", taskID, strings.Join(names, ", "))
+	}
+
+	return nil, fmt.Errorf("task %s has no container %s, choose one of: %s", taskID, containerName, strings.Join(names, ", "))
+}
@@ -0,0 +1,47 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsecs "github.com/aws/aws-sdk-go/service/ecs"
+)
+
+func TestExecContainer(t *testing.T) {
+	app := &awsecs.Container{Name: aws.String("app"), RuntimeId: aws.String("abc-123")}
+	sidecar := &awsecs.Container{Name: aws.String("datadog"), RuntimeId: aws.String("def-456")}
+	task := func(enabled bool, status string, containers ...*awsecs.Container) *awsecs.Task {
+		return &awsecs.Task{
+			Containers:           containers,
+			EnableExecuteCommand: aws.Bool(enabled),
+			LastStatus:           aws.String(status),
+			TaskArn:              aws.String("arn:aws:ecs:us-east-1:123456789012:task/my-cluster/0123456789abcdef"),
+		}
+	}
+
+	var tests = []struct {
+		name          string
+		task          *awsecs.Task
+		containerName string
+		expected      *awsecs.Container
+	}{
+		{"only container", task(true, "RUNNING", app), "", app},
+		{"named container", task(true, "RUNNING", app, sidecar), "datadog", sidecar},
+		{"ambiguous container", task(true, "RUNNING", app, sidecar), "", nil},
+		{"unknown container", task(true, "RUNNING", app), "envoy", nil},
+		{"execute command disabled", task(false, "RUNNING", app), "", nil},
+		{"not running", task(true, "STOPPED", app), "", nil},
+	}
+
+	for _, test := range tests {
+		container, err := execContainer(test.task, test.containerName)
+
+		if test.expected == nil && err == nil {
+			t.Errorf("%s: expected error, got none", test.name)
+		}
+
+		if test.expected != nil && container != test.expected {
+			t.Errorf("%s: expected container %s, got %v (%v)", test.name, aws.StringValue(test.expected.Name), container, err)
+		}
+	}
+}
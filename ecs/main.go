@@ -1,6 +1,7 @@
 package ecs
 
 import (
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ecs"
 )
@@ -8,11 +9,53 @@ import (
 type ECS struct {
 	svc         *ecs.ECS
 	ClusterName string
+
+	//Namespace scopes task groups so teams sharing a cluster don't see each
+	//other's tasks (tasks are started by fargate:<namespace>:<task group>)
+	Namespace string
+}
+
+//Config selects the cluster, task group namespace, and region a client works
+//with, so clients for several clusters or regions can be used side by side
+type Config struct {
+	ClusterName string
+	Namespace   string
+
+	//Region overrides the session's region when set
+	Region string
 }
 
 func New(sess *session.Session, clusterName string) ECS {
+	return NewWithConfig(sess, Config{ClusterName: clusterName})
+}
+
+//NewWithConfig returns a client for the cluster and region in config rather
+//than the session's region
+func NewWithConfig(sess *session.Session, config Config) ECS {
+	awsConfig := aws.NewConfig()
+
+	if config.Region != "" {
+		awsConfig = awsConfig.WithRegion(config.Region)
+	}
+
 	return ECS{
-		ClusterName: clusterName,
-		svc:         ecs.New(sess),
+		ClusterName: config.ClusterName,
+		Namespace:   config.Namespace,
+		svc:         ecs.New(sess, awsConfig),
+	}
+}
+
+//Region returns the region the client makes requests to
+func (ecs *ECS) Region() string {
+	return aws.StringValue(ecs.svc.Config.Region)
+}
+
+func containsString(slice []string, element string) bool {
+	for _, elem := range slice {
+		if elem == element {
+			return true
+		}
 	}
+
+	return false
 }
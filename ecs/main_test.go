@@ -0,0 +1,24 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+func TestNewWithConfig(t *testing.T) {
+	sess := session.Must(session.NewSession(&aws.Config{Region: aws.String("us-east-1")}))
+
+	ecs := New(sess, "fargate")
+
+	if ecs.ClusterName != "fargate" || ecs.Region() != "us-east-1" {
+		t.Errorf("expected fargate in us-east-1, got %s in %s", ecs.ClusterName, ecs.Region())
+	}
+
+	ecs = NewWithConfig(sess, Config{ClusterName: "web", Namespace: "staging", Region: "eu-west-1"})
+
+	if ecs.ClusterName != "web" || ecs.Namespace != "staging" || ecs.Region() != "eu-west-1" {
+		t.Errorf("expected web (staging) in eu-west-1, got %s (%s) in %s", ecs.ClusterName, ecs.Namespace, ecs.Region())
+	}
+}
@@ -2,6 +2,8 @@ package ecs
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -10,6 +12,12 @@ import (
 	"github.com/turnerlabs/fargate/console"
 )
 
+var validPropagateTags = []string{
+	awsecs.PropagateTagsService,
+	awsecs.PropagateTagsTaskDefinition,
+	awsecs.PropagateTagsNone,
+}
+
 type CreateServiceInput struct {
 	Cluster           string
 	DesiredCount      int64
@@ -21,6 +29,20 @@ type CreateServiceInput struct {
 	TaskDefinitionArn string
 }
 
+//ValidatePropagateTags checks where a service's tasks copy their tags from is
+//one ECS accepts. An empty value is valid.
+//
+//SERVICE copies the tags on the service itself, TASK_DEFINITION copies the tags
+//on the task definition revision the task was launched from, and NONE copies
+//no tags.
+func ValidatePropagateTags(propagateTags string) error {
+	if propagateTags != "" && !containsString(validPropagateTags, propagateTags) {
+		return fmt.Errorf("invalid tag propagation %s [valid values: %s]", propagateTags, strings.Join(validPropagateTags, ", "))
+	}
+
+	return nil
+}
+
 type ServiceRegistry struct {
 	ContainerName string
 	ContainerPort int64
@@ -29,25 +51,49 @@ type ServiceRegistry struct {
 }
 
 type Service struct {
-	Cluster           string
-	Cpu               string
-	Deployments       []Deployment
-	DesiredCount      int64
-	EnvVars           []EnvVar
-	Events            []Event
-	Image             string
-	Memory            string
-	Name              string
-	PendingCount      int64
-	RunningCount      int64
-	SecurityGroupIds  []string
-	ServiceRegistries []ServiceRegistry
-	TargetGroupArn    string
-	TaskDefinitionArn string
-	TaskRole          string
-	SecretVars        []EnvVar
-	SubnetIds         []string
-	Status            string
+	CircuitBreaker       bool
+	Cluster              string
+	ContainerName        string
+	Cpu                  string
+	DeploymentController string
+	Deployments          []Deployment
+	DesiredCount         int64
+	EnableExecuteCommand bool
+	EnableManagedTags    bool
+	EnvVars              []EnvVar
+	Events               []Event
+	HealthCheck          *ContainerHealthCheck
+	Image                string
+	Memory               string
+	Name                 string
+	PendingCount         int64
+	PropagateTags        string
+	RunningCount         int64
+	SecurityGroupIds     []string
+	ServiceRegistries    []ServiceRegistry
+	TargetGroupArn       string
+	TaskDefinitionArn    string
+	TaskRole             string
+	SecretVars           []EnvVar
+	SubnetIds            []string
+	Status               string
+
+	HealthCheckGracePeriodSeconds int64
+}
+
+//IsDeployedByECS returns whether the service uses ECS rolling updates, as
+//opposed to CodeDeploy or an external deployment controller
+func (s *Service) IsDeployedByECS() bool {
+	return s.DeploymentController == "" || s.DeploymentController == awsecs.DeploymentControllerTypeEcs
+}
+
+//ContainerHealthCheck is the health check ECS runs in a service's primary
+//container, which decides whether its tasks are healthy
+type ContainerHealthCheck struct {
+	IntervalSeconds    int64
+	Retries            int64
+	StartPeriodSeconds int64
+	TimeoutSeconds     int64
 }
 
 type Event struct {
@@ -55,14 +101,57 @@ type Event struct {
 	Message   string
 }
 
+//deploymentStatusPrimary is the status of the deployment ECS is rolling out or
+//has rolled out, the SDK has no constant for it
+const deploymentStatusPrimary = "PRIMARY"
+
+//failureEventPattern matches the service events that report something going
+//wrong, e.g. "(service web) (task 1234) failed ELB health checks in (target-group
+//...)" or "(service web) was unable to place a task because no container
+//instance met all of its requirements", as opposed to routine events about
+//starting and stopping tasks or reaching a steady state
+var failureEventPattern = regexp.MustCompile(`(?i)unable to|fail|unhealthy|error|insufficient|circuit breaker|roll(ed|ing) back|timed out|throttl|exceeded|denied|not authorized`)
+
+//IsFailure returns whether the event reports a problem with the service
+func (e Event) IsFailure() bool {
+	return failureEventPattern.MatchString(e.Message)
+}
+
+//FailureEvents returns the events that report problems with a service, newest
+//first, leaving out those before since unless it is zero
+func FailureEvents(events []Event, since time.Time) []Event {
+	var failures []Event
+
+	for _, event := range events {
+		if event.IsFailure() && !event.CreatedAt.Before(since) {
+			failures = append(failures, event)
+		}
+	}
+
+	return failures
+}
+
 type Deployment struct {
-	CreatedAt    time.Time
-	DesiredCount int64
-	Id           string
-	Image        string
-	PendingCount int64
-	RunningCount int64
-	Status       string
+	CreatedAt         time.Time
+	DesiredCount      int64
+	Id                string
+	Image             string
+	PendingCount      int64
+	RunningCount      int64
+	Status            string
+	TaskDefinitionArn string
+}
+
+//PrimaryDeployment returns the deployment of the service's most recent task
+//definition, or nil if the service has none
+func (s *Service) PrimaryDeployment() *Deployment {
+	for i := range s.Deployments {
+		if s.Deployments[i].Status == deploymentStatusPrimary {
+			return &s.Deployments[i]
+		}
+	}
+
+	return nil
 }
 
 func (s *Service) AddEvent(e Event) {
@@ -190,6 +279,63 @@ func (ecs *ECS) ListServices() []Service {
 	return services
 }
 
+//describeServicesLimit is the most services DescribeServices accepts per call
+const describeServicesLimit = 10
+
+//ListServiceTaskDefinitions returns the task definitions used by the
+//deployments of every service in the cluster, whatever its launch type or
+//capacity provider strategy (unlike ListServices, which only lists services
+//with the FARGATE launch type)
+func (ecs *ECS) ListServiceTaskDefinitions() ([]string, error) {
+	var serviceArns []string
+	var taskDefinitionArns []string
+
+	err := ecs.svc.ListServicesPages(
+		&awsecs.ListServicesInput{
+			Cluster: aws.String(ecs.ClusterName),
+		},
+		func(resp *awsecs.ListServicesOutput, lastPage bool) bool {
+			serviceArns = append(serviceArns, aws.StringValueSlice(resp.ServiceArns)...)
+			return true
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for start := 0; start < len(serviceArns); start += describeServicesLimit {
+		end := start + describeServicesLimit
+
+		if end > len(serviceArns) {
+			end = len(serviceArns)
+		}
+
+		resp, err := ecs.svc.DescribeServices(
+			&awsecs.DescribeServicesInput{
+				Cluster:  aws.String(ecs.ClusterName),
+				Services: aws.StringSlice(serviceArns[start:end]),
+			},
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(resp.Failures) > 0 {
+			return nil, fmt.Errorf("could not describe service %s: %s", aws.StringValue(resp.Failures[0].Arn), aws.StringValue(resp.Failures[0].Reason))
+		}
+
+		for _, service := range resp.Services {
+			for _, deployment := range service.Deployments {
+				taskDefinitionArns = append(taskDefinitionArns, aws.StringValue(deployment.TaskDefinition))
+			}
+		}
+	}
+
+	return taskDefinitionArns, nil
+}
+
 func (ecs *ECS) DescribeServices(serviceArns []string) []Service {
 	var services []Service
 
@@ -216,11 +362,24 @@ func (ecs *ECS) DescribeServices(serviceArns []string) []Service {
 			DesiredCount:      aws.Int64Value(service.DesiredCount),
 			Name:              aws.StringValue(service.ServiceName),
 			PendingCount:      aws.Int64Value(service.PendingCount),
+			PropagateTags:     aws.StringValue(service.PropagateTags),
 			RunningCount:      aws.Int64Value(service.RunningCount),
 			SecurityGroupIds:  aws.StringValueSlice(securityGroupIds),
 			Status:            aws.StringValue(service.Status),
 			SubnetIds:         aws.StringValueSlice(subnetIds),
 			TaskDefinitionArn: aws.StringValue(service.TaskDefinition),
+
+			EnableExecuteCommand:          aws.BoolValue(service.EnableExecuteCommand),
+			EnableManagedTags:             aws.BoolValue(service.EnableECSManagedTags),
+			HealthCheckGracePeriodSeconds: aws.Int64Value(service.HealthCheckGracePeriodSeconds),
+		}
+
+		if service.DeploymentController != nil {
+			s.DeploymentController = aws.StringValue(service.DeploymentController.Type)
+		}
+
+		if config := service.DeploymentConfiguration; config != nil && config.DeploymentCircuitBreaker != nil {
+			s.CircuitBreaker = aws.BoolValue(config.DeploymentCircuitBreaker.Enable)
 		}
 
 		taskDefinition := ecs.DescribeTaskDefinition(aws.StringValue(service.TaskDefinition)).TaskDefinition
@@ -230,6 +389,7 @@ func (ecs *ECS) DescribeServices(serviceArns []string) []Service {
 		s.TaskRole = aws.StringValue(taskDefinition.TaskRoleArn)
 
 		if len(service.LoadBalancers) > 0 {
+			s.ContainerName = aws.StringValue(service.LoadBalancers[0].ContainerName)
 			s.TargetGroupArn = aws.StringValue(service.LoadBalancers[0].TargetGroupArn)
 		}
 
@@ -245,10 +405,19 @@ func (ecs *ECS) DescribeServices(serviceArns []string) []Service {
 			)
 		}
 
-		if len(taskDefinition.ContainerDefinitions) > 0 {
-			s.Image = aws.StringValue(taskDefinition.ContainerDefinitions[0].Image)
+		if container := PrimaryContainerDefinition(taskDefinition, s.ContainerName); container != nil {
+			s.Image = aws.StringValue(container.Image)
+
+			if healthCheck := container.HealthCheck; healthCheck != nil {
+				s.HealthCheck = &ContainerHealthCheck{
+					IntervalSeconds:    aws.Int64Value(healthCheck.Interval),
+					Retries:            aws.Int64Value(healthCheck.Retries),
+					StartPeriodSeconds: aws.Int64Value(healthCheck.StartPeriod),
+					TimeoutSeconds:     aws.Int64Value(healthCheck.Timeout),
+				}
+			}
 
-			for _, env := range taskDefinition.ContainerDefinitions[0].Environment {
+			for _, env := range container.Environment {
 				s.EnvVars = append(
 					s.EnvVars,
 					EnvVar{
@@ -258,7 +427,7 @@ func (ecs *ECS) DescribeServices(serviceArns []string) []Service {
 				)
 			}
 
-			for _, secret := range taskDefinition.ContainerDefinitions[0].Secrets {
+			for _, secret := range container.Secrets {
 				s.SecretVars = append(
 					s.SecretVars,
 					EnvVar{
@@ -286,10 +455,15 @@ func (ecs *ECS) DescribeServices(serviceArns []string) []Service {
 				RunningCount: aws.Int64Value(d.RunningCount),
 				CreatedAt:    aws.TimeValue(d.CreatedAt),
 				Id:           ecs.GetRevisionNumber(aws.StringValue(d.TaskDefinition)),
+
+				TaskDefinitionArn: aws.StringValue(d.TaskDefinition),
 			}
 
 			deploymentTaskDefinition := ecs.DescribeTaskDefinition(aws.StringValue(d.TaskDefinition)).TaskDefinition
-			deployment.Image = aws.StringValue(deploymentTaskDefinition.ContainerDefinitions[0].Image)
+
+			if container := PrimaryContainerDefinition(deploymentTaskDefinition, s.ContainerName); container != nil {
+				deployment.Image = aws.StringValue(container.Image)
+			}
 
 			s.AddDeployment(deployment)
 		}
@@ -314,6 +488,49 @@ func (ecs *ECS) UpdateServiceTaskDefinition(serviceName, taskDefinitionArn strin
 	}
 }
 
+//SetEnableExecuteCommand turns ECS Exec on or off for a service. Only tasks
+//started afterwards are affected, so a new deployment replaces the running ones.
+func (ecs *ECS) SetEnableExecuteCommand(serviceName string, enabled bool) {
+	_, err := ecs.svc.UpdateService(
+		&awsecs.UpdateServiceInput{
+			Cluster:              aws.String(ecs.ClusterName),
+			Service:              aws.String(serviceName),
+			EnableExecuteCommand: aws.Bool(enabled),
+			ForceNewDeployment:   aws.Bool(true),
+		},
+	)
+
+	if err != nil {
+		console.ErrorExit(err, "Could not update ECS service execute command setting")
+	}
+}
+
+//SetTagPropagation sets whether ECS adds its managed tags to a service's tasks
+//and where the tasks copy their other tags from. Only tasks started afterwards
+//are tagged, so a new deployment replaces the running ones.
+func (ecs *ECS) SetTagPropagation(serviceName string, enableManagedTags bool, propagateTags string) {
+	if err := ValidatePropagateTags(propagateTags); err != nil {
+		console.ErrorExit(err, "Invalid tag propagation")
+	}
+
+	input := &awsecs.UpdateServiceInput{
+		Cluster:              aws.String(ecs.ClusterName),
+		Service:              aws.String(serviceName),
+		EnableECSManagedTags: aws.Bool(enableManagedTags),
+		ForceNewDeployment:   aws.Bool(true),
+	}
+
+	if propagateTags != "" {
+		input.SetPropagateTags(propagateTags)
+	}
+
+	_, err := ecs.svc.UpdateService(input)
+
+	if err != nil {
+		console.ErrorExit(err, "Could not update ECS service tag propagation")
+	}
+}
+
 func (ecs *ECS) RestartService(serviceName string) {
 	_, err := ecs.svc.UpdateService(
 		&awsecs.UpdateServiceInput{
@@ -338,7 +555,15 @@ func (ecs *ECS) RestartService(serviceName string) {
 }
 
 func (ecs *ECS) WaitUntilServiceStable(serviceName string) {
-	err := ecs.svc.WaitUntilServicesStable(
+	if err := ecs.WaitUntilServiceStableWithContext(aws.BackgroundContext(), serviceName); err != nil {
+		console.ErrorExit(err, "Could not wait for ECS service to reach a steady state")
+	}
+}
+
+//WaitUntilServiceInactive waits for a deleted service to finish draining its
+//tasks and become inactive
+func (ecs *ECS) WaitUntilServiceInactive(serviceName string) {
+	err := ecs.svc.WaitUntilServicesInactive(
 		&awsecs.DescribeServicesInput{
 			Cluster:  aws.String(ecs.ClusterName),
 			Services: aws.StringSlice([]string{serviceName}),
@@ -346,6 +571,18 @@ func (ecs *ECS) WaitUntilServiceStable(serviceName string) {
 	)
 
 	if err != nil {
-		console.ErrorExit(err, "Could not wait for ECS service to reach a steady state")
+		console.ErrorExit(err, "Could not wait for ECS service to become inactive")
 	}
 }
+
+//WaitUntilServiceStableWithContext waits for a service to reach a steady
+//state, returning early with an error if the context is cancelled
+func (ecs *ECS) WaitUntilServiceStableWithContext(ctx aws.Context, serviceName string) error {
+	return ecs.svc.WaitUntilServicesStableWithContext(
+		ctx,
+		&awsecs.DescribeServicesInput{
+			Cluster:  aws.String(ecs.ClusterName),
+			Services: aws.StringSlice([]string{serviceName}),
+		},
+	)
+}
@@ -0,0 +1,201 @@
+package ecs
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	awsecs "github.com/aws/aws-sdk-go/service/ecs"
+)
+
+func TestValidatePropagateTags(t *testing.T) {
+	var tests = []struct {
+		propagateTags string
+		valid         bool
+	}{
+		{"", true},
+		{"SERVICE", true},
+		{"TASK_DEFINITION", true},
+		{"NONE", true},
+		{"TASK", false},
+		{"service", false},
+	}
+
+	for _, test := range tests {
+		err := ValidatePropagateTags(test.propagateTags)
+
+		if test.valid && err != nil {
+			t.Errorf("expected %q to be valid, got %v", test.propagateTags, err)
+		}
+
+		if !test.valid && err == nil {
+			t.Errorf("expected %q to be invalid", test.propagateTags)
+		}
+	}
+}
+
+func TestServiceIsDeployedByECS(t *testing.T) {
+	var tests = []struct {
+		deploymentController string
+		deployedByECS        bool
+	}{
+		{"", true},
+		{"ECS", true},
+		{"CODE_DEPLOY", false},
+		{"EXTERNAL", false},
+	}
+
+	for _, test := range tests {
+		service := Service{DeploymentController: test.deploymentController}
+
+		if got := service.IsDeployedByECS(); got != test.deployedByECS {
+			t.Errorf("IsDeployedByECS() with %q => %t, want %t", test.deploymentController, got, test.deployedByECS)
+		}
+	}
+}
+
+func TestEventIsFailure(t *testing.T) {
+	var tests = []struct {
+		message string
+		failure bool
+	}{
+		{"(service web) has reached a steady state.", false},
+		{"(service web) has started 2 tasks: (task 0123456789abcdef).", false},
+		{"(service web) has stopped 1 running tasks: (task 0123456789abcdef).", false},
+		{"(service web) registered 1 targets in (target-group arn:aws:elasticloadbalancing:us-east-1:123456789012:targetgroup/web/73e2d6bc24d8a067)", false},
+		{"(service web) (port 80) is unhealthy in (target-group arn:aws:elasticloadbalancing:us-east-1:123456789012:targetgroup/web/73e2d6bc24d8a067) due to (reason Health checks failed).", true},
+		{"(service web) (task 0123456789abcdef) failed container health checks.", true},
+		{"(service web) was unable to place a task. Reason: CannotPullContainerError: pull image manifest has been retried 5 time(s).", true},
+		{"(service web) deployment ecs-svc/1234567890 deployment failed: tasks failed to start.", true},
+		{"(service web) rolling back to deployment ecs-svc/0987654321.", true},
+	}
+
+	for _, test := range tests {
+		if got := (Event{Message: test.message}).IsFailure(); got != test.failure {
+			t.Errorf("IsFailure(%q) => %t, want %t", test.message, got, test.failure)
+		}
+	}
+}
+
+func TestFailureEvents(t *testing.T) {
+	deployed := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	events := []Event{
+		Event{CreatedAt: deployed.Add(2 * time.Minute), Message: "(service web) (task 1) failed container health checks."},
+		Event{CreatedAt: deployed.Add(time.Minute), Message: "(service web) has started 1 tasks: (task 1)."},
+		Event{CreatedAt: deployed.Add(-time.Hour), Message: "(service web) was unable to place a task."},
+	}
+
+	if failures := FailureEvents(events, deployed); len(failures) != 1 || failures[0] != events[0] {
+		t.Errorf("expected the health check failure, got %v", failures)
+	}
+
+	if failures := FailureEvents(events, time.Time{}); len(failures) != 2 {
+		t.Errorf("expected 2 failures, got %v", failures)
+	}
+}
+
+func TestServicePrimaryDeployment(t *testing.T) {
+	service := Service{
+		Deployments: []Deployment{
+			Deployment{Id: "3", Status: "ACTIVE"},
+			Deployment{Id: "4", Status: "PRIMARY"},
+		},
+	}
+
+	if deployment := service.PrimaryDeployment(); deployment == nil || deployment.Id != "4" {
+		t.Errorf("expected deployment 4, got %v", deployment)
+	}
+
+	if deployment := (&Service{}).PrimaryDeployment(); deployment != nil {
+		t.Errorf("expected no deployment, got %v", deployment)
+	}
+}
+
+func TestListServiceTaskDefinitions(t *testing.T) {
+	var listInput *awsecs.ListServicesInput
+	var batches [][]string
+
+	ecs := newRecordingECS(func(r *request.Request) {
+		switch input := r.Params.(type) {
+		case *awsecs.ListServicesInput:
+			listInput = input
+
+			var serviceArns []string
+
+			for i := 0; i < 12; i++ {
+				serviceArns = append(serviceArns, fmt.Sprintf("service-%d", i))
+			}
+
+			r.Data.(*awsecs.ListServicesOutput).ServiceArns = aws.StringSlice(serviceArns)
+		case *awsecs.DescribeServicesInput:
+			batches = append(batches, aws.StringValueSlice(input.Services))
+
+			for _, serviceArn := range aws.StringValueSlice(input.Services) {
+				r.Data.(*awsecs.DescribeServicesOutput).Services = append(r.Data.(*awsecs.DescribeServicesOutput).Services,
+					&awsecs.Service{
+						Deployments: []*awsecs.Deployment{
+							&awsecs.Deployment{TaskDefinition: aws.String(serviceArn + ":2")},
+						},
+					},
+				)
+			}
+		}
+	})
+
+	taskDefinitionArns, err := ecs.ListServiceTaskDefinitions()
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if listInput.LaunchType != nil {
+		t.Errorf("expected services of every launch type to be listed, got %s", aws.StringValue(listInput.LaunchType))
+	}
+
+	if len(batches) != 2 || len(batches[0]) != 10 || len(batches[1]) != 2 {
+		t.Errorf("expected batches of 10 and 2 services, got %v", batches)
+	}
+
+	if len(taskDefinitionArns) != 12 || taskDefinitionArns[11] != "service-11:2" {
+		t.Errorf("expected a task definition for each service, got %v", taskDefinitionArns)
+	}
+}
+
+func TestListServiceTaskDefinitionsError(t *testing.T) {
+	var tests = []struct {
+		name   string
+		record func(*request.Request)
+	}{
+		{
+			"list error",
+			func(r *request.Request) {
+				r.Error = errors.New("AccessDenied")
+			},
+		},
+		{
+			"describe failure",
+			func(r *request.Request) {
+				switch r.Params.(type) {
+				case *awsecs.ListServicesInput:
+					r.Data.(*awsecs.ListServicesOutput).ServiceArns = aws.StringSlice([]string{"web"})
+				case *awsecs.DescribeServicesInput:
+					r.Data.(*awsecs.DescribeServicesOutput).Failures = []*awsecs.Failure{
+						&awsecs.Failure{Arn: aws.String("web"), Reason: aws.String("MISSING")},
+					}
+				}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		ecs := newRecordingECS(test.record)
+
+		if taskDefinitionArns, err := ecs.ListServiceTaskDefinitions(); err == nil || !reflect.DeepEqual(taskDefinitionArns, []string(nil)) {
+			t.Errorf("%s: expected an error and no task definitions, got %v, %v", test.name, taskDefinitionArns, err)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package ecs
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsecs "github.com/aws/aws-sdk-go/service/ecs"
+)
+
+var containerNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,255}$`)
+
+//Sidecar is an additional container that runs alongside a task's primary
+//container, such as a log router, proxy, or metrics agent. Sidecars aren't
+//essential, so the task keeps running if one stops.
+type Sidecar struct {
+	Name    string
+	Image   string
+	EnvVars []EnvVar
+	Port    int64
+}
+
+//Validate checks the sidecar has a valid container name and an image
+func (s Sidecar) Validate() error {
+	if !containerNameRegexp.MatchString(s.Name) {
+		return fmt.Errorf("invalid sidecar name %s (use letters, numbers, hyphens, and underscores)", s.Name)
+	}
+
+	if s.Image == "" {
+		return fmt.Errorf("sidecar %s has no image", s.Name)
+	}
+
+	if s.Port < 0 || s.Port > 65535 {
+		return fmt.Errorf("invalid port %d for sidecar %s", s.Port, s.Name)
+	}
+
+	return nil
+}
+
+//ContainerDefinition returns the sidecar's container definition, logging to
+//the same place as the primary container
+func (s Sidecar) ContainerDefinition(logConfiguration *awsecs.LogConfiguration) *awsecs.ContainerDefinition {
+	containerDefinition := &awsecs.ContainerDefinition{
+		Environment:      convertEnvVars(s.EnvVars),
+		Essential:        aws.Bool(false),
+		Image:            aws.String(s.Image),
+		LogConfiguration: logConfiguration,
+		Name:             aws.String(s.Name),
+	}
+
+	if s.Port != 0 {
+		containerDefinition.SetPortMappings(
+			[]*awsecs.PortMapping{
+				&awsecs.PortMapping{
+					ContainerPort: aws.Int64(s.Port),
+				},
+			},
+		)
+	}
+
+	return containerDefinition
+}
+
+//setSidecars replaces the containers with the same names as the sidecars, or
+//appends the sidecars when there are none
+func setSidecars(containers []*awsecs.ContainerDefinition, sidecars []Sidecar, logConfiguration *awsecs.LogConfiguration) []*awsecs.ContainerDefinition {
+	for _, sidecar := range sidecars {
+		containerDefinition := sidecar.ContainerDefinition(logConfiguration)
+		match := false
+
+		for i, container := range containers {
+			if aws.StringValue(container.Name) == sidecar.Name {
+				containers[i] = containerDefinition
+				match = true
+				break
+			}
+		}
+
+		if !match {
+			containers = append(containers, containerDefinition)
+		}
+	}
+
+	return containers
+}
@@ -0,0 +1,87 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsecs "github.com/aws/aws-sdk-go/service/ecs"
+)
+
+func TestSidecarContainerDefinition(t *testing.T) {
+	logConfiguration := &awsecs.LogConfiguration{LogDriver: aws.String(awsecs.LogDriverAwslogs)}
+	sidecar := Sidecar{
+		Name:    "datadog",
+		Image:   "public.ecr.aws/datadog/agent:7",
+		EnvVars: []EnvVar{{Key: "DD_SITE", Value: "datadoghq.com"}},
+		Port:    8126,
+	}
+
+	container := sidecar.ContainerDefinition(logConfiguration)
+
+	if aws.BoolValue(container.Essential) {
+		t.Error("expected sidecar not to be essential")
+	}
+
+	if aws.StringValue(container.Name) != "datadog" || aws.StringValue(container.Image) != "public.ecr.aws/datadog/agent:7" {
+		t.Errorf("unexpected name or image: %s", container)
+	}
+
+	if len(container.Environment) != 1 || aws.StringValue(container.Environment[0].Name) != "DD_SITE" {
+		t.Errorf("unexpected environment: %v", container.Environment)
+	}
+
+	if len(container.PortMappings) != 1 || aws.Int64Value(container.PortMappings[0].ContainerPort) != 8126 {
+		t.Errorf("unexpected port mappings: %v", container.PortMappings)
+	}
+
+	if container.LogConfiguration != logConfiguration {
+		t.Error("expected the sidecar to use the given log configuration")
+	}
+}
+
+func TestSidecarValidate(t *testing.T) {
+	var tests = []struct {
+		sidecar Sidecar
+		valid   bool
+	}{
+		{Sidecar{Name: "log-router", Image: "amazon/aws-for-fluent-bit"}, true},
+		{Sidecar{Name: "", Image: "amazon/aws-for-fluent-bit"}, false},
+		{Sidecar{Name: "log router", Image: "amazon/aws-for-fluent-bit"}, false},
+		{Sidecar{Name: "log-router"}, false},
+		{Sidecar{Name: "log-router", Image: "amazon/aws-for-fluent-bit", Port: 70000}, false},
+	}
+
+	for _, test := range tests {
+		if err := test.sidecar.Validate(); (err == nil) != test.valid {
+			t.Errorf("%+v: expected valid %t, got %v", test.sidecar, test.valid, err)
+		}
+	}
+}
+
+func TestSetSidecars(t *testing.T) {
+	containers := []*awsecs.ContainerDefinition{
+		{Name: aws.String("web"), Essential: aws.Bool(true)},
+		{Name: aws.String("datadog"), Image: aws.String("public.ecr.aws/datadog/agent:6")},
+	}
+
+	containers = setSidecars(containers, []Sidecar{
+		{Name: "datadog", Image: "public.ecr.aws/datadog/agent:7"},
+		{Name: "log-router", Image: "amazon/aws-for-fluent-bit"},
+	}, nil)
+
+	if len(containers) != 3 {
+		t.Fatalf("expected 3 containers, got %d", len(containers))
+	}
+
+	if aws.StringValue(containers[1].Image) != "public.ecr.aws/datadog/agent:7" {
+		t.Errorf("expected datadog to be replaced in place, got %s", aws.StringValue(containers[1].Image))
+	}
+
+	if aws.StringValue(containers[2].Name) != "log-router" {
+		t.Errorf("expected log-router to be appended, got %s", aws.StringValue(containers[2].Name))
+	}
+
+	if !aws.BoolValue(containers[0].Essential) {
+		t.Error("expected the primary container to stay essential")
+	}
+}
@@ -0,0 +1,55 @@
+package ecs
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsecs "github.com/aws/aws-sdk-go/service/ecs"
+)
+
+//ManagedByTagKey and ManagedByTagValue mark resources created by this tool
+const (
+	ManagedByTagKey   = "managedBy"
+	ManagedByTagValue = "fargate"
+)
+
+//Tags converts tags to ECS tags, sorted by key
+func Tags(tags map[string]string) []*awsecs.Tag {
+	var keys []string
+	var ecsTags []*awsecs.Tag
+
+	for key := range tags {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		ecsTags = append(
+			ecsTags,
+			&awsecs.Tag{
+				Key:   aws.String(key),
+				Value: aws.String(tags[key]),
+			},
+		)
+	}
+
+	return ecsTags
+}
+
+//TagResource adds tags to an ECS resource such as a task definition or
+//service. Later task definition revisions registered by this tool copy them.
+func (ecs *ECS) TagResource(resourceArn string, tags map[string]string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	_, err := ecs.svc.TagResource(
+		&awsecs.TagResourceInput{
+			ResourceArn: aws.String(resourceArn),
+			Tags:        Tags(tags),
+		},
+	)
+
+	return err
+}
@@ -0,0 +1,24 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func TestTags(t *testing.T) {
+	tags := Tags(map[string]string{"team": "web", "managedBy": "fargate"})
+
+	if len(tags) != 2 {
+		t.Fatalf("expected 2 tags, got %d", len(tags))
+	}
+
+	if aws.StringValue(tags[0].Key) != "managedBy" || aws.StringValue(tags[0].Value) != "fargate" ||
+		aws.StringValue(tags[1].Key) != "team" || aws.StringValue(tags[1].Value) != "web" {
+		t.Errorf("expected tags sorted by key, got %v", tags)
+	}
+
+	if Tags(nil) != nil {
+		t.Error("expected no tags")
+	}
+}
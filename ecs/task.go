@@ -1,13 +1,16 @@
 package ecs
 
 import (
+	"encoding/json"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	awsecs "github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/turnerlabs/fargate/arn"
 	"github.com/turnerlabs/fargate/console"
 )
 
@@ -15,118 +18,355 @@ const (
 	detailNetworkInterfaceId  = "networkInterfaceId"
 	detailSubnetId            = "subnetId"
 	startedByFormat           = "fargate:%s"
-	taskGroupStartedByPattern = "fargate:(.*)"
+	namespacedStartedByFormat = "fargate:%s:%s"
+	taskGroupStartedByPattern = "^fargate:(?:([^:]*):)?(.+)$"
 	eniAttachmentType         = "ElasticNetworkInterface"
+
+	//StoppedTaskLimit is how many stopped tasks are listed unless all are asked for
+	StoppedTaskLimit = 10
+
+	//startedByMaxLength is the longest startedBy value ECS keeps; longer values
+	//are cut short, so a value this long may belong to a longer task group name
+	startedByMaxLength = 128
+
+	//describeTasksBatchSize is the most tasks DescribeTasks accepts per call
+	describeTasksBatchSize = 100
 )
 
+var taskGroupStartedByRegexp = regexp.MustCompile(taskGroupStartedByPattern)
+
+var networkFailure = regexp.MustCompile(`(CannotPullContainerError|ResourceInitializationError).*(?i:timeout|dial tcp|connection refused|no such host|deadline exceeded)`)
+
 type Task struct {
-	Cpu              string
-	CreatedAt        time.Time
-	DeploymentId     string
-	DesiredStatus    string
-	EniId            string
-	EnvVars          []EnvVar
-	Image            string
-	LastStatus       string
-	Memory           string
-	SecurityGroupIds []string
-	StartedBy        string
-	SubnetId         string
-	TaskId           string
-	TaskRole         string
+	Cpu              string    `json:"cpu"`
+	CreatedAt        time.Time `json:"createdAt"`
+	DeploymentId     string    `json:"deploymentId"`
+	DesiredStatus    string    `json:"desiredStatus"`
+	EniId            string    `json:"eniId"`
+	EnvVars          []EnvVar  `json:"envVars"`
+	Image            string    `json:"image"`
+	LastStatus       string    `json:"lastStatus"`
+	Memory           string    `json:"memory"`
+	SecurityGroupIds []string  `json:"securityGroupIds"`
+	StartedBy        string    `json:"startedBy"`
+	StoppedAt        time.Time `json:"stoppedAt"`
+	StoppedReason    string    `json:"stoppedReason"`
+	SubnetId         string    `json:"subnetId"`
+	TaskId           string    `json:"taskId"`
+	TaskRole         string    `json:"taskRole"`
 }
 
 func (t *Task) RunningFor() time.Duration {
 	return time.Now().Sub(t.CreatedAt).Truncate(time.Second)
 }
 
+//Transition returns "starting" or "stopping" while a task's last status hasn't
+//reached its desired status, e.g. a task still running while it is drained
+func (t Task) Transition() string {
+	if t.DesiredStatus == "" || t.LastStatus == t.DesiredStatus {
+		return ""
+	}
+
+	if t.DesiredStatus == awsecs.DesiredStatusStopped {
+		return "stopping"
+	}
+
+	return "starting"
+}
+
+//MarshalJSON adds whether the task is transitioning to its fields
+func (t Task) MarshalJSON() ([]byte, error) {
+	type task Task
+
+	return json.Marshal(
+		struct {
+			task
+			Transitioning bool `json:"transitioning"`
+		}{task(t), t.Transition() != ""},
+	)
+}
+
 type TaskGroup struct {
-	TaskGroupName string
-	Instances     int64
+	TaskGroupName string `json:"taskGroupName"`
+	Namespace     string `json:"namespace"`
+	Instances     int64  `json:"instances"`
+}
+
+//StartedBy returns the startedBy value used to tag tasks in a task group,
+//optionally scoped to a namespace (e.g. fargate:staging:migrate)
+func StartedBy(namespace, taskGroupName string) string {
+	if namespace == "" {
+		return fmt.Sprintf(startedByFormat, taskGroupName)
+	}
+
+	return fmt.Sprintf(namespacedStartedByFormat, namespace, taskGroupName)
+}
+
+//ValidateTaskGroupName checks a task group name fits in a startedBy value with
+//its namespace. The startedBy value must be shorter than the 128 characters
+//ECS keeps, so that a value which was cut short can always be recognized.
+func ValidateTaskGroupName(namespace, taskGroupName string) error {
+	if taskGroupName == "" {
+		return fmt.Errorf("task group name is required")
+	}
+
+	if strings.Contains(taskGroupName, ":") {
+		return fmt.Errorf("invalid task group name %s [cannot contain colons]", taskGroupName)
+	}
+
+	if length := len(StartedBy(namespace, taskGroupName)); length >= startedByMaxLength {
+		return fmt.Errorf("task group name %s is too long [at most %d characters with namespace %q]",
+			taskGroupName, len(taskGroupName)-(length-startedByMaxLength)-1, namespace)
+	}
+
+	return nil
+}
+
+//IsTruncatedStartedBy returns whether ECS may have cut a startedBy value short,
+//which happened to tasks started with long task group names before names were
+//validated
+func IsTruncatedStartedBy(startedBy string) bool {
+	return len(startedBy) >= startedByMaxLength
+}
+
+//ParseStartedBy returns the namespace and task group name from a startedBy
+//value; tasks started before namespaces existed have an empty namespace
+func ParseStartedBy(startedBy string) (namespace, taskGroupName string, ok bool) {
+	matches := taskGroupStartedByRegexp.FindStringSubmatch(startedBy)
+
+	if len(matches) != 3 {
+		return "", "", false
+	}
+
+	return matches[1], matches[2], true
 }
 
 type RunTaskInput struct {
 	ClusterName       string
 	Count             int64
+	Namespace         string
 	SecurityGroupIds  []string
 	SubnetIds         []string
 	TaskDefinitionArn string
-	TaskName          string
+
+	//TaskName is the task group the tasks are started in. With the namespace
+	//it must fit in the task's startedBy value (fargate:<namespace>:<name>),
+	//which must be shorter than 128 characters.
+	TaskName string
+
+	//AssignPublicIp gives each task a public IP. Tasks in private subnets
+	//don't need one, but need a NAT gateway or VPC endpoints to pull images.
+	AssignPublicIp bool
+
+	//TaskRoleArn overrides the task definition's task role, e.g. to run a
+	//one-off task with the same permissions as a service
+	TaskRoleArn string
+
+	//CapacityProviders (e.g. from SpotStrategy) runs the tasks with a capacity
+	//provider strategy instead of the FARGATE launch type
+	CapacityProviders []CapacityProviderStrategyItem
+
+	//PlatformVersion pins the Fargate platform version, LATEST by default
+	PlatformVersion string
+
+	//EnableExecuteCommand allows commands to be run in the tasks with ECS
+	//Exec (task exec). The task role needs the ssmmessages permissions.
+	EnableExecuteCommand bool
 }
 
 func (ecs *ECS) RunTask(i *RunTaskInput) {
-	_, err := ecs.svc.RunTask(
-		&awsecs.RunTaskInput{
-			Cluster:        aws.String(i.ClusterName),
-			Count:          aws.Int64(i.Count),
-			TaskDefinition: aws.String(i.TaskDefinitionArn),
-			LaunchType:     aws.String(awsecs.CompatibilityFargate),
-			StartedBy:      aws.String(fmt.Sprintf(startedByFormat, i.TaskName)),
-			NetworkConfiguration: &awsecs.NetworkConfiguration{
-				AwsvpcConfiguration: &awsecs.AwsVpcConfiguration{
-					AssignPublicIp: aws.String(awsecs.AssignPublicIpEnabled),
-					Subnets:        aws.StringSlice(i.SubnetIds),
-					SecurityGroups: aws.StringSlice(i.SecurityGroupIds),
-				},
+	if err := ValidatePlatformVersion(i.PlatformVersion); err != nil {
+		console.ErrorExit(err, "Invalid ECS task configuration")
+	}
+
+	if err := ValidateTaskGroupName(i.Namespace, i.TaskName); err != nil {
+		console.ErrorExit(err, "Invalid ECS task configuration")
+	}
+
+	input := &awsecs.RunTaskInput{
+		Cluster:        aws.String(i.ClusterName),
+		Count:          aws.Int64(i.Count),
+		TaskDefinition: aws.String(i.TaskDefinitionArn),
+		StartedBy:      aws.String(StartedBy(i.Namespace, i.TaskName)),
+		NetworkConfiguration: &awsecs.NetworkConfiguration{
+			AwsvpcConfiguration: &awsecs.AwsVpcConfiguration{
+				AssignPublicIp: assignPublicIp(i.AssignPublicIp),
+				Subnets:        aws.StringSlice(i.SubnetIds),
+				SecurityGroups: aws.StringSlice(i.SecurityGroupIds),
 			},
 		},
-	)
+	}
+
+	if i.PlatformVersion != "" {
+		input.SetPlatformVersion(i.PlatformVersion)
+	}
+
+	if i.EnableExecuteCommand {
+		input.SetEnableExecuteCommand(true)
+	}
+
+	if len(i.CapacityProviders) > 0 {
+		input.SetCapacityProviderStrategy(capacityProviderStrategy(i.CapacityProviders))
+	} else {
+		input.SetLaunchType(awsecs.CompatibilityFargate)
+	}
+
+	if i.TaskRoleArn != "" {
+		input.Overrides = &awsecs.TaskOverride{
+			TaskRoleArn: aws.String(i.TaskRoleArn),
+		}
+	}
+
+	_, err := ecs.svc.RunTask(input)
 
 	if err != nil {
-		console.ErrorExit(err, "Could not run ECS task")
+		console.ErrorExit(ecs.capacityProviderError(err), "Could not run ECS task")
 	}
 }
 
 func (ecs *ECS) DescribeTasksForService(serviceName string) []Task {
+	return ecs.DescribeServiceTasks(ecs.DescribeService(serviceName))
+}
+
+//DescribeServiceTasks describes the tasks of a service that has already been
+//described
+func (ecs *ECS) DescribeServiceTasks(service Service) []Task {
 	return ecs.listTasks(
 		&awsecs.ListTasksInput{
 			Cluster:     aws.String(ecs.ClusterName),
 			LaunchType:  aws.String(awsecs.CompatibilityFargate),
-			ServiceName: aws.String(serviceName),
+			ServiceName: aws.String(service.Name),
+		},
+		service.ContainerName,
+	)
+}
+
+//DescribeStoppedTasksForService describes a service's recently stopped tasks,
+//which ECS keeps for about an hour
+func (ecs *ECS) DescribeStoppedTasksForService(serviceName string) []Task {
+	service := ecs.DescribeService(serviceName)
+
+	return ecs.listTasks(
+		&awsecs.ListTasksInput{
+			Cluster:       aws.String(ecs.ClusterName),
+			DesiredStatus: aws.String(awsecs.DesiredStatusStopped),
+			ServiceName:   aws.String(serviceName),
+		},
+		service.ContainerName,
+	)
+}
+
+//DescribeStoppingTasksForService describes a service's tasks that are being
+//stopped but haven't stopped yet, such as tasks draining during a deploy
+func (ecs *ECS) DescribeStoppingTasksForService(serviceName string) []Task {
+	return ecs.DescribeStoppingServiceTasks(ecs.DescribeService(serviceName))
+}
+
+//DescribeStoppingServiceTasks describes the stopping tasks of a service that
+//has already been described. Tasks that have already stopped are left out
+//before their task definitions and network interfaces are looked up.
+func (ecs *ECS) DescribeStoppingServiceTasks(service Service) []Task {
+	var stopping []*awsecs.Task
+
+	taskArns := ecs.listTaskArns(
+		&awsecs.ListTasksInput{
+			Cluster:       aws.String(ecs.ClusterName),
+			DesiredStatus: aws.String(awsecs.DesiredStatusStopped),
+			ServiceName:   aws.String(service.Name),
+		},
+	)
+
+	for _, t := range ecs.describeECSTasks(taskArns) {
+		if aws.StringValue(t.LastStatus) != awsecs.DesiredStatusStopped {
+			stopping = append(stopping, t)
+		}
+	}
+
+	return ecs.toTasks(stopping, service.ContainerName)
+}
+
+//DescribeStoppedTasksForTaskGroup describes a task group's recently stopped
+//tasks, which ECS keeps for about an hour
+func (ecs *ECS) DescribeStoppedTasksForTaskGroup(taskGroupName string) []Task {
+	return ecs.listTasks(
+		&awsecs.ListTasksInput{
+			Cluster:       aws.String(ecs.ClusterName),
+			DesiredStatus: aws.String(awsecs.DesiredStatusStopped),
+			StartedBy:     aws.String(StartedBy(ecs.Namespace, taskGroupName)),
 		},
+		"",
 	)
 }
 
+//LatestStoppedTasks sorts stopped tasks with the most recently stopped first
+//and returns up to limit of them, or all of them if limit is 0. Tasks that
+//are still stopping sort first.
+func LatestStoppedTasks(tasks []Task, limit int) []Task {
+	sorted := append([]Task{}, tasks...)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].StoppedAt.IsZero() != sorted[j].StoppedAt.IsZero() {
+			return sorted[i].StoppedAt.IsZero()
+		}
+
+		return sorted[i].StoppedAt.After(sorted[j].StoppedAt)
+	})
+
+	if limit > 0 && len(sorted) > limit {
+		return sorted[:limit]
+	}
+
+	return sorted
+}
+
 func (ecs *ECS) DescribeTasksForTaskGroup(taskGroupName string) []Task {
 	return ecs.listTasks(
 		&awsecs.ListTasksInput{
-			StartedBy: aws.String(fmt.Sprintf(startedByFormat, taskGroupName)),
+			StartedBy: aws.String(StartedBy(ecs.Namespace, taskGroupName)),
 			Cluster:   aws.String(ecs.ClusterName),
 		},
+		"",
 	)
 }
 
+//ListTaskGroups returns the task groups started by this tool in the
+//configured namespace. Tasks whose startedBy value was cut short are left
+//out, since tasks from different task groups could share the shortened name.
 func (ecs *ECS) ListTaskGroups() []*TaskGroup {
 	var taskGroups []*TaskGroup
 
-	taskGroupStartedByRegexp := regexp.MustCompile(taskGroupStartedByPattern)
-
 	input := &awsecs.ListTasksInput{
 		Cluster: aws.String(ecs.ClusterName),
 	}
 
 OUTER:
-	for _, task := range ecs.listTasks(input) {
-		matches := taskGroupStartedByRegexp.FindStringSubmatch(task.StartedBy)
+	for _, task := range ecs.listTasks(input, "") {
+		if IsTruncatedStartedBy(task.StartedBy) {
+			console.Debug("Skipping task %s with truncated startedBy %s", task.TaskId, task.StartedBy)
+			continue
+		}
 
-		if len(matches) == 2 {
-			taskGroupName := matches[1]
+		namespace, taskGroupName, ok := ParseStartedBy(task.StartedBy)
 
-			for _, taskGroup := range taskGroups {
-				if taskGroup.TaskGroupName == taskGroupName {
-					taskGroup.Instances++
-					continue OUTER
-				}
-			}
+		if !ok || namespace != ecs.Namespace {
+			continue
+		}
 
-			taskGroups = append(
-				taskGroups,
-				&TaskGroup{
-					TaskGroupName: taskGroupName,
-					Instances:     1,
-				},
-			)
+		for _, taskGroup := range taskGroups {
+			if taskGroup.TaskGroupName == taskGroupName {
+				taskGroup.Instances++
+				continue OUTER
+			}
 		}
+
+		taskGroups = append(
+			taskGroups,
+			&TaskGroup{
+				TaskGroupName: taskGroupName,
+				Namespace:     namespace,
+				Instances:     1,
+			},
+		)
 	}
 
 	return taskGroups
@@ -151,17 +391,18 @@ func (ecs *ECS) StopTask(taskId string) {
 	}
 }
 
-func (ecs *ECS) listTasks(input *awsecs.ListTasksInput) []Task {
-	var tasks []Task
-	var taskArnBatches [][]string
+func (ecs *ECS) listTasks(input *awsecs.ListTasksInput, containerName string) []Task {
+	return ecs.describeTasks(ecs.listTaskArns(input), containerName)
+}
+
+//listTaskArns returns the ARNs of every task matching input
+func (ecs *ECS) listTaskArns(input *awsecs.ListTasksInput) []string {
+	var taskArns []string
 
 	err := ecs.svc.ListTasksPages(
 		input,
 		func(resp *awsecs.ListTasksOutput, lastPage bool) bool {
-			if len(resp.TaskArns) > 0 {
-				taskArnBatches = append(taskArnBatches, aws.StringValueSlice(resp.TaskArns))
-			}
-
+			taskArns = append(taskArns, aws.StringValueSlice(resp.TaskArns)...)
 			return true
 		},
 	)
@@ -170,39 +411,69 @@ func (ecs *ECS) listTasks(input *awsecs.ListTasksInput) []Task {
 		console.ErrorExit(err, "Could not list ECS tasks")
 	}
 
-	if len(taskArnBatches) > 0 {
-		for _, taskArnBatch := range taskArnBatches {
-			for _, task := range ecs.DescribeTasks(taskArnBatch) {
-				tasks = append(tasks, task)
-			}
+	return taskArns
+}
+
+//DescribeTasks describes tasks, reading the image and environment from the
+//primary container of each task's definition
+func (ecs *ECS) DescribeTasks(taskIds []string) []Task {
+	return ecs.describeTasks(taskIds, "")
+}
+
+//describeTasks describes tasks whose primary container is named
+//containerName, e.g. the container a service's load balancer targets
+func (ecs *ECS) describeTasks(taskIds []string, containerName string) []Task {
+	return ecs.toTasks(ecs.describeECSTasks(taskIds), containerName)
+}
+
+//describeECSTasks describes tasks describeTasksBatchSize at a time, the most
+//the API accepts
+func (ecs *ECS) describeECSTasks(taskIds []string) []*awsecs.Task {
+	var ecsTasks []*awsecs.Task
+
+	for start := 0; start < len(taskIds); start += describeTasksBatchSize {
+		end := start + describeTasksBatchSize
+
+		if end > len(taskIds) {
+			end = len(taskIds)
+		}
+
+		resp, err := ecs.svc.DescribeTasks(
+			&awsecs.DescribeTasksInput{
+				Cluster: aws.String(ecs.ClusterName),
+				Tasks:   aws.StringSlice(taskIds[start:end]),
+			},
+		)
+
+		if err != nil {
+			console.ErrorExit(err, "Could not describe ECS tasks")
 		}
+
+		ecsTasks = append(ecsTasks, resp.Tasks...)
 	}
 
-	return tasks
+	return ecsTasks
 }
 
-func (ecs *ECS) DescribeTasks(taskIds []string) []Task {
+//toTasks converts described tasks, reading the image and environment from
+//the container named containerName, or the primary container, of each task's
+//definition, and their security groups from their network interfaces
+func (ecs *ECS) toTasks(ecsTasks []*awsecs.Task, containerName string) []Task {
 	var tasks []Task
+	var taskDefinitionArns []string
 
-	if len(taskIds) == 0 {
+	if len(ecsTasks) == 0 {
 		return tasks
 	}
 
-	resp, err := ecs.svc.DescribeTasks(
-		&awsecs.DescribeTasksInput{
-			Cluster: aws.String(ecs.ClusterName),
-			Tasks:   aws.StringSlice(taskIds),
-		},
-	)
-
-	if err != nil {
-		console.ErrorExit(err, "Could not describe ECS tasks")
+	for _, t := range ecsTasks {
+		taskDefinitionArns = append(taskDefinitionArns, aws.StringValue(t.TaskDefinitionArn))
 	}
 
-	for _, t := range resp.Tasks {
-		taskArn := aws.StringValue(t.TaskArn)
-		contents := strings.Split(taskArn, "/")
-		taskID := contents[len(contents)-1]
+	ecs.prefetchTaskDefinitions(taskDefinitionArns)
+
+	for _, t := range ecsTasks {
+		taskID := arn.ResourceName(aws.StringValue(t.TaskArn))
 
 		task := Task{
 			Cpu:           aws.StringValue(t.Cpu),
@@ -213,13 +484,16 @@ func (ecs *ECS) DescribeTasks(taskIds []string) []Task {
 			Memory:        aws.StringValue(t.Memory),
 			TaskId:        taskID,
 			StartedBy:     aws.StringValue(t.StartedBy),
+			StoppedAt:     aws.TimeValue(t.StoppedAt),
+			StoppedReason: aws.StringValue(t.StoppedReason),
 		}
 
 		taskDefinition := ecs.DescribeTaskDefinition(aws.StringValue(t.TaskDefinitionArn))
-		task.Image = aws.StringValue(taskDefinition.TaskDefinition.ContainerDefinitions[0].Image)
+		container := PrimaryContainerDefinition(taskDefinition.TaskDefinition, containerName)
+		task.Image = aws.StringValue(container.Image)
 		task.TaskRole = aws.StringValue(taskDefinition.TaskDefinition.TaskRoleArn)
 
-		for _, environment := range taskDefinition.TaskDefinition.ContainerDefinitions[0].Environment {
+		for _, environment := range container.Environment {
 			task.EnvVars = append(
 				task.EnvVars,
 				EnvVar{
@@ -269,3 +543,23 @@ func determineENIDetails(t *awsecs.Task) (bool, string, string) {
 
 	return foundEni, eniId, subnetId
 }
+
+//assignPublicIp converts whether tasks get a public IP to the ECS setting
+func assignPublicIp(enabled bool) *string {
+	if enabled {
+		return aws.String(awsecs.AssignPublicIpEnabled)
+	}
+
+	return aws.String(awsecs.AssignPublicIpDisabled)
+}
+
+//NetworkFailureHint suggests a fix for tasks that stopped because they
+//couldn't reach ECR or other AWS services, typically tasks without a public IP
+//in private subnets with no route out
+func NetworkFailureHint(stoppedReason string) string {
+	if !networkFailure.MatchString(stoppedReason) {
+		return ""
+	}
+
+	return "Tasks couldn't reach AWS to start. Tasks without a public IP need a NAT gateway or VPC endpoints (ECR, S3, CloudWatch Logs, and any secrets services) to pull images and start."
+}
@@ -2,9 +2,11 @@ package ecs
 
 import (
 	"fmt"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/aws/aws-sdk-go/aws"
 	awsecs "github.com/aws/aws-sdk-go/service/ecs"
@@ -13,7 +15,18 @@ import (
 
 const logStreamPrefix = "fargate"
 
-var taskDefinitionCache = make(map[string]*awsecs.DescribeTaskDefinitionOutput)
+//maxConcurrentTaskDefinitionDescribes bounds how many task definitions are
+//described at once when prefetching them
+const maxConcurrentTaskDefinitionDescribes = 5
+
+var repositoryCredentialsRegexp = regexp.MustCompile(`^arn:aws[a-z-]*:secretsmanager:[a-z0-9-]+:\d{12}:secret:[\w/+=.@-]+$`)
+
+//taskDefinitionCache is shared by every client, so it's keyed by region as
+//well as ARN (a family name alone means different things in each region)
+var (
+	taskDefinitionCache      = make(map[string]*awsecs.DescribeTaskDefinitionOutput)
+	taskDefinitionCacheMutex sync.Mutex
+)
 
 //CreateTaskDefinitionInput ...
 type CreateTaskDefinitionInput struct {
@@ -30,12 +43,77 @@ type CreateTaskDefinitionInput struct {
 	TaskRole         string
 	Type             string
 	Tags             []*awsecs.Tag
+	AppMesh          *AppMesh
+	PidMode          string
+	Sidecars         []Sidecar
+	Volumes          []*awsecs.Volume
+	MountPoints      []*awsecs.MountPoint
+	PortProtocol     string
+}
+
+//Validate checks the input for values Fargate would reject
+//
+//The task's container is its essential container, so it needs a name and an
+//image.
+func (input *CreateTaskDefinitionInput) Validate() error {
+	if input.Name == "" || input.Image == "" {
+		return fmt.Errorf("task definition has no essential container [a container name and image are required]")
+	}
+
+	if err := ValidatePidMode(input.PidMode); err != nil {
+		return err
+	}
+
+	for _, sidecar := range input.Sidecars {
+		if err := sidecar.Validate(); err != nil {
+			return err
+		}
+
+		if sidecar.Name == input.Name {
+			return fmt.Errorf("sidecar %s has the same name as the task's container", sidecar.Name)
+		}
+	}
+
+	volumes := make(map[string]bool)
+
+	for _, volume := range input.Volumes {
+		volumes[aws.StringValue(volume.Name)] = true
+	}
+
+	for _, mountPoint := range input.MountPoints {
+		if !volumes[aws.StringValue(mountPoint.SourceVolume)] {
+			return fmt.Errorf("mount point %s has no volume named %s", aws.StringValue(mountPoint.ContainerPath), aws.StringValue(mountPoint.SourceVolume))
+		}
+	}
+
+	return nil
+}
+
+//ValidatePidMode checks a task's PID mode is one Fargate supports, which is
+//only the task PID mode. It lets sidecars such as APM agents see the processes
+//of the other containers in the task. An empty PID mode is valid.
+func ValidatePidMode(pidMode string) error {
+	if pidMode != "" && pidMode != awsecs.PidModeTask {
+		return fmt.Errorf("invalid PID mode %s [Fargate supports: %s]", pidMode, awsecs.PidModeTask)
+	}
+
+	return nil
+}
+
+//ValidateRepositoryCredentials checks private registry credentials reference
+//a Secrets Manager secret by ARN, which is the only form ECS accepts
+func ValidateRepositoryCredentials(secretArn string) error {
+	if !repositoryCredentialsRegexp.MatchString(secretArn) {
+		return fmt.Errorf("invalid repository credentials %s [expected a Secrets Manager secret ARN, e.g. arn:aws:secretsmanager:us-east-1:123456789012:secret:registry-AbCdEf]", secretArn)
+	}
+
+	return nil
 }
 
 //EnvVar ...
 type EnvVar struct {
-	Key   string
-	Value string
+	Key   string `json:"key"`
+	Value string `json:"value"`
 }
 
 //Secret ...
@@ -60,6 +138,32 @@ func (a envSorter) Less(i, j int) bool {
 func (ecs *ECS) CreateTaskDefinition(input *CreateTaskDefinitionInput) string {
 	console.Debug("Creating ECS task definition")
 
+	registerInput, err := input.RegisterTaskDefinitionInput()
+
+	if err != nil {
+		console.ErrorExit(err, "Invalid ECS task definition configuration")
+	}
+
+	resp, err := ecs.svc.RegisterTaskDefinition(registerInput)
+
+	if err != nil {
+		console.ErrorExit(err, "Couldn't register ECS task definition")
+	}
+
+	td := resp.TaskDefinition
+
+	console.Debug("Created ECS task definition [%s:%d]", aws.StringValue(td.Family), aws.Int64Value(td.Revision))
+
+	return aws.StringValue(td.TaskDefinitionArn)
+}
+
+//RegisterTaskDefinitionInput validates an input and builds the task
+//definition it describes, without calling AWS
+func (input *CreateTaskDefinitionInput) RegisterTaskDefinitionInput() (*awsecs.RegisterTaskDefinitionInput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
 	logConfiguration := &awsecs.LogConfiguration{
 		LogDriver: aws.String(awsecs.LogDriverAwslogs),
 		Options: map[string]*string{
@@ -74,43 +178,124 @@ func (ecs *ECS) CreateTaskDefinition(input *CreateTaskDefinitionInput) string {
 		Essential:        aws.Bool(true),
 		Image:            aws.String(input.Image),
 		LogConfiguration: logConfiguration,
+		MountPoints:      input.MountPoints,
 		Name:             aws.String(input.Name),
 		Secrets:          input.Secrets(),
 	}
 
 	if input.Port != 0 {
-		containerDefinition.SetPortMappings(
-			[]*awsecs.PortMapping{
-				&awsecs.PortMapping{
-					ContainerPort: aws.Int64(int64(input.Port)),
+		containerDefinition.SetPortMappings(portMappings(input.Port, input.PortProtocol))
+	}
+
+	registerInput := &awsecs.RegisterTaskDefinitionInput{
+		ContainerDefinitions:    []*awsecs.ContainerDefinition{containerDefinition},
+		Cpu:                     aws.String(input.Cpu),
+		ExecutionRoleArn:        aws.String(input.ExecutionRoleArn),
+		Family:                  aws.String(fmt.Sprintf("%s_%s", input.Type, input.Name)),
+		Memory:                  aws.String(input.Memory),
+		NetworkMode:             aws.String(awsecs.NetworkModeAwsvpc),
+		RequiresCompatibilities: aws.StringSlice([]string{awsecs.CompatibilityFargate}),
+		TaskRoleArn:             aws.String(input.TaskRole),
+		Tags:                    input.Tags,
+		Volumes:                 input.Volumes,
+	}
+
+	if input.PidMode != "" {
+		registerInput.SetPidMode(input.PidMode)
+	}
+
+	//route traffic through an envoy sidecar when joining an app mesh
+	if input.AppMesh != nil {
+		input.AppMesh.SetDefaults(input.Port)
+
+		if err := input.AppMesh.Validate(); err != nil {
+			return nil, err
+		}
+
+		containerDefinition.SetDependsOn(
+			[]*awsecs.ContainerDependency{
+				&awsecs.ContainerDependency{
+					ContainerName: aws.String(appMeshEnvoyContainerName),
+					Condition:     aws.String(awsecs.ContainerConditionHealthy),
 				},
 			},
 		)
+
+		registerInput.ContainerDefinitions = append(registerInput.ContainerDefinitions, input.AppMesh.EnvoyContainerDefinition(logConfiguration))
+		registerInput.SetProxyConfiguration(input.AppMesh.ProxyConfiguration())
 	}
 
-	resp, err := ecs.svc.RegisterTaskDefinition(
-		&awsecs.RegisterTaskDefinitionInput{
-			ContainerDefinitions:    []*awsecs.ContainerDefinition{containerDefinition},
-			Cpu:                     aws.String(input.Cpu),
-			ExecutionRoleArn:        aws.String(input.ExecutionRoleArn),
-			Family:                  aws.String(fmt.Sprintf("%s_%s", input.Type, input.Name)),
-			Memory:                  aws.String(input.Memory),
-			NetworkMode:             aws.String(awsecs.NetworkModeAwsvpc),
-			RequiresCompatibilities: aws.StringSlice([]string{awsecs.CompatibilityFargate}),
-			TaskRoleArn:             aws.String(input.TaskRole),
-			Tags:                    input.Tags,
-		},
-	)
+	registerInput.ContainerDefinitions = setSidecars(registerInput.ContainerDefinitions, input.Sidecars, logConfiguration)
 
-	if err != nil {
-		console.ErrorExit(err, "Couldn't register ECS task definition")
+	return registerInput, nil
+}
+
+//portMappings maps a container port for a listener protocol. UDP listeners
+//need a UDP port mapping, and TCP_UDP listeners need both; everything else,
+//including HTTP and HTTPS, is TCP.
+func portMappings(port int64, protocol string) []*awsecs.PortMapping {
+	var transportProtocols []string
+
+	switch strings.ToUpper(protocol) {
+	case "UDP":
+		transportProtocols = []string{awsecs.TransportProtocolUdp}
+	case "TCP_UDP":
+		transportProtocols = []string{awsecs.TransportProtocolTcp, awsecs.TransportProtocolUdp}
+	default:
+		transportProtocols = []string{awsecs.TransportProtocolTcp}
 	}
 
-	td := resp.TaskDefinition
+	var mappings []*awsecs.PortMapping
 
-	console.Debug("Created ECS task definition [%s:%d]", aws.StringValue(td.Family), aws.Int64Value(td.Revision))
+	for _, transportProtocol := range transportProtocols {
+		mappings = append(mappings,
+			&awsecs.PortMapping{
+				ContainerPort: aws.Int64(port),
+				Protocol:      aws.String(transportProtocol),
+			},
+		)
+	}
 
-	return aws.StringValue(td.TaskDefinitionArn)
+	return mappings
+}
+
+//PrimaryContainerDefinition returns the application container of a task
+//definition: the container with the given name (e.g. the one a service's load
+//balancer targets), else the container named after the task definition family
+//(as created by this tool), else the first container
+func PrimaryContainerDefinition(taskDefinition *awsecs.TaskDefinition, containerName string) *awsecs.ContainerDefinition {
+	if taskDefinition == nil || len(taskDefinition.ContainerDefinitions) == 0 {
+		return nil
+	}
+
+	family := aws.StringValue(taskDefinition.Family)
+	familyName := family[strings.Index(family, "_")+1:]
+
+	for _, name := range []string{containerName, family, familyName} {
+		if name == "" {
+			continue
+		}
+
+		for _, container := range taskDefinition.ContainerDefinitions {
+			if aws.StringValue(container.Name) == name {
+				return container
+			}
+		}
+	}
+
+	return taskDefinition.ContainerDefinitions[0]
+}
+
+//primaryContainerDefinition returns the application container of a task
+//definition like PrimaryContainerDefinition, exiting if it has none
+func primaryContainerDefinition(taskDefinition *awsecs.TaskDefinition, containerName string) *awsecs.ContainerDefinition {
+	container := PrimaryContainerDefinition(taskDefinition, containerName)
+
+	if container == nil {
+		console.IssueExit("No container found in task definition %s", aws.StringValue(taskDefinition.TaskDefinitionArn))
+	}
+
+	return container
 }
 
 //Environment converts envvars to AWS format
@@ -154,43 +339,48 @@ func convertSecretVars(secretVars []Secret) []*awsecs.Secret {
 	return secrets
 }
 
+//addVarsToEnvironment sets variables on a container's environment. Existing
+//variables keep their position with the new value, and new variables are
+//appended in the order given.
 func addVarsToEnvironment(currentVars []*awsecs.KeyValuePair, envVars []EnvVar) []*awsecs.KeyValuePair {
-	environment := convertEnvVars(envVars)
+	environment := append([]*awsecs.KeyValuePair{}, currentVars...)
 
-	for _, curr := range currentVars {
-		key := aws.StringValue(curr.Name)
+	for _, envVar := range convertEnvVars(envVars) {
 		match := false
 
-		for _, sec := range environment {
-			if aws.StringValue(sec.Name) == key {
+		for i, curr := range environment {
+			if aws.StringValue(curr.Name) == aws.StringValue(envVar.Name) {
+				environment[i] = envVar
 				match = true
 				break
 			}
 		}
 
 		if !match {
-			environment = append(environment, curr)
+			environment = append(environment, envVar)
 		}
 	}
 
 	return environment
 }
 
+//addVarsToSecrets sets secrets on a container, in the same way as addVarsToEnvironment
 func addVarsToSecrets(currentVars []*awsecs.Secret, secretVars []Secret) []*awsecs.Secret {
-	secrets := convertSecretVars(secretVars)
+	secrets := append([]*awsecs.Secret{}, currentVars...)
 
-	for _, curr := range currentVars {
-		key := aws.StringValue(curr.Name)
+	for _, secret := range convertSecretVars(secretVars) {
 		match := false
-		for _, sec := range secrets {
-			if aws.StringValue(sec.Name) == key {
+
+		for i, curr := range secrets {
+			if aws.StringValue(curr.Name) == aws.StringValue(secret.Name) {
+				secrets[i] = secret
 				match = true
 				break
 			}
 		}
 
 		if !match {
-			secrets = append(secrets, curr)
+			secrets = append(secrets, secret)
 		}
 	}
 
@@ -200,8 +390,14 @@ func addVarsToSecrets(currentVars []*awsecs.Secret, secretVars []Secret) []*awse
 //DescribeTaskDefinition fetches a task definition output from cache or aws
 //(includes the taskdefinition itself along with its tags)
 func (ecs *ECS) DescribeTaskDefinition(taskDefinitionArn string) *awsecs.DescribeTaskDefinitionOutput {
-	if taskDefinitionCache[taskDefinitionArn] != nil {
-		return taskDefinitionCache[taskDefinitionArn]
+	key := ecs.Region() + "/" + taskDefinitionArn
+
+	taskDefinitionCacheMutex.Lock()
+	cached := taskDefinitionCache[key]
+	taskDefinitionCacheMutex.Unlock()
+
+	if cached != nil {
+		return cached
 	}
 
 	includeTags := "TAGS"
@@ -216,28 +412,93 @@ func (ecs *ECS) DescribeTaskDefinition(taskDefinitionArn string) *awsecs.Describ
 		console.ErrorExit(err, "Could not describe ECS task definition")
 	}
 
-	taskDefinitionCache[taskDefinitionArn] = resp
+	taskDefinitionCacheMutex.Lock()
+	taskDefinitionCache[key] = resp
+	taskDefinitionCacheMutex.Unlock()
 
-	return taskDefinitionCache[taskDefinitionArn]
+	return resp
 }
 
-//UpdateTaskDefinitionImage registers a new task definition with the updated image
-func (ecs *ECS) UpdateTaskDefinitionImage(taskDefinitionArn, image string) string {
+//prefetchTaskDefinitions describes task definitions concurrently, each once,
+//so later DescribeTaskDefinition calls for them are answered from the cache
+func (ecs *ECS) prefetchTaskDefinitions(taskDefinitionArns []string) {
+	slots := make(chan struct{}, maxConcurrentTaskDefinitionDescribes)
+	seen := make(map[string]bool)
+
+	var wg sync.WaitGroup
+
+	for _, taskDefinitionArn := range taskDefinitionArns {
+		if seen[taskDefinitionArn] {
+			continue
+		}
+
+		seen[taskDefinitionArn] = true
+		wg.Add(1)
+
+		go func(taskDefinitionArn string) {
+			defer wg.Done()
+
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			ecs.DescribeTaskDefinition(taskDefinitionArn)
+		}(taskDefinitionArn)
+	}
+
+	wg.Wait()
+}
+
+//UpdateTaskDefinitionImage registers a new task definition with the updated
+//image in the named (or primary) container
+func (ecs *ECS) UpdateTaskDefinitionImage(taskDefinitionArn, containerName, image string) string {
 	dtd := ecs.DescribeTaskDefinition(taskDefinitionArn)
-	dtd.TaskDefinition.ContainerDefinitions[0].Image = aws.String(image)
+	primaryContainerDefinition(dtd.TaskDefinition, containerName).Image = aws.String(image)
 	return ecs.registerTaskDefinition(dtd)
 }
 
+//TaskDefinitionUpdate is what UpdateTaskDefinitionImageAndEnvVars changes in
+//a task definition besides its image and env vars; zero values leave the
+//task definition as it is
+type TaskDefinitionUpdate struct {
+	//ContainerName picks the container to update, the primary container by
+	//default (see PrimaryContainerDefinition)
+	ContainerName string
+
+	//Secrets are replaced or added to like env vars
+	Secrets []Secret
+
+	//EnvironmentFiles (S3 object ARNs) are added to any existing ones
+	EnvironmentFiles []string
+
+	//Sidecars replace containers with the same name or are added alongside
+	//the primary container, logging to the same place
+	Sidecars []Sidecar
+
+	//EFSVolumes replace volumes with the same names and are mounted into the
+	//primary container
+	EFSVolumes []EFSVolume
+
+	//RepositoryCredentials (a Secrets Manager secret ARN) are used to pull the
+	//primary container's image from a private registry
+	RepositoryCredentials string
+
+	TaskRoleArn string
+
+	//PidMode sets the task's PID mode, which can only be task on Fargate
+	PidMode string
+}
+
 //UpdateTaskDefinitionImageAndEnvVars creates a new, updated task definition
-// based on the specified image and env vars.
+// based on the specified image and env vars, along with any other changes in
+// update.
 // Note that any existing envvars are replaced by the new ones
-func (ecs *ECS) UpdateTaskDefinitionImageAndEnvVars(taskDefinitionArnOrFamily string, image string, environmentVariables []EnvVar, replaceVars bool, secretVariables []Secret) string {
+func (ecs *ECS) UpdateTaskDefinitionImageAndEnvVars(taskDefinitionArnOrFamily string, image string, environmentVariables []EnvVar, replaceVars bool, update TaskDefinitionUpdate) string {
 
 	//fetch task definition details (for specific or latest active)
 	dtd := ecs.DescribeTaskDefinition(taskDefinitionArnOrFamily)
 
 	//which container are we updating?
-	container := dtd.TaskDefinition.ContainerDefinitions[0]
+	container := primaryContainerDefinition(dtd.TaskDefinition, update.ContainerName)
 
 	//update image if specified
 	if image != "" {
@@ -253,28 +514,94 @@ func (ecs *ECS) UpdateTaskDefinitionImageAndEnvVars(taskDefinitionArnOrFamily st
 			container.Environment = envvars
 
 		} else {
-			for _, e := range envvars {
-				container.Environment = append(container.Environment, e)
-			}
+			container.Environment = addVarsToEnvironment(container.Environment, environmentVariables)
 		}
 	}
 
 	//convert secrets to aws input format
-	if len(secretVariables) > 0 {
-		secrets := convertSecretVars(secretVariables)
+	if len(update.Secrets) > 0 {
+		secrets := convertSecretVars(update.Secrets)
 
 		if replaceVars {
 			container.Secrets = secrets
 		} else {
-			for _, s := range secrets {
-				container.Secrets = append(container.Secrets, s)
-			}
+			container.Secrets = addVarsToSecrets(container.Secrets, update.Secrets)
+		}
+	}
+
+	container.EnvironmentFiles = addEnvironmentFiles(container.EnvironmentFiles, update.EnvironmentFiles)
+
+	if update.RepositoryCredentials != "" {
+		if err := ValidateRepositoryCredentials(update.RepositoryCredentials); err != nil {
+			console.ErrorExit(err, "Invalid repository credentials")
+		}
+
+		container.RepositoryCredentials = &awsecs.RepositoryCredentials{
+			CredentialsParameter: aws.String(update.RepositoryCredentials),
+		}
+	}
+
+	for _, sidecar := range update.Sidecars {
+		if err := sidecar.Validate(); err != nil {
+			console.ErrorExit(err, "Invalid sidecar")
+		}
+
+		if sidecar.Name == aws.StringValue(container.Name) {
+			console.IssueExit("Sidecar %s has the same name as the task's container", sidecar.Name)
+		}
+	}
+
+	dtd.TaskDefinition.ContainerDefinitions = setSidecars(dtd.TaskDefinition.ContainerDefinitions, update.Sidecars, container.LogConfiguration)
+
+	for _, efsVolume := range update.EFSVolumes {
+		if err := efsVolume.Validate(); err != nil {
+			console.ErrorExit(err, "Invalid EFS volume")
 		}
 	}
 
+	dtd.TaskDefinition.Volumes = setEFSVolumes(dtd.TaskDefinition.Volumes, container, update.EFSVolumes)
+
+	if update.TaskRoleArn != "" {
+		dtd.TaskDefinition.TaskRoleArn = aws.String(update.TaskRoleArn)
+	}
+
+	if update.PidMode != "" {
+		if err := ValidatePidMode(update.PidMode); err != nil {
+			console.ErrorExit(err, "Invalid PID mode")
+		}
+
+		dtd.TaskDefinition.PidMode = aws.String(update.PidMode)
+	}
+
 	return ecs.registerTaskDefinition(dtd)
 }
 
+//addEnvironmentFiles appends S3 environment files (by object ARN) that
+//aren't already loaded by the container
+func addEnvironmentFiles(current []*awsecs.EnvironmentFile, objectArns []string) []*awsecs.EnvironmentFile {
+	for _, objectArn := range objectArns {
+		found := false
+
+		for _, environmentFile := range current {
+			if aws.StringValue(environmentFile.Value) == objectArn {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			current = append(current,
+				&awsecs.EnvironmentFile{
+					Type:  aws.String(awsecs.EnvironmentFileTypeS3),
+					Value: aws.String(objectArn),
+				},
+			)
+		}
+	}
+
+	return current
+}
+
 //registers a new task definition based on a task definition output struct
 //which includes tags
 func (ecs *ECS) registerTaskDefinition(dtd *awsecs.DescribeTaskDefinitionOutput) string {
@@ -290,6 +617,9 @@ func (ecs *ECS) registerTaskDefinition(dtd *awsecs.DescribeTaskDefinitionOutput)
 		TaskRoleArn:             dtd.TaskDefinition.TaskRoleArn,
 		Volumes:                 dtd.TaskDefinition.Volumes,
 		RuntimePlatform:         dtd.TaskDefinition.RuntimePlatform,
+		ProxyConfiguration:      dtd.TaskDefinition.ProxyConfiguration,
+		PidMode:                 dtd.TaskDefinition.PidMode,
+		IpcMode:                 dtd.TaskDefinition.IpcMode,
 	}
 
 	//it's unfortunate that the tags aren't included in the task definition itself :(
@@ -306,30 +636,34 @@ func (ecs *ECS) registerTaskDefinition(dtd *awsecs.DescribeTaskDefinitionOutput)
 	return aws.StringValue(resp.TaskDefinition.TaskDefinitionArn)
 }
 
-//AddEnvVarsToTaskDefinition registers a new task definition with the envvars appended
-func (ecs *ECS) AddEnvVarsToTaskDefinition(taskDefinitionArn string, envVars []EnvVar, secretVars []Secret) string {
+//AddEnvVarsToTaskDefinition registers a new task definition with the envvars
+//appended to the named (or primary) container
+func (ecs *ECS) AddEnvVarsToTaskDefinition(taskDefinitionArn, containerName string, envVars []EnvVar, secretVars []Secret) string {
 	dtd := ecs.DescribeTaskDefinition(taskDefinitionArn)
+	container := primaryContainerDefinition(dtd.TaskDefinition, containerName)
 
 	if len(envVars) > 0 {
-		dtd.TaskDefinition.ContainerDefinitions[0].Environment = addVarsToEnvironment(dtd.TaskDefinition.ContainerDefinitions[0].Environment, envVars)
+		container.Environment = addVarsToEnvironment(container.Environment, envVars)
 	}
 
 	if len(secretVars) > 0 {
-		dtd.TaskDefinition.ContainerDefinitions[0].Secrets = addVarsToSecrets(dtd.TaskDefinition.ContainerDefinitions[0].Secrets, secretVars)
+		container.Secrets = addVarsToSecrets(container.Secrets, secretVars)
 	}
 
 	return ecs.registerTaskDefinition(dtd)
 }
 
-//RemoveEnvVarsFromTaskDefinition registers a new task definition with the specified keys removed
-func (ecs *ECS) RemoveEnvVarsFromTaskDefinition(taskDefinitionArn string, keys []string) string {
+//RemoveEnvVarsFromTaskDefinition registers a new task definition with the
+//specified keys removed from the named (or primary) container
+func (ecs *ECS) RemoveEnvVarsFromTaskDefinition(taskDefinitionArn, containerName string, keys []string) string {
 	var newEnvironment []*awsecs.KeyValuePair
 	var newSecrets []*awsecs.Secret
 
 	//look up task definition
 	dtd := ecs.DescribeTaskDefinition(taskDefinitionArn)
-	environment := dtd.TaskDefinition.ContainerDefinitions[0].Environment
-	secrets := dtd.TaskDefinition.ContainerDefinitions[0].Secrets
+	container := primaryContainerDefinition(dtd.TaskDefinition, containerName)
+	environment := container.Environment
+	secrets := container.Secrets
 
 	//iterate existing envvars
 	for _, keyValuePair := range environment {
@@ -367,19 +701,20 @@ func (ecs *ECS) RemoveEnvVarsFromTaskDefinition(taskDefinitionArn string, keys [
 		}
 	}
 
-	dtd.TaskDefinition.ContainerDefinitions[0].Environment = newEnvironment
-	dtd.TaskDefinition.ContainerDefinitions[0].Secrets = newSecrets
+	container.Environment = newEnvironment
+	container.Secrets = newSecrets
 
 	return ecs.registerTaskDefinition(dtd)
 }
 
-//GetEnvVarsFromTaskDefinition retrieves envvars from an existing task definition
-func (ecs *ECS) GetEnvVarsFromTaskDefinition(taskDefinitionArn string) []EnvVar {
+//GetEnvVarsFromTaskDefinition retrieves envvars from the named (or primary)
+//container of an existing task definition
+func (ecs *ECS) GetEnvVarsFromTaskDefinition(taskDefinitionArn, containerName string) []EnvVar {
 	var envVars []EnvVar
 
 	taskDefinition := ecs.DescribeTaskDefinition(taskDefinitionArn).TaskDefinition
 
-	for _, keyValuePair := range taskDefinition.ContainerDefinitions[0].Environment {
+	for _, keyValuePair := range primaryContainerDefinition(taskDefinition, containerName).Environment {
 		envVars = append(envVars,
 			EnvVar{
 				Key:   aws.StringValue(keyValuePair.Name),
@@ -391,13 +726,14 @@ func (ecs *ECS) GetEnvVarsFromTaskDefinition(taskDefinitionArn string) []EnvVar
 	return envVars
 }
 
-//GetSecretVarsFromTaskDefinition retrieves secret vars from an existing task definition
-func (ecs *ECS) GetSecretVarsFromTaskDefinition(taskDefinitionArn string) []EnvVar {
+//GetSecretVarsFromTaskDefinition retrieves secret vars from the named (or
+//primary) container of an existing task definition
+func (ecs *ECS) GetSecretVarsFromTaskDefinition(taskDefinitionArn, containerName string) []EnvVar {
 	var secretVars []EnvVar
 
 	taskDefinition := ecs.DescribeTaskDefinition(taskDefinitionArn).TaskDefinition
 
-	for _, keyValuePair := range taskDefinition.ContainerDefinitions[0].Secrets {
+	for _, keyValuePair := range primaryContainerDefinition(taskDefinition, containerName).Secrets {
 		secretVars = append(secretVars,
 			EnvVar{
 				Key:   aws.StringValue(keyValuePair.Name),
@@ -435,6 +771,60 @@ func (ecs *ECS) GetTaskDefinitionARN(region string, account string, family strin
 	return fmt.Sprintf("arn:aws:ecs:%s:%s:task-definition/%s:%s", region, account, family, revisionNumber)
 }
 
+//ListTaskDefinitionRevisions returns the ARNs of a family's active task
+//definition revisions, newest first
+func (ecs *ECS) ListTaskDefinitionRevisions(family string) ([]string, error) {
+	var revisions []string
+
+	err := ecs.svc.ListTaskDefinitionsPages(
+		&awsecs.ListTaskDefinitionsInput{
+			FamilyPrefix: aws.String(family),
+			Sort:         aws.String(awsecs.SortOrderDesc),
+			Status:       aws.String(awsecs.TaskDefinitionStatusActive),
+		},
+		func(resp *awsecs.ListTaskDefinitionsOutput, lastPage bool) bool {
+			//the family is matched as a prefix, so web also matches web-worker
+			for _, taskDefinitionArn := range aws.StringValueSlice(resp.TaskDefinitionArns) {
+				if ecs.GetTaskFamily(taskDefinitionArn) == family {
+					revisions = append(revisions, taskDefinitionArn)
+				}
+			}
+
+			return true
+		},
+	)
+
+	return revisions, err
+}
+
+//DeregisterTaskDefinition marks a task definition revision inactive, so new
+//tasks and services can no longer use it
+func (ecs *ECS) DeregisterTaskDefinition(taskDefinitionArn string) error {
+	_, err := ecs.svc.DeregisterTaskDefinition(
+		&awsecs.DeregisterTaskDefinitionInput{
+			TaskDefinition: aws.String(taskDefinitionArn),
+		},
+	)
+
+	return err
+}
+
+//SupersededRevisions returns the revisions, given newest first, other than
+//the newest keep revisions and any revision in use
+func SupersededRevisions(revisions []string, keep int, inUse []string) []string {
+	var superseded []string
+
+	for i, revision := range revisions {
+		if i < keep || containsString(inUse, revision) {
+			continue
+		}
+
+		superseded = append(superseded, revision)
+	}
+
+	return superseded
+}
+
 //GetTaskFamily returns the task family from a task definition ARN
 func (ecs *ECS) GetTaskFamily(taskDefinitionArn string) string {
 	contents := strings.Split(taskDefinitionArn, ":")
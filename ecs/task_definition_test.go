@@ -1,9 +1,14 @@
 package ecs
 
 import (
+	"reflect"
+	"sync"
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
+	awsecs "github.com/aws/aws-sdk-go/service/ecs"
 )
 
 func TestSortEnvVars(t *testing.T) {
@@ -121,3 +126,413 @@ func TestResolveRevisionNumber_InvalidInput(t *testing.T) {
 		t.Error("Expected empty string")
 	}
 }
+
+func TestCreateTaskDefinitionInputValidate(t *testing.T) {
+	var tests = []struct {
+		pidMode string
+		valid   bool
+	}{
+		{"", true},
+		{"task", true},
+		{"host", false},
+	}
+
+	for _, test := range tests {
+		input := &CreateTaskDefinitionInput{Name: "web", Image: "web:1.0", PidMode: test.pidMode}
+		err := input.Validate()
+
+		if test.valid && err != nil {
+			t.Errorf("expected pidMode %q to be valid, got %v", test.pidMode, err)
+		}
+
+		if !test.valid && err == nil {
+			t.Errorf("expected pidMode %q to be invalid", test.pidMode)
+		}
+	}
+}
+
+func TestCreateTaskDefinitionInputValidateContainerAndVolumes(t *testing.T) {
+	volume := EFSVolume{FileSystemID: "fs-12345678", ContainerPath: "/data"}
+
+	var tests = []struct {
+		name  string
+		input CreateTaskDefinitionInput
+		valid bool
+	}{
+		{"no image", CreateTaskDefinitionInput{Name: "web"}, false},
+		{"no name", CreateTaskDefinitionInput{Image: "web:1.0"}, false},
+		{"mount point with volume", CreateTaskDefinitionInput{Name: "web", Image: "web:1.0", Volumes: []*awsecs.Volume{volume.Volume()}, MountPoints: []*awsecs.MountPoint{volume.MountPoint()}}, true},
+		{"mount point without volume", CreateTaskDefinitionInput{Name: "web", Image: "web:1.0", MountPoints: []*awsecs.MountPoint{volume.MountPoint()}}, false},
+	}
+
+	for _, test := range tests {
+		if err := test.input.Validate(); (err == nil) != test.valid {
+			t.Errorf("%s: Validate() => %v, want valid %t", test.name, err, test.valid)
+		}
+	}
+}
+
+func TestCreateTaskDefinitionInputRegisterTaskDefinitionInput(t *testing.T) {
+	input := &CreateTaskDefinitionInput{
+		Cpu:          "256",
+		Memory:       "512",
+		Image:        "web:1.0",
+		Name:         "web",
+		Type:         "service",
+		Port:         53,
+		PortProtocol: "UDP",
+		Sidecars:     []Sidecar{{Name: "datadog", Image: "datadog/agent:7"}},
+	}
+
+	registerInput, err := input.RegisterTaskDefinitionInput()
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if family := aws.StringValue(registerInput.Family); family != "service_web" {
+		t.Errorf("expected family service_web, got %s", family)
+	}
+
+	if len(registerInput.ContainerDefinitions) != 2 {
+		t.Fatalf("expected 2 container definitions, got %d", len(registerInput.ContainerDefinitions))
+	}
+
+	container := registerInput.ContainerDefinitions[0]
+
+	if !aws.BoolValue(container.Essential) || aws.StringValue(container.Image) != "web:1.0" {
+		t.Errorf("expected essential container with image web:1.0, got %v", container)
+	}
+
+	if protocol := aws.StringValue(container.PortMappings[0].Protocol); protocol != awsecs.TransportProtocolUdp {
+		t.Errorf("expected udp port mapping, got %s", protocol)
+	}
+
+	if _, err := (&CreateTaskDefinitionInput{Name: "web"}).RegisterTaskDefinitionInput(); err == nil {
+		t.Error("expected an error for an input without an image")
+	}
+}
+
+func TestPrefetchTaskDefinitions(t *testing.T) {
+	var mutex sync.Mutex
+	described := make(map[string]int)
+
+	ecs := newRecordingECS(func(r *request.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		described[aws.StringValue(r.Params.(*awsecs.DescribeTaskDefinitionInput).TaskDefinition)]++
+	})
+
+	web := "arn:aws:ecs:us-east-1:123456789012:task-definition/prefetch-web:1"
+	worker := "arn:aws:ecs:us-east-1:123456789012:task-definition/prefetch-worker:1"
+
+	ecs.prefetchTaskDefinitions([]string{web, worker, web, web})
+
+	if len(described) != 2 || described[web] != 1 || described[worker] != 1 {
+		t.Errorf("expected each task definition to be described once, got %v", described)
+	}
+
+	//later lookups are answered from the cache
+	ecs.DescribeTaskDefinition(web)
+
+	if described[web] != 1 {
+		t.Errorf("expected %s to be cached, described %d times", web, described[web])
+	}
+}
+
+func TestSupersededRevisions(t *testing.T) {
+	revisions := []string{
+		"arn:aws:ecs:us-east-1:123456789012:task-definition/web:5",
+		"arn:aws:ecs:us-east-1:123456789012:task-definition/web:4",
+		"arn:aws:ecs:us-east-1:123456789012:task-definition/web:3",
+		"arn:aws:ecs:us-east-1:123456789012:task-definition/web:2",
+		"arn:aws:ecs:us-east-1:123456789012:task-definition/web:1",
+	}
+
+	var tests = []struct {
+		keep     int
+		inUse    []string
+		expected []string
+	}{
+		{2, nil, revisions[2:]},
+		{2, []string{revisions[3]}, []string{revisions[2], revisions[4]}},
+		{5, nil, nil},
+		{10, nil, nil},
+	}
+
+	for _, test := range tests {
+		if got := SupersededRevisions(revisions, test.keep, test.inUse); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("SupersededRevisions(keep %d, in use %v) => %v, want %v", test.keep, test.inUse, got, test.expected)
+		}
+	}
+}
+
+func TestListTaskDefinitionRevisions(t *testing.T) {
+	var input *awsecs.ListTaskDefinitionsInput
+
+	ecs := newRecordingECS(func(r *request.Request) {
+		input = r.Params.(*awsecs.ListTaskDefinitionsInput)
+		r.Data.(*awsecs.ListTaskDefinitionsOutput).TaskDefinitionArns = aws.StringSlice([]string{
+			"arn:aws:ecs:us-east-1:123456789012:task-definition/web-worker:3",
+			"arn:aws:ecs:us-east-1:123456789012:task-definition/web:2",
+			"arn:aws:ecs:us-east-1:123456789012:task-definition/web:1",
+		})
+	})
+
+	revisions, err := ecs.ListTaskDefinitionRevisions("web")
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := []string{
+		"arn:aws:ecs:us-east-1:123456789012:task-definition/web:2",
+		"arn:aws:ecs:us-east-1:123456789012:task-definition/web:1",
+	}
+
+	if !reflect.DeepEqual(revisions, expected) {
+		t.Errorf("expected %v, got %v", expected, revisions)
+	}
+
+	if aws.StringValue(input.Sort) != awsecs.SortOrderDesc || aws.StringValue(input.Status) != awsecs.TaskDefinitionStatusActive {
+		t.Errorf("expected active revisions newest first, got sort %s and status %s", aws.StringValue(input.Sort), aws.StringValue(input.Status))
+	}
+}
+
+func TestPrimaryContainerDefinition(t *testing.T) {
+	envoy := &awsecs.ContainerDefinition{Name: aws.String("envoy"), Image: aws.String("envoy:latest")}
+	app := &awsecs.ContainerDefinition{Name: aws.String("web"), Image: aws.String("web:1.0")}
+	datadog := &awsecs.ContainerDefinition{Name: aws.String("datadog-agent"), Image: aws.String("datadog/agent")}
+
+	var tests = []struct {
+		name          string
+		family        string
+		containerName string
+		containers    []*awsecs.ContainerDefinition
+		want          *awsecs.ContainerDefinition
+	}{
+		{"load balancer container second", "service_web", "web", []*awsecs.ContainerDefinition{envoy, app}, app},
+		{"family convention second", "service_web", "", []*awsecs.ContainerDefinition{datadog, app}, app},
+		{"unprefixed family second", "web", "", []*awsecs.ContainerDefinition{datadog, app}, app},
+		{"fallback to first", "other", "", []*awsecs.ContainerDefinition{datadog, app}, datadog},
+		{"unknown container name", "other", "missing", []*awsecs.ContainerDefinition{envoy, app}, envoy},
+	}
+
+	for _, test := range tests {
+		taskDefinition := &awsecs.TaskDefinition{
+			Family:               aws.String(test.family),
+			ContainerDefinitions: test.containers,
+		}
+
+		if got := PrimaryContainerDefinition(taskDefinition, test.containerName); got != test.want {
+			t.Errorf("%s: expected %s, got %s", test.name, aws.StringValue(test.want.Name), aws.StringValue(got.Name))
+		}
+	}
+
+	if got := PrimaryContainerDefinition(&awsecs.TaskDefinition{}, ""); got != nil {
+		t.Errorf("expected nil for a task definition without containers, got %v", got)
+	}
+}
+
+//newSidecarFirstECS returns a client whose task definitions have a sidecar
+//before the web container, recording the task definitions it registers
+func newSidecarFirstECS(registered *[]*awsecs.RegisterTaskDefinitionInput) ECS {
+	return newRecordingECS(func(r *request.Request) {
+		switch input := r.Params.(type) {
+		case *awsecs.DescribeTaskDefinitionInput:
+			r.Data.(*awsecs.DescribeTaskDefinitionOutput).TaskDefinition = &awsecs.TaskDefinition{
+				Family:            aws.String("service_web"),
+				TaskDefinitionArn: input.TaskDefinition,
+				ContainerDefinitions: []*awsecs.ContainerDefinition{
+					&awsecs.ContainerDefinition{
+						Name:        aws.String("datadog-agent"),
+						Image:       aws.String("datadog/agent:7"),
+						Environment: []*awsecs.KeyValuePair{{Name: aws.String("DD_SITE"), Value: aws.String("datadoghq.com")}},
+					},
+					&awsecs.ContainerDefinition{
+						Name:        aws.String("web"),
+						Image:       aws.String("web:1.0"),
+						Environment: []*awsecs.KeyValuePair{{Name: aws.String("PORT"), Value: aws.String("8080")}},
+					},
+				},
+			}
+		case *awsecs.RegisterTaskDefinitionInput:
+			*registered = append(*registered, input)
+			r.Data.(*awsecs.RegisterTaskDefinitionOutput).TaskDefinition = &awsecs.TaskDefinition{
+				TaskDefinitionArn: aws.String("arn:aws:ecs:us-east-1:123456789012:task-definition/service_web:2"),
+			}
+		}
+	})
+}
+
+func TestTaskDefinitionUpdatesWithSidecarFirst(t *testing.T) {
+	var registered []*awsecs.RegisterTaskDefinitionInput
+
+	ecs := newSidecarFirstECS(&registered)
+
+	ecs.UpdateTaskDefinitionImage("arn:aws:ecs:us-east-1:123456789012:task-definition/service_web:101", "web", "web:2.0")
+	ecs.AddEnvVarsToTaskDefinition("arn:aws:ecs:us-east-1:123456789012:task-definition/service_web:102", "", []EnvVar{{Key: "FOO", Value: "bar"}}, nil)
+	ecs.RemoveEnvVarsFromTaskDefinition("arn:aws:ecs:us-east-1:123456789012:task-definition/service_web:103", "", []string{"PORT", "DD_SITE"})
+	ecs.UpdateTaskDefinitionImageAndEnvVars("arn:aws:ecs:us-east-1:123456789012:task-definition/service_web:104", "web:3.0", nil, false, TaskDefinitionUpdate{})
+
+	if len(registered) != 4 {
+		t.Fatalf("expected 4 task definitions to be registered, got %d", len(registered))
+	}
+
+	for i, input := range registered {
+		sidecar := input.ContainerDefinitions[0]
+
+		if aws.StringValue(sidecar.Image) != "datadog/agent:7" || len(sidecar.Environment) != 1 {
+			t.Errorf("update %d: expected the sidecar to be unchanged, got %v", i, sidecar)
+		}
+	}
+
+	web := func(i int) *awsecs.ContainerDefinition { return registered[i].ContainerDefinitions[1] }
+
+	if image := aws.StringValue(web(0).Image); image != "web:2.0" {
+		t.Errorf("expected the web container image to be updated, got %s", image)
+	}
+
+	if len(web(1).Environment) != 2 || aws.StringValue(web(1).Environment[1].Name) != "FOO" {
+		t.Errorf("expected FOO to be added to the web container, got %v", web(1).Environment)
+	}
+
+	if len(web(2).Environment) != 0 {
+		t.Errorf("expected PORT to be removed from the web container, got %v", web(2).Environment)
+	}
+
+	if image := aws.StringValue(web(3).Image); image != "web:3.0" {
+		t.Errorf("expected the web container image to be updated, got %s", image)
+	}
+}
+
+func TestGetEnvVarsFromTaskDefinitionWithSidecarFirst(t *testing.T) {
+	ecs := newSidecarFirstECS(nil)
+
+	envVars := ecs.GetEnvVarsFromTaskDefinition("arn:aws:ecs:us-east-1:123456789012:task-definition/service_web:105", "")
+
+	if expected := []EnvVar{{Key: "PORT", Value: "8080"}}; !reflect.DeepEqual(envVars, expected) {
+		t.Errorf("expected %v, got %v", expected, envVars)
+	}
+}
+
+func TestAddEnvironmentFiles(t *testing.T) {
+	current := []*awsecs.EnvironmentFile{
+		&awsecs.EnvironmentFile{
+			Type:  aws.String(awsecs.EnvironmentFileTypeS3),
+			Value: aws.String("arn:aws:s3:::shared/base.env"),
+		},
+	}
+
+	files := addEnvironmentFiles(current, []string{"arn:aws:s3:::shared/base.env", "arn:aws:s3:::my-bucket/app.env"})
+
+	if len(files) != 2 {
+		t.Fatalf("expected 2 environment files, got %d", len(files))
+	}
+
+	if aws.StringValue(files[1].Value) != "arn:aws:s3:::my-bucket/app.env" || aws.StringValue(files[1].Type) != "s3" {
+		t.Errorf("expected s3 file arn:aws:s3:::my-bucket/app.env, got %s %s", aws.StringValue(files[1].Type), aws.StringValue(files[1].Value))
+	}
+
+	if files := addEnvironmentFiles(nil, nil); len(files) != 0 {
+		t.Errorf("expected no environment files, got %v", files)
+	}
+}
+
+func TestAddVarsToEnvironment(t *testing.T) {
+	current := []*awsecs.KeyValuePair{
+		{Name: aws.String("ZEBRA"), Value: aws.String("z")},
+		{Name: aws.String("LOG_LEVEL"), Value: aws.String("info")},
+		{Name: aws.String("APPLE"), Value: aws.String("a")},
+	}
+
+	environment := addVarsToEnvironment(current, []EnvVar{{Key: "LOG_LEVEL", Value: "debug"}, {Key: "EXTRA", Value: "1"}})
+	expected := []string{"ZEBRA=z", "LOG_LEVEL=debug", "APPLE=a", "EXTRA=1"}
+
+	if len(environment) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, environment)
+	}
+
+	for i, kv := range environment {
+		if got := aws.StringValue(kv.Name) + "=" + aws.StringValue(kv.Value); got != expected[i] {
+			t.Errorf("expected %s at %d, got %s", expected[i], i, got)
+		}
+	}
+
+	if aws.StringValue(current[1].Value) != "info" {
+		t.Error("expected the current environment not to be modified")
+	}
+}
+
+func TestAddVarsToSecrets(t *testing.T) {
+	current := []*awsecs.Secret{
+		{Name: aws.String("B"), ValueFrom: aws.String("arn:b")},
+		{Name: aws.String("A"), ValueFrom: aws.String("arn:a")},
+	}
+
+	secrets := addVarsToSecrets(current, []Secret{{Key: "A", ValueFrom: "arn:a2"}, {Key: "C", ValueFrom: "arn:c"}})
+	expected := []string{"B=arn:b", "A=arn:a2", "C=arn:c"}
+
+	if len(secrets) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, secrets)
+	}
+
+	for i, secret := range secrets {
+		if got := aws.StringValue(secret.Name) + "=" + aws.StringValue(secret.ValueFrom); got != expected[i] {
+			t.Errorf("expected %s at %d, got %s", expected[i], i, got)
+		}
+	}
+}
+
+func TestValidateRepositoryCredentials(t *testing.T) {
+	var tests = []struct {
+		secretArn string
+		valid     bool
+	}{
+		{"arn:aws:secretsmanager:us-east-1:123456789012:secret:registry-AbCdEf", true},
+		{"arn:aws:secretsmanager:us-east-1:123456789012:secret:team/dockerhub-AbCdEf", true},
+		{"arn:aws-us-gov:secretsmanager:us-gov-west-1:123456789012:secret:registry-AbCdEf", true},
+		{"arn:aws:ssm:us-east-1:123456789012:parameter/registry", false},
+		{"registry-AbCdEf", false},
+		{"arn:aws:secretsmanager:us-east-1:123456789012:secret:registry-AbCdEf:password::", false},
+	}
+
+	for _, test := range tests {
+		err := ValidateRepositoryCredentials(test.secretArn)
+
+		if test.valid && err != nil {
+			t.Errorf("%s: expected valid, got %v", test.secretArn, err)
+		}
+
+		if !test.valid && err == nil {
+			t.Errorf("%s: expected error, got none", test.secretArn)
+		}
+	}
+}
+
+func TestPortMappings(t *testing.T) {
+	var tests = []struct {
+		protocol  string
+		protocols []string
+	}{
+		{"", []string{"tcp"}},
+		{"HTTP", []string{"tcp"}},
+		{"UDP", []string{"udp"}},
+		{"TCP_UDP", []string{"tcp", "udp"}},
+	}
+
+	for _, test := range tests {
+		mappings := portMappings(1935, test.protocol)
+
+		if len(mappings) != len(test.protocols) {
+			t.Fatalf("%s: expected %d port mappings, got %v", test.protocol, len(test.protocols), mappings)
+		}
+
+		for i, mapping := range mappings {
+			if aws.Int64Value(mapping.ContainerPort) != 1935 || aws.StringValue(mapping.Protocol) != test.protocols[i] {
+				t.Errorf("%s: expected %s:1935, got %s", test.protocol, test.protocols[i], mapping)
+			}
+		}
+	}
+}
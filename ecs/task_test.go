@@ -1,8 +1,17 @@
 package ecs
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
 	awsecs "github.com/aws/aws-sdk-go/service/ecs"
 )
 
@@ -111,3 +120,299 @@ func TestDetermineENIDetails_ServiceConnect(t *testing.T) {
 		t.Errorf("Should find subnetid. Was %s expected %s", subnetResult, expectedSubnet)
 	}
 }
+
+func TestStartedBy(t *testing.T) {
+	if got := StartedBy("", "migrate"); got != "fargate:migrate" {
+		t.Errorf("Expected fargate:migrate, got %s", got)
+	}
+
+	if got := StartedBy("staging", "migrate"); got != "fargate:staging:migrate" {
+		t.Errorf("Expected fargate:staging:migrate, got %s", got)
+	}
+}
+
+func TestParseStartedBy(t *testing.T) {
+	var tests = []struct {
+		startedBy     string
+		namespace     string
+		taskGroupName string
+		ok            bool
+	}{
+		{"fargate:migrate", "", "migrate", true},
+		{"fargate:staging:migrate", "staging", "migrate", true},
+		{"fargate:team-a:nightly-report", "team-a", "nightly-report", true},
+		{"ecs-svc/1234567890", "", "", false},
+		{"fargate:", "", "", false},
+		{"", "", "", false},
+	}
+
+	for _, test := range tests {
+		namespace, taskGroupName, ok := ParseStartedBy(test.startedBy)
+
+		if ok != test.ok || namespace != test.namespace || taskGroupName != test.taskGroupName {
+			t.Errorf("ParseStartedBy(%q) => (%q, %q, %t), want (%q, %q, %t)",
+				test.startedBy, namespace, taskGroupName, ok, test.namespace, test.taskGroupName, test.ok)
+		}
+	}
+}
+
+func TestValidateTaskGroupName(t *testing.T) {
+	//fargate: is 8 characters, so 119 leaves the startedBy value at 127
+	longest := strings.Repeat("a", 119)
+
+	var tests = []struct {
+		namespace     string
+		taskGroupName string
+		valid         bool
+	}{
+		{"", "migrate", true},
+		{"", longest, true},
+		{"", longest + "a", false},
+		{"staging", longest[:111], true},
+		{"staging", longest[:112], false},
+		{"", "", false},
+		{"", "team:migrate", false},
+	}
+
+	for _, test := range tests {
+		err := ValidateTaskGroupName(test.namespace, test.taskGroupName)
+
+		if test.valid && err != nil {
+			t.Errorf("%q/%d chars: expected valid, got %v", test.namespace, len(test.taskGroupName), err)
+		}
+
+		if !test.valid && err == nil {
+			t.Errorf("%q/%d chars: expected error, got none", test.namespace, len(test.taskGroupName))
+		}
+	}
+
+	if !IsTruncatedStartedBy(StartedBy("", longest+"a")) {
+		t.Error("expected a 128 character startedBy value to be treated as truncated")
+	}
+
+	if IsTruncatedStartedBy(StartedBy("", longest)) {
+		t.Error("expected a 127 character startedBy value not to be treated as truncated")
+	}
+}
+
+func TestParseStartedBy_RoundTrip(t *testing.T) {
+	for _, namespace := range []string{"", "prod"} {
+		startedBy := StartedBy(namespace, "web")
+		gotNamespace, gotName, ok := ParseStartedBy(startedBy)
+
+		if !ok || gotNamespace != namespace || gotName != "web" {
+			t.Errorf("Expected (%q, web) from %s, got (%q, %q)", namespace, startedBy, gotNamespace, gotName)
+		}
+	}
+}
+
+func TestNetworkFailureHint(t *testing.T) {
+	var tests = []struct {
+		stoppedReason string
+		hint          bool
+	}{
+		{"CannotPullContainerError: Error response from daemon: Get https://123456789012.dkr.ecr.us-east-1.amazonaws.com/v2/: net/http: request canceled while waiting for connection (Client.Timeout exceeded while awaiting headers)", true},
+		{"ResourceInitializationError: unable to pull secrets or registry auth: execution resource retrieval failed: unable to retrieve ecr registry auth: service call has been retried 3 time(s): RequestError: send request failed caused by: Post \"https://api.ecr.us-east-1.amazonaws.com/\": dial tcp 10.0.0.1:443: i/o timeout", true},
+		{"CannotPullContainerError: pull image manifest has been retried 5 time(s): failed to resolve ref docker.io/library/web:missing: not found", false},
+		{"Essential container in task exited", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		if got := NetworkFailureHint(test.stoppedReason) != ""; got != test.hint {
+			t.Errorf("NetworkFailureHint(%q) hint => %t, want %t", test.stoppedReason, got, test.hint)
+		}
+	}
+}
+
+func TestAssignPublicIp(t *testing.T) {
+	if got := aws.StringValue(assignPublicIp(true)); got != awsecs.AssignPublicIpEnabled {
+		t.Errorf("assignPublicIp(true) => %s, want %s", got, awsecs.AssignPublicIpEnabled)
+	}
+
+	if got := aws.StringValue(assignPublicIp(false)); got != awsecs.AssignPublicIpDisabled {
+		t.Errorf("assignPublicIp(false) => %s, want %s", got, awsecs.AssignPublicIpDisabled)
+	}
+}
+
+func TestTaskJSON(t *testing.T) {
+	task := Task{
+		TaskId:  "abc123",
+		EnvVars: []EnvVar{{Key: "FOO", Value: "bar"}},
+	}
+
+	raw, err := json.Marshal(task)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var fields map[string]interface{}
+	json.Unmarshal(raw, &fields)
+
+	for _, key := range []string{"cpu", "createdAt", "deploymentId", "desiredStatus", "eniId", "envVars", "image",
+		"lastStatus", "memory", "securityGroupIds", "startedBy", "stoppedAt", "stoppedReason", "subnetId", "taskId", "taskRole", "transitioning"} {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("expected key %s in %s", key, raw)
+		}
+	}
+
+	if len(fields) != 17 {
+		t.Errorf("expected 17 keys, got %d: %s", len(fields), raw)
+	}
+
+	if !strings.Contains(string(raw), `"envVars":[{"key":"FOO","value":"bar"}]`) {
+		t.Errorf("unexpected envVars encoding: %s", raw)
+	}
+}
+
+func TestTaskTransition(t *testing.T) {
+	var tests = []struct {
+		desiredStatus string
+		lastStatus    string
+		transition    string
+	}{
+		{"RUNNING", "RUNNING", ""},
+		{"STOPPED", "STOPPED", ""},
+		{"RUNNING", "PROVISIONING", "starting"},
+		{"RUNNING", "PENDING", "starting"},
+		{"STOPPED", "RUNNING", "stopping"},
+		{"STOPPED", "DEACTIVATING", "stopping"},
+		{"", "RUNNING", ""},
+	}
+
+	for _, test := range tests {
+		task := Task{DesiredStatus: test.desiredStatus, LastStatus: test.lastStatus}
+
+		if got := task.Transition(); got != test.transition {
+			t.Errorf("Transition() for desired %s, last %s => %q, want %q", test.desiredStatus, test.lastStatus, got, test.transition)
+		}
+	}
+}
+
+func TestTaskJSONTransitioning(t *testing.T) {
+	raw, _ := json.Marshal(Task{DesiredStatus: "STOPPED", LastStatus: "RUNNING"})
+
+	if !strings.Contains(string(raw), `"transitioning":true`) {
+		t.Errorf("expected transitioning to be true: %s", raw)
+	}
+}
+
+func TestLatestStoppedTasks(t *testing.T) {
+	now := time.Now()
+	tasks := []Task{
+		{TaskId: "oldest", StoppedAt: now.Add(-30 * time.Minute)},
+		{TaskId: "newest", StoppedAt: now.Add(-1 * time.Minute)},
+		{TaskId: "stopping"},
+		{TaskId: "middle", StoppedAt: now.Add(-10 * time.Minute)},
+	}
+
+	var tests = []struct {
+		limit   int
+		taskIds []string
+	}{
+		{0, []string{"stopping", "newest", "middle", "oldest"}},
+		{2, []string{"stopping", "newest"}},
+		{10, []string{"stopping", "newest", "middle", "oldest"}},
+	}
+
+	for _, test := range tests {
+		var taskIds []string
+
+		for _, task := range LatestStoppedTasks(tasks, test.limit) {
+			taskIds = append(taskIds, task.TaskId)
+		}
+
+		if strings.Join(taskIds, ",") != strings.Join(test.taskIds, ",") {
+			t.Errorf("LatestStoppedTasks(%d) => %v, want %v", test.limit, taskIds, test.taskIds)
+		}
+	}
+
+	if tasks[0].TaskId != "oldest" {
+		t.Error("expected the tasks to be left unsorted")
+	}
+}
+
+//newRecordingECS returns a client that passes each request to record instead
+//of sending it, answering with an empty response
+func newRecordingECS(record func(*request.Request)) ECS {
+	sess := session.Must(session.NewSession(&aws.Config{
+		Region:      aws.String("us-east-1"),
+		Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+	}))
+	ecs := New(sess, "cluster")
+
+	ecs.svc.Handlers.Send.Clear()
+	ecs.svc.Handlers.UnmarshalMeta.Clear()
+	ecs.svc.Handlers.ValidateResponse.Clear()
+	ecs.svc.Handlers.Unmarshal.Clear()
+	ecs.svc.Handlers.Send.PushBack(record)
+
+	return ecs
+}
+
+func TestDescribeTasksBatchesTaskIds(t *testing.T) {
+	var batches [][]string
+
+	ecs := newRecordingECS(func(r *request.Request) {
+		batches = append(batches, aws.StringValueSlice(r.Params.(*awsecs.DescribeTasksInput).Tasks))
+	})
+
+	var taskIds []string
+
+	for i := 0; i < 150; i++ {
+		taskIds = append(taskIds, fmt.Sprintf("task-%d", i))
+	}
+
+	ecs.DescribeTasks(taskIds)
+
+	if len(batches) != 2 {
+		t.Fatalf("expected 2 DescribeTasks calls, got %d", len(batches))
+	}
+
+	if len(batches[0]) != 100 || len(batches[1]) != 50 {
+		t.Errorf("expected batches of 100 and 50 tasks, got %d and %d", len(batches[0]), len(batches[1]))
+	}
+
+	if batches[1][0] != "task-100" {
+		t.Errorf("expected the second batch to start with task-100, got %s", batches[1][0])
+	}
+}
+
+func TestDescribeStoppingServiceTasks(t *testing.T) {
+	var mutex sync.Mutex
+	var describedTaskDefinitions []string
+
+	stopping := "arn:aws:ecs:us-east-1:123456789012:task-definition/stopping-web:2"
+	stopped := "arn:aws:ecs:us-east-1:123456789012:task-definition/stopping-web:1"
+
+	ecs := newRecordingECS(func(r *request.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		switch data := r.Data.(type) {
+		case *awsecs.ListTasksOutput:
+			data.TaskArns = aws.StringSlice([]string{"task-stopping", "task-stopped"})
+		case *awsecs.DescribeTasksOutput:
+			data.Tasks = []*awsecs.Task{
+				{TaskArn: aws.String("task-stopping"), LastStatus: aws.String("DEACTIVATING"), TaskDefinitionArn: aws.String(stopping)},
+				{TaskArn: aws.String("task-stopped"), LastStatus: aws.String("STOPPED"), TaskDefinitionArn: aws.String(stopped)},
+			}
+		case *awsecs.DescribeTaskDefinitionOutput:
+			describedTaskDefinitions = append(describedTaskDefinitions, aws.StringValue(r.Params.(*awsecs.DescribeTaskDefinitionInput).TaskDefinition))
+			data.TaskDefinition = &awsecs.TaskDefinition{
+				ContainerDefinitions: []*awsecs.ContainerDefinition{{Name: aws.String("web")}},
+			}
+		}
+	})
+
+	tasks := ecs.DescribeStoppingServiceTasks(Service{Name: "web"})
+
+	if len(tasks) != 1 || tasks[0].TaskId != "task-stopping" {
+		t.Fatalf("expected only the stopping task, got %v", tasks)
+	}
+
+	if len(describedTaskDefinitions) != 1 || describedTaskDefinitions[0] != stopping {
+		t.Errorf("expected only the stopping task's definition to be described, got %v", describedTaskDefinitions)
+	}
+}
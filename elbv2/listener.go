@@ -54,6 +54,33 @@ func (r Rule) String() string {
 	return strings.Join([]string{r.Type, r.Value}, "=")
 }
 
+// ListenerRule is a complete routing rule on a listener, with all of its conditions.
+// TargetGroupARN is the target group of the rule's first action, while
+// TargetGroupARNs has every target group the rule forwards to.
+type ListenerRule struct {
+	ARN             string
+	Conditions      []string
+	IsDefault       bool
+	ListenerARN     string
+	Priority        int
+	TargetGroupARN  string
+	TargetGroupARNs []string
+}
+
+// ForwardsTo returns whether the rule forwards any traffic to the target group.
+func (r ListenerRule) ForwardsTo(targetGroupARN string) bool {
+	for _, arn := range r.TargetGroupARNs {
+		if arn == targetGroupARN {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ListenerRules is a collection of listener rules.
+type ListenerRules []ListenerRule
+
 // CreateListenerParameters are the parameters required to create a new listener.
 type CreateListenerParameters struct {
 	CertificateARNs       []string
@@ -250,6 +277,79 @@ func (elbv2 SDKClient) DescribeRules(listenerARN string) []Rule {
 	return rules
 }
 
+// ListRules returns every rule on a listener, including the default rule.
+func (elbv2 SDKClient) ListRules(listenerARN string) (ListenerRules, error) {
+	var rules ListenerRules
+
+	input := &awselbv2.DescribeRulesInput{
+		ListenerArn: aws.String(listenerARN),
+	}
+
+	for {
+		resp, err := elbv2.client.DescribeRules(input)
+
+		if err != nil {
+			return rules, err
+		}
+
+		for _, r := range resp.Rules {
+			priority, _ := strconv.Atoi(aws.StringValue(r.Priority))
+			rule := ListenerRule{
+				ARN:         aws.StringValue(r.RuleArn),
+				IsDefault:   aws.BoolValue(r.IsDefault),
+				ListenerARN: listenerARN,
+				Priority:    priority,
+			}
+
+			if len(r.Actions) > 0 {
+				rule.TargetGroupARN = aws.StringValue(r.Actions[0].TargetGroupArn)
+			}
+
+			rule.TargetGroupARNs = actionTargetGroupARNs(r.Actions)
+
+			for _, c := range r.Conditions {
+				for _, v := range c.Values {
+					rule.Conditions = append(rule.Conditions, fmt.Sprintf("%s=%s", aws.StringValue(c.Field), aws.StringValue(v)))
+				}
+			}
+
+			rules = append(rules, rule)
+		}
+
+		if aws.StringValue(resp.NextMarker) == "" {
+			return rules, nil
+		}
+
+		input.SetMarker(aws.StringValue(resp.NextMarker))
+	}
+}
+
+// actionTargetGroupARNs returns the distinct target groups that actions forward
+// to, whether directly or weighted through a forward config.
+func actionTargetGroupARNs(actions []*awselbv2.Action) []string {
+	var arns []string
+
+	seen := make(map[string]bool)
+	add := func(arn string) {
+		if arn != "" && !seen[arn] {
+			seen[arn] = true
+			arns = append(arns, arn)
+		}
+	}
+
+	for _, action := range actions {
+		add(aws.StringValue(action.TargetGroupArn))
+
+		if action.ForwardConfig != nil {
+			for _, tg := range action.ForwardConfig.TargetGroups {
+				add(aws.StringValue(tg.TargetGroupArn))
+			}
+		}
+	}
+
+	return arns
+}
+
 func (elbv2 SDKClient) GetHighestPriorityFromListener(listenerARN string) int64 {
 	var priorities []int
 
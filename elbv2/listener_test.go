@@ -153,3 +153,106 @@ func TestCreateListeners(t *testing.T) {
 		t.Errorf("expected ARN %s, got %s", lbARN, arn)
 	}
 }
+
+func TestListRules(t *testing.T) {
+	listenerARN := "arn:aws:elasticloadbalancing:us-west-2:123456789012:listener/app/my-load-balancer/50dc6c495c0c9188/f2f7dc8efc522ab2"
+	targetGroupARN := "arn:aws:elasticloadbalancing:us-west-2:123456789012:targetgroup/my-targets/73e2d6bc24d8a067"
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockELBV2API := sdk.NewMockELBV2API(mockCtrl)
+	elbv2 := SDKClient{client: mockELBV2API}
+
+	firstPage := &awselbv2.DescribeRulesOutput{
+		Rules: []*awselbv2.Rule{
+			&awselbv2.Rule{
+				RuleArn:  aws.String("arn:rule/1"),
+				Priority: aws.String("10"),
+				Actions: []*awselbv2.Action{
+					&awselbv2.Action{TargetGroupArn: aws.String(targetGroupARN)},
+				},
+				Conditions: []*awselbv2.RuleCondition{
+					&awselbv2.RuleCondition{
+						Field:  aws.String("host-header"),
+						Values: aws.StringSlice([]string{"api.example.com"}),
+					},
+					&awselbv2.RuleCondition{
+						Field:  aws.String("path-pattern"),
+						Values: aws.StringSlice([]string{"/v1/*"}),
+					},
+				},
+			},
+		},
+		NextMarker: aws.String("next"),
+	}
+	secondPage := &awselbv2.DescribeRulesOutput{
+		Rules: []*awselbv2.Rule{
+			&awselbv2.Rule{
+				RuleArn:   aws.String("arn:rule/default"),
+				Priority:  aws.String("default"),
+				IsDefault: aws.Bool(true),
+				Actions: []*awselbv2.Action{
+					&awselbv2.Action{TargetGroupArn: aws.String(targetGroupARN)},
+				},
+			},
+		},
+	}
+
+	gomock.InOrder(
+		mockELBV2API.EXPECT().DescribeRules(&awselbv2.DescribeRulesInput{ListenerArn: aws.String(listenerARN)}).Return(firstPage, nil),
+		mockELBV2API.EXPECT().DescribeRules(&awselbv2.DescribeRulesInput{ListenerArn: aws.String(listenerARN), Marker: aws.String("next")}).Return(secondPage, nil),
+	)
+
+	rules, err := elbv2.ListRules(listenerARN)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := ListenerRules{
+		ListenerRule{
+			ARN:             "arn:rule/1",
+			Conditions:      []string{"host-header=api.example.com", "path-pattern=/v1/*"},
+			ListenerARN:     listenerARN,
+			Priority:        10,
+			TargetGroupARN:  targetGroupARN,
+			TargetGroupARNs: []string{targetGroupARN},
+		},
+		ListenerRule{
+			ARN:             "arn:rule/default",
+			IsDefault:       true,
+			ListenerARN:     listenerARN,
+			TargetGroupARN:  targetGroupARN,
+			TargetGroupARNs: []string{targetGroupARN},
+		},
+	}
+
+	if !reflect.DeepEqual(rules, expected) {
+		t.Errorf("expected %+v, got %+v", expected, rules)
+	}
+}
+
+func TestActionTargetGroupARNs(t *testing.T) {
+	actions := []*awselbv2.Action{
+		&awselbv2.Action{
+			TargetGroupArn: aws.String("arn:tg/web"),
+			ForwardConfig: &awselbv2.ForwardActionConfig{
+				TargetGroups: []*awselbv2.TargetGroupTuple{
+					&awselbv2.TargetGroupTuple{TargetGroupArn: aws.String("arn:tg/web")},
+					&awselbv2.TargetGroupTuple{TargetGroupArn: aws.String("arn:tg/canary")},
+				},
+			},
+		},
+	}
+
+	if got, want := actionTargetGroupARNs(actions), []string{"arn:tg/web", "arn:tg/canary"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	rule := ListenerRule{TargetGroupARNs: actionTargetGroupARNs(actions)}
+
+	if !rule.ForwardsTo("arn:tg/canary") || rule.ForwardsTo("arn:tg/api") {
+		t.Errorf("expected rule to forward to arn:tg/canary and not arn:tg/api, got %v", rule.TargetGroupARNs)
+	}
+}
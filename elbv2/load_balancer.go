@@ -2,6 +2,7 @@ package elbv2
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	awselbv2 "github.com/aws/aws-sdk-go/service/elbv2"
@@ -22,6 +23,20 @@ type LoadBalancer struct {
 	VPCID            string
 }
 
+// ValidateProtocol returns an error if the load balancer can't listen with the
+// given protocol. Only network load balancers support UDP and TCP_UDP, e.g.
+// for game servers, DNS, or SIP.
+func (lb LoadBalancer) ValidateProtocol(protocol string) error {
+	switch strings.ToUpper(protocol) {
+	case awselbv2.ProtocolEnumUdp, awselbv2.ProtocolEnumTcpUdp:
+		if lb.Type != awselbv2.LoadBalancerTypeEnumNetwork {
+			return fmt.Errorf("load balancer %s (type %s) can't listen on %s, which requires a network load balancer", lb.Name, lb.Type, strings.ToUpper(protocol))
+		}
+	}
+
+	return nil
+}
+
 // LoadBalancers is a collection of Elastic Load Balancing (v2) load balancers.
 type LoadBalancers []LoadBalancer
 
@@ -187,3 +187,30 @@ func TestCreateLoadBalancerWithError(t *testing.T) {
 		t.Fatalf("expected error, got none")
 	}
 }
+
+func TestLoadBalancerValidateProtocol(t *testing.T) {
+	var tests = []struct {
+		lbType   string
+		protocol string
+		valid    bool
+	}{
+		{"network", "UDP", true},
+		{"network", "tcp_udp", true},
+		{"network", "TCP", true},
+		{"application", "HTTPS", true},
+		{"application", "UDP", false},
+		{"application", "TCP_UDP", false},
+	}
+
+	for _, test := range tests {
+		err := LoadBalancer{Name: "web", Type: test.lbType}.ValidateProtocol(test.protocol)
+
+		if test.valid && err != nil {
+			t.Errorf("%s on %s: expected no error, got %v", test.protocol, test.lbType, err)
+		}
+
+		if !test.valid && err == nil {
+			t.Errorf("%s on %s: expected error, got none", test.protocol, test.lbType)
+		}
+	}
+}
@@ -14,6 +14,7 @@ import (
 type Client interface {
 	CreateListener(CreateListenerParameters) (string, error)
 	DescribeListeners(string) (Listeners, error)
+	ListRules(string) (ListenerRules, error)
 
 	DescribeLoadBalancers() (LoadBalancers, error)
 	DescribeLoadBalancersByName([]string) (LoadBalancers, error)
@@ -123,3 +123,18 @@ func (mr *MockClientMockRecorder) DescribeLoadBalancersByName(arg0 interface{})
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeLoadBalancersByName", reflect.TypeOf((*MockClient)(nil).DescribeLoadBalancersByName), arg0)
 }
+
+// ListRules mocks base method.
+func (m *MockClient) ListRules(arg0 string) (elbv2.ListenerRules, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRules", arg0)
+	ret0, _ := ret[0].(elbv2.ListenerRules)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRules indicates an expected call of ListRules.
+func (mr *MockClientMockRecorder) ListRules(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRules", reflect.TypeOf((*MockClient)(nil).ListRules), arg0)
+}
@@ -1,6 +1,12 @@
 package elbv2
 
 import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
 	"github.com/aws/aws-sdk-go/aws"
 	awselbv2 "github.com/aws/aws-sdk-go/service/elbv2"
 	"github.com/turnerlabs/fargate/console"
@@ -10,25 +16,166 @@ type TargetGroup struct {
 	Name            string
 	Arn             string
 	LoadBalancerARN string
+
+	HealthCheckIntervalSeconds int64
+	HealthCheckTimeoutSeconds  int64
+	HealthyThresholdCount      int64
+	UnhealthyThresholdCount    int64
+}
+
+// TargetHealth is the health of a single target registered with a target group.
+type TargetHealth struct {
+	Description string
+	ID          string
+	Port        int64
+	Reason      string
+	State       string
 }
 
+// TargetHealths is a collection of target health descriptions.
+type TargetHealths []TargetHealth
+
+// Healthy returns the number of targets that are passing health checks.
+func (t TargetHealths) Healthy() int {
+	var healthy int
+
+	for _, target := range t {
+		if target.State == awselbv2.TargetHealthStateEnumHealthy {
+			healthy++
+		}
+	}
+
+	return healthy
+}
+
+// TrafficPort is the health check port value that checks targets on the port they receive traffic on.
+const TrafficPort = "traffic-port"
+
+// CreateTargetGroupParameters configures a new target group. Health check
+// settings left empty use the ELB defaults.
 type CreateTargetGroupParameters struct {
-	Name     string
-	Port     int64
-	Protocol string
-	VPCID    string
+	Name            string
+	Port            int64
+	Protocol        string
+	VPCID           string
+	HealthCheckPort string
+	Tags            map[string]string
+
+	HealthCheckPath            string
+	HealthCheckIntervalSeconds int64
+	HealthyThresholdCount      int64
+	Matcher                    string
+}
+
+var matcherRegexp = regexp.MustCompile(`^[1-5][0-9]{2}(-[1-5][0-9]{2})?(,[1-5][0-9]{2}(-[1-5][0-9]{2})?)*$`)
+
+// ValidateHealthCheck returns an error for health check settings the target
+// group's protocol doesn't support or that are out of range. TCP, TLS, UDP,
+// and TCP_UDP health checks only test the connection, so they have no path or
+// matcher.
+func (i CreateTargetGroupParameters) ValidateHealthCheck() error {
+	if i.HealthCheckPort != "" {
+		if err := ValidateHealthCheckPort(i.HealthCheckPort); err != nil {
+			return err
+		}
+	}
+
+	if i.Protocol != "" && i.Protocol != awselbv2.ProtocolEnumHttp && i.Protocol != awselbv2.ProtocolEnumHttps {
+		if i.HealthCheckPath != "" || i.Matcher != "" {
+			return fmt.Errorf("health check path and matcher can't be used with %s target groups, which only check that targets accept connections", i.Protocol)
+		}
+	}
+
+	if i.HealthCheckPath != "" && !strings.HasPrefix(i.HealthCheckPath, "/") {
+		return fmt.Errorf("invalid health check path %s, must start with /", i.HealthCheckPath)
+	}
+
+	if i.HealthCheckIntervalSeconds != 0 && (i.HealthCheckIntervalSeconds < 5 || i.HealthCheckIntervalSeconds > 300) {
+		return fmt.Errorf("invalid health check interval %d, must be 5-300 seconds", i.HealthCheckIntervalSeconds)
+	}
+
+	if i.HealthyThresholdCount != 0 && (i.HealthyThresholdCount < 2 || i.HealthyThresholdCount > 10) {
+		return fmt.Errorf("invalid healthy threshold %d, must be 2-10", i.HealthyThresholdCount)
+	}
+
+	if i.Matcher != "" && !matcherRegexp.MatchString(i.Matcher) {
+		return fmt.Errorf("invalid health check matcher %s, must be HTTP codes such as 200, 200,204, or 200-299", i.Matcher)
+	}
+
+	return nil
+}
+
+// ValidateHealthCheckPort returns an error unless port is a valid port number or traffic-port.
+func ValidateHealthCheckPort(port string) error {
+	if port == TrafficPort {
+		return nil
+	}
+
+	number, err := strconv.ParseInt(port, 10, 64)
+
+	if err != nil || number < 1 || number > 65535 {
+		return fmt.Errorf("invalid health check port %s, must be a port number (1-65535) or %s", port, TrafficPort)
+	}
+
+	return nil
+}
+
+// tags converts tags to ELB tags, sorted by key.
+func tags(tags map[string]string) []*awselbv2.Tag {
+	var keys []string
+	var elbTags []*awselbv2.Tag
+
+	for key := range tags {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		elbTags = append(elbTags, &awselbv2.Tag{Key: aws.String(key), Value: aws.String(tags[key])})
+	}
+
+	return elbTags
 }
 
 func (elbv2 SDKClient) CreateTargetGroup(i CreateTargetGroupParameters) (string, error) {
-	resp, err := elbv2.client.CreateTargetGroup(
-		&awselbv2.CreateTargetGroupInput{
-			Name:       aws.String(i.Name),
-			Port:       aws.Int64(i.Port),
-			Protocol:   aws.String(i.Protocol),
-			TargetType: aws.String(awselbv2.TargetTypeEnumIp),
-			VpcId:      aws.String(i.VPCID),
-		},
-	)
+	input := &awselbv2.CreateTargetGroupInput{
+		Name:       aws.String(i.Name),
+		Port:       aws.Int64(i.Port),
+		Protocol:   aws.String(i.Protocol),
+		TargetType: aws.String(awselbv2.TargetTypeEnumIp),
+		VpcId:      aws.String(i.VPCID),
+	}
+
+	if err := i.ValidateHealthCheck(); err != nil {
+		return "", err
+	}
+
+	if i.HealthCheckPort != "" {
+		input.SetHealthCheckPort(i.HealthCheckPort)
+	}
+
+	if i.HealthCheckPath != "" {
+		input.SetHealthCheckPath(i.HealthCheckPath)
+	}
+
+	if i.HealthCheckIntervalSeconds != 0 {
+		input.SetHealthCheckIntervalSeconds(i.HealthCheckIntervalSeconds)
+	}
+
+	if i.HealthyThresholdCount != 0 {
+		input.SetHealthyThresholdCount(i.HealthyThresholdCount)
+	}
+
+	if i.Matcher != "" {
+		input.SetMatcher(&awselbv2.Matcher{HttpCode: aws.String(i.Matcher)})
+	}
+
+	if len(i.Tags) > 0 {
+		input.SetTags(tags(i.Tags))
+	}
+
+	resp, err := elbv2.client.CreateTargetGroup(input)
 
 	if err != nil {
 		return "", err
@@ -61,6 +208,52 @@ func (elbv2 SDKClient) DeleteTargetGroupByArn(targetGroupARN string) {
 	}
 }
 
+// SetHealthCheckPort changes the port the load balancer health checks a target
+// group's targets on, which is a port number or traffic-port.
+func (elbv2 SDKClient) SetHealthCheckPort(targetGroupARN, port string) error {
+	if err := ValidateHealthCheckPort(port); err != nil {
+		return err
+	}
+
+	_, err := elbv2.client.ModifyTargetGroup(
+		&awselbv2.ModifyTargetGroupInput{
+			TargetGroupArn:  aws.String(targetGroupARN),
+			HealthCheckPort: aws.String(port),
+		},
+	)
+
+	return err
+}
+
+// DeregisterTargets removes targets from a target group, so the load balancer
+// stops routing requests to them once connection draining completes.
+func (elbv2 SDKClient) DeregisterTargets(targetGroupARN string, targets TargetHealths) error {
+	var descriptions []*awselbv2.TargetDescription
+
+	if len(targets) == 0 {
+		return nil
+	}
+
+	for _, target := range targets {
+		description := &awselbv2.TargetDescription{Id: aws.String(target.ID)}
+
+		if target.Port != 0 {
+			description.Port = aws.Int64(target.Port)
+		}
+
+		descriptions = append(descriptions, description)
+	}
+
+	_, err := elbv2.client.DeregisterTargets(
+		&awselbv2.DeregisterTargetsInput{
+			TargetGroupArn: aws.String(targetGroupARN),
+			Targets:        descriptions,
+		},
+	)
+
+	return err
+}
+
 func (elbv2 SDKClient) GetTargetGroupArn(targetGroupName string) string {
 	resp, _ := elbv2.client.DescribeTargetGroups(
 		&awselbv2.DescribeTargetGroupsInput{
@@ -102,6 +295,11 @@ func (elbv2 SDKClient) DescribeTargetGroups(targetGroupARNs []string) []TargetGr
 		tg := TargetGroup{
 			Name: aws.StringValue(targetGroup.TargetGroupName),
 			Arn:  aws.StringValue(targetGroup.TargetGroupArn),
+
+			HealthCheckIntervalSeconds: aws.Int64Value(targetGroup.HealthCheckIntervalSeconds),
+			HealthCheckTimeoutSeconds:  aws.Int64Value(targetGroup.HealthCheckTimeoutSeconds),
+			HealthyThresholdCount:      aws.Int64Value(targetGroup.HealthyThresholdCount),
+			UnhealthyThresholdCount:    aws.Int64Value(targetGroup.UnhealthyThresholdCount),
 		}
 
 		if len(targetGroup.LoadBalancerArns) > 0 {
@@ -149,3 +347,35 @@ func (elbv2 SDKClient) describeTargetGroupByArn(targetGroupARN string) *awselbv2
 
 	return resp.TargetGroups[0]
 }
+
+// DescribeTargetHealth returns the health of each target registered with the given target group.
+func (elbv2 SDKClient) DescribeTargetHealth(targetGroupARN string) (TargetHealths, error) {
+	var targetHealths TargetHealths
+
+	resp, err := elbv2.client.DescribeTargetHealth(
+		&awselbv2.DescribeTargetHealthInput{
+			TargetGroupArn: aws.String(targetGroupARN),
+		},
+	)
+
+	if err != nil {
+		return targetHealths, err
+	}
+
+	for _, description := range resp.TargetHealthDescriptions {
+		targetHealth := TargetHealth{
+			ID:   aws.StringValue(description.Target.Id),
+			Port: aws.Int64Value(description.Target.Port),
+		}
+
+		if description.TargetHealth != nil {
+			targetHealth.Description = aws.StringValue(description.TargetHealth.Description)
+			targetHealth.Reason = aws.StringValue(description.TargetHealth.Reason)
+			targetHealth.State = aws.StringValue(description.TargetHealth.State)
+		}
+
+		targetHealths = append(targetHealths, targetHealth)
+	}
+
+	return targetHealths, nil
+}
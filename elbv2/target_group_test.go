@@ -84,3 +84,279 @@ func TestCreateTargetGroupError(t *testing.T) {
 		t.Errorf("expected empty ARN, got %s", arn)
 	}
 }
+
+func TestCreateTargetGroupWithHealthCheckPort(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockELBV2API := sdk.NewMockELBV2API(mockCtrl)
+	elbv2 := SDKClient{client: mockELBV2API}
+
+	i := &awselbv2.CreateTargetGroupInput{
+		Name:            aws.String("default"),
+		Port:            aws.Int64(8080),
+		Protocol:        aws.String("HTTP"),
+		TargetType:      aws.String("ip"),
+		VpcId:           aws.String("vpc-1234567"),
+		HealthCheckPort: aws.String("8081"),
+	}
+	o := &awselbv2.CreateTargetGroupOutput{
+		TargetGroups: []*awselbv2.TargetGroup{
+			&awselbv2.TargetGroup{
+				TargetGroupArn: aws.String("arn:aws:elasticloadbalancing:us-west-2:123456789012:targetgroup/default/73e2d6bc24d8a067"),
+			},
+		},
+	}
+
+	mockELBV2API.EXPECT().CreateTargetGroup(i).Return(o, nil)
+
+	_, err := elbv2.CreateTargetGroup(
+		CreateTargetGroupParameters{
+			Name:            "default",
+			Port:            int64(8080),
+			Protocol:        "HTTP",
+			VPCID:           "vpc-1234567",
+			HealthCheckPort: "8081",
+		},
+	)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestCreateTargetGroupWithInvalidHealthCheckPort(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockELBV2API := sdk.NewMockELBV2API(mockCtrl)
+	elbv2 := SDKClient{client: mockELBV2API}
+
+	_, err := elbv2.CreateTargetGroup(
+		CreateTargetGroupParameters{
+			Name:            "default",
+			Port:            int64(8080),
+			Protocol:        "HTTP",
+			VPCID:           "vpc-1234567",
+			HealthCheckPort: "70000",
+		},
+	)
+
+	if err == nil {
+		t.Fatalf("expected error, got none")
+	}
+}
+
+func TestCreateTargetGroupWithHealthCheck(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockELBV2API := sdk.NewMockELBV2API(mockCtrl)
+	elbv2 := SDKClient{client: mockELBV2API}
+
+	i := &awselbv2.CreateTargetGroupInput{
+		Name:                       aws.String("default"),
+		Port:                       aws.Int64(8080),
+		Protocol:                   aws.String("HTTP"),
+		TargetType:                 aws.String("ip"),
+		VpcId:                      aws.String("vpc-1234567"),
+		HealthCheckPath:            aws.String("/healthz"),
+		HealthCheckIntervalSeconds: aws.Int64(10),
+		HealthyThresholdCount:      aws.Int64(3),
+		Matcher:                    &awselbv2.Matcher{HttpCode: aws.String("200,204")},
+		Tags: []*awselbv2.Tag{
+			&awselbv2.Tag{Key: aws.String("managedBy"), Value: aws.String("fargate")},
+			&awselbv2.Tag{Key: aws.String("team"), Value: aws.String("web")},
+		},
+	}
+	o := &awselbv2.CreateTargetGroupOutput{
+		TargetGroups: []*awselbv2.TargetGroup{
+			&awselbv2.TargetGroup{
+				TargetGroupArn: aws.String("arn:aws:elasticloadbalancing:us-west-2:123456789012:targetgroup/default/73e2d6bc24d8a067"),
+			},
+		},
+	}
+
+	mockELBV2API.EXPECT().CreateTargetGroup(i).Return(o, nil)
+
+	_, err := elbv2.CreateTargetGroup(
+		CreateTargetGroupParameters{
+			Name:                       "default",
+			Port:                       int64(8080),
+			Protocol:                   "HTTP",
+			VPCID:                      "vpc-1234567",
+			HealthCheckPath:            "/healthz",
+			HealthCheckIntervalSeconds: 10,
+			HealthyThresholdCount:      3,
+			Matcher:                    "200,204",
+			Tags:                       map[string]string{"team": "web", "managedBy": "fargate"},
+		},
+	)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestValidateHealthCheck(t *testing.T) {
+	var tests = []struct {
+		name   string
+		params CreateTargetGroupParameters
+		valid  bool
+	}{
+		{"defaults", CreateTargetGroupParameters{Protocol: "HTTP"}, true},
+		{"http path and matcher", CreateTargetGroupParameters{Protocol: "HTTP", HealthCheckPath: "/healthz", Matcher: "200-299"}, true},
+		{"tcp interval", CreateTargetGroupParameters{Protocol: "TCP", HealthCheckIntervalSeconds: 30}, true},
+		{"tcp path", CreateTargetGroupParameters{Protocol: "TCP", HealthCheckPath: "/healthz"}, false},
+		{"tcp matcher", CreateTargetGroupParameters{Protocol: "TCP", Matcher: "200"}, false},
+		{"relative path", CreateTargetGroupParameters{Protocol: "HTTP", HealthCheckPath: "healthz"}, false},
+		{"short interval", CreateTargetGroupParameters{Protocol: "HTTP", HealthCheckIntervalSeconds: 1}, false},
+		{"high threshold", CreateTargetGroupParameters{Protocol: "HTTP", HealthyThresholdCount: 11}, false},
+		{"invalid matcher", CreateTargetGroupParameters{Protocol: "HTTP", Matcher: "ok"}, false},
+		{"invalid port", CreateTargetGroupParameters{Protocol: "HTTP", HealthCheckPort: "0"}, false},
+	}
+
+	for _, test := range tests {
+		if err := test.params.ValidateHealthCheck(); (err == nil) != test.valid {
+			t.Errorf("%s: expected valid %t, got %v", test.name, test.valid, err)
+		}
+	}
+}
+
+func TestValidateHealthCheckPort(t *testing.T) {
+	var tests = []struct {
+		port  string
+		valid bool
+	}{
+		{"traffic-port", true},
+		{"8081", true},
+		{"1", true},
+		{"65535", true},
+		{"0", false},
+		{"65536", false},
+		{"http", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		err := ValidateHealthCheckPort(test.port)
+
+		if test.valid && err != nil {
+			t.Errorf("ValidateHealthCheckPort(%q) returned %v, want nil", test.port, err)
+		}
+
+		if !test.valid && err == nil {
+			t.Errorf("ValidateHealthCheckPort(%q) returned nil, want error", test.port)
+		}
+	}
+}
+
+func TestDescribeTargetHealth(t *testing.T) {
+	targetGroupARN := "arn:aws:elasticloadbalancing:us-west-2:123456789012:targetgroup/my-targets/73e2d6bc24d8a067"
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockELBV2API := sdk.NewMockELBV2API(mockCtrl)
+	elbv2 := SDKClient{client: mockELBV2API}
+
+	i := &awselbv2.DescribeTargetHealthInput{
+		TargetGroupArn: aws.String(targetGroupARN),
+	}
+	o := &awselbv2.DescribeTargetHealthOutput{
+		TargetHealthDescriptions: []*awselbv2.TargetHealthDescription{
+			&awselbv2.TargetHealthDescription{
+				Target:       &awselbv2.TargetDescription{Id: aws.String("10.0.0.1"), Port: aws.Int64(80)},
+				TargetHealth: &awselbv2.TargetHealth{State: aws.String("healthy")},
+			},
+			&awselbv2.TargetHealthDescription{
+				Target: &awselbv2.TargetDescription{Id: aws.String("10.0.0.2"), Port: aws.Int64(80)},
+				TargetHealth: &awselbv2.TargetHealth{
+					State:  aws.String("unhealthy"),
+					Reason: aws.String("Target.FailedHealthChecks"),
+				},
+			},
+		},
+	}
+
+	mockELBV2API.EXPECT().DescribeTargetHealth(i).Return(o, nil)
+
+	targetHealths, err := elbv2.DescribeTargetHealth(targetGroupARN)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(targetHealths) != 2 {
+		t.Fatalf("expected 2 targets, got %d", len(targetHealths))
+	}
+
+	if targetHealths.Healthy() != 1 {
+		t.Errorf("expected 1 healthy target, got %d", targetHealths.Healthy())
+	}
+
+	if targetHealths[1].Reason != "Target.FailedHealthChecks" {
+		t.Errorf("expected reason Target.FailedHealthChecks, got %s", targetHealths[1].Reason)
+	}
+}
+
+func TestSetHealthCheckPort(t *testing.T) {
+	targetGroupARN := "arn:aws:elasticloadbalancing:us-west-2:123456789012:targetgroup/my-targets/73e2d6bc24d8a067"
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockELBV2API := sdk.NewMockELBV2API(mockCtrl)
+	elbv2 := SDKClient{client: mockELBV2API}
+
+	i := &awselbv2.ModifyTargetGroupInput{
+		TargetGroupArn:  aws.String(targetGroupARN),
+		HealthCheckPort: aws.String("8081"),
+	}
+
+	mockELBV2API.EXPECT().ModifyTargetGroup(i).Return(&awselbv2.ModifyTargetGroupOutput{}, nil)
+
+	if err := elbv2.SetHealthCheckPort(targetGroupARN, "8081"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := elbv2.SetHealthCheckPort(targetGroupARN, "0"); err == nil {
+		t.Error("expected error for port 0, got none")
+	}
+}
+
+func TestDeregisterTargets(t *testing.T) {
+	targetGroupARN := "arn:aws:elasticloadbalancing:us-west-2:123456789012:targetgroup/my-targets/73e2d6bc24d8a067"
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockELBV2API := sdk.NewMockELBV2API(mockCtrl)
+	elbv2 := SDKClient{client: mockELBV2API}
+
+	i := &awselbv2.DeregisterTargetsInput{
+		TargetGroupArn: aws.String(targetGroupARN),
+		Targets: []*awselbv2.TargetDescription{
+			&awselbv2.TargetDescription{Id: aws.String("10.0.0.1"), Port: aws.Int64(80)},
+			&awselbv2.TargetDescription{Id: aws.String("10.0.0.2"), Port: aws.Int64(80)},
+		},
+	}
+
+	mockELBV2API.EXPECT().DeregisterTargets(i).Return(&awselbv2.DeregisterTargetsOutput{}, nil)
+
+	err := elbv2.DeregisterTargets(
+		targetGroupARN,
+		TargetHealths{
+			TargetHealth{ID: "10.0.0.1", Port: 80},
+			TargetHealth{ID: "10.0.0.2", Port: 80},
+		},
+	)
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := elbv2.DeregisterTargets(targetGroupARN, nil); err != nil {
+		t.Errorf("expected no error with no targets, got %v", err)
+	}
+}
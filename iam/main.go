@@ -0,0 +1,19 @@
+package iam
+
+import (
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+)
+
+// SDKClient implements access to AWS Identity and Access Management via the AWS SDK.
+type SDKClient struct {
+	client iamiface.IAMAPI
+}
+
+// New returns an SDKClient configured with the given session.
+func New(sess *session.Session) SDKClient {
+	return SDKClient{
+		client: iam.New(sess),
+	}
+}
@@ -0,0 +1,442 @@
+package iam
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsiam "github.com/aws/aws-sdk-go/service/iam"
+	"github.com/turnerlabs/fargate/arn"
+)
+
+// EnvironmentFilesPolicyName is the inline policy on a task execution role
+// that grants access to the task's S3 environment files.
+const EnvironmentFilesPolicyName = "fargate-environment-files"
+
+// SecretsPolicyName is the inline policy on a task execution role that grants
+// access to the task's SSM parameters and Secrets Manager secrets.
+const SecretsPolicyName = "fargate-secrets"
+
+// EFSPolicyName is the inline policy on a task role that grants access to the
+// task's EFS file systems when they're mounted with IAM authorization.
+const EFSPolicyName = "fargate-efs"
+
+// ExecPolicyName is the inline policy on a task role that lets ECS Exec open
+// Session Manager channels to the task's containers.
+const ExecPolicyName = "fargate-exec"
+
+// execActions are the Session Manager actions ECS Exec needs in the task.
+var execActions = []string{
+	"ssmmessages:CreateControlChannel",
+	"ssmmessages:CreateDataChannel",
+	"ssmmessages:OpenControlChannel",
+	"ssmmessages:OpenDataChannel",
+}
+
+const (
+	efsClientMount               = "elasticfilesystem:ClientMount"
+	efsClientWrite               = "elasticfilesystem:ClientWrite"
+	ssmGetParameters             = "ssm:GetParameters"
+	secretsManagerGetSecretValue = "secretsmanager:GetSecretValue"
+)
+
+// ECSTasksPrincipal is the service principal ECS tasks assume roles as.
+const ECSTasksPrincipal = "ecs-tasks.amazonaws.com"
+
+type policyDocument struct {
+	Version   string
+	Statement []policyStatement
+}
+
+type policyStatement struct {
+	Effect   string
+	Action   []string
+	Resource []string
+}
+
+type trustPolicyDocument struct {
+	Statement []trustPolicyStatement
+}
+
+type trustPolicyStatement struct {
+	Effect    string
+	Action    json.RawMessage
+	Principal struct {
+		Service json.RawMessage
+	}
+}
+
+// RoleName returns the name of a role from its ARN, e.g. ecsTaskExecutionRole
+// for arn:aws:iam::123456789012:role/service/ecsTaskExecutionRole.
+func RoleName(roleArn string) string {
+	return arn.ResourceName(roleArn)
+}
+
+// RoleArn returns the ARN of a role given by name or ARN. ARNs are returned
+// as given, without checking the role exists.
+func (iam SDKClient) RoleArn(nameOrArn string) (string, error) {
+	if arn.IsARN(nameOrArn) {
+		return nameOrArn, nil
+	}
+
+	resp, err := iam.client.GetRole(
+		&awsiam.GetRoleInput{
+			RoleName: aws.String(nameOrArn),
+		},
+	)
+
+	if err != nil {
+		return "", err
+	}
+
+	return aws.StringValue(resp.Role.Arn), nil
+}
+
+// CanBeAssumedByECSTasks returns whether a role's trust policy allows ECS
+// tasks to assume it, i.e. whether it can be used as a task role.
+func (iam SDKClient) CanBeAssumedByECSTasks(roleArn string) (bool, error) {
+	resp, err := iam.client.GetRole(
+		&awsiam.GetRoleInput{
+			RoleName: aws.String(RoleName(roleArn)),
+		},
+	)
+
+	if err != nil {
+		return false, err
+	}
+
+	//policy documents are returned URL encoded
+	raw, err := url.QueryUnescape(aws.StringValue(resp.Role.AssumeRolePolicyDocument))
+
+	if err != nil {
+		return false, err
+	}
+
+	var document trustPolicyDocument
+
+	if err := json.Unmarshal([]byte(raw), &document); err != nil {
+		return false, fmt.Errorf("could not parse trust policy on role %s: %v", RoleName(roleArn), err)
+	}
+
+	for _, statement := range document.Statement {
+		if statement.Effect == "Allow" &&
+			containsStringOrList(statement.Action, "sts:AssumeRole") &&
+			containsStringOrList(statement.Principal.Service, ECSTasksPrincipal) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// GrantS3GetObject allows a role to read the given S3 objects. Objects already
+// granted by a previous call are kept.
+func (iam SDKClient) GrantS3GetObject(roleArn string, objectArns []string) error {
+	roleName := RoleName(roleArn)
+	existing, err := iam.policyResources(roleName, EnvironmentFilesPolicyName)
+
+	if err != nil {
+		return err
+	}
+
+	resources := appendMissing(existing["s3:GetObject"], objectArns...)
+
+	document, err := json.Marshal(
+		policyDocument{
+			Version: "2012-10-17",
+			Statement: []policyStatement{
+				policyStatement{
+					Effect:   "Allow",
+					Action:   []string{"s3:GetObject"},
+					Resource: resources,
+				},
+				policyStatement{
+					Effect:   "Allow",
+					Action:   []string{"s3:GetBucketLocation"},
+					Resource: bucketArns(resources),
+				},
+			},
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = iam.client.PutRolePolicy(
+		&awsiam.PutRolePolicyInput{
+			PolicyDocument: aws.String(string(document)),
+			PolicyName:     aws.String(EnvironmentFilesPolicyName),
+			RoleName:       aws.String(roleName),
+		},
+	)
+
+	return err
+}
+
+// GrantSecretsRead allows a role to read the given SSM parameters and Secrets
+// Manager secrets, referenced by the valueFrom of a container secret. Secrets
+// already granted by a previous call are kept. Secrets encrypted with a
+// customer managed KMS key also need kms:Decrypt, which isn't granted here.
+func (iam SDKClient) GrantSecretsRead(roleArn string, valueFroms []string) error {
+	roleName := RoleName(roleArn)
+	existing, err := iam.policyResources(roleName, SecretsPolicyName)
+
+	if err != nil {
+		return err
+	}
+
+	parameters := existing[ssmGetParameters]
+	secrets := existing[secretsManagerGetSecretValue]
+
+	for _, valueFrom := range valueFroms {
+		resource := SecretResourceArn(valueFrom)
+
+		switch arnService(resource) {
+		case "secretsmanager":
+			secrets = appendMissing(secrets, resource)
+		case "ssm":
+			parameters = appendMissing(parameters, resource)
+		default:
+			return fmt.Errorf("invalid secret %s [expected an SSM parameter or Secrets Manager secret ARN]", valueFrom)
+		}
+	}
+
+	document := policyDocument{Version: "2012-10-17"}
+
+	if len(parameters) > 0 {
+		document.Statement = append(document.Statement,
+			policyStatement{
+				Effect:   "Allow",
+				Action:   []string{ssmGetParameters},
+				Resource: parameters,
+			},
+		)
+	}
+
+	if len(secrets) > 0 {
+		document.Statement = append(document.Statement,
+			policyStatement{
+				Effect:   "Allow",
+				Action:   []string{secretsManagerGetSecretValue},
+				Resource: secrets,
+			},
+		)
+	}
+
+	if len(document.Statement) == 0 {
+		return nil
+	}
+
+	raw, err := json.Marshal(document)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = iam.client.PutRolePolicy(
+		&awsiam.PutRolePolicyInput{
+			PolicyDocument: aws.String(string(raw)),
+			PolicyName:     aws.String(SecretsPolicyName),
+			RoleName:       aws.String(roleName),
+		},
+	)
+
+	return err
+}
+
+// GrantEFSClientAccess allows a role to mount and write to the given EFS file
+// systems. ECS authorizes EFS mounts with the task role, not the execution
+// role. File systems already granted by a previous call are kept.
+func (iam SDKClient) GrantEFSClientAccess(roleArn string, fileSystemArns []string) error {
+	roleName := RoleName(roleArn)
+	existing, err := iam.policyResources(roleName, EFSPolicyName)
+
+	if err != nil {
+		return err
+	}
+
+	resources := appendMissing(existing[efsClientMount], fileSystemArns...)
+
+	document, err := json.Marshal(
+		policyDocument{
+			Version: "2012-10-17",
+			Statement: []policyStatement{
+				policyStatement{
+					Effect:   "Allow",
+					Action:   []string{efsClientMount, efsClientWrite},
+					Resource: resources,
+				},
+			},
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = iam.client.PutRolePolicy(
+		&awsiam.PutRolePolicyInput{
+			PolicyDocument: aws.String(string(document)),
+			PolicyName:     aws.String(EFSPolicyName),
+			RoleName:       aws.String(roleName),
+		},
+	)
+
+	return err
+}
+
+// GrantExecuteCommand allows ECS Exec sessions to be opened in tasks that run
+// with a role. The Session Manager channel actions don't support resource
+// level permissions, so they are granted on every resource.
+func (iam SDKClient) GrantExecuteCommand(roleArn string) error {
+	document, err := json.Marshal(
+		policyDocument{
+			Version: "2012-10-17",
+			Statement: []policyStatement{
+				policyStatement{
+					Effect:   "Allow",
+					Action:   execActions,
+					Resource: []string{"*"},
+				},
+			},
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = iam.client.PutRolePolicy(
+		&awsiam.PutRolePolicyInput{
+			PolicyDocument: aws.String(string(document)),
+			PolicyName:     aws.String(ExecPolicyName),
+			RoleName:       aws.String(RoleName(roleArn)),
+		},
+	)
+
+	return err
+}
+
+// SecretResourceArn returns the ARN to grant access to for a container
+// secret's valueFrom. Secrets Manager references can select a JSON key,
+// stage, or version after the secret ARN, e.g.
+// arn:aws:secretsmanager:us-east-1:123456789012:secret:db-AbCdEf:password::,
+// which aren't part of the secret's ARN.
+func SecretResourceArn(valueFrom string) string {
+	parsed, err := arn.Parse(valueFrom)
+
+	if err != nil || parsed.Service != "secretsmanager" {
+		return valueFrom
+	}
+
+	parts := strings.SplitN(parsed.ResourceID, ":", 2)
+	parsed.Resource = parsed.ResourceType + ":" + parts[0]
+
+	return parsed.String()
+}
+
+//arnService returns the service of an ARN, e.g. ssm, or "" if it isn't an ARN
+func arnService(s string) string {
+	parsed, err := arn.Parse(s)
+
+	if err != nil {
+		return ""
+	}
+
+	return parsed.Service
+}
+
+//policyResources returns the resources granted by an inline policy, by action
+func (iam SDKClient) policyResources(roleName, policyName string) (map[string][]string, error) {
+	resources := make(map[string][]string)
+	resp, err := iam.client.GetRolePolicy(
+		&awsiam.GetRolePolicyInput{
+			PolicyName: aws.String(policyName),
+			RoleName:   aws.String(roleName),
+		},
+	)
+
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == awsiam.ErrCodeNoSuchEntityException {
+			return resources, nil
+		}
+
+		return nil, err
+	}
+
+	//policy documents are returned URL encoded
+	raw, err := url.QueryUnescape(aws.StringValue(resp.PolicyDocument))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var document policyDocument
+
+	if err := json.Unmarshal([]byte(raw), &document); err != nil {
+		return nil, fmt.Errorf("could not parse policy %s on role %s: %v", policyName, roleName, err)
+	}
+
+	for _, statement := range document.Statement {
+		for _, action := range statement.Action {
+			resources[action] = append(resources[action], statement.Resource...)
+		}
+	}
+
+	return resources, nil
+}
+
+func appendMissing(values []string, add ...string) []string {
+	for _, value := range add {
+		if !contains(values, value) {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+func bucketArns(objectArns []string) []string {
+	var buckets []string
+
+	for _, objectArn := range objectArns {
+		bucket := strings.SplitN(objectArn, "/", 2)[0]
+
+		if !contains(buckets, bucket) {
+			buckets = append(buckets, bucket)
+		}
+	}
+
+	return buckets
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+//policy elements can be a single string or a list of strings
+func containsStringOrList(raw json.RawMessage, value string) bool {
+	var single string
+
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return single == value
+	}
+
+	var list []string
+
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return contains(list, value)
+	}
+
+	return false
+}
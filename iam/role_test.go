@@ -0,0 +1,389 @@
+package iam
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsiam "github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+)
+
+type mockIAMAPI struct {
+	iamiface.IAMAPI
+	policy    string
+	trust     string
+	getErr    error
+	putInput  *awsiam.PutRolePolicyInput
+	getPolicy *awsiam.GetRolePolicyInput
+}
+
+func (m *mockIAMAPI) GetRolePolicy(i *awsiam.GetRolePolicyInput) (*awsiam.GetRolePolicyOutput, error) {
+	m.getPolicy = i
+
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+
+	return &awsiam.GetRolePolicyOutput{PolicyDocument: aws.String(url.QueryEscape(m.policy))}, nil
+}
+
+func (m *mockIAMAPI) GetRole(i *awsiam.GetRoleInput) (*awsiam.GetRoleOutput, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+
+	return &awsiam.GetRoleOutput{
+		Role: &awsiam.Role{
+			Arn:                      aws.String("arn:aws:iam::123456789012:role/" + aws.StringValue(i.RoleName)),
+			AssumeRolePolicyDocument: aws.String(url.QueryEscape(m.trust)),
+			RoleName:                 i.RoleName,
+		},
+	}, nil
+}
+
+func (m *mockIAMAPI) PutRolePolicy(i *awsiam.PutRolePolicyInput) (*awsiam.PutRolePolicyOutput, error) {
+	m.putInput = i
+
+	return &awsiam.PutRolePolicyOutput{}, nil
+}
+
+func TestRoleName(t *testing.T) {
+	var tests = []struct {
+		arn  string
+		name string
+	}{
+		{"arn:aws:iam::123456789012:role/ecsTaskExecutionRole", "ecsTaskExecutionRole"},
+		{"arn:aws:iam::123456789012:role/service/ecsTaskExecutionRole", "ecsTaskExecutionRole"},
+	}
+
+	for _, test := range tests {
+		if name := RoleName(test.arn); name != test.name {
+			t.Errorf("expected %s, got %s", test.name, name)
+		}
+	}
+}
+
+func TestGrantS3GetObject(t *testing.T) {
+	mockIAM := &mockIAMAPI{
+		getErr: awserr.New(awsiam.ErrCodeNoSuchEntityException, "not found", nil),
+	}
+	iam := SDKClient{client: mockIAM}
+
+	err := iam.GrantS3GetObject(
+		"arn:aws:iam::123456789012:role/ecsTaskExecutionRole",
+		[]string{"arn:aws:s3:::my-bucket/app.env"},
+	)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if aws.StringValue(mockIAM.putInput.RoleName) != "ecsTaskExecutionRole" {
+		t.Errorf("expected role ecsTaskExecutionRole, got %s", aws.StringValue(mockIAM.putInput.RoleName))
+	}
+
+	if aws.StringValue(mockIAM.putInput.PolicyName) != EnvironmentFilesPolicyName {
+		t.Errorf("expected policy %s, got %s", EnvironmentFilesPolicyName, aws.StringValue(mockIAM.putInput.PolicyName))
+	}
+
+	var document policyDocument
+	json.Unmarshal([]byte(aws.StringValue(mockIAM.putInput.PolicyDocument)), &document)
+
+	if !reflect.DeepEqual(document.Statement[0].Resource, []string{"arn:aws:s3:::my-bucket/app.env"}) {
+		t.Errorf("expected object resource, got %v", document.Statement[0].Resource)
+	}
+
+	if !reflect.DeepEqual(document.Statement[1].Resource, []string{"arn:aws:s3:::my-bucket"}) {
+		t.Errorf("expected bucket resource, got %v", document.Statement[1].Resource)
+	}
+}
+
+func TestGrantS3GetObjectKeepsExistingObjects(t *testing.T) {
+	mockIAM := &mockIAMAPI{
+		policy: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:GetObject"],"Resource":["arn:aws:s3:::shared/base.env"]}]}`,
+	}
+	iam := SDKClient{client: mockIAM}
+
+	err := iam.GrantS3GetObject(
+		"arn:aws:iam::123456789012:role/ecsTaskExecutionRole",
+		[]string{"arn:aws:s3:::shared/base.env", "arn:aws:s3:::my-bucket/app.env"},
+	)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var document policyDocument
+	json.Unmarshal([]byte(aws.StringValue(mockIAM.putInput.PolicyDocument)), &document)
+
+	expected := []string{"arn:aws:s3:::shared/base.env", "arn:aws:s3:::my-bucket/app.env"}
+
+	if !reflect.DeepEqual(document.Statement[0].Resource, expected) {
+		t.Errorf("expected %v, got %v", expected, document.Statement[0].Resource)
+	}
+}
+
+func TestGrantS3GetObjectError(t *testing.T) {
+	mockIAM := &mockIAMAPI{getErr: errors.New("boom")}
+	iam := SDKClient{client: mockIAM}
+
+	err := iam.GrantS3GetObject(
+		"arn:aws:iam::123456789012:role/ecsTaskExecutionRole",
+		[]string{"arn:aws:s3:::my-bucket/app.env"},
+	)
+
+	if err == nil {
+		t.Error("expected error, got nil")
+	}
+
+	if mockIAM.putInput != nil {
+		t.Error("expected policy not to be updated")
+	}
+}
+
+func TestCanBeAssumedByECSTasks(t *testing.T) {
+	var tests = []struct {
+		name  string
+		trust string
+		want  bool
+	}{
+		{
+			"ecs tasks",
+			`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":"ecs-tasks.amazonaws.com"},"Action":"sts:AssumeRole"}]}`,
+			true,
+		},
+		{
+			"list of services",
+			`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":["lambda.amazonaws.com","ecs-tasks.amazonaws.com"]},"Action":["sts:AssumeRole"]}]}`,
+			true,
+		},
+		{
+			"other service",
+			`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":"lambda.amazonaws.com"},"Action":"sts:AssumeRole"}]}`,
+			false,
+		},
+		{
+			"denied",
+			`{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Principal":{"Service":"ecs-tasks.amazonaws.com"},"Action":"sts:AssumeRole"}]}`,
+			false,
+		},
+		{
+			"account principal",
+			`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":"arn:aws:iam::123456789012:root"},"Action":"sts:AssumeRole"}]}`,
+			false,
+		},
+	}
+
+	for _, test := range tests {
+		iam := SDKClient{client: &mockIAMAPI{trust: test.trust}}
+
+		got, err := iam.CanBeAssumedByECSTasks("arn:aws:iam::123456789012:role/app")
+
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", test.name, err)
+		}
+
+		if got != test.want {
+			t.Errorf("%s: expected %t, got %t", test.name, test.want, got)
+		}
+	}
+}
+
+func TestGrantSecretsRead(t *testing.T) {
+	mockIAM := &mockIAMAPI{
+		getErr: awserr.New(awsiam.ErrCodeNoSuchEntityException, "not found", nil),
+	}
+	iam := SDKClient{client: mockIAM}
+
+	err := iam.GrantSecretsRead(
+		"arn:aws:iam::123456789012:role/ecsTaskExecutionRole",
+		[]string{
+			"arn:aws:ssm:us-east-1:123456789012:parameter/my-app/db-password",
+			"arn:aws:secretsmanager:us-east-1:123456789012:secret:api-key-AbCdEf:token::",
+		},
+	)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if aws.StringValue(mockIAM.putInput.PolicyName) != SecretsPolicyName {
+		t.Errorf("expected policy %s, got %s", SecretsPolicyName, aws.StringValue(mockIAM.putInput.PolicyName))
+	}
+
+	var document policyDocument
+	json.Unmarshal([]byte(aws.StringValue(mockIAM.putInput.PolicyDocument)), &document)
+
+	expected := []policyStatement{
+		{
+			Effect:   "Allow",
+			Action:   []string{"ssm:GetParameters"},
+			Resource: []string{"arn:aws:ssm:us-east-1:123456789012:parameter/my-app/db-password"},
+		},
+		{
+			Effect:   "Allow",
+			Action:   []string{"secretsmanager:GetSecretValue"},
+			Resource: []string{"arn:aws:secretsmanager:us-east-1:123456789012:secret:api-key-AbCdEf"},
+		},
+	}
+
+	if !reflect.DeepEqual(document.Statement, expected) {
+		t.Errorf("expected %+v, got %+v", expected, document.Statement)
+	}
+}
+
+func TestGrantSecretsReadKeepsExistingSecrets(t *testing.T) {
+	mockIAM := &mockIAMAPI{
+		policy: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["secretsmanager:GetSecretValue"],"Resource":["arn:aws:secretsmanager:us-east-1:123456789012:secret:db-AbCdEf"]}]}`,
+	}
+	iam := SDKClient{client: mockIAM}
+
+	err := iam.GrantSecretsRead(
+		"arn:aws:iam::123456789012:role/ecsTaskExecutionRole",
+		[]string{"arn:aws:ssm:us-east-1:123456789012:parameter/my-app/db-password"},
+	)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var document policyDocument
+	json.Unmarshal([]byte(aws.StringValue(mockIAM.putInput.PolicyDocument)), &document)
+
+	if len(document.Statement) != 2 {
+		t.Fatalf("expected 2 statements, got %+v", document.Statement)
+	}
+
+	if !reflect.DeepEqual(document.Statement[1].Resource, []string{"arn:aws:secretsmanager:us-east-1:123456789012:secret:db-AbCdEf"}) {
+		t.Errorf("expected existing secret to be kept, got %v", document.Statement[1].Resource)
+	}
+}
+
+func TestGrantSecretsReadInvalidSecret(t *testing.T) {
+	mockIAM := &mockIAMAPI{
+		getErr: awserr.New(awsiam.ErrCodeNoSuchEntityException, "not found", nil),
+	}
+	iam := SDKClient{client: mockIAM}
+
+	err := iam.GrantSecretsRead("arn:aws:iam::123456789012:role/ecsTaskExecutionRole", []string{"db-password"})
+
+	if err == nil {
+		t.Error("expected error, got nil")
+	}
+
+	if mockIAM.putInput != nil {
+		t.Error("expected policy not to be updated")
+	}
+}
+
+func TestSecretResourceArn(t *testing.T) {
+	var tests = []struct {
+		valueFrom string
+		arn       string
+	}{
+		{"arn:aws:ssm:us-east-1:123456789012:parameter/db-password", "arn:aws:ssm:us-east-1:123456789012:parameter/db-password"},
+		{"arn:aws:secretsmanager:us-east-1:123456789012:secret:db-AbCdEf", "arn:aws:secretsmanager:us-east-1:123456789012:secret:db-AbCdEf"},
+		{"arn:aws:secretsmanager:us-east-1:123456789012:secret:db-AbCdEf:password::", "arn:aws:secretsmanager:us-east-1:123456789012:secret:db-AbCdEf"},
+	}
+
+	for _, test := range tests {
+		if arn := SecretResourceArn(test.valueFrom); arn != test.arn {
+			t.Errorf("SecretResourceArn(%s) => %s, want %s", test.valueFrom, arn, test.arn)
+		}
+	}
+}
+
+func TestGrantEFSClientAccess(t *testing.T) {
+	mockIAM := &mockIAMAPI{
+		policy: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["elasticfilesystem:ClientMount","elasticfilesystem:ClientWrite"],"Resource":["arn:aws:elasticfilesystem:us-east-1:123456789012:file-system/fs-11111111"]}]}`,
+	}
+	iam := SDKClient{client: mockIAM}
+
+	err := iam.GrantEFSClientAccess(
+		"arn:aws:iam::123456789012:role/my-app-task",
+		[]string{"arn:aws:elasticfilesystem:us-east-1:123456789012:file-system/fs-22222222"},
+	)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if aws.StringValue(mockIAM.putInput.PolicyName) != EFSPolicyName {
+		t.Errorf("expected policy %s, got %s", EFSPolicyName, aws.StringValue(mockIAM.putInput.PolicyName))
+	}
+
+	var document policyDocument
+	json.Unmarshal([]byte(aws.StringValue(mockIAM.putInput.PolicyDocument)), &document)
+
+	expected := []string{
+		"arn:aws:elasticfilesystem:us-east-1:123456789012:file-system/fs-11111111",
+		"arn:aws:elasticfilesystem:us-east-1:123456789012:file-system/fs-22222222",
+	}
+
+	if !reflect.DeepEqual(document.Statement[0].Resource, expected) {
+		t.Errorf("expected %v, got %v", expected, document.Statement[0].Resource)
+	}
+
+	if !reflect.DeepEqual(document.Statement[0].Action, []string{efsClientMount, efsClientWrite}) {
+		t.Errorf("expected mount and write actions, got %v", document.Statement[0].Action)
+	}
+}
+
+func TestGrantExecuteCommand(t *testing.T) {
+	mockIAM := &mockIAMAPI{}
+	iam := SDKClient{client: mockIAM}
+
+	if err := iam.GrantExecuteCommand("arn:aws:iam::123456789012:role/my-app-task"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if aws.StringValue(mockIAM.putInput.PolicyName) != ExecPolicyName {
+		t.Errorf("expected policy %s, got %s", ExecPolicyName, aws.StringValue(mockIAM.putInput.PolicyName))
+	}
+
+	if aws.StringValue(mockIAM.putInput.RoleName) != "my-app-task" {
+		t.Errorf("expected role my-app-task, got %s", aws.StringValue(mockIAM.putInput.RoleName))
+	}
+
+	var document policyDocument
+	json.Unmarshal([]byte(aws.StringValue(mockIAM.putInput.PolicyDocument)), &document)
+
+	if !reflect.DeepEqual(document.Statement[0].Action, execActions) {
+		t.Errorf("expected ssmmessages actions, got %v", document.Statement[0].Action)
+	}
+
+	if !reflect.DeepEqual(document.Statement[0].Resource, []string{"*"}) {
+		t.Errorf("expected all resources, got %v", document.Statement[0].Resource)
+	}
+}
+
+func TestRoleArn(t *testing.T) {
+	iam := SDKClient{client: &mockIAMAPI{}}
+
+	roleArn, err := iam.RoleArn("my-app-task")
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if roleArn != "arn:aws:iam::123456789012:role/my-app-task" {
+		t.Errorf("expected arn:aws:iam::123456789012:role/my-app-task, got %s", roleArn)
+	}
+
+	if roleArn, _ := iam.RoleArn("arn:aws:iam::123456789012:role/service/my-app-task"); roleArn != "arn:aws:iam::123456789012:role/service/my-app-task" {
+		t.Errorf("expected the ARN to be returned as given, got %s", roleArn)
+	}
+}
+
+func TestRoleArn_Error(t *testing.T) {
+	iam := SDKClient{client: &mockIAMAPI{getErr: errors.New("NoSuchEntity")}}
+
+	if _, err := iam.RoleArn("missing"); err == nil {
+		t.Errorf("expected error, got none")
+	}
+}
@@ -1,10 +1,9 @@
 package servicediscovery
 
 import (
-	"strings"
-
 	"github.com/aws/aws-sdk-go/aws"
 	awssd "github.com/aws/aws-sdk-go/service/servicediscovery"
+	"github.com/turnerlabs/fargate/arn"
 	"github.com/turnerlabs/fargate/console"
 )
 
@@ -23,8 +22,7 @@ type Service struct {
 func (sd *ServiceDiscovery) GetService(registryArn string) Service {
 	var service Service
 
-	arnSlice := strings.Split(registryArn, "/")
-	id := arnSlice[len(arnSlice)-1]
+	id := arn.ResourceName(registryArn)
 
 	resp, err := sd.svc.GetService(
 		&awssd.GetServiceInput{
@@ -0,0 +1,19 @@
+package ssm
+
+import (
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+// SDKClient implements access to AWS Systems Manager Parameter Store via the AWS SDK.
+type SDKClient struct {
+	client ssmiface.SSMAPI
+}
+
+// New returns an SDKClient configured with the given session.
+func New(sess *session.Session) SDKClient {
+	return SDKClient{
+		client: ssm.New(sess),
+	}
+}
@@ -0,0 +1,110 @@
+package ssm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsssm "github.com/aws/aws-sdk-go/service/ssm"
+)
+
+// Parameter is a value stored in Parameter Store.
+type Parameter struct {
+	ARN    string
+	Name   string
+	Secure bool
+	Value  string
+}
+
+// Key returns the last segment of the parameter's path, e.g. DB_HOST for /myapp/prod/DB_HOST.
+func (p Parameter) Key() string {
+	return p.Name[strings.LastIndex(p.Name, "/")+1:]
+}
+
+// GetParametersByPath returns every parameter under the given path, recursively.
+// SecureString parameters are not decrypted; reference them by ARN instead.
+func (ssm SDKClient) GetParametersByPath(path string) ([]Parameter, error) {
+	var parameters []Parameter
+
+	input := &awsssm.GetParametersByPathInput{
+		Path:           aws.String(path),
+		Recursive:      aws.Bool(true),
+		WithDecryption: aws.Bool(false),
+	}
+
+	err := ssm.client.GetParametersByPathPages(
+		input,
+		func(resp *awsssm.GetParametersByPathOutput, lastPage bool) bool {
+			for _, p := range resp.Parameters {
+				parameters = append(parameters, newParameter(p))
+			}
+
+			return true
+		},
+	)
+
+	return parameters, err
+}
+
+// getParametersLimit is the most parameters GetParameters accepts per call.
+const getParametersLimit = 10
+
+// GetParameters returns the named parameters, in the order given, or an error
+// naming any that don't exist. SecureString parameters are not decrypted;
+// reference them by ARN instead.
+func (ssm SDKClient) GetParameters(names []string) ([]Parameter, error) {
+	found := make(map[string]Parameter)
+	var missing []string
+
+	for start := 0; start < len(names); start += getParametersLimit {
+		end := start + getParametersLimit
+
+		if end > len(names) {
+			end = len(names)
+		}
+
+		resp, err := ssm.client.GetParameters(
+			&awsssm.GetParametersInput{
+				Names:          aws.StringSlice(names[start:end]),
+				WithDecryption: aws.Bool(false),
+			},
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range resp.Parameters {
+			found[aws.StringValue(p.Name)] = newParameter(p)
+		}
+
+		missing = append(missing, aws.StringValueSlice(resp.InvalidParameters)...)
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("SSM parameters not found: %s", strings.Join(missing, ", "))
+	}
+
+	var parameters []Parameter
+
+	for _, name := range names {
+		parameter, ok := found[name]
+
+		if !ok {
+			return nil, fmt.Errorf("SSM parameter not found: %s", name)
+		}
+
+		parameters = append(parameters, parameter)
+	}
+
+	return parameters, nil
+}
+
+func newParameter(p *awsssm.Parameter) Parameter {
+	return Parameter{
+		ARN:    aws.StringValue(p.ARN),
+		Name:   aws.StringValue(p.Name),
+		Secure: aws.StringValue(p.Type) == awsssm.ParameterTypeSecureString,
+		Value:  aws.StringValue(p.Value),
+	}
+}
@@ -0,0 +1,164 @@
+package ssm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsssm "github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+type mockSSMAPI struct {
+	ssmiface.SSMAPI
+	pages []*awsssm.GetParametersByPathOutput
+	err   error
+	input *awsssm.GetParametersByPathInput
+
+	parameters []*awsssm.Parameter
+	calls      [][]string
+}
+
+func (m *mockSSMAPI) GetParametersByPathPages(i *awsssm.GetParametersByPathInput, fn func(*awsssm.GetParametersByPathOutput, bool) bool) error {
+	m.input = i
+
+	for n, page := range m.pages {
+		if !fn(page, n == len(m.pages)-1) {
+			break
+		}
+	}
+
+	return m.err
+}
+
+func (m *mockSSMAPI) GetParameters(i *awsssm.GetParametersInput) (*awsssm.GetParametersOutput, error) {
+	names := aws.StringValueSlice(i.Names)
+	m.calls = append(m.calls, names)
+
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	resp := &awsssm.GetParametersOutput{}
+
+	for _, name := range names {
+		found := false
+
+		for _, p := range m.parameters {
+			if aws.StringValue(p.Name) == name {
+				resp.Parameters = append(resp.Parameters, p)
+				found = true
+			}
+		}
+
+		if !found {
+			resp.InvalidParameters = append(resp.InvalidParameters, aws.String(name))
+		}
+	}
+
+	return resp, nil
+}
+
+func TestGetParametersByPath(t *testing.T) {
+	mockSSM := &mockSSMAPI{
+		pages: []*awsssm.GetParametersByPathOutput{
+			&awsssm.GetParametersByPathOutput{
+				Parameters: []*awsssm.Parameter{
+					&awsssm.Parameter{
+						ARN:   aws.String("arn:aws:ssm:us-east-1:123456789012:parameter/myapp/prod/DB_HOST"),
+						Name:  aws.String("/myapp/prod/DB_HOST"),
+						Type:  aws.String(awsssm.ParameterTypeString),
+						Value: aws.String("db.example.com"),
+					},
+				},
+			},
+			&awsssm.GetParametersByPathOutput{
+				Parameters: []*awsssm.Parameter{
+					&awsssm.Parameter{
+						ARN:   aws.String("arn:aws:ssm:us-east-1:123456789012:parameter/myapp/prod/DB_PASSWORD"),
+						Name:  aws.String("/myapp/prod/DB_PASSWORD"),
+						Type:  aws.String(awsssm.ParameterTypeSecureString),
+						Value: aws.String("AQICAHh..."),
+					},
+				},
+			},
+		},
+	}
+	ssm := SDKClient{client: mockSSM}
+
+	parameters, err := ssm.GetParametersByPath("/myapp/prod/")
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if aws.StringValue(mockSSM.input.Path) != "/myapp/prod/" || !aws.BoolValue(mockSSM.input.Recursive) {
+		t.Errorf("expected recursive request for /myapp/prod/, got %v", mockSSM.input)
+	}
+
+	if len(parameters) != 2 {
+		t.Fatalf("expected 2 parameters, got %d", len(parameters))
+	}
+
+	if parameters[0].Key() != "DB_HOST" || parameters[0].Secure || parameters[0].Value != "db.example.com" {
+		t.Errorf("unexpected parameter %+v", parameters[0])
+	}
+
+	if parameters[1].Key() != "DB_PASSWORD" || !parameters[1].Secure {
+		t.Errorf("unexpected parameter %+v", parameters[1])
+	}
+}
+
+func TestGetParametersByPathError(t *testing.T) {
+	ssm := SDKClient{client: &mockSSMAPI{err: errors.New("boom")}}
+
+	if _, err := ssm.GetParametersByPath("/myapp/prod/"); err == nil {
+		t.Fatalf("expected error, got none")
+	}
+}
+
+func TestGetParameters(t *testing.T) {
+	mockSSM := &mockSSMAPI{}
+	var names []string
+
+	for i := 0; i < 12; i++ {
+		name := fmt.Sprintf("/myapp/prod/VAR_%d", i)
+		names = append(names, name)
+		mockSSM.parameters = append(mockSSM.parameters,
+			&awsssm.Parameter{
+				ARN:   aws.String("arn:aws:ssm:us-east-1:123456789012:parameter" + name),
+				Name:  aws.String(name),
+				Type:  aws.String(awsssm.ParameterTypeString),
+				Value: aws.String(fmt.Sprint(i)),
+			},
+		)
+	}
+
+	ssm := SDKClient{client: mockSSM}
+
+	parameters, err := ssm.GetParameters(names)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(mockSSM.calls) != 2 || len(mockSSM.calls[0]) != 10 || len(mockSSM.calls[1]) != 2 {
+		t.Errorf("expected requests for 10 and 2 parameters, got %v", mockSSM.calls)
+	}
+
+	if len(parameters) != 12 || parameters[11].Name != "/myapp/prod/VAR_11" || parameters[11].Value != "11" {
+		t.Errorf("unexpected parameters %+v", parameters)
+	}
+}
+
+func TestGetParametersNotFound(t *testing.T) {
+	ssm := SDKClient{client: &mockSSMAPI{}}
+
+	_, err := ssm.GetParameters([]string{"/myapp/prod/MISSING"})
+
+	if err == nil || !strings.Contains(err.Error(), "/myapp/prod/MISSING") {
+		t.Errorf("expected an error naming the missing parameter, got %v", err)
+	}
+}